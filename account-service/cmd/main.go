@@ -1,8 +1,13 @@
 package main
 
 import (
+	"context"
+
 	"github.com/danielkhtse/supreme-adventure/account-service/internal/api"
+	"github.com/danielkhtse/supreme-adventure/account-service/internal/grpcapi"
 	"github.com/danielkhtse/supreme-adventure/account-service/internal/service"
+	"github.com/danielkhtse/supreme-adventure/common/auth"
+	log "github.com/sirupsen/logrus"
 )
 
 func main() {
@@ -10,12 +15,37 @@ func main() {
 	// Initial Account Service, handle migrations
 	accountService := service.NewAccountService()
 
+	// Prune change feed events past their retention
+	accountService.StartEventRetention(context.Background())
+
+	// Process queued bulk account imports
+	accountService.StartImportWorker(context.Background())
+
+	// Verify bearer tokens against the configured JWKS, and API keys
+	authenticator, err := auth.FromEnv(context.Background(), accountService.APIKeys())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if authenticator == nil {
+		log.Warn("Authentication disabled, every request is served without credentials")
+	}
+
+	// Verify the service tokens of transaction-service on the internal transfer endpoints
+	serviceAuth, err := auth.ServiceAuthFromEnv("account-service")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if serviceAuth == nil {
+		log.Warn("Service authentication disabled, internal endpoints are served without service tokens")
+	}
+
+	// Initialize Accounts gRPC server
+	var grpcServer grpcapi.Server
+	grpcServer.Initialize(accountService, authenticator, serviceAuth)
+	go grpcServer.Run()
+
 	// Initialize Accounts API server
 	var server api.Server
-	server.Initialize(accountService)
+	server.Initialize(accountService, authenticator, serviceAuth)
 	server.Run()
-
-	// TODO: Account Service GRPC Server
-	// grpcServer := grpc.NewServer()
-	// grpcServer.Serve(lis)
 }
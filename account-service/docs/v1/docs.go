@@ -0,0 +1,1846 @@
+// Package v1 Code generated by swaggo/swag. DO NOT EDIT
+package v1
+
+import "github.com/swaggo/swag"
+
+const docTemplate = `{
+    "schemes": {{ marshal .Schemes }},
+    "swagger": "2.0",
+    "info": {
+        "description": "{{escape .Description}}",
+        "title": "{{.Title}}",
+        "contact": {},
+        "version": "{{.Version}}"
+    },
+    "host": "{{.Host}}",
+    "basePath": "{{.BasePath}}",
+    "paths": {
+        "/accounts": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List accounts for back-office tooling, filtered by status, currency, owner, balance and creation time.\nRequires a role on every account.\nPass next_cursor back as cursor, with the same filters and sort, to fetch the next page.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Account"
+                ],
+                "summary": "List accounts",
+                "parameters": [
+                    {
+                        "enum": [
+                            "active",
+                            "inactive"
+                        ],
+                        "type": "string",
+                        "description": "Account status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Currency code",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Owner reference",
+                        "name": "owner",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum balance (inclusive)",
+                        "name": "min_balance",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum balance (inclusive)",
+                        "name": "max_balance",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after (RFC 3339)",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before (RFC 3339)",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "created_at",
+                            "-created_at",
+                            "balance",
+                            "-balance"
+                        ],
+                        "type": "string",
+                        "description": "Sort order, a leading - sorts descending (default -created_at)",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor returned as next_cursor by the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of accounts (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.AccountPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter, sort, cursor or limit",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The caller may not view every account",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new account with initial balance, the caller becomes the owner of the account",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Account"
+                ],
+                "summary": "Create a new account",
+                "parameters": [
+                    {
+                        "description": "Account creation request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.CreateAccountRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created account",
+                        "schema": {
+                            "$ref": "#/definitions/api.AccountResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or account already exists",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/accounts/export": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stream every account matching the filters as CSV, for result sets too large to page through.\nRequires a role on every account.",
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "Account"
+                ],
+                "summary": "Export accounts as CSV",
+                "parameters": [
+                    {
+                        "enum": [
+                            "active",
+                            "inactive"
+                        ],
+                        "type": "string",
+                        "description": "Account status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Currency code",
+                        "name": "currency",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Owner reference",
+                        "name": "owner",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Minimum balance (inclusive)",
+                        "name": "min_balance",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum balance (inclusive)",
+                        "name": "max_balance",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after (RFC 3339)",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before (RFC 3339)",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "created_at",
+                            "-created_at",
+                            "balance",
+                            "-balance"
+                        ],
+                        "type": "string",
+                        "description": "Sort order, a leading - sorts descending (default -created_at)",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "CSV with a header row: id, owner, status, currency, balance, initial_balance, version, created_at, updated_at",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filter or sort",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The caller may not view every account",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/accounts/imports": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Upload a CSV or JSONL file of accounts to create them in the background. Columns (CSV header) or keys (JSONL) are account_id, initial_balance, currency and owner; only account_id is required.\nEvery row is validated with the account rules. Rejected rows are listed in the error report, accounts that already exist with the same attributes are skipped so a file can be imported again.\nWith dry_run the rows are validated and reported without creating accounts. A failed import can be resumed after its last committed chunk.",
+                "consumes": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Account Import"
+                ],
+                "summary": "Import accounts",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "jsonl"
+                        ],
+                        "type": "string",
+                        "description": "csv or jsonl, derived from Content-Type when omitted",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Validate and report without creating accounts",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Rows committed per transaction (default 500, max 1000)",
+                        "name": "chunk_size",
+                        "in": "query"
+                    },
+                    {
+                        "description": "CSV or JSONL file",
+                        "name": "file",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Queued import",
+                        "schema": {
+                            "$ref": "#/definitions/models.AccountImport"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid parameters, header or file",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "File larger than 64 MiB",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/accounts/imports/{import_id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Progress of an import: rows processed so far and how many were created, skipped or rejected.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Account Import"
+                ],
+                "summary": "Get an account import",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Import ID",
+                        "name": "import_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AccountImport"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid import ID",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Import not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/accounts/imports/{import_id}/errors": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Rejected rows of an import in file order, with every problem of the row. Pass next_cursor back as cursor to fetch the next page.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Account Import"
+                ],
+                "summary": "List the errors of an account import",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Import ID",
+                        "name": "import_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor returned as next_cursor by the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of entries (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.ImportErrorPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid import ID, cursor or limit",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Import not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/accounts/imports/{import_id}/resume": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Queue a failed import again, it continues after the last committed chunk.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Account Import"
+                ],
+                "summary": "Resume an account import",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Import ID",
+                        "name": "import_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Queued import",
+                        "schema": {
+                            "$ref": "#/definitions/models.AccountImport"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid import ID",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Import not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Import has not failed",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/accounts/{account_id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get account details by ID. The ETag header carries the account version, send it as If-None-Match to receive 304 when unchanged.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Account"
+                ],
+                "summary": "Get account details by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "account_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of a previously fetched version",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Account details",
+                        "schema": {
+                            "$ref": "#/definitions/api.AccountResponse"
+                        }
+                    },
+                    "304": {
+                        "description": "Account not modified"
+                    },
+                    "400": {
+                        "description": "Invalid account ID format",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The caller holds no role on the account",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Account not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/accounts/{account_id}/activity": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Balance movements of an account, newest first, with counterparty, direction, amount, balance after the movement and the linked transaction.\nPass next_cursor back as cursor to fetch older activity.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Account"
+                ],
+                "summary": "List account activity",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "account_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor returned as next_cursor by the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of entries (default 50, max 200)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.ActivityPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid account ID, cursor or limit",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The caller holds no role on the account",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Account not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/accounts/{account_id}/grants": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the principals holding a role on an account. Principals holding a role on every account through AUTHZ_ROLES are not listed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Account"
+                ],
+                "summary": "List the roles granted on an account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "account_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.AccountGrant"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid account ID format",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The caller holds no role on the account",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Account not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Give a principal a role on an account, replacing the role it held. Only owners and admins can grant roles.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Account"
+                ],
+                "summary": "Grant a role on an account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "account_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Role to grant",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.GrantAccountRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AccountGrant"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid account ID, principal or role",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The caller may not manage the account",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Account not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove the role of a principal on an account. Only owners and admins can revoke roles.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Account"
+                ],
+                "summary": "Revoke a role on an account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "account_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Principal whose role is revoked",
+                        "name": "principal",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid account ID format or missing principal",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The caller may not manage the account",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Account not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/accounts/{account_id}/statements/camt053": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "ISO 20022 camt.053.001.08 end-of-day statement of an account for a past UTC day: opening and closing booked balances (OPBD, CLBD), a summary and one booked entry per balance movement.\nEntries of transfers carry the transfer ID as EndToEndId, the transaction ID as TxId, the counterparty account and the transaction description as unstructured remittance information.",
+                "produces": [
+                    "application/xml"
+                ],
+                "tags": [
+                    "Statement"
+                ],
+                "summary": "Get a camt.053 statement",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "account_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Statement day (YYYY-MM-DD, UTC), yesterday by default",
+                        "name": "date",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "camt.053.001.08 document",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid account ID or date",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The caller holds no role on the account",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Account not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/accounts/{account_id}/statements/camt054": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "ISO 20022 camt.054.001.08 debit/credit notification of the balance movements of an account in a period of at most 31 days, with the entries described like in camt.053 statements.",
+                "produces": [
+                    "application/xml"
+                ],
+                "tags": [
+                    "Statement"
+                ],
+                "summary": "Get a camt.054 notification",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "account_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the period, inclusive (RFC 3339)",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the period, exclusive (RFC 3339), now by default",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "debit",
+                            "credit"
+                        ],
+                        "type": "string",
+                        "description": "Notify debits or credits only",
+                        "name": "direction",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "camt.054.001.08 document",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid account ID, period or direction",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The caller holds no role on the account",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Account not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/accounts/{account_id}/status": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Set the account status to inactive (frozen) or active. Send the account ETag as If-Match to fail with 412 when it was modified concurrently.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Account"
+                ],
+                "summary": "Freeze or unfreeze an account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "account_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag the account must still have",
+                        "name": "If-Match",
+                        "in": "header"
+                    },
+                    {
+                        "description": "New account status",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.UpdateAccountStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated account",
+                        "schema": {
+                            "$ref": "#/definitions/api.AccountResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The caller may not manage the account",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Account not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Lock timeout",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Account version does not match If-Match",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/accounts/{account_id}/stream": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stream balance changes of an account as server-sent events, or as WebSocket JSON messages when the request is a WebSocket upgrade.\nEach event carries its ID, reconnect with the Last-Event-ID header (or last_event_id query parameter) to resume.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Account"
+                ],
+                "summary": "Stream account balance changes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "account_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resume after this event ID",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resume after this event ID, for clients unable to set headers",
+                        "name": "last_event_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stream of account.balance_changed events",
+                        "schema": {
+                            "$ref": "#/definitions/events.Event"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid account ID or last event ID format",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not allowed to access this account",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Account not found",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/events": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ordered change feed of account events (account.created, transfer.applied, account.balance_changed).\nPass next_cursor back as after to continue, with wait to long-poll until new events arrive.\nWithout account_id the feed of every account is returned, which requires a role on every account.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Event"
+                ],
+                "summary": "List account events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only events affecting this account",
+                        "name": "account_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor returned as next_cursor by the previous call, latest to start after the newest event, omit to start from the oldest retained event",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum number of events (default 100, max 1000)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Seconds to wait for new events when none are available (max 30)",
+                        "name": "wait",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/feed.Page"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid account ID, cursor, limit or wait",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The caller may not view the account, or every account without account_id",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Events after the cursor were deleted by the retention period",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/health-check": {
+            "get": {
+                "description": "Check if the API is healthy",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Check API health status",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/response.ProblemResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.AccountResponse": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "description": "The unique identifier of the account",
+                    "type": "string",
+                    "example": "0"
+                },
+                "balance": {
+                    "description": "The current balance in smallest currency units (e.g. cents for USD)",
+                    "type": "integer"
+                },
+                "currency": {
+                    "description": "The ISO 4217 currency of the balance",
+                    "type": "string"
+                },
+                "version": {
+                    "description": "The account version, increased on every balance or status change and also returned as the ETag header",
+                    "type": "integer"
+                }
+            }
+        },
+        "api.CreateAccountRequest": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "description": "The unique identifier for the new account, generated by the server when omitted",
+                    "type": "string",
+                    "example": "7300512345678901"
+                },
+                "initial_balance": {
+                    "description": "The initial balance in smallest currency units (e.g. cents for USD)",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "owner": {
+                    "description": "Optional reference to the customer owning the account, used to search accounts",
+                    "type": "string",
+                    "maxLength": 64
+                }
+            }
+        },
+        "api.GrantAccountRoleRequest": {
+            "type": "object",
+            "required": [
+                "principal",
+                "role"
+            ],
+            "properties": {
+                "principal": {
+                    "description": "Subject of the caller receiving the role, api-key:\u003cid\u003e for an API key",
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "role": {
+                    "description": "owner (view, debit and manage roles), operator (view and debit) or auditor (view)",
+                    "enum": [
+                        "owner",
+                        "operator",
+                        "auditor"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.AccountRole"
+                        }
+                    ]
+                }
+            }
+        },
+        "api.UpdateAccountStatusRequest": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "status": {
+                    "description": "The new status, transfers from and to inactive (frozen) accounts are rejected",
+                    "enum": [
+                        "active",
+                        "inactive"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.AccountStatus"
+                        }
+                    ]
+                }
+            }
+        },
+        "events.Event": {
+            "type": "object",
+            "properties": {
+                "account_ids": {
+                    "description": "The accounts affected by the event",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "data": {
+                    "description": "Event specific payload",
+                    "type": "object"
+                },
+                "id": {
+                    "description": "Time-ordered unique identifier of the event",
+                    "type": "string",
+                    "example": "0"
+                },
+                "occurred_at": {
+                    "description": "When the change happened",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "The kind of event",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/events.Type"
+                        }
+                    ]
+                }
+            }
+        },
+        "events.Type": {
+            "type": "string",
+            "enum": [
+                "account.created",
+                "account.balance_changed",
+                "transfer.applied",
+                "transaction.status_changed"
+            ],
+            "x-enum-varnames": [
+                "TypeAccountCreated",
+                "TypeAccountBalanceChanged",
+                "TypeTransferApplied",
+                "TypeTransactionStatusChanged"
+            ]
+        },
+        "feed.Page": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "description": "Events in commit order",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/events.Event"
+                    }
+                },
+                "has_more": {
+                    "description": "Whether more events were available than the limit allowed",
+                    "type": "boolean"
+                },
+                "next_cursor": {
+                    "description": "Cursor to pass as after to continue reading, equal to the request cursor when no events were returned",
+                    "type": "string"
+                }
+            }
+        },
+        "models.Account": {
+            "type": "object",
+            "required": [
+                "currency",
+                "id",
+                "status"
+            ],
+            "properties": {
+                "balance": {
+                    "description": "We will store the smallest units for the currency (e.g. cents for USD)",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "currency": {
+                    "description": "We simply support USD for now",
+                    "type": "string",
+                    "enum": [
+                        "USD"
+                    ]
+                },
+                "id": {
+                    "type": "string",
+                    "example": "0"
+                },
+                "initial_balance": {
+                    "description": "audit trail for the initial balance. TODO: discussion, reflect from transactions for audit trail",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "owner": {
+                    "description": "opaque reference to the customer owning the account",
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "status": {
+                    "enum": [
+                        "active",
+                        "inactive"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.AccountStatus"
+                        }
+                    ]
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "bumped on every balance or status mutation for optimistic concurrency control",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.AccountActivity": {
+            "type": "object",
+            "required": [
+                "account_id",
+                "amount",
+                "direction",
+                "id",
+                "type"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "string",
+                    "example": "0"
+                },
+                "amount": {
+                    "description": "always positive, the direction gives the sign",
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "balance_after": {
+                    "description": "balance of the account right after the movement",
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "counterparty_account_id": {
+                    "description": "zero for the opening balance",
+                    "type": "string",
+                    "example": "0"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "direction": {
+                    "enum": [
+                        "credit",
+                        "debit"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.ActivityDirection"
+                        }
+                    ]
+                },
+                "id": {
+                    "type": "string",
+                    "example": "0"
+                },
+                "transaction_id": {
+                    "description": "transaction-service transaction that caused the movement",
+                    "type": "string",
+                    "example": "0"
+                },
+                "transfer_id": {
+                    "description": "caller supplied transfer ID",
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "type": {
+                    "enum": [
+                        "opening",
+                        "transfer"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.ActivityType"
+                        }
+                    ]
+                }
+            }
+        },
+        "models.AccountGrant": {
+            "type": "object",
+            "required": [
+                "account_id",
+                "principal",
+                "role"
+            ],
+            "properties": {
+                "account_id": {
+                    "type": "string",
+                    "example": "0"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "granted_by": {
+                    "description": "subject which granted the role",
+                    "type": "string"
+                },
+                "principal": {
+                    "description": "subject of the caller, or api-key:\u003cid\u003e",
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "role": {
+                    "enum": [
+                        "owner",
+                        "operator",
+                        "auditor"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/types.AccountRole"
+                        }
+                    ]
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AccountImport": {
+            "type": "object",
+            "properties": {
+                "chunk_size": {
+                    "type": "integer"
+                },
+                "completed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "created_rows": {
+                    "description": "accounts created, or that would be created by a dry run",
+                    "type": "integer"
+                },
+                "dry_run": {
+                    "description": "validate every row without creating accounts",
+                    "type": "boolean"
+                },
+                "failed_rows": {
+                    "description": "rows listed in the error report",
+                    "type": "integer"
+                },
+                "format": {
+                    "$ref": "#/definitions/types.AccountImportFormat"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "0"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "processed_rows": {
+                    "description": "Checkpoint, the first ProcessedRows rows of the file are done",
+                    "type": "integer"
+                },
+                "skipped_rows": {
+                    "description": "accounts that already existed with the same attributes",
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/types.AccountImportStatus"
+                },
+                "total_rows": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AccountImportError": {
+            "type": "object",
+            "properties": {
+                "account_id": {
+                    "type": "string",
+                    "example": "0"
+                },
+                "line": {
+                    "description": "Line of the row in the file, the CSV header is line 1",
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "response.ProblemResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Stable machine-readable error code",
+                    "type": "string",
+                    "example": "insufficient_funds"
+                },
+                "detail": {
+                    "description": "Explanation specific to this occurrence of the problem",
+                    "type": "string",
+                    "example": "insufficient balance"
+                },
+                "message": {
+                    "description": "Message contains details about what went wrong",
+                    "type": "string",
+                    "example": "Invalid request parameters"
+                },
+                "status": {
+                    "description": "HTTP status code",
+                    "type": "integer",
+                    "example": 400
+                },
+                "title": {
+                    "description": "Short summary of the problem type, the reason phrase of the status",
+                    "type": "string",
+                    "example": "Bad Request"
+                },
+                "type": {
+                    "description": "URI reference identifying the problem type, derived from the code",
+                    "type": "string",
+                    "example": "urn:supreme-adventure:problem:insufficient_funds"
+                }
+            }
+        },
+        "service.AccountPage": {
+            "type": "object",
+            "properties": {
+                "accounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Account"
+                    }
+                },
+                "has_more": {
+                    "description": "Whether more accounts match the filter",
+                    "type": "boolean"
+                },
+                "next_cursor": {
+                    "description": "Opaque cursor of the next page, empty on the last page",
+                    "type": "string"
+                }
+            }
+        },
+        "service.ActivityPage": {
+            "type": "object",
+            "properties": {
+                "activities": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AccountActivity"
+                    }
+                },
+                "has_more": {
+                    "description": "Whether older activity exists",
+                    "type": "boolean"
+                },
+                "next_cursor": {
+                    "description": "Opaque cursor of the next (older) page, empty on the last page",
+                    "type": "string"
+                }
+            }
+        },
+        "service.ImportErrorPage": {
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.AccountImportError"
+                    }
+                },
+                "has_more": {
+                    "description": "Whether more errors exist",
+                    "type": "boolean"
+                },
+                "next_cursor": {
+                    "description": "Opaque cursor of the next page, empty on the last page",
+                    "type": "string"
+                }
+            }
+        },
+        "types.AccountImportFormat": {
+            "type": "string",
+            "enum": [
+                "csv",
+                "jsonl"
+            ],
+            "x-enum-varnames": [
+                "AccountImportFormatCSV",
+                "AccountImportFormatJSONL"
+            ]
+        },
+        "types.AccountImportStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "running",
+                "completed",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "AccountImportStatusPending",
+                "AccountImportStatusRunning",
+                "AccountImportStatusCompleted",
+                "AccountImportStatusFailed"
+            ]
+        },
+        "types.AccountRole": {
+            "type": "string",
+            "enum": [
+                "owner",
+                "operator",
+                "auditor",
+                "admin"
+            ],
+            "x-enum-varnames": [
+                "AccountRoleOwner",
+                "AccountRoleOperator",
+                "AccountRoleAuditor",
+                "AccountRoleAdmin"
+            ]
+        },
+        "types.AccountStatus": {
+            "type": "string",
+            "enum": [
+                "active",
+                "inactive"
+            ],
+            "x-enum-varnames": [
+                "AccountStatusActive",
+                "AccountStatusInactive"
+            ]
+        },
+        "types.ActivityDirection": {
+            "type": "string",
+            "enum": [
+                "credit",
+                "debit"
+            ],
+            "x-enum-varnames": [
+                "ActivityDirectionCredit",
+                "ActivityDirectionDebit"
+            ]
+        },
+        "types.ActivityType": {
+            "type": "string",
+            "enum": [
+                "opening",
+                "transfer"
+            ],
+            "x-enum-varnames": [
+                "ActivityTypeOpening",
+                "ActivityTypeTransfer"
+            ]
+        }
+    },
+    "securityDefinitions": {
+        "BearerAuth": {
+            "description": "JWT bearer token, \"Bearer \u003ctoken\u003e\"",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
+    }
+}`
+
+// SwaggerInfo holds exported Swagger Info so clients can modify it
+var SwaggerInfo = &swag.Spec{
+	Version:          "1.0",
+	Host:             "",
+	BasePath:         "/v1",
+	Schemes:          []string{},
+	Title:            "Account Service API",
+	Description:      "Accounts, balances and transfers, with their activity, streams and change feed",
+	InfoInstanceName: "swagger",
+	SwaggerTemplate:  docTemplate,
+	LeftDelim:        "{{",
+	RightDelim:       "}}",
+}
+
+func init() {
+	swag.Register(SwaggerInfo.InstanceName(), SwaggerInfo)
+}
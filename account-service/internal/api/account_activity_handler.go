@@ -0,0 +1,55 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/danielkhtse/supreme-adventure/common/authz"
+	"github.com/danielkhtse/supreme-adventure/common/pagination"
+	"github.com/danielkhtse/supreme-adventure/common/response"
+	log "github.com/sirupsen/logrus"
+)
+
+// @Summary List account activity
+// @Description Balance movements of an account, newest first, with counterparty, direction, amount, balance after the movement and the linked transaction.
+// @Description Pass next_cursor back as cursor to fetch older activity.
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param account_id path string true "Account ID"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param limit query int false "Maximum number of entries (default 50, max 200)"
+// @Success 200 {object} service.ActivityPage
+// @Failure 400 {object} response.ProblemResponse "Invalid account ID, cursor or limit"
+// @Failure 403 {object} response.ProblemResponse "The caller holds no role on the account"
+// @Failure 404 {object} response.ProblemResponse "Account not found"
+// @Failure 500 {object} response.ProblemResponse "Internal server error"
+// @Security BearerAuth
+// @Router /accounts/{account_id}/activity [get]
+func (s *Server) ListAccountActivityHandler(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := s.authorizeAccount(w, r, authz.ActionView)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+
+	limit := 0
+	if value := query.Get("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > pagination.MaxLimit {
+			response.SendError(w, response.StatusBadRequest, "limit must be between 1 and 200")
+			return
+		}
+	}
+
+	page, err := s.AccountService.ListAccountActivity(r.Context(), accountID, query.Get("cursor"), limit)
+	if err != nil {
+		log.WithError(err).Error("failed to list account activity")
+		response.SendProblem(w, err)
+		return
+	}
+
+	response.SendSuccess(w, response.StatusOK, page)
+}
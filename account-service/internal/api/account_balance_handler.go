@@ -4,34 +4,34 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
-	"strings"
 
+	"github.com/danielkhtse/supreme-adventure/account-service/internal/service"
+	"github.com/danielkhtse/supreme-adventure/common/authz"
 	"github.com/danielkhtse/supreme-adventure/common/response"
 	"github.com/danielkhtse/supreme-adventure/common/types"
 	"github.com/danielkhtse/supreme-adventure/common/validation"
 	"github.com/gorilla/mux"
 )
 
-// @Summary Transfer funds between accounts
-// @Description Transfer funds from source account to destination account
-// @Tags Account
-// @Accept json
-// @Produce json
-// @Param account_id path string true "Source Account ID"
-// @Param request body TransferFundsRequest true "Transfer request details"
-// @Success 200
-// @Failure 400 {object} response.ErrorResponse "Invalid request parameters or insufficient balance"
-// @Failure 404 {object} response.ErrorResponse "Source or destination account not found"
-// @Failure 500 {object} response.ErrorResponse "Internal server error"
-// @Router /accounts/{account_id}/transfer [post]
+// TransferFundsRequest represents the request body of an internal transfer
 type TransferFundsRequest struct {
 	// The destination account ID to transfer funds to
-	DestAccountID types.AccountID `json:"dest_account_id" validate:"required,uuid"` // @example 12345
+	DestAccountID types.AccountID `json:"dest_account_id,string" validate:"required" example:"12345"` // @example 12345
 
 	// The amount to transfer in smallest currency units (e.g. cents for USD)
 	Amount types.AccountBalance `json:"amount" validate:"required,min=1"` // @example 1000
+
+	// Optional caller supplied ID, a transfer already applied under the same ID is not applied again
+	TransferID string `json:"transfer_id,omitempty" validate:"omitempty,max=64"` // @example 7300512345678901
+
+	// Optional transaction-service transaction this transfer belongs to, shown in the account activity
+	TransactionID types.TransactionID `json:"transaction_id,string,omitempty" example:"7300512345678901"` // @example 7300512345678901
 }
 
+// TransferFundsHandler transfers funds from the source account of the route to the destination account. It is an
+// internal endpoint, served only to services presenting a service token: clients create transactions instead, so
+// every transfer is recorded by transaction-service. Send the source account ETag as If-Match to fail with 412
+// when it was modified concurrently.
 func (s *Server) TransferFundsHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	sourceIDStr := vars["account_id"]
@@ -46,6 +46,23 @@ func (s *Server) TransferFundsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := authz.Check(r.Context(), s.Policy, authz.ActionDebit, types.AccountID(sourceAccountID)); err != nil {
+		response.SendProblem(w, err)
+		return
+	}
+
+	versions, wildcard, ok := response.IfMatchVersions(r)
+	if !ok {
+		response.SendError(w, response.StatusBadRequest, "invalid If-Match header")
+		return
+	}
+	var ifMatch []types.AccountVersion
+	if !wildcard {
+		for _, version := range versions {
+			ifMatch = append(ifMatch, types.AccountVersion(version))
+		}
+	}
+
 	var req TransferFundsRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		response.SendError(w, response.StatusBadRequest, "invalid request body")
@@ -57,20 +74,13 @@ func (s *Server) TransferFundsHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = s.AccountService.TransferFunds(types.AccountID(sourceAccountID), req.DestAccountID, req.Amount)
+	err = s.AccountService.TransferFunds(r.Context(), types.AccountID(sourceAccountID), req.DestAccountID, req.Amount, service.TransferOptions{
+		TransferID:    req.TransferID,
+		TransactionID: req.TransactionID,
+		IfMatch:       ifMatch,
+	})
 	if err != nil {
-		errStr := err.Error()
-		if strings.Contains(errStr, "source account not found") {
-			response.SendError(w, response.StatusNotFound, errStr)
-		} else if strings.Contains(errStr, "destination account not found") {
-			response.SendError(w, response.StatusNotFound, errStr)
-		} else if strings.Contains(errStr, "insufficient balance") {
-			response.SendError(w, response.StatusBadRequest, errStr)
-		} else if strings.Contains(errStr, "amount must be positive") {
-			response.SendError(w, response.StatusBadRequest, errStr)
-		} else {
-			response.SendError(w, response.StatusInternalServerError, "failed to transfer funds")
-		}
+		response.SendProblem(w, err)
 		return
 	}
 
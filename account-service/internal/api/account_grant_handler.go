@@ -0,0 +1,142 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/danielkhtse/supreme-adventure/common/authz"
+	"github.com/danielkhtse/supreme-adventure/common/models"
+	"github.com/danielkhtse/supreme-adventure/common/response"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"github.com/danielkhtse/supreme-adventure/common/validation"
+	"github.com/gorilla/mux"
+)
+
+// GrantAccountRoleRequest represents the request body for granting a role on an account
+type GrantAccountRoleRequest struct {
+	// Subject of the caller receiving the role, api-key:<id> for an API key
+	Principal string `json:"principal" validate:"required,max=255"` // @example alice
+
+	// owner (view, debit and manage roles), operator (view and debit) or auditor (view)
+	Role types.AccountRole `json:"role" validate:"required,oneof=owner operator auditor"` // @example operator
+}
+
+// @Summary List the roles granted on an account
+// @Description List the principals holding a role on an account. Principals holding a role on every account through AUTHZ_ROLES are not listed.
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param account_id path string true "Account ID"
+// @Success 200 {array} models.AccountGrant
+// @Failure 400 {object} response.ProblemResponse "Invalid account ID format"
+// @Failure 403 {object} response.ProblemResponse "The caller holds no role on the account"
+// @Failure 404 {object} response.ProblemResponse "Account not found"
+// @Security BearerAuth
+// @Router /accounts/{account_id}/grants [get]
+func (s *Server) ListAccountGrantsHandler(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := s.authorizeAccount(w, r, authz.ActionView)
+	if !ok {
+		return
+	}
+
+	grants, err := s.AccountService.ListAccountGrants(accountID)
+	if err != nil {
+		response.SendProblem(w, err)
+		return
+	}
+
+	response.SendSuccess(w, response.StatusOK, &grants)
+}
+
+// @Summary Grant a role on an account
+// @Description Give a principal a role on an account, replacing the role it held. Only owners and admins can grant roles.
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param account_id path string true "Account ID"
+// @Param request body GrantAccountRoleRequest true "Role to grant"
+// @Success 200 {object} models.AccountGrant
+// @Failure 400 {object} response.ProblemResponse "Invalid account ID, principal or role"
+// @Failure 403 {object} response.ProblemResponse "The caller may not manage the account"
+// @Failure 404 {object} response.ProblemResponse "Account not found"
+// @Security BearerAuth
+// @Router /accounts/{account_id}/grants [put]
+func (s *Server) GrantAccountRoleHandler(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := s.authorizeAccount(w, r, authz.ActionManage)
+	if !ok {
+		return
+	}
+
+	var request GrantAccountRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		response.SendError(w, response.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := validation.ValidateStruct(request); err != nil {
+		response.SendError(w, response.StatusBadRequest, err.Error())
+		return
+	}
+
+	grant := &models.AccountGrant{
+		AccountID: accountID,
+		Principal: request.Principal,
+		Role:      request.Role,
+	}
+	if err := s.AccountService.GrantAccountRole(r.Context(), grant); err != nil {
+		response.SendProblem(w, err)
+		return
+	}
+
+	response.SendSuccess(w, response.StatusOK, grant)
+}
+
+// @Summary Revoke a role on an account
+// @Description Remove the role of a principal on an account. Only owners and admins can revoke roles.
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param account_id path string true "Account ID"
+// @Param principal query string true "Principal whose role is revoked"
+// @Success 204
+// @Failure 400 {object} response.ProblemResponse "Invalid account ID format or missing principal"
+// @Failure 403 {object} response.ProblemResponse "The caller may not manage the account"
+// @Failure 404 {object} response.ProblemResponse "Account not found"
+// @Security BearerAuth
+// @Router /accounts/{account_id}/grants [delete]
+func (s *Server) RevokeAccountRoleHandler(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := s.authorizeAccount(w, r, authz.ActionManage)
+	if !ok {
+		return
+	}
+
+	principal := r.URL.Query().Get("principal")
+	if principal == "" {
+		response.SendError(w, response.StatusBadRequest, "principal is required")
+		return
+	}
+
+	if err := s.AccountService.RevokeAccountRole(r.Context(), accountID, principal); err != nil {
+		response.SendProblem(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// authorizeAccount parses the account ID of the route and checks the caller may perform action on the account,
+// writing the error response when it may not
+func (s *Server) authorizeAccount(w http.ResponseWriter, r *http.Request, action authz.Action) (types.AccountID, bool) {
+	accountID, err := strconv.ParseUint(mux.Vars(r)["account_id"], 10, 64)
+	if err != nil {
+		response.SendError(w, response.StatusBadRequest, "invalid account ID format")
+		return 0, false
+	}
+
+	if err := authz.Check(r.Context(), s.Policy, action, types.AccountID(accountID)); err != nil {
+		response.SendProblem(w, err)
+		return 0, false
+	}
+	return types.AccountID(accountID), true
+}
@@ -4,8 +4,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"strconv"
-	"strings"
 
+	"github.com/danielkhtse/supreme-adventure/common/authz"
 	"github.com/danielkhtse/supreme-adventure/common/models"
 	"github.com/danielkhtse/supreme-adventure/common/response"
 	"github.com/danielkhtse/supreme-adventure/common/types"
@@ -16,22 +16,32 @@ import (
 // AccountResponse represents the response for account operations
 type AccountResponse struct {
 	// The unique identifier of the account
-	ID types.AccountID `json:"account_id"`
+	ID types.AccountID `json:"account_id,string"`
 
 	// The current balance in smallest currency units (e.g. cents for USD)
 	Balance types.AccountBalance `json:"balance"`
+
+	// The ISO 4217 currency of the balance
+	Currency string `json:"currency"`
+
+	// The account version, increased on every balance or status change and also returned as the ETag header
+	Version types.AccountVersion `json:"version"`
 }
 
 // @Summary Get account details by ID
-// @Description Get account details by ID
+// @Description Get account details by ID. The ETag header carries the account version, send it as If-None-Match to receive 304 when unchanged.
 // @Tags Account
 // @Accept json
 // @Produce json
 // @Param account_id path string true "Account ID"
+// @Param If-None-Match header string false "ETag of a previously fetched version"
 // @Success 200 {object} AccountResponse "Account details"
-// @Failure 400 {object} response.ErrorResponse "Invalid account ID format"
-// @Failure 404 {object} response.ErrorResponse "Account not found"
-// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Success 304 "Account not modified"
+// @Failure 400 {object} response.ProblemResponse "Invalid account ID format"
+// @Failure 403 {object} response.ProblemResponse "The caller holds no role on the account"
+// @Failure 404 {object} response.ProblemResponse "Account not found"
+// @Failure 500 {object} response.ProblemResponse "Internal server error"
+// @Security BearerAuth
 // @Router /accounts/{account_id} [get]
 func (s *Server) GetAccountHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -42,36 +52,54 @@ func (s *Server) GetAccountHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := authz.Check(r.Context(), s.Policy, authz.ActionView, types.AccountID(requestAccountId)); err != nil {
+		response.SendProblem(w, err)
+		return
+	}
+
 	account, err := s.AccountService.GetAccount(types.AccountID(requestAccountId))
 	if err != nil {
-		response.SendError(w, response.StatusNotFound, "Account not found")
+		response.SendProblem(w, err)
 		return
 	}
 
+	etag := response.ETag(uint64(account.Version))
+	if !response.NoneMatch(r, etag) {
+		response.SendNotModified(w, etag)
+		return
+	}
+
+	w.Header().Set("ETag", etag)
 	response.SendSuccess(w, response.StatusOK, &AccountResponse{
-		ID:      account.ID,
-		Balance: account.Balance, // smallest units for the currency (e.g. cents for USD)
+		ID:       account.ID,
+		Balance:  account.Balance, // smallest units for the currency (e.g. cents for USD)
+		Currency: account.Currency,
+		Version:  account.Version,
 	})
 }
 
 // CreateAccountRequest represents the request body for creating an account
 type CreateAccountRequest struct {
-	// The unique identifier for the new account
-	AccountID types.AccountID `json:"account_id" validate:"required,uuid"`
+	// The unique identifier for the new account, generated by the server when omitted
+	AccountID types.AccountID `json:"account_id,string" validate:"omitempty" example:"7300512345678901"`
 
 	// The initial balance in smallest currency units (e.g. cents for USD)
-	InitialBalance types.AccountBalance `json:"initial_balance" validate:"required,min=0"`
+	InitialBalance types.AccountBalance `json:"initial_balance" validate:"min=0"`
+
+	// Optional reference to the customer owning the account, used to search accounts
+	Owner string `json:"owner,omitempty" validate:"omitempty,max=64"`
 }
 
 // @Summary Create a new account
-// @Description Create a new account with initial balance
+// @Description Create a new account with initial balance, the caller becomes the owner of the account
 // @Tags Account
 // @Accept json
 // @Produce json
 // @Param request body CreateAccountRequest true "Account creation request"
-// @Success 201
-// @Failure 400 {object} response.ErrorResponse "Invalid request body or account already exists"
-// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Success 201 {object} AccountResponse "Created account"
+// @Failure 400 {object} response.ProblemResponse "Invalid request body or account already exists"
+// @Failure 500 {object} response.ProblemResponse "Internal server error"
+// @Security BearerAuth
 // @Router /accounts [post]
 func (s *Server) CreateAccountHandler(w http.ResponseWriter, r *http.Request) {
 	var request CreateAccountRequest
@@ -85,17 +113,21 @@ func (s *Server) CreateAccountHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := s.AccountService.CreateAccount(&models.Account{
+	account := &models.Account{
 		ID:             request.AccountID,
 		InitialBalance: request.InitialBalance,
-	}); err != nil {
-		if strings.Contains(err.Error(), "already exists") {
-			response.SendError(w, response.StatusBadRequest, "Account already exists")
-		} else {
-			response.SendError(w, response.StatusInternalServerError, "Failed to create account")
-		}
+		Owner:          request.Owner,
+	}
+	if err := s.AccountService.CreateAccount(r.Context(), account); err != nil {
+		response.SendProblem(w, err)
 		return
 	}
 
-	response.SendSuccess[struct{}](w, response.StatusCreated, nil)
+	w.Header().Set("ETag", response.ETag(uint64(account.Version)))
+	response.SendSuccess(w, response.StatusCreated, &AccountResponse{
+		ID:       account.ID,
+		Balance:  account.Balance,
+		Currency: account.Currency,
+		Version:  account.Version,
+	})
 }
@@ -0,0 +1,202 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/danielkhtse/supreme-adventure/common/pagination"
+	"github.com/danielkhtse/supreme-adventure/common/response"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+)
+
+// maxImportFileSize caps the uploaded file, the file is stored with the import until it completes
+const maxImportFileSize = 64 << 20
+
+// @Summary Import accounts
+// @Description Upload a CSV or JSONL file of accounts to create them in the background. Columns (CSV header) or keys (JSONL) are account_id, initial_balance, currency and owner; only account_id is required.
+// @Description Every row is validated with the account rules. Rejected rows are listed in the error report, accounts that already exist with the same attributes are skipped so a file can be imported again.
+// @Description With dry_run the rows are validated and reported without creating accounts. A failed import can be resumed after its last committed chunk.
+// @Tags Account Import
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Produce json
+// @Param format query string false "csv or jsonl, derived from Content-Type when omitted" Enums(csv, jsonl)
+// @Param dry_run query bool false "Validate and report without creating accounts"
+// @Param chunk_size query int false "Rows committed per transaction (default 500, max 1000)"
+// @Param file body string true "CSV or JSONL file"
+// @Success 202 {object} models.AccountImport "Queued import"
+// @Failure 400 {object} response.ProblemResponse "Invalid parameters, header or file"
+// @Failure 413 {object} response.ProblemResponse "File larger than 64 MiB"
+// @Failure 500 {object} response.ProblemResponse "Internal server error"
+// @Security BearerAuth
+// @Router /accounts/imports [post]
+func (s *Server) CreateAccountImportHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	format := types.AccountImportFormat(query.Get("format"))
+	if format == "" {
+		format = importFormatOf(r.Header.Get("Content-Type"))
+	}
+	if format != types.AccountImportFormatCSV && format != types.AccountImportFormatJSONL {
+		response.SendError(w, response.StatusBadRequest, "format must be csv or jsonl")
+		return
+	}
+
+	dryRun := false
+	if value := query.Get("dry_run"); value != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			response.SendError(w, response.StatusBadRequest, "dry_run must be true or false")
+			return
+		}
+	}
+
+	chunkSize := 0
+	if value := query.Get("chunk_size"); value != "" {
+		var err error
+		if chunkSize, err = strconv.Atoi(value); err != nil {
+			response.SendError(w, response.StatusBadRequest, "chunk_size must be an integer")
+			return
+		}
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxImportFileSize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			response.SendError(w, response.StatusRequestEntityTooLarge, "import file must not exceed 64 MiB")
+			return
+		}
+		response.SendError(w, response.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	accountImport, err := s.AccountService.CreateAccountImport(r.Context(), format, data, dryRun, chunkSize)
+	if err != nil {
+		log.WithError(err).Error("failed to create account import")
+		response.SendProblem(w, err)
+		return
+	}
+
+	response.SendSuccess(w, response.StatusAccepted, accountImport)
+}
+
+// @Summary Get an account import
+// @Description Progress of an import: rows processed so far and how many were created, skipped or rejected.
+// @Tags Account Import
+// @Produce json
+// @Param import_id path string true "Import ID"
+// @Success 200 {object} models.AccountImport
+// @Failure 400 {object} response.ProblemResponse "Invalid import ID"
+// @Failure 404 {object} response.ProblemResponse "Import not found"
+// @Failure 500 {object} response.ProblemResponse "Internal server error"
+// @Security BearerAuth
+// @Router /accounts/imports/{import_id} [get]
+func (s *Server) GetAccountImportHandler(w http.ResponseWriter, r *http.Request) {
+	importID, ok := importIDOf(w, r)
+	if !ok {
+		return
+	}
+
+	accountImport, err := s.AccountService.GetAccountImport(importID)
+	if err != nil {
+		response.SendProblem(w, err)
+		return
+	}
+
+	response.SendSuccess(w, response.StatusOK, accountImport)
+}
+
+// @Summary List the errors of an account import
+// @Description Rejected rows of an import in file order, with every problem of the row. Pass next_cursor back as cursor to fetch the next page.
+// @Tags Account Import
+// @Produce json
+// @Param import_id path string true "Import ID"
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param limit query int false "Maximum number of entries (default 50, max 200)"
+// @Success 200 {object} service.ImportErrorPage
+// @Failure 400 {object} response.ProblemResponse "Invalid import ID, cursor or limit"
+// @Failure 404 {object} response.ProblemResponse "Import not found"
+// @Failure 500 {object} response.ProblemResponse "Internal server error"
+// @Security BearerAuth
+// @Router /accounts/imports/{import_id}/errors [get]
+func (s *Server) ListAccountImportErrorsHandler(w http.ResponseWriter, r *http.Request) {
+	importID, ok := importIDOf(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+
+	limit := 0
+	if value := query.Get("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > pagination.MaxLimit {
+			response.SendError(w, response.StatusBadRequest, "limit must be between 1 and 200")
+			return
+		}
+	}
+
+	page, err := s.AccountService.ListAccountImportErrors(r.Context(), importID, query.Get("cursor"), limit)
+	if err != nil {
+		log.WithError(err).Error("failed to list account import errors")
+		response.SendProblem(w, err)
+		return
+	}
+
+	response.SendSuccess(w, response.StatusOK, page)
+}
+
+// @Summary Resume an account import
+// @Description Queue a failed import again, it continues after the last committed chunk.
+// @Tags Account Import
+// @Produce json
+// @Param import_id path string true "Import ID"
+// @Success 202 {object} models.AccountImport "Queued import"
+// @Failure 400 {object} response.ProblemResponse "Invalid import ID"
+// @Failure 404 {object} response.ProblemResponse "Import not found"
+// @Failure 409 {object} response.ProblemResponse "Import has not failed"
+// @Failure 500 {object} response.ProblemResponse "Internal server error"
+// @Security BearerAuth
+// @Router /accounts/imports/{import_id}/resume [post]
+func (s *Server) ResumeAccountImportHandler(w http.ResponseWriter, r *http.Request) {
+	importID, ok := importIDOf(w, r)
+	if !ok {
+		return
+	}
+
+	accountImport, err := s.AccountService.ResumeAccountImport(r.Context(), importID)
+	if err != nil {
+		response.SendProblem(w, err)
+		return
+	}
+
+	response.SendSuccess(w, response.StatusAccepted, accountImport)
+}
+
+func importIDOf(w http.ResponseWriter, r *http.Request) (types.AccountImportID, bool) {
+	importID, err := strconv.ParseUint(mux.Vars(r)["import_id"], 10, 64)
+	if err != nil {
+		response.SendError(w, response.StatusBadRequest, "Invalid import ID format")
+		return 0, false
+	}
+	return types.AccountImportID(importID), true
+}
+
+// importFormatOf derives the import format from the Content-Type of the upload
+func importFormatOf(contentType string) types.AccountImportFormat {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "text/csv":
+		return types.AccountImportFormatCSV
+	case "application/x-ndjson", "application/jsonl", "application/x-jsonlines":
+		return types.AccountImportFormatJSONL
+	}
+	return ""
+}
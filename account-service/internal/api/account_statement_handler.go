@@ -0,0 +1,121 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/danielkhtse/supreme-adventure/common/authz"
+	"github.com/danielkhtse/supreme-adventure/common/iso20022"
+	"github.com/danielkhtse/supreme-adventure/common/response"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// @Summary Get a camt.053 statement
+// @Description ISO 20022 camt.053.001.08 end-of-day statement of an account for a past UTC day: opening and closing booked balances (OPBD, CLBD), a summary and one booked entry per balance movement.
+// @Description Entries of transfers carry the transfer ID as EndToEndId, the transaction ID as TxId, the counterparty account and the transaction description as unstructured remittance information.
+// @Tags Statement
+// @Produce application/xml
+// @Param account_id path string true "Account ID"
+// @Param date query string false "Statement day (YYYY-MM-DD, UTC), yesterday by default"
+// @Success 200 {string} string "camt.053.001.08 document"
+// @Failure 400 {object} response.ProblemResponse "Invalid account ID or date"
+// @Failure 403 {object} response.ProblemResponse "The caller holds no role on the account"
+// @Failure 404 {object} response.ProblemResponse "Account not found"
+// @Failure 500 {object} response.ProblemResponse "Internal server error"
+// @Security BearerAuth
+// @Router /accounts/{account_id}/statements/camt053 [get]
+func (s *Server) GetCamt053StatementHandler(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := s.authorizeAccount(w, r, authz.ActionView)
+	if !ok {
+		return
+	}
+
+	date := time.Now().UTC().AddDate(0, 0, -1)
+	if value := r.URL.Query().Get("date"); value != "" {
+		var err error
+		date, err = time.Parse(time.DateOnly, value)
+		if err != nil {
+			response.SendError(w, response.StatusBadRequest, "date must be a date (YYYY-MM-DD)")
+			return
+		}
+	}
+
+	document, err := s.AccountService.AccountStatement(r.Context(), accountID, date)
+	if err != nil {
+		log.WithError(err).Error("failed to build account statement")
+		response.SendProblem(w, err)
+		return
+	}
+
+	sendCamtDocument(w, document, "camt053-"+strconv.FormatUint(uint64(accountID), 10)+"-"+date.Format(time.DateOnly)+".xml")
+}
+
+// @Summary Get a camt.054 notification
+// @Description ISO 20022 camt.054.001.08 debit/credit notification of the balance movements of an account in a period of at most 31 days, with the entries described like in camt.053 statements.
+// @Tags Statement
+// @Produce application/xml
+// @Param account_id path string true "Account ID"
+// @Param from query string true "Start of the period, inclusive (RFC 3339)"
+// @Param to query string false "End of the period, exclusive (RFC 3339), now by default"
+// @Param direction query string false "Notify debits or credits only" Enums(debit, credit)
+// @Success 200 {string} string "camt.054.001.08 document"
+// @Failure 400 {object} response.ProblemResponse "Invalid account ID, period or direction"
+// @Failure 403 {object} response.ProblemResponse "The caller holds no role on the account"
+// @Failure 404 {object} response.ProblemResponse "Account not found"
+// @Failure 500 {object} response.ProblemResponse "Internal server error"
+// @Security BearerAuth
+// @Router /accounts/{account_id}/statements/camt054 [get]
+func (s *Server) GetCamt054NotificationHandler(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := s.authorizeAccount(w, r, authz.ActionView)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	from, err := time.Parse(time.RFC3339, query.Get("from"))
+	if err != nil {
+		response.SendError(w, response.StatusBadRequest, "from must be an RFC 3339 timestamp")
+		return
+	}
+	to := time.Now()
+	if value := query.Get("to"); value != "" {
+		to, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			response.SendError(w, response.StatusBadRequest, "to must be an RFC 3339 timestamp")
+			return
+		}
+	}
+
+	direction := types.ActivityDirection(query.Get("direction"))
+	if direction != "" && direction != types.ActivityDirectionDebit && direction != types.ActivityDirectionCredit {
+		response.SendError(w, response.StatusBadRequest, "direction must be debit or credit")
+		return
+	}
+
+	document, err := s.AccountService.AccountNotification(r.Context(), accountID, from, to, direction)
+	if err != nil {
+		log.WithError(err).Error("failed to build account notification")
+		response.SendProblem(w, err)
+		return
+	}
+
+	sendCamtDocument(w, document, "camt054-"+strconv.FormatUint(uint64(accountID), 10)+"-"+from.UTC().Format("20060102T150405Z")+".xml")
+}
+
+// sendCamtDocument encodes the document before the status line is sent, so an encoding error is still a problem response
+func sendCamtDocument(w http.ResponseWriter, document any, filename string) {
+	var buf bytes.Buffer
+	if err := iso20022.Encode(&buf, document); err != nil {
+		log.WithError(err).Error("failed to encode ISO 20022 document")
+		response.SendProblem(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", iso20022.ContentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/danielkhtse/supreme-adventure/common/authz"
+	"github.com/danielkhtse/supreme-adventure/common/response"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"github.com/danielkhtse/supreme-adventure/common/validation"
+)
+
+// UpdateAccountStatusRequest represents the request body for freezing or unfreezing an account
+type UpdateAccountStatusRequest struct {
+	// The new status, transfers from and to inactive (frozen) accounts are rejected
+	Status types.AccountStatus `json:"status" validate:"required,oneof=active inactive"` // @example inactive
+}
+
+// @Summary Freeze or unfreeze an account
+// @Description Set the account status to inactive (frozen) or active. Send the account ETag as If-Match to fail with 412 when it was modified concurrently.
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Param account_id path string true "Account ID"
+// @Param If-Match header string false "ETag the account must still have"
+// @Param request body UpdateAccountStatusRequest true "New account status"
+// @Success 200 {object} AccountResponse "Updated account"
+// @Failure 400 {object} response.ProblemResponse "Invalid request parameters"
+// @Failure 403 {object} response.ProblemResponse "The caller may not manage the account"
+// @Failure 404 {object} response.ProblemResponse "Account not found"
+// @Failure 409 {object} response.ProblemResponse "Lock timeout"
+// @Failure 412 {object} response.ProblemResponse "Account version does not match If-Match"
+// @Failure 500 {object} response.ProblemResponse "Internal server error"
+// @Security BearerAuth
+// @Router /accounts/{account_id}/status [put]
+func (s *Server) UpdateAccountStatusHandler(w http.ResponseWriter, r *http.Request) {
+	accountID, ok := s.authorizeAccount(w, r, authz.ActionManage)
+	if !ok {
+		return
+	}
+
+	versions, wildcard, ok := response.IfMatchVersions(r)
+	if !ok {
+		response.SendError(w, response.StatusBadRequest, "invalid If-Match header")
+		return
+	}
+	var ifMatch []types.AccountVersion
+	if !wildcard {
+		for _, version := range versions {
+			ifMatch = append(ifMatch, types.AccountVersion(version))
+		}
+	}
+
+	var request UpdateAccountStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		response.SendError(w, response.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := validation.ValidateStruct(request); err != nil {
+		response.SendError(w, response.StatusBadRequest, err.Error())
+		return
+	}
+
+	account, err := s.AccountService.UpdateAccountStatus(r.Context(), accountID, request.Status, ifMatch)
+	if err != nil {
+		response.SendProblem(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", response.ETag(uint64(account.Version)))
+	response.SendSuccess(w, response.StatusOK, &AccountResponse{
+		ID:       account.ID,
+		Balance:  account.Balance,
+		Currency: account.Currency,
+		Version:  account.Version,
+	})
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/danielkhtse/supreme-adventure/common/authz"
+	"github.com/danielkhtse/supreme-adventure/common/feed"
+	"github.com/danielkhtse/supreme-adventure/common/response"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+)
+
+// @Summary List account events
+// @Description Ordered change feed of account events (account.created, transfer.applied, account.balance_changed).
+// @Description Pass next_cursor back as after to continue, with wait to long-poll until new events arrive.
+// @Description Without account_id the feed of every account is returned, which requires a role on every account.
+// @Tags Event
+// @Accept json
+// @Produce json
+// @Param account_id query string false "Only events affecting this account"
+// @Param after query string false "Cursor returned as next_cursor by the previous call, latest to start after the newest event, omit to start from the oldest retained event"
+// @Param limit query int false "Maximum number of events (default 100, max 1000)"
+// @Param wait query int false "Seconds to wait for new events when none are available (max 30)"
+// @Success 200 {object} feed.Page
+// @Failure 400 {object} response.ProblemResponse "Invalid account ID, cursor, limit or wait"
+// @Failure 403 {object} response.ProblemResponse "The caller may not view the account, or every account without account_id"
+// @Failure 410 {object} response.ProblemResponse "Events after the cursor were deleted by the retention period"
+// @Failure 500 {object} response.ProblemResponse "Internal server error"
+// @Security BearerAuth
+// @Router /events [get]
+func (s *Server) ListEventsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	accountID := authz.AllAccounts
+	if value := query.Get("account_id"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 64)
+		if err != nil || parsed == 0 {
+			response.SendError(w, response.StatusBadRequest, "invalid account ID format")
+			return
+		}
+		accountID = types.AccountID(parsed)
+	}
+	if err := authz.Check(r.Context(), s.Policy, authz.ActionView, accountID); err != nil {
+		response.SendProblem(w, err)
+		return
+	}
+
+	limit := feed.DefaultLimit
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > feed.MaxLimit {
+			response.SendError(w, response.StatusBadRequest, "limit must be between 1 and 1000")
+			return
+		}
+		limit = parsed
+	}
+
+	var wait time.Duration
+	if value := query.Get("wait"); value != "" {
+		seconds, err := strconv.Atoi(value)
+		if err != nil || seconds < 0 || time.Duration(seconds)*time.Second > feed.MaxWait {
+			response.SendError(w, response.StatusBadRequest, "wait must be between 0 and 30 seconds")
+			return
+		}
+		wait = time.Duration(seconds) * time.Second
+	}
+
+	page, err := s.AccountService.ListEvents(r.Context(), query.Get("after"), accountID, limit, wait)
+	if err != nil {
+		response.SendProblem(w, err)
+		return
+	}
+
+	response.SendSuccess(w, response.StatusOK, page)
+}
@@ -3,28 +3,85 @@ package api
 import (
 	"net/http"
 
+	"github.com/danielkhtse/supreme-adventure/common/apiversion"
+	"github.com/danielkhtse/supreme-adventure/common/auth"
+	"github.com/danielkhtse/supreme-adventure/common/requestmeta"
 	"github.com/gorilla/mux"
 )
 
 const (
 	accountsRoute = "/accounts"
+	eventsRoute   = "/events"
+
+	// internalRoute prefixes the routes served only to other services, outside the versioned public API
+	internalRoute = "/internal"
 )
 
 // NewRouter creates and configures a new router
 func (s *Server) InitializeRoutes() *mux.Router {
 	r := mux.NewRouter()
+	r.Use(requestmeta.Middleware)
 
 	r.HandleFunc("/health-check", s.HealthCheckHandler).Methods("GET")
 
+	fs := http.FileServer(http.Dir("account-service/docs"))
+	r.PathPrefix("/docs/").Handler(http.StripPrefix("/docs/", fs))
+
+	s.registerInternalRoutes(r.PathPrefix(internalRoute).Subrouter())
+
+	//the unversioned routes are kept as deprecated aliases of v1 for clients predating it
+	apiversion.Mount(r,
+		apiversion.Version{Prefix: "/v1", Register: s.registerV1Routes},
+		apiversion.Version{
+			Register:    s.registerV1Routes,
+			Deprecation: apiversion.LegacyDeprecation,
+			Sunset:      apiversion.LegacySunset,
+			Successor:   "/v1",
+		},
+	)
+
+	return r
+}
+
+// registerV1Routes adds the routes of API v1, whose request and response types are defined in this package
+func (s *Server) registerV1Routes(r *mux.Router) {
 	accounts := r.PathPrefix(accountsRoute).Subrouter()
 
+	//back-office listing, registered before the single account routes so export is not taken for an account ID
+	accounts.Handle("", s.Auth.Require(auth.ScopeAccountsRead, s.ListAccountsHandler)).Methods("GET")
+	accounts.Handle("/export", s.Auth.Require(auth.ScopeAccountsRead, s.ExportAccountsHandler)).Methods("GET")
+
+	//bulk import jobs, also registered before the single account routes
+	accounts.Handle("/imports", s.Auth.Require(auth.ScopeAccountsWrite, s.CreateAccountImportHandler)).Methods("POST")
+	accounts.Handle("/imports/{import_id}", s.Auth.Require(auth.ScopeAccountsRead, s.GetAccountImportHandler)).Methods("GET")
+	accounts.Handle("/imports/{import_id}/errors", s.Auth.Require(auth.ScopeAccountsRead, s.ListAccountImportErrorsHandler)).Methods("GET")
+	accounts.Handle("/imports/{import_id}/resume", s.Auth.Require(auth.ScopeAccountsWrite, s.ResumeAccountImportHandler)).Methods("POST")
+
 	//single account handlers
-	accounts.HandleFunc("", s.CreateAccountHandler).Methods("POST")
-	accounts.HandleFunc("/{account_id}", s.GetAccountHandler).Methods("GET")
-	accounts.HandleFunc("/{account_id}/balance/transfer", s.TransferFundsHandler).Methods("PUT")
+	accounts.Handle("", s.Auth.Require(auth.ScopeAccountsWrite, s.CreateAccountHandler)).Methods("POST")
+	accounts.Handle("/{account_id}", s.Auth.Require(auth.ScopeAccountsRead, s.GetAccountHandler)).Methods("GET")
+	accounts.Handle("/{account_id}/status", s.Auth.Require(auth.ScopeAccountsWrite, s.UpdateAccountStatusHandler)).Methods("PUT")
+	accounts.Handle("/{account_id}/grants", s.Auth.Require(auth.ScopeAccountsRead, s.ListAccountGrantsHandler)).Methods("GET")
+	accounts.Handle("/{account_id}/grants", s.Auth.Require(auth.ScopeAccountsWrite, s.GrantAccountRoleHandler)).Methods("PUT")
+	accounts.Handle("/{account_id}/grants", s.Auth.Require(auth.ScopeAccountsWrite, s.RevokeAccountRoleHandler)).Methods("DELETE")
+	accounts.Handle("/{account_id}/activity", s.Auth.Require(auth.ScopeAccountsRead, s.ListAccountActivityHandler)).Methods("GET")
+	accounts.Handle("/{account_id}/stream", s.Auth.Require(auth.ScopeAccountsRead, s.StreamAccountHandler)).Methods("GET")
 
-	fs := http.FileServer(http.Dir("account-service/docs"))
-	r.PathPrefix("/docs/").Handler(http.StripPrefix("/docs/", fs))
+	//ISO 20022 statements and notifications
+	accounts.Handle("/{account_id}/statements/camt053", s.Auth.Require(auth.ScopeAccountsRead, s.GetCamt053StatementHandler)).Methods("GET")
+	accounts.Handle("/{account_id}/statements/camt054", s.Auth.Require(auth.ScopeAccountsRead, s.GetCamt054NotificationHandler)).Methods("GET")
 
-	return r
+	//change feed
+	r.Handle(eventsRoute, s.Auth.Require(auth.ScopeEventsRead, s.ListEventsHandler)).Methods("GET")
+}
+
+// registerInternalRoutes adds the routes served only to services presenting a service token, which are not part
+// of the public API
+func (s *Server) registerInternalRoutes(r *mux.Router) {
+	r.Use(s.Service.Require)
+
+	accounts := r.PathPrefix(accountsRoute).Subrouter()
+
+	//transfers execute transactions, transaction-service forwards the token of the caller creating the transaction
+	accounts.Handle("/{account_id}/balance/transfer", s.Auth.Require(auth.ScopeTransactionsWrite, s.TransferFundsHandler)).Methods("PUT")
 }
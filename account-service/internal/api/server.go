@@ -5,27 +5,55 @@ import (
 	"os"
 
 	"github.com/danielkhtse/supreme-adventure/account-service/internal/service"
+	"github.com/danielkhtse/supreme-adventure/common/apiversion"
+	"github.com/danielkhtse/supreme-adventure/common/auth"
+	"github.com/danielkhtse/supreme-adventure/common/authz"
+	"github.com/danielkhtse/supreme-adventure/common/requestmeta"
+	"github.com/danielkhtse/supreme-adventure/common/stream"
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 	"github.com/rs/cors"
 	log "github.com/sirupsen/logrus"
 )
 
+// @title Account Service API
+// @version 1.0
+// @description Accounts, balances and transfers, with their activity, streams and change feed
+// @BasePath /v1
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description JWT bearer token, "Bearer <token>"
+
 // Accounts API server
 type Server struct {
 	AccountService *service.AccountService
+	Streamer       *stream.Handler
+	Auth           *auth.Authenticator
+	Service        *auth.ServiceAuth
+	Policy         authz.Policy
 	Router         *mux.Router
 	Port           string
 }
 
-func (server *Server) Initialize(accountService *service.AccountService) {
+func (server *Server) Initialize(accountService *service.AccountService, authenticator *auth.Authenticator, serviceAuth *auth.ServiceAuth) {
 	server.AccountService = accountService
+	server.Auth = authenticator
+	server.Service = serviceAuth
+	server.Policy = accountService.Policy()
 	server.Port = os.Getenv("ACCOUNT_API_SERVER_PORT")
 
 	if server.Port == "" {
 		log.Fatal("ACCOUNT_API_SERVER_PORT environment variable not set")
 	}
 
+	server.Streamer = &stream.Handler{
+		Feed:          accountService,
+		Broker:        accountService.Events(),
+		Authorizer:    authz.StreamAuthorizer{Policy: server.Policy},
+		AllowedOrigin: os.Getenv("ENV_CORS_ALLOWED_ORIGIN"),
+	}
+
 	server.Router = server.InitializeRoutes()
 }
 
@@ -35,9 +63,11 @@ func (server *Server) Run() {
 	allow := os.Getenv("ENV_CORS_ALLOWED_ORIGIN")
 	log.Info("Allow origin: " + allow)
 	c := cors.New(cors.Options{
-		AllowedOrigins:   []string{allow},
-		AllowCredentials: true,
-		AllowedHeaders:   []string{"*"},
+		AllowedOrigins: []string{allow},
+		//bearer tokens are sent explicitly, cross-origin requests never need cookies
+		AllowCredentials: false,
+		AllowedHeaders:   []string{requestmeta.AuthorizationHeader, "Content-Type", "If-Match", "If-None-Match", requestmeta.RequestIDHeader, stream.LastEventIDHeader},
+		ExposedHeaders:   []string{"ETag", "WWW-Authenticate", requestmeta.RequestIDHeader, apiversion.DeprecationHeader, apiversion.SunsetHeader, apiversion.LinkHeader},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH", "HEAD"},
 	})
 	handler := c.Handler(server.Router)
@@ -0,0 +1,92 @@
+// Package client calls the services account-service depends on
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/danielkhtse/supreme-adventure/common/models"
+	"github.com/danielkhtse/supreme-adventure/common/requestmeta"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"github.com/danielkhtse/supreme-adventure/sdk"
+)
+
+// TransactionServiceURLEnv is the address of transaction-service (e.g. http://transaction-service:8081)
+const TransactionServiceURLEnv = "TRANSACTION_SERVICE_URL"
+
+// TransactionLookup fetches the transactions behind account activity from transaction-service
+type TransactionLookup struct {
+	client *sdk.TransactionClient
+}
+
+// NewTransactionLookup returns a lookup of the transaction-service at baseURL. The request ID and credentials of
+// the caller are forwarded, so transaction-service serves the lookup only when the caller may read the transactions.
+func NewTransactionLookup(baseURL string) *TransactionLookup {
+	config := sdk.DefaultConfig()
+	config.HTTPClient = &http.Client{Transport: forwardingTransport{base: http.DefaultTransport}}
+	return &TransactionLookup{client: sdk.NewTransactionClientWithConfig(baseURL, config)}
+}
+
+// forwardingTransport adds the request ID, authorization and forwarding headers of the request being served,
+// carried by the request context, to the requests it sends
+type forwardingTransport struct {
+	base http.RoundTripper
+}
+
+func (t forwardingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	requestmeta.SetHeaders(req.Context(), req.Header)
+	return t.base.RoundTrip(req)
+}
+
+// Transactions returns the transactions with the given IDs. The transactions of the account created in
+// [from, to) are listed in bulk, the others are fetched one by one and skipped when transaction-service does
+// not know them.
+func (l *TransactionLookup) Transactions(ctx context.Context, accountID types.AccountID, ids []types.TransactionID, from, to time.Time) (map[types.TransactionID]models.Transaction, error) {
+	wanted := make(map[types.TransactionID]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	transactions := make(map[types.TransactionID]models.Transaction, len(ids))
+	params := sdk.ListTransactionsParams{AccountID: uint64(accountID), CreatedFrom: from, CreatedTo: to, Limit: 200}
+	for transaction, err := range l.client.Transactions(ctx, params) {
+		if err != nil {
+			return nil, err
+		}
+		if id := types.TransactionID(transaction.ID); wanted[id] {
+			transactions[id] = transactionModel(transaction)
+		}
+	}
+
+	for id := range wanted {
+		if _, ok := transactions[id]; ok {
+			continue
+		}
+		transaction, err := l.client.GetTransaction(ctx, uint64(id))
+		if errors.Is(err, sdk.ErrTransactionNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		transactions[id] = transactionModel(*transaction)
+	}
+	return transactions, nil
+}
+
+func transactionModel(transaction sdk.Transaction) models.Transaction {
+	return models.Transaction{
+		ID:              types.TransactionID(transaction.ID),
+		SourceAccountID: types.AccountID(transaction.SourceAccountID),
+		DestAccountID:   types.AccountID(transaction.DestAccountID),
+		Amount:          types.AccountBalance(transaction.Amount),
+		Currency:        transaction.Currency,
+		Status:          types.TransactionStatus(transaction.Status),
+		Description:     transaction.Description,
+		CreatedAt:       transaction.CreatedAt,
+		UpdatedAt:       transaction.UpdatedAt,
+	}
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/danielkhtse/supreme-adventure/common/auth"
+	"github.com/danielkhtse/supreme-adventure/common/auth/authtest"
+	"github.com/danielkhtse/supreme-adventure/common/requestmeta"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitTransactionLookup(t *testing.T) {
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+
+	var fetched []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/transactions":
+			assert.Equal(t, "1", r.URL.Query().Get("account_id"))
+			assert.Equal(t, from.Format(time.RFC3339), r.URL.Query().Get("created_from"))
+			assert.Equal(t, to.Format(time.RFC3339), r.URL.Query().Get("created_to"))
+			json.NewEncoder(w).Encode(map[string]any{
+				"transactions": []map[string]any{
+					{"id": "9", "source_account_id": "1", "destination_account_id": "2", "amount": 50, "description": "Invoice 118"},
+					{"id": "10", "source_account_id": "1", "destination_account_id": "3", "amount": 5, "description": "Not wanted"},
+				},
+			})
+		case "/v1/transactions/11":
+			fetched = append(fetched, r.URL.Path)
+			json.NewEncoder(w).Encode(map[string]any{"id": "11", "description": "Created the day before"})
+		default:
+			fetched = append(fetched, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]any{"code": "transaction_not_found", "status": 404})
+		}
+	}))
+	defer server.Close()
+
+	transactions, err := NewTransactionLookup(server.URL).Transactions(context.Background(), 1, []types.TransactionID{9, 11, 12}, from, to)
+	require.NoError(t, err)
+	assert.Len(t, transactions, 2)
+	assert.Equal(t, "Invoice 118", transactions[9].Description)
+	assert.Equal(t, types.AccountBalance(50), transactions[9].Amount)
+	assert.Equal(t, "Created the day before", transactions[11].Description)
+	assert.ElementsMatch(t, []string{"/v1/transactions/11", "/v1/transactions/12"}, fetched)
+}
+
+func TestUnitTransactionLookupForwardsCredentials(t *testing.T) {
+	issuer := authtest.NewIssuer(t)
+	server := httptest.NewServer(requestmeta.Middleware(issuer.Authenticator.Require(auth.ScopeTransactionsRead, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "req-1", r.Header.Get(requestmeta.RequestIDHeader))
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v1/transactions":
+			json.NewEncoder(w).Encode(map[string]any{
+				"transactions": []map[string]any{{"id": "9", "source_account_id": "1", "description": "Invoice 118"}},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})))
+	defer server.Close()
+
+	lookup := NewTransactionLookup(server.URL)
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	lookupAs := func(authorization string) (map[types.TransactionID]string, error) {
+		ctx := requestmeta.NewContext(context.Background(), requestmeta.Metadata{RequestID: "req-1", Authorization: authorization})
+		transactions, err := lookup.Transactions(ctx, 1, []types.TransactionID{9}, from, from.AddDate(0, 0, 1))
+		descriptions := map[types.TransactionID]string{}
+		for id, transaction := range transactions {
+			descriptions[id] = transaction.Description
+		}
+		return descriptions, err
+	}
+
+	t.Run("Caller credentials", func(t *testing.T) {
+		descriptions, err := lookupAs(issuer.Token(t, "user-1", auth.ScopeTransactionsRead))
+		require.NoError(t, err)
+		assert.Equal(t, map[types.TransactionID]string{9: "Invoice 118"}, descriptions)
+	})
+
+	t.Run("Without credentials", func(t *testing.T) {
+		_, err := lookupAs("")
+		assert.Error(t, err)
+	})
+}
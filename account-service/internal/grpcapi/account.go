@@ -0,0 +1,134 @@
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/danielkhtse/supreme-adventure/account-service/internal/service"
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/authz"
+	"github.com/danielkhtse/supreme-adventure/common/events"
+	"github.com/danielkhtse/supreme-adventure/common/models"
+	ledgerv1 "github.com/danielkhtse/supreme-adventure/common/proto/ledger/v1"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// CreateAccount creates a new account, an ID is generated when none is provided
+func (s *Server) CreateAccount(ctx context.Context, req *ledgerv1.CreateAccountRequest) (*ledgerv1.CreateAccountResponse, error) {
+	if req.GetInitialBalance() < 0 {
+		return nil, toStatus(apperr.Invalid("initial balance must not be negative"))
+	}
+	if len(req.GetOwner()) > 64 {
+		return nil, toStatus(apperr.Invalid("owner must be at most 64 characters"))
+	}
+
+	account := &models.Account{
+		ID:             types.AccountID(req.GetAccountId()),
+		InitialBalance: types.AccountBalance(req.GetInitialBalance()),
+		Owner:          req.GetOwner(),
+	}
+	if err := s.AccountService.CreateAccount(ctx, account); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &ledgerv1.CreateAccountResponse{Account: toAccount(account)}, nil
+}
+
+// GetAccount retrieves an account by ID
+func (s *Server) GetAccount(ctx context.Context, req *ledgerv1.GetAccountRequest) (*ledgerv1.GetAccountResponse, error) {
+	if err := authz.Check(ctx, s.Policy, authz.ActionView, types.AccountID(req.GetAccountId())); err != nil {
+		return nil, toStatus(err)
+	}
+
+	account, err := s.AccountService.GetAccount(types.AccountID(req.GetAccountId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &ledgerv1.GetAccountResponse{Account: toAccount(account)}, nil
+}
+
+// TransferFunds transfers funds between two accounts
+func (s *Server) TransferFunds(ctx context.Context, req *ledgerv1.TransferFundsRequest) (*ledgerv1.TransferFundsResponse, error) {
+	if err := authz.Check(ctx, s.Policy, authz.ActionDebit, types.AccountID(req.GetSourceAccountId())); err != nil {
+		return nil, toStatus(err)
+	}
+
+	ifMatch := make([]types.AccountVersion, 0, len(req.GetIfMatchVersions()))
+	for _, version := range req.GetIfMatchVersions() {
+		ifMatch = append(ifMatch, types.AccountVersion(version))
+	}
+
+	err := s.AccountService.TransferFunds(
+		ctx,
+		types.AccountID(req.GetSourceAccountId()),
+		types.AccountID(req.GetDestAccountId()),
+		types.AccountBalance(req.GetAmount()),
+		service.TransferOptions{
+			TransferID:    req.GetTransferId(),
+			TransactionID: types.TransactionID(req.GetTransactionId()),
+			IfMatch:       ifMatch,
+		},
+	)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &ledgerv1.TransferFundsResponse{}, nil
+}
+
+// WatchAccount streams the balance changes of an account, replaying retained changes after last_event_id first
+func (s *Server) WatchAccount(req *ledgerv1.WatchAccountRequest, stream ledgerv1.AccountService_WatchAccountServer) error {
+	accountID := types.AccountID(req.GetAccountId())
+	if err := s.Streamer.Authorize(stream.Context(), accountID); err != nil {
+		return toStatus(err)
+	}
+	if _, err := s.AccountService.GetAccount(accountID); err != nil {
+		return toStatus(err)
+	}
+
+	err := s.Streamer.Watch(stream.Context(), accountID, req.GetLastEventId(), func(event events.Event) error {
+		var change events.BalanceChange
+		if err := json.Unmarshal(event.Data, &change); err != nil {
+			return status.Errorf(codes.Internal, "failed to decode event %d", event.ID)
+		}
+		return stream.Send(&ledgerv1.WatchAccountResponse{BalanceChange: toBalanceChange(event, change)})
+	})
+	if err != nil && stream.Context().Err() != nil {
+		return status.FromContextError(stream.Context().Err()).Err()
+	}
+	return err
+}
+
+func toAccount(account *models.Account) *ledgerv1.Account {
+	return &ledgerv1.Account{
+		Id:             uint64(account.ID),
+		Balance:        int64(account.Balance),
+		InitialBalance: int64(account.InitialBalance),
+		Currency:       account.Currency,
+		Status:         string(account.Status),
+		Owner:          account.Owner,
+		Version:        uint64(account.Version),
+		CreatedAt:      timestamppb.New(account.CreatedAt),
+		UpdatedAt:      timestamppb.New(account.UpdatedAt),
+	}
+}
+
+func toBalanceChange(event events.Event, change events.BalanceChange) *ledgerv1.BalanceChange {
+	balanceChange := &ledgerv1.BalanceChange{
+		EventId:       event.ID,
+		AccountId:     uint64(change.AccountID),
+		Amount:        int64(change.Amount),
+		Currency:      change.Currency,
+		TransactionId: uint64(change.TransactionID),
+		OccurredAt:    timestamppb.New(event.OccurredAt),
+	}
+	if change.Balance != nil {
+		balance := int64(*change.Balance)
+		balanceChange.Balance = &balance
+	}
+	return balanceChange
+}
@@ -0,0 +1,100 @@
+package grpcapi
+
+import (
+	"net"
+	"os"
+
+	"github.com/danielkhtse/supreme-adventure/account-service/internal/service"
+	"github.com/danielkhtse/supreme-adventure/common/auth"
+	"github.com/danielkhtse/supreme-adventure/common/authz"
+	"github.com/danielkhtse/supreme-adventure/common/events"
+	ledgerv1 "github.com/danielkhtse/supreme-adventure/common/proto/ledger/v1"
+	"github.com/danielkhtse/supreme-adventure/common/requestmeta"
+	"github.com/danielkhtse/supreme-adventure/common/stream"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// Accounts gRPC server
+type Server struct {
+	ledgerv1.UnimplementedAccountServiceServer
+
+	AccountService *service.AccountService
+	Streamer       *stream.Handler
+	Auth           *auth.Authenticator
+	Service        *auth.ServiceAuth
+	Policy         authz.Policy
+	GRPCServer     *grpc.Server
+	Health         *health.Server
+	Port           string
+}
+
+func (server *Server) Initialize(accountService *service.AccountService, authenticator *auth.Authenticator, serviceAuth *auth.ServiceAuth) {
+	server.AccountService = accountService
+	server.Auth = authenticator
+	server.Service = serviceAuth
+	server.Policy = accountService.Policy()
+	server.Port = os.Getenv("ACCOUNT_GRPC_SERVER_PORT")
+
+	if server.Port == "" {
+		log.Fatal("ACCOUNT_GRPC_SERVER_PORT environment variable not set")
+	}
+
+	server.Streamer = &stream.Handler{
+		Feed:       accountService,
+		Broker:     accountService.Events(),
+		Authorizer: authz.StreamAuthorizer{Policy: server.Policy},
+		Types:      []events.Type{events.TypeAccountBalanceChanged},
+	}
+
+	server.GRPCServer = NewGRPCServer(server)
+}
+
+// methodScopes are the scopes required by the methods of the account service, health checks and reflection are
+// served without authentication
+var methodScopes = map[string]string{
+	ledgerv1.AccountService_CreateAccount_FullMethodName: auth.ScopeAccountsWrite,
+	ledgerv1.AccountService_GetAccount_FullMethodName:    auth.ScopeAccountsRead,
+	ledgerv1.AccountService_TransferFunds_FullMethodName: auth.ScopeTransactionsWrite,
+	ledgerv1.AccountService_WatchAccount_FullMethodName:  auth.ScopeAccountsRead,
+}
+
+// internalMethods are the methods served only to services presenting a service token
+var internalMethods = map[string]bool{
+	ledgerv1.AccountService_TransferFunds_FullMethodName: true,
+}
+
+// NewGRPCServer creates a gRPC server serving the account service, the health service and reflection
+func NewGRPCServer(server *Server) *grpc.Server {
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			requestmeta.UnaryServerInterceptor(),
+			server.Service.UnaryServerInterceptor(internalMethods),
+			server.Auth.UnaryServerInterceptor(methodScopes),
+		),
+		grpc.ChainStreamInterceptor(requestmeta.StreamServerInterceptor(), server.Auth.StreamServerInterceptor(methodScopes)),
+	)
+	ledgerv1.RegisterAccountServiceServer(grpcServer, server)
+
+	server.Health = health.NewServer()
+	server.Health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	server.Health.SetServingStatus(ledgerv1.AccountService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(grpcServer, server.Health)
+
+	reflection.Register(grpcServer)
+	return grpcServer
+}
+
+func (server *Server) Run() {
+	lis, err := net.Listen("tcp", ":"+server.Port)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Info("Listening to port " + server.Port)
+	log.Info("Account gRPC Server Started")
+	log.Fatal(server.GRPCServer.Serve(lis))
+}
@@ -0,0 +1,150 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/auth"
+	"github.com/danielkhtse/supreme-adventure/common/auth/authtest"
+	"github.com/danielkhtse/supreme-adventure/common/authz"
+	ledgerv1 "github.com/danielkhtse/supreme-adventure/common/proto/ledger/v1"
+	"github.com/danielkhtse/supreme-adventure/common/stream"
+	"github.com/danielkhtse/supreme-adventure/common/stream/streamtest"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestUnitToStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		code codes.Code
+	}{
+		{apperr.ErrAccountNotFound, codes.NotFound},
+		{apperr.ErrAccountAlreadyExists.WithMessage("account with ID 1 already exists"), codes.AlreadyExists},
+		{apperr.ErrTransferIDConflict, codes.AlreadyExists},
+		{apperr.ErrInsufficientFunds, codes.FailedPrecondition},
+		{apperr.ErrAccountFrozen, codes.FailedPrecondition},
+		{apperr.ErrVersionMismatch, codes.Aborted},
+		{apperr.ErrLockTimeout, codes.Aborted},
+		{apperr.Invalid("amount must be positive"), codes.InvalidArgument},
+		{apperr.ErrSameAccount, codes.InvalidArgument},
+		{apperr.ErrAccountAccessDenied, codes.PermissionDenied},
+		{fmt.Errorf("failed to list: %w", apperr.ErrInvalidCursor), codes.InvalidArgument},
+		{errors.New("connection refused"), codes.Internal},
+	}
+
+	for _, test := range tests {
+		t.Run(test.err.Error(), func(t *testing.T) {
+			assert.Equal(t, test.code, status.Code(toStatus(test.err)))
+		})
+	}
+}
+
+func TestUnitMethodScopes(t *testing.T) {
+	//a method missing from methodScopes would be served without authentication
+	desc := ledgerv1.AccountService_ServiceDesc
+	for _, method := range desc.Methods {
+		assert.Contains(t, methodScopes, "/"+desc.ServiceName+"/"+method.MethodName)
+	}
+	for _, stream := range desc.Streams {
+		assert.Contains(t, methodScopes, "/"+desc.ServiceName+"/"+stream.StreamName)
+	}
+}
+
+func TestUnitGRPCServer(t *testing.T) {
+	issuer := authtest.NewIssuer(t)
+	lis := bufconn.Listen(1024 * 1024)
+	serviceAuth, err := auth.NewServiceAuth("transaction-service", []string{strings.Repeat("s", 32)})
+	require.NoError(t, err)
+	server := &Server{Auth: issuer.Authenticator, Service: serviceAuth, Policy: denyAll{}}
+	server.Streamer = &stream.Handler{Feed: streamtest.NewFeed(), Authorizer: authz.StreamAuthorizer{Policy: server.Policy}}
+	grpcServer := NewGRPCServer(server)
+	go grpcServer.Serve(lis)
+	defer grpcServer.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	t.Run("Health", func(t *testing.T) {
+		resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{
+			Service: ledgerv1.AccountService_ServiceDesc.ServiceName,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+	})
+
+	t.Run("Authentication", func(t *testing.T) {
+		client := ledgerv1.NewAccountServiceClient(conn)
+
+		_, err := client.CreateAccount(context.Background(), &ledgerv1.CreateAccountRequest{})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", issuer.Token(t, "user-1", auth.ScopeAccountsRead))
+		_, err = client.CreateAccount(ctx, &ledgerv1.CreateAccountRequest{})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("Negative initial balance", func(t *testing.T) {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", issuer.Token(t, "user-1", auth.ScopeAccountsWrite))
+		_, err := ledgerv1.NewAccountServiceClient(conn).CreateAccount(ctx, &ledgerv1.CreateAccountRequest{
+			InitialBalance: -1,
+		})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Account access denied", func(t *testing.T) {
+		client := ledgerv1.NewAccountServiceClient(conn)
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization",
+			issuer.Token(t, "user-1", auth.ScopeAccountsRead, auth.ScopeTransactionsWrite))
+
+		_, err := client.GetAccount(ctx, &ledgerv1.GetAccountRequest{AccountId: 1})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		e, ok := apperr.FromGRPC(err)
+		require.True(t, ok)
+		assert.ErrorIs(t, e, apperr.ErrAccountAccessDenied)
+
+		ctx, err = serviceAuth.OutgoingContext(ctx)
+		require.NoError(t, err)
+		_, err = client.TransferFunds(ctx, &ledgerv1.TransferFundsRequest{SourceAccountId: 1, DestAccountId: 2, Amount: 100})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+		watch, err := client.WatchAccount(ctx, &ledgerv1.WatchAccountRequest{AccountId: 1})
+		require.NoError(t, err)
+		_, err = watch.Recv()
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("Transfer without service token", func(t *testing.T) {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization",
+			issuer.Token(t, "user-1", auth.ScopeTransactionsWrite))
+
+		_, err := ledgerv1.NewAccountServiceClient(conn).TransferFunds(ctx, &ledgerv1.TransferFundsRequest{SourceAccountId: 1, DestAccountId: 2, Amount: 100})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+// denyAll is a policy denying every action
+type denyAll struct{}
+
+func (denyAll) Authorize(_ context.Context, _ string, action authz.Action, accountID types.AccountID) error {
+	return apperr.ErrAccountAccessDenied.WithMessage("the caller may not %s account %d", action, accountID)
+}
@@ -1,12 +1,20 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
 	"os"
 
+	"github.com/danielkhtse/supreme-adventure/account-service/internal/client"
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/auth"
+	"github.com/danielkhtse/supreme-adventure/common/authz"
 	"github.com/danielkhtse/supreme-adventure/common/db"
+	"github.com/danielkhtse/supreme-adventure/common/events"
+	"github.com/danielkhtse/supreme-adventure/common/feed"
+	"github.com/danielkhtse/supreme-adventure/common/idgen"
 	"github.com/danielkhtse/supreme-adventure/common/models"
 	"github.com/danielkhtse/supreme-adventure/common/types"
 	"github.com/joho/godotenv"
@@ -15,7 +23,20 @@ import (
 
 // AccountService handles business logic for account operations
 type AccountService struct {
-	db *gorm.DB
+	db          *gorm.DB
+	idGenerator idgen.Generator
+	broker      *events.Broker
+	feed        *feed.Store
+
+	// apiKeys verifies the API keys managed by transaction-service, which shares the api_keys table
+	apiKeys *auth.APIKeyStore
+
+	// policy authorizes callers to act on accounts, with the roles granted in the account_grants table shared
+	// with transaction-service
+	policy authz.Policy
+
+	// transactions is nil when transaction-service is not configured, statements then carry no remittance information
+	transactions TransactionSource
 }
 
 // NewAccountService creates a new AccountService instance
@@ -38,28 +59,118 @@ func NewAccountService() *AccountService {
 	}
 
 	//TODO: use migration script to replace AutoMigrate
-	if err := db.GetDB().AutoMigrate(&models.Account{}); err != nil {
+	if err := db.GetDB().AutoMigrate(&models.Account{}, &models.AppliedTransfer{}, &models.AccountActivity{}, &models.AccountImport{}, &models.AccountImportError{}); err != nil {
+		log.Fatal(err)
+	}
+
+	idGenerator, err := idgen.NewGeneratorFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	retention, err := feed.RetentionFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	broker := events.NewBroker()
+	eventStore := feed.NewStore(db.GetDB(), models.AccountEventTableName, broker, retention)
+	if err := eventStore.Migrate(); err != nil {
+		log.Fatal(err)
+	}
+
+	apiKeys := auth.NewAPIKeyStore(db.GetDB())
+	if err := apiKeys.Migrate(); err != nil {
+		log.Fatal(err)
+	}
+
+	roles, err := authz.RolesFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	policy := authz.NewGrantPolicy(db.GetDB(), roles)
+	if err := policy.Migrate(); err != nil {
 		log.Fatal(err)
 	}
 
-	return &AccountService{
-		db: db.GetDB(),
+	service := &AccountService{
+		db:          db.GetDB(),
+		idGenerator: idGenerator,
+		broker:      broker,
+		feed:        eventStore,
+		apiKeys:     apiKeys,
+		policy:      policy,
 	}
+	if url := os.Getenv(client.TransactionServiceURLEnv); url != "" {
+		service.transactions = client.NewTransactionLookup(url)
+	}
+	return service
+}
+
+// APIKeys returns the store verifying the API keys forwarded by transaction-service
+func (s *AccountService) APIKeys() *auth.APIKeyStore {
+	return s.apiKeys
+}
+
+// Policy returns the policy authorizing callers to act on accounts
+func (s *AccountService) Policy() authz.Policy {
+	return s.policy
 }
 
-// CreateAccount creates a new account
-func (s *AccountService) CreateAccount(account *models.Account) error {
+// CreateAccount creates a new account, an ID is generated when none is provided. The authenticated caller
+// creating the account becomes its owner.
+func (s *AccountService) CreateAccount(ctx context.Context, account *models.Account) error {
 	if account == nil {
 		return errors.New("account cannot be nil")
 	}
 
+	if account.ID == 0 {
+		id, err := s.idGenerator.NextID()
+		if err != nil {
+			return fmt.Errorf("failed to generate account ID: %w", err)
+		}
+		account.ID = types.AccountID(id)
+	}
+
 	// Check if account already exists
 	var existingAccount models.Account
 	if err := s.db.Model(&models.Account{}).First(&existingAccount, "id = ?", account.ID).Error; err == nil {
-		return fmt.Errorf("account with ID %d already exists", account.ID)
+		return apperr.ErrAccountAlreadyExists.WithMessage("account with ID %d already exists", account.ID)
+	}
+
+	var recorded []events.Event
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Account{}).Create(account).Error; err != nil {
+			return err
+		}
+
+		if err := s.recordOpeningActivity(tx, account); err != nil {
+			return err
+		}
+
+		if identity, ok := auth.FromContext(ctx); ok {
+			owner := &models.AccountGrant{
+				AccountID: account.ID,
+				Principal: identity.Subject,
+				Role:      types.AccountRoleOwner,
+				GrantedBy: identity.Subject,
+			}
+			if err := tx.Create(owner).Error; err != nil {
+				return err
+			}
+		}
+
+		var err error
+		recorded, err = s.recordAccountCreated(tx, account)
+		return err
+	})
+	if err != nil {
+		return err
 	}
 
-	return s.db.Model(&models.Account{}).Create(account).Error
+	auth.Audit(ctx).WithField("account_id", account.ID).Info("account created")
+	s.publishEvents(recorded)
+	return nil
 }
 
 // GetAccount retrieves an account by ID
@@ -67,7 +178,7 @@ func (s *AccountService) GetAccount(id types.AccountID) (*models.Account, error)
 	var account models.Account
 	if err := s.db.First(&account, "id = ?", id).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("account not found")
+			return nil, apperr.ErrAccountNotFound
 		}
 		return nil, err
 	}
@@ -1,32 +1,64 @@
 package service
 
 import (
+	"context"
 	"errors"
 
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/auth"
 	"github.com/danielkhtse/supreme-adventure/common/models"
 	"github.com/danielkhtse/supreme-adventure/common/types"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	log "github.com/sirupsen/logrus"
 )
 
+// accountLockTimeout bounds how long a transaction waits for an account row locked by another transaction
+const accountLockTimeout = "5s"
+
+// lockNotAvailable is the SQLSTATE Postgres reports when lock_timeout expires
+const lockNotAvailable = "55P03"
+
+// TransferOptions are the optional parameters of TransferFunds
+type TransferOptions struct {
+	// TransferID makes the call idempotent, a transfer already applied under the same ID succeeds without moving funds again
+	TransferID string
+
+	// TransactionID links the resulting account activity to the transaction-service transaction
+	TransactionID types.TransactionID
+
+	// IfMatch, when set, requires the source account version to equal one of the values
+	IfMatch []types.AccountVersion
+}
+
 // TransferFunds transfers funds between two accounts
-func (s *AccountService) TransferFunds(sourceAccountID types.AccountID, destAccountID types.AccountID, amount types.AccountBalance) error {
+func (s *AccountService) TransferFunds(ctx context.Context, sourceAccountID types.AccountID, destAccountID types.AccountID, amount types.AccountBalance, opts TransferOptions) error {
+	transferID := opts.TransferID
 	log.WithFields(log.Fields{
+		"transfer_id":       transferID,
+		"transaction_id":    opts.TransactionID,
 		"source_account_id": sourceAccountID,
 		"dest_account_id":   destAccountID,
 		"amount":            amount,
 	}).Info("starting funds transfer")
 
 	if amount <= 0 {
-		log.WithError(errors.New("amount must be positive")).Error("invalid transfer amount")
-		return errors.New("amount must be positive")
+		err := apperr.Invalid("amount must be positive")
+		log.WithError(err).Error("invalid transfer amount")
+		return err
+	}
+
+	if len(transferID) > 64 {
+		log.WithField("transfer_id", transferID).Error("transfer ID too long")
+		return apperr.Invalid("transfer ID must be at most 64 characters")
 	}
 
 	// Prevent self-transfers
 	if sourceAccountID == destAccountID {
-		log.WithError(errors.New("cannot transfer to same account")).Error("invalid transfer")
-		return errors.New("cannot transfer to same account")
+		log.WithError(apperr.ErrSameAccount).Error("invalid transfer")
+		return apperr.ErrSameAccount
 	}
 
 	// Lock accounts in consistent order to prevent deadlocks
@@ -50,34 +82,26 @@ func (s *AccountService) TransferFunds(sourceAccountID types.AccountID, destAcco
 		}
 	}()
 
+	if err := setLockTimeout(tx); err != nil {
+		tx.Rollback()
+		log.WithError(err).Error("failed to set lock timeout")
+		return err
+	}
+
 	log.WithField("account_id", firstAccountID).Debug("acquiring lock on first account")
 	var firstAccount models.Account
-	if err := tx.Set("gorm:query_option", "FOR UPDATE WAIT 5").First(&firstAccount, firstAccountID).Error; err != nil {
+	if err := lockAccount(tx, &firstAccount, firstAccountID); err != nil {
 		tx.Rollback()
 		log.WithError(err).Error("failed to acquire first account")
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("account not found")
-		}
-		// Check for lock timeout error
-		if err.Error() == "lock timeout" {
-			return errors.New("failed to acquire lock - timeout after 5 seconds")
-		}
-		return err
+		return lockError(err, firstAccountID == sourceAccountID)
 	}
 
 	log.WithField("account_id", secondAccountID).Debug("acquiring lock on second account")
 	var secondAccount models.Account
-	if err := tx.Set("gorm:query_option", "FOR UPDATE WAIT 5").First(&secondAccount, secondAccountID).Error; err != nil {
+	if err := lockAccount(tx, &secondAccount, secondAccountID); err != nil {
 		tx.Rollback()
 		log.WithError(err).Error("failed to acquire second account")
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("account not found")
-		}
-		// Check for lock timeout error
-		if err.Error() == "lock timeout" {
-			return errors.New("failed to acquire lock - timeout after 5 seconds")
-		}
-		return err
+		return lockError(err, secondAccountID == sourceAccountID)
 	}
 
 	// Map back to source and dest accounts
@@ -88,23 +112,74 @@ func (s *AccountService) TransferFunds(sourceAccountID types.AccountID, destAcco
 		destAccount = firstAccount
 	}
 
+	// Checked after locking so a concurrent request with the same transfer ID has committed by now
+	if transferID != "" {
+		var applied models.AppliedTransfer
+		result := tx.Where("transfer_id = ?", transferID).Limit(1).Find(&applied)
+		if result.Error != nil {
+			tx.Rollback()
+			log.WithError(result.Error).Error("failed to look up transfer ID")
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			tx.Rollback()
+			if !applied.SameTransfer(sourceAccountID, destAccountID, amount) {
+				log.WithField("transfer_id", transferID).Error("transfer ID reused for a different transfer")
+				return apperr.ErrTransferIDConflict
+			}
+			log.WithField("transfer_id", transferID).Info("transfer already applied")
+			return nil
+		}
+	}
+
+	for _, account := range []*models.Account{&sourceAccount, &destAccount} {
+		if account.Status == types.AccountStatusInactive {
+			tx.Rollback()
+			log.WithField("account_id", account.ID).Error("account is inactive")
+			return apperr.ErrAccountFrozen.WithMessage("account %d is inactive", account.ID)
+		}
+	}
+
+	if len(opts.IfMatch) > 0 && !matchesVersion(sourceAccount.Version, opts.IfMatch) {
+		tx.Rollback()
+		log.WithFields(log.Fields{
+			"current_version":  sourceAccount.Version,
+			"expected_version": opts.IfMatch,
+		}).Error("source account version mismatch")
+		return apperr.ErrVersionMismatch
+	}
+
+	// Balances are computed with checked arithmetic, both accounts must hold the currency of the source account
+	transferred := types.Money{Amount: amount, Currency: sourceAccount.Currency}
+	sourceBalance, err := sourceAccount.BalanceMoney().Sub(transferred)
+	if err != nil {
+		tx.Rollback()
+		log.WithError(err).Error("failed to compute source account balance")
+		return apperr.Money(err)
+	}
+	destBalance, err := destAccount.BalanceMoney().Add(transferred)
+	if err != nil {
+		tx.Rollback()
+		log.WithError(err).Error("failed to compute destination account balance")
+		return apperr.Money(err)
+	}
+
 	// Check balance after getting locked records
-	if sourceAccount.Balance < amount {
+	if sourceBalance.Amount < 0 {
 		tx.Rollback()
 		log.WithFields(log.Fields{
 			"available_balance": sourceAccount.Balance,
 			"required_amount":   amount,
 		}).Error("insufficient balance")
-		return errors.New("insufficient balance")
+		return apperr.ErrInsufficientFunds
 	}
 
 	log.WithFields(log.Fields{
 		"account_id":  sourceAccount.ID,
 		"old_balance": sourceAccount.Balance,
-		"new_balance": sourceAccount.Balance - amount,
+		"new_balance": sourceBalance.Amount,
 	}).Debug("updating source account balance")
-	sourceAccount.Balance -= amount
-	if err := tx.Save(&sourceAccount).Error; err != nil {
+	if err := updateBalance(tx, &sourceAccount, sourceBalance.Amount); err != nil {
 		tx.Rollback()
 		log.WithError(err).Error("failed to update source account")
 		return err
@@ -113,15 +188,40 @@ func (s *AccountService) TransferFunds(sourceAccountID types.AccountID, destAcco
 	log.WithFields(log.Fields{
 		"account_id":  destAccount.ID,
 		"old_balance": destAccount.Balance,
-		"new_balance": destAccount.Balance + amount,
+		"new_balance": destBalance.Amount,
 	}).Debug("updating destination account balance")
-	destAccount.Balance += amount
-	if err := tx.Save(&destAccount).Error; err != nil {
+	if err := updateBalance(tx, &destAccount, destBalance.Amount); err != nil {
 		tx.Rollback()
 		log.WithError(err).Error("failed to update destination account")
 		return err
 	}
 
+	if transferID != "" {
+		if err := tx.Create(&models.AppliedTransfer{
+			TransferID:      transferID,
+			SourceAccountID: sourceAccountID,
+			DestAccountID:   destAccountID,
+			Amount:          amount,
+		}).Error; err != nil {
+			tx.Rollback()
+			log.WithError(err).Error("failed to record transfer ID")
+			return err
+		}
+	}
+
+	if err := s.recordTransferActivity(tx, &sourceAccount, &destAccount, amount, opts); err != nil {
+		tx.Rollback()
+		log.WithError(err).Error("failed to record account activity")
+		return err
+	}
+
+	recorded, err := s.recordTransferEvents(tx, &sourceAccount, &destAccount, amount, opts.TransactionID)
+	if err != nil {
+		tx.Rollback()
+		log.WithError(err).Error("failed to record transfer events")
+		return err
+	}
+
 	log.Debug("committing transaction")
 	if err := tx.Commit().Error; err != nil {
 		tx.Rollback()
@@ -129,9 +229,74 @@ func (s *AccountService) TransferFunds(sourceAccountID types.AccountID, destAcco
 		return err
 	}
 
-	log.WithFields(log.Fields{
-		"source_balance": sourceAccount.Balance,
-		"dest_balance":   destAccount.Balance,
+	auth.Audit(ctx).WithFields(log.Fields{
+		"transfer_id":       transferID,
+		"transaction_id":    opts.TransactionID,
+		"source_account_id": sourceAccountID,
+		"dest_account_id":   destAccountID,
+		"amount":            amount,
+		"source_balance":    sourceAccount.Balance,
+		"dest_balance":      destAccount.Balance,
 	}).Info("successfully completed funds transfer")
+
+	s.publishEvents(recorded)
+	return nil
+}
+
+// updateBalance writes the new balance of an account and bumps its version. Only the balance is
+// written and the version guard rejects the update if the row changed since it was read.
+func updateBalance(tx *gorm.DB, account *models.Account, balance types.AccountBalance) error {
+	result := tx.Model(account).Where("version = ?", account.Version).Updates(map[string]any{
+		"balance": balance,
+		"version": gorm.Expr("version + 1"),
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return apperr.ErrVersionMismatch
+	}
+
+	account.Balance = balance
+	account.Version++
 	return nil
 }
+
+// lockError converts the error of locking an account row
+func lockError(err error, source bool) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if source {
+			return apperr.ErrAccountNotFound.WithMessage("source account not found")
+		}
+		return apperr.ErrAccountNotFound.WithMessage("destination account not found")
+	}
+	if isLockTimeout(err) {
+		return apperr.ErrLockTimeout
+	}
+	return err
+}
+
+// setLockTimeout makes the row locks of the transaction fail after accountLockTimeout instead of waiting indefinitely
+func setLockTimeout(tx *gorm.DB) error {
+	return tx.Exec("SET LOCAL lock_timeout = '" + accountLockTimeout + "'").Error
+}
+
+// lockAccount reads an account with SELECT ... FOR UPDATE, holding the row until the transaction ends
+func lockAccount(tx *gorm.DB, account *models.Account, id types.AccountID) error {
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(account, id).Error
+}
+
+// isLockTimeout reports whether err is Postgres giving up on a row lock after lock_timeout
+func isLockTimeout(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == lockNotAvailable
+}
+
+func matchesVersion(version types.AccountVersion, candidates []types.AccountVersion) bool {
+	for _, candidate := range candidates {
+		if candidate == version {
+			return true
+		}
+	}
+	return false
+}
@@ -1,21 +1,37 @@
 package service
 
 import (
+	"context"
+	"math"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/idgen"
 	"github.com/danielkhtse/supreme-adventure/common/types"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 )
 
+// lockedAccountQuery is the SELECT ... FOR UPDATE that locks an account row
+const lockedAccountQuery = `SELECT \* FROM "accounts" WHERE "accounts"."id" = \$1 ORDER BY "accounts"."id" LIMIT \$2 FOR UPDATE`
+
+// lockTimeoutStatement bounds the wait for locked account rows
+const lockTimeoutStatement = `SET LOCAL lock_timeout = '5s'`
+
 func TestUnitTransferFunds(t *testing.T) {
 	mockDB, mock, db := setupMockDB(t)
 	defer mockDB.Close()
 
+	idGenerator, err := idgen.NewSnowflake(1)
+	require.NoError(t, err)
+
 	service := &AccountService{
-		db: db,
+		db:          db,
+		idGenerator: idGenerator,
 	}
 
 	t.Run("Successful transfer", func(t *testing.T) {
@@ -26,41 +42,50 @@ func TestUnitTransferFunds(t *testing.T) {
 
 		// Begin transaction
 		mock.ExpectBegin()
+		mock.ExpectExec(lockTimeoutStatement).WillReturnResult(sqlmock.NewResult(0, 0))
 
-		// Query source account with FOR UPDATE NOWAIT
-		mock.ExpectQuery(`SELECT \* FROM "accounts" WHERE "accounts"."id" = \$1 ORDER BY "accounts"."id" LIMIT \$2`).
+		// Lock source account
+		mock.ExpectQuery(lockedAccountQuery).
 			WithArgs(1, 1).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "initial_balance", "currency", "status", "created_at", "updated_at"}).
-				AddRow(sourceID, float64(100), float64(100), "USD", "active", time.Time{}, time.Time{}))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "initial_balance", "currency", "status", "version", "created_at", "updated_at"}).
+				AddRow(sourceID, float64(100), float64(100), "USD", "active", 3, time.Time{}, time.Time{}))
 
 		t.Log("Source account found with balance: 100")
 
-		// Query destination account with FOR UPDATE NOWAIT
-		mock.ExpectQuery(`SELECT \* FROM "accounts" WHERE "accounts"."id" = \$1 ORDER BY "accounts"."id" LIMIT \$2`).
+		// Lock destination account
+		mock.ExpectQuery(lockedAccountQuery).
 			WithArgs(2, 1).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "initial_balance", "currency", "status", "created_at", "updated_at"}).
-				AddRow(destID, float64(0), float64(0), "USD", "active", time.Time{}, time.Time{}))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "initial_balance", "currency", "status", "version", "created_at", "updated_at"}).
+				AddRow(destID, float64(0), float64(0), "USD", "active", 3, time.Time{}, time.Time{}))
 
 		t.Log("Destination account found with balance: 0")
 
 		// Update source account
-		mock.ExpectExec(`UPDATE "accounts" SET "balance"=\$1,"initial_balance"=\$2,"currency"=\$3,"status"=\$4,"created_at"=\$5,"updated_at"=\$6 WHERE "id" = \$7`).
-			WithArgs(50, 100, "USD", "active", time.Time{}, sqlmock.AnyArg(), 1).
+		mock.ExpectExec(`UPDATE "accounts" SET "balance"=\$1,"version"=version \+ 1,"updated_at"=\$2 WHERE version = \$3 AND "id" = \$4`).
+			WithArgs(50, sqlmock.AnyArg(), 3, 1).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		t.Log("Updated source account balance to: 50")
 
 		// Update destination account
-		mock.ExpectExec(`UPDATE "accounts" SET "balance"=\$1,"initial_balance"=\$2,"currency"=\$3,"status"=\$4,"created_at"=\$5,"updated_at"=\$6 WHERE "id" = \$7`).
-			WithArgs(50, 0, "USD", "active", time.Time{}, sqlmock.AnyArg(), 2).
+		mock.ExpectExec(`UPDATE "accounts" SET "balance"=\$1,"version"=version \+ 1,"updated_at"=\$2 WHERE version = \$3 AND "id" = \$4`).
+			WithArgs(50, sqlmock.AnyArg(), 3, 2).
 			WillReturnResult(sqlmock.NewResult(1, 1))
 
 		t.Log("Updated destination account balance to: 50")
 
+		// Record the debit and credit activity of both accounts
+		mock.ExpectExec(`INSERT INTO "account_activities" \("id","account_id","type","direction","counterparty_account_id","amount","balance_after","transaction_id","transfer_id","created_at"\) VALUES \(\$1,\$2,\$3,\$4,\$5,\$6,\$7,\$8,\$9,\$10\),\(\$11,\$12,\$13,\$14,\$15,\$16,\$17,\$18,\$19,\$20\)`).
+			WithArgs(
+				sqlmock.AnyArg(), 1, "transfer", "debit", 2, 50, 50, 0, "", sqlmock.AnyArg(),
+				sqlmock.AnyArg(), 2, "transfer", "credit", 1, 50, 50, 0, "", sqlmock.AnyArg(),
+			).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+
 		// Commit transaction
 		mock.ExpectCommit()
 
-		err := service.TransferFunds(sourceID, destID, amount)
+		err := service.TransferFunds(context.Background(), sourceID, destID, amount, TransferOptions{IfMatch: []types.AccountVersion{3}})
 		assert.NoError(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 		t.Log("Transfer completed successfully")
@@ -77,40 +102,273 @@ func TestUnitTransferFunds(t *testing.T) {
 
 		// Begin transaction
 		mock.ExpectBegin()
+		mock.ExpectExec(lockTimeoutStatement).WillReturnResult(sqlmock.NewResult(0, 0))
 
-		// Query source account with FOR UPDATE NOWAIT
-		mock.ExpectQuery(`SELECT \* FROM "accounts" WHERE "accounts"."id" = \$1 ORDER BY "accounts"."id" LIMIT \$2`).
+		// Lock source account
+		mock.ExpectQuery(lockedAccountQuery).
 			WithArgs(1, 1).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "initial_balance", "currency", "status", "created_at", "updated_at"}).
-				AddRow(1, 100, 0, "", "", createdAt, updatedAt))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "initial_balance", "currency", "status", "version", "created_at", "updated_at"}).
+				AddRow(1, 100, 0, "", "", 1, createdAt, updatedAt))
 
 		t.Log("Source account found with balance: 100")
 
-		// Query destination account with FOR UPDATE NOWAIT
-		mock.ExpectQuery(`SELECT \* FROM "accounts" WHERE "accounts"."id" = \$1 ORDER BY "accounts"."id" LIMIT \$2`).
+		// Lock destination account
+		mock.ExpectQuery(lockedAccountQuery).
 			WithArgs(2, 1).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "initial_balance", "currency", "status", "created_at", "updated_at"}).
-				AddRow(2, 0, 0, "", "", createdAt, updatedAt))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "initial_balance", "currency", "status", "version", "created_at", "updated_at"}).
+				AddRow(2, 0, 0, "", "", 1, createdAt, updatedAt))
 
 		t.Log("Destination account found with balance: 0")
 
 		// Expect rollback since balance is insufficient
 		mock.ExpectRollback()
 
-		err := service.TransferFunds(sourceID, destID, amount)
+		err := service.TransferFunds(context.Background(), sourceID, destID, amount, TransferOptions{})
 		assert.Error(t, err)
+		assert.ErrorIs(t, err, apperr.ErrInsufficientFunds)
 		assert.Contains(t, err.Error(), "insufficient balance")
 		assert.NoError(t, mock.ExpectationsWereMet())
 		t.Log("Transfer failed as expected due to insufficient balance")
 	})
 
+	t.Run("Frozen account", func(t *testing.T) {
+		t.Log("Testing transfer to an inactive account")
+		createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(lockTimeoutStatement).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(lockedAccountQuery).
+			WithArgs(1, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "initial_balance", "currency", "status", "version", "created_at", "updated_at"}).
+				AddRow(1, 100, 0, "", "active", 1, createdAt, createdAt))
+		mock.ExpectQuery(lockedAccountQuery).
+			WithArgs(2, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "initial_balance", "currency", "status", "version", "created_at", "updated_at"}).
+				AddRow(2, 0, 0, "", "inactive", 1, createdAt, createdAt))
+		mock.ExpectRollback()
+
+		err := service.TransferFunds(context.Background(), 1, 2, 50, TransferOptions{})
+		assert.ErrorIs(t, err, apperr.ErrAccountFrozen)
+		assert.EqualError(t, err, "account 2 is inactive")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Currency mismatch", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(lockTimeoutStatement).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(lockedAccountQuery).
+			WithArgs(1, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "version"}).AddRow(1, 100, "USD", "active", 1))
+		mock.ExpectQuery(lockedAccountQuery).
+			WithArgs(2, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "version"}).AddRow(2, 0, "EUR", "active", 1))
+		mock.ExpectRollback()
+
+		err := service.TransferFunds(context.Background(), 1, 2, 50, TransferOptions{})
+		assert.ErrorIs(t, err, apperr.ErrCurrencyMismatch)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Balance overflow", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(lockTimeoutStatement).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(lockedAccountQuery).
+			WithArgs(1, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "version"}).AddRow(1, 100, "USD", "active", 1))
+		mock.ExpectQuery(lockedAccountQuery).
+			WithArgs(2, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "version"}).AddRow(2, int64(math.MaxInt64-10), "USD", "active", 1))
+		mock.ExpectRollback()
+
+		err := service.TransferFunds(context.Background(), 1, 2, 50, TransferOptions{})
+		assert.ErrorIs(t, err, apperr.ErrInvalidArgument)
+		assert.Contains(t, err.Error(), "amount out of range")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Version mismatch", func(t *testing.T) {
+		t.Log("Testing transfer with a stale If-Match version")
+		sourceID := types.AccountID(1)
+		destID := types.AccountID(2)
+		amount := types.AccountBalance(50)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(lockTimeoutStatement).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		mock.ExpectQuery(lockedAccountQuery).
+			WithArgs(1, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "version"}).AddRow(1, 100, "USD", "active", 4))
+
+		mock.ExpectQuery(lockedAccountQuery).
+			WithArgs(2, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "version"}).AddRow(2, 0, "USD", "active", 1))
+
+		// Expect rollback since the source account changed since version 3
+		mock.ExpectRollback()
+
+		err := service.TransferFunds(context.Background(), sourceID, destID, amount, TransferOptions{IfMatch: []types.AccountVersion{3}})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "version mismatch")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Concurrent modification", func(t *testing.T) {
+		t.Log("Testing transfer when the guarded update affects no rows")
+		sourceID := types.AccountID(1)
+		destID := types.AccountID(2)
+		amount := types.AccountBalance(50)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(lockTimeoutStatement).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		mock.ExpectQuery(lockedAccountQuery).
+			WithArgs(1, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "version"}).AddRow(1, 100, "USD", "active", 4))
+
+		mock.ExpectQuery(lockedAccountQuery).
+			WithArgs(2, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "version"}).AddRow(2, 0, "USD", "active", 1))
+
+		mock.ExpectExec(`UPDATE "accounts" SET "balance"=\$1,"version"=version \+ 1,"updated_at"=\$2 WHERE version = \$3 AND "id" = \$4`).
+			WithArgs(50, sqlmock.AnyArg(), 4, 1).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		mock.ExpectRollback()
+
+		err := service.TransferFunds(context.Background(), sourceID, destID, amount, TransferOptions{})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "version mismatch")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Duplicate transfer ID", func(t *testing.T) {
+		t.Log("Testing retried transfer with an already applied transfer ID")
+		sourceID := types.AccountID(1)
+		destID := types.AccountID(2)
+		amount := types.AccountBalance(50)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(lockTimeoutStatement).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		mock.ExpectQuery(lockedAccountQuery).
+			WithArgs(1, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "version"}).AddRow(1, 50, "USD", "active", 4))
+
+		mock.ExpectQuery(lockedAccountQuery).
+			WithArgs(2, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "version"}).AddRow(2, 50, "USD", "active", 2))
+
+		mock.ExpectQuery(`SELECT \* FROM "applied_transfers" WHERE transfer_id = \$1 LIMIT \$2`).
+			WithArgs("42", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"transfer_id", "source_account_id", "dest_account_id", "amount"}).
+				AddRow("42", 1, 2, 50))
+
+		// Nothing is written, the earlier transfer stands
+		mock.ExpectRollback()
+
+		err := service.TransferFunds(context.Background(), sourceID, destID, amount, TransferOptions{TransferID: "42"})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+
+		t.Log("Testing transfer ID reused with a different amount")
+		mock.ExpectBegin()
+		mock.ExpectExec(lockTimeoutStatement).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(lockedAccountQuery).
+			WithArgs(1, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "version"}).AddRow(1, 50, "USD", "active", 4))
+		mock.ExpectQuery(lockedAccountQuery).
+			WithArgs(2, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "version"}).AddRow(2, 50, "USD", "active", 2))
+		mock.ExpectQuery(`SELECT \* FROM "applied_transfers" WHERE transfer_id = \$1 LIMIT \$2`).
+			WithArgs("42", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"transfer_id", "source_account_id", "dest_account_id", "amount"}).
+				AddRow("42", 1, 2, 50))
+		mock.ExpectRollback()
+
+		err = service.TransferFunds(context.Background(), sourceID, destID, amount+1, TransferOptions{TransferID: "42"})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "already used")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Records transfer ID", func(t *testing.T) {
+		t.Log("Testing first transfer under a transfer ID")
+		sourceID := types.AccountID(1)
+		destID := types.AccountID(2)
+		amount := types.AccountBalance(50)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(lockTimeoutStatement).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(lockedAccountQuery).
+			WithArgs(1, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "version"}).AddRow(1, 100, "USD", "active", 1))
+		mock.ExpectQuery(lockedAccountQuery).
+			WithArgs(2, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "version"}).AddRow(2, 0, "USD", "active", 1))
+		mock.ExpectQuery(`SELECT \* FROM "applied_transfers" WHERE transfer_id = \$1 LIMIT \$2`).
+			WithArgs("43", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"transfer_id"}))
+		mock.ExpectExec(`UPDATE "accounts"`).WithArgs(50, sqlmock.AnyArg(), 1, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`UPDATE "accounts"`).WithArgs(50, sqlmock.AnyArg(), 1, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO "applied_transfers" \("transfer_id","source_account_id","dest_account_id","amount","created_at"\)`).
+			WithArgs("43", 1, 2, 50, sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO "account_activities"`).
+			WithArgs(
+				sqlmock.AnyArg(), 1, "transfer", "debit", 2, 50, 50, 43, "43", sqlmock.AnyArg(),
+				sqlmock.AnyArg(), 2, "transfer", "credit", 1, 50, 50, 43, "43", sqlmock.AnyArg(),
+			).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		err := service.TransferFunds(context.Background(), sourceID, destID, amount, TransferOptions{TransferID: "43", TransactionID: 43})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Overlapping retry", func(t *testing.T) {
+		t.Log("Testing a retry that waited on the lock held by the first attempt")
+		mock.ExpectBegin()
+		mock.ExpectExec(lockTimeoutStatement).WillReturnResult(sqlmock.NewResult(0, 0))
+		// The first attempt has committed, bumping the source version past the If-Match value
+		mock.ExpectQuery(lockedAccountQuery).
+			WithArgs(1, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "version"}).AddRow(1, 50, "USD", "active", 4))
+		mock.ExpectQuery(lockedAccountQuery).
+			WithArgs(2, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency", "status", "version"}).AddRow(2, 50, "USD", "active", 2))
+		mock.ExpectQuery(`SELECT \* FROM "applied_transfers" WHERE transfer_id = \$1 LIMIT \$2`).
+			WithArgs("44", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"transfer_id", "source_account_id", "dest_account_id", "amount"}).
+				AddRow("44", 1, 2, 50))
+		mock.ExpectRollback()
+
+		err := service.TransferFunds(context.Background(), 1, 2, 50, TransferOptions{TransferID: "44", IfMatch: []types.AccountVersion{3}})
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Lock timeout", func(t *testing.T) {
+		t.Log("Testing transfer when an account stays locked past the lock timeout")
+		mock.ExpectBegin()
+		mock.ExpectExec(lockTimeoutStatement).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(lockedAccountQuery).
+			WithArgs(1, 1).
+			WillReturnError(&pgconn.PgError{Code: "55P03", Message: "canceling statement due to lock timeout"})
+		mock.ExpectRollback()
+
+		err := service.TransferFunds(context.Background(), 1, 2, 50, TransferOptions{})
+		assert.ErrorIs(t, err, apperr.ErrLockTimeout)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
 	t.Run("Invalid amount", func(t *testing.T) {
 		t.Log("Testing transfer with invalid (zero) amount")
 		sourceID := types.AccountID(1)
 		destID := types.AccountID(2)
 		amount := types.AccountBalance(0)
 
-		err := service.TransferFunds(sourceID, destID, amount)
+		err := service.TransferFunds(context.Background(), sourceID, destID, amount, TransferOptions{})
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "amount must be positive")
 		assert.NoError(t, mock.ExpectationsWereMet())
@@ -125,15 +383,16 @@ func TestUnitTransferFunds(t *testing.T) {
 
 		// Begin transaction since TransferFunds starts a transaction
 		mock.ExpectBegin()
+		mock.ExpectExec(lockTimeoutStatement).WillReturnResult(sqlmock.NewResult(0, 0))
 
-		mock.ExpectQuery(`SELECT \* FROM "accounts" WHERE "accounts"."id" = \$1 ORDER BY "accounts"."id" LIMIT \$2`).
+		mock.ExpectQuery(lockedAccountQuery).
 			WithArgs(2, 1).
 			WillReturnError(gorm.ErrRecordNotFound)
 
 		// Expect rollback since source account not found
 		mock.ExpectRollback()
 
-		err := service.TransferFunds(sourceID, destID, amount)
+		err := service.TransferFunds(context.Background(), sourceID, destID, amount, TransferOptions{})
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "account not found")
 		assert.NoError(t, mock.ExpectationsWereMet())
@@ -151,24 +410,26 @@ func TestUnitTransferFunds(t *testing.T) {
 
 		// Begin transaction since TransferFunds starts a transaction
 		mock.ExpectBegin()
+		mock.ExpectExec(lockTimeoutStatement).WillReturnResult(sqlmock.NewResult(0, 0))
 
-		mock.ExpectQuery(`SELECT \* FROM "accounts" WHERE "accounts"."id" = \$1 ORDER BY "accounts"."id" LIMIT \$2`).
+		mock.ExpectQuery(lockedAccountQuery).
 			WithArgs(1, 1).
-			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "initial_balance", "currency", "status", "created_at", "updated_at"}).
-				AddRow(1, 100, 0, "", "", createdAt, updatedAt))
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "initial_balance", "currency", "status", "version", "created_at", "updated_at"}).
+				AddRow(1, 100, 0, "", "", 1, createdAt, updatedAt))
 
 		t.Log("Source account found with balance: 100")
 
-		mock.ExpectQuery(`SELECT \* FROM "accounts" WHERE "accounts"."id" = \$1 ORDER BY "accounts"."id" LIMIT \$2`).
+		mock.ExpectQuery(lockedAccountQuery).
 			WithArgs(999, 1).
 			WillReturnError(gorm.ErrRecordNotFound)
 
 		// Expect rollback since destination account not found
 		mock.ExpectRollback()
 
-		err := service.TransferFunds(sourceID, destID, amount)
+		err := service.TransferFunds(context.Background(), sourceID, destID, amount, TransferOptions{})
 		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "account not found")
+		assert.ErrorIs(t, err, apperr.ErrAccountNotFound)
+		assert.EqualError(t, err, "destination account not found")
 		assert.NoError(t, mock.ExpectationsWereMet())
 		t.Log("Transfer failed as expected due to destination account not found")
 	})
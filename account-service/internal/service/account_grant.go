@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/auth"
+	"github.com/danielkhtse/supreme-adventure/common/models"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"gorm.io/gorm/clause"
+)
+
+const maxPrincipalLength = 255
+
+// ListAccountGrants returns the roles granted on an account, by principal
+func (s *AccountService) ListAccountGrants(accountID types.AccountID) ([]models.AccountGrant, error) {
+	if _, err := s.GetAccount(accountID); err != nil {
+		return nil, err
+	}
+
+	var grants []models.AccountGrant
+	if err := s.db.Where("account_id = ?", accountID).Order("principal").Find(&grants).Error; err != nil {
+		return nil, fmt.Errorf("failed to list account grants: %w", err)
+	}
+	return grants, nil
+}
+
+// GrantAccountRole gives a principal a role on an account, replacing the role it held. The admin role is only
+// held on every account and cannot be granted per account.
+func (s *AccountService) GrantAccountRole(ctx context.Context, grant *models.AccountGrant) error {
+	if grant == nil {
+		return apperr.Invalid("grant cannot be nil")
+	}
+
+	grant.Principal = strings.TrimSpace(grant.Principal)
+	if grant.Principal == "" || len(grant.Principal) > maxPrincipalLength {
+		return apperr.Invalid("principal is required and must be at most %d characters", maxPrincipalLength)
+	}
+	switch grant.Role {
+	case types.AccountRoleOwner, types.AccountRoleOperator, types.AccountRoleAuditor:
+	default:
+		return apperr.Invalid("role must be one of owner, operator or auditor")
+	}
+
+	if _, err := s.GetAccount(grant.AccountID); err != nil {
+		return err
+	}
+
+	grant.GrantedBy = ""
+	if identity, ok := auth.FromContext(ctx); ok {
+		grant.GrantedBy = identity.Subject
+	}
+
+	err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "account_id"}, {Name: "principal"}},
+		DoUpdates: clause.AssignmentColumns([]string{"role", "granted_by", "updated_at"}),
+	}).Create(grant).Error
+	if err != nil {
+		return fmt.Errorf("failed to grant account role: %w", err)
+	}
+
+	auth.Audit(ctx).WithField("account_id", grant.AccountID).WithField("principal", grant.Principal).
+		WithField("role", grant.Role).Info("account role granted")
+	return nil
+}
+
+// RevokeAccountRole removes the role of a principal on an account, revoking a role which is not held succeeds
+// without changes
+func (s *AccountService) RevokeAccountRole(ctx context.Context, accountID types.AccountID, principal string) error {
+	if _, err := s.GetAccount(accountID); err != nil {
+		return err
+	}
+
+	result := s.db.Where("account_id = ? AND principal = ?", accountID, principal).Delete(&models.AccountGrant{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke account role: %w", result.Error)
+	}
+
+	if result.RowsAffected > 0 {
+		auth.Audit(ctx).WithField("account_id", accountID).WithField("principal", principal).Info("account role revoked")
+	}
+	return nil
+}
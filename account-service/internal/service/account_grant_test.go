@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/auth"
+	"github.com/danielkhtse/supreme-adventure/common/models"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+const accountQuery = `SELECT \* FROM "accounts" WHERE id = \$1 ORDER BY "accounts"."id" LIMIT \$2`
+
+func TestUnitGrantAccountRole(t *testing.T) {
+	mockDB, mock, db := setupMockDB(t)
+	defer mockDB.Close()
+
+	service := &AccountService{db: db}
+	ctx := auth.NewContext(context.Background(), &auth.Identity{Subject: "alice"})
+
+	t.Run("Granted", func(t *testing.T) {
+		mock.ExpectQuery(accountQuery).WithArgs(1, 1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO "account_grants" .* ON CONFLICT \("account_id","principal"\) DO UPDATE SET "role"="excluded"."role","granted_by"="excluded"."granted_by","updated_at"="excluded"."updated_at"`).
+			WithArgs(1, "bob", "operator", "alice", sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		grant := &models.AccountGrant{AccountID: 1, Principal: " bob ", Role: types.AccountRoleOperator}
+		require.NoError(t, service.GrantAccountRole(ctx, grant))
+		assert.Equal(t, "bob", grant.Principal)
+		assert.Equal(t, "alice", grant.GrantedBy)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			grant *models.AccountGrant
+		}{
+			{"Nil", nil},
+			{"Missing principal", &models.AccountGrant{AccountID: 1, Role: types.AccountRoleOwner}},
+			{"Unknown role", &models.AccountGrant{AccountID: 1, Principal: "bob", Role: "customer"}},
+			{"Admin role", &models.AccountGrant{AccountID: 1, Principal: "bob", Role: types.AccountRoleAdmin}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				assert.ErrorIs(t, service.GrantAccountRole(ctx, tt.grant), apperr.ErrInvalidArgument)
+			})
+		}
+	})
+
+	t.Run("Account not found", func(t *testing.T) {
+		mock.ExpectQuery(accountQuery).WillReturnError(gorm.ErrRecordNotFound)
+
+		err := service.GrantAccountRole(ctx, &models.AccountGrant{AccountID: 2, Principal: "bob", Role: types.AccountRoleAuditor})
+		assert.ErrorIs(t, err, apperr.ErrAccountNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUnitRevokeAccountRole(t *testing.T) {
+	mockDB, mock, db := setupMockDB(t)
+	defer mockDB.Close()
+
+	service := &AccountService{db: db}
+
+	t.Run("Revoked", func(t *testing.T) {
+		mock.ExpectQuery(accountQuery).WithArgs(1, 1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectBegin()
+		mock.ExpectExec(`DELETE FROM "account_grants" WHERE account_id = \$1 AND principal = \$2`).
+			WithArgs(1, "bob").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, service.RevokeAccountRole(context.Background(), 1, "bob"))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not granted", func(t *testing.T) {
+		mock.ExpectQuery(accountQuery).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectBegin()
+		mock.ExpectExec(`DELETE FROM "account_grants"`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		assert.NoError(t, service.RevokeAccountRole(context.Background(), 1, "bob"))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
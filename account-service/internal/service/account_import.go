@@ -0,0 +1,388 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/auth"
+	"github.com/danielkhtse/supreme-adventure/common/events"
+	"github.com/danielkhtse/supreme-adventure/common/models"
+	"github.com/danielkhtse/supreme-adventure/common/pagination"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	DefaultImportChunkSize = 500
+	MaxImportChunkSize     = 1000
+
+	importPollInterval = time.Second
+
+	// while a replica processes an import, other replicas skip it until the lease expires, every chunk renews it
+	importClaimLease = time.Minute
+
+	importErrorSort = "line"
+)
+
+// errImportLeaseLost means another replica took over the import, the chunk is rolled back and left to it
+var errImportLeaseLost = errors.New("account import lease lost")
+
+// ImportErrorPage is one page of the error report of an import, in file order
+type ImportErrorPage struct {
+	Errors []models.AccountImportError `json:"errors"`
+
+	// Opaque cursor of the next page, empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+
+	// Whether more errors exist
+	HasMore bool `json:"has_more"`
+}
+
+// CreateAccountImport validates the layout of an import file and queues it, rows are processed by the import worker
+func (s *AccountService) CreateAccountImport(ctx context.Context, format types.AccountImportFormat, data []byte, dryRun bool, chunkSize int) (*models.AccountImport, error) {
+	if chunkSize == 0 {
+		chunkSize = DefaultImportChunkSize
+	}
+	if chunkSize < 1 || chunkSize > MaxImportChunkSize {
+		return nil, apperr.Invalid("chunk_size must be between 1 and %d", MaxImportChunkSize)
+	}
+
+	rows, err := parseImportFile(format, data)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := s.idGenerator.NextID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate account import ID: %w", err)
+	}
+
+	accountImport := &models.AccountImport{
+		ID:         types.AccountImportID(id),
+		Format:     format,
+		DryRun:     dryRun,
+		Status:     types.AccountImportStatusPending,
+		ChunkSize:  chunkSize,
+		TotalRows:  len(rows),
+		Data:       data,
+		LeaseUntil: time.Now().UTC(),
+	}
+	if err := s.db.Create(accountImport).Error; err != nil {
+		return nil, fmt.Errorf("failed to create account import: %w", err)
+	}
+
+	auth.Audit(ctx).WithFields(log.Fields{
+		"import_id": accountImport.ID,
+		"rows":      accountImport.TotalRows,
+		"dry_run":   dryRun,
+	}).Info("account import queued")
+	return accountImport, nil
+}
+
+// GetAccountImport returns the progress of an import
+func (s *AccountService) GetAccountImport(id types.AccountImportID) (*models.AccountImport, error) {
+	var accountImport models.AccountImport
+	if err := s.db.Omit("data").First(&accountImport, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperr.ErrImportNotFound
+		}
+		return nil, err
+	}
+	return &accountImport, nil
+}
+
+// ListAccountImportErrors returns the rejected rows of an import ordered by line
+func (s *AccountService) ListAccountImportErrors(ctx context.Context, id types.AccountImportID, cursor string, limit int) (*ImportErrorPage, error) {
+	after, err := pagination.Decode(cursor, importErrorSort)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.GetAccountImport(id); err != nil {
+		return nil, err
+	}
+
+	limit = pagination.ClampLimit(limit)
+
+	query := s.db.WithContext(ctx).Where("import_id = ?", id)
+	if after != nil {
+		query = query.Where("line > ?", after.ID)
+	}
+
+	var importErrors []models.AccountImportError
+	if err := query.Order("line").Limit(limit + 1).Find(&importErrors).Error; err != nil {
+		return nil, fmt.Errorf("failed to list account import errors: %w", err)
+	}
+
+	page := &ImportErrorPage{Errors: importErrors}
+	if len(importErrors) > limit {
+		page.HasMore = true
+		page.Errors = importErrors[:limit]
+		page.NextCursor = pagination.Cursor{
+			Sort: importErrorSort,
+			ID:   uint64(page.Errors[limit-1].Line),
+		}.Encode()
+	}
+	if page.Errors == nil {
+		page.Errors = []models.AccountImportError{}
+	}
+	return page, nil
+}
+
+// ResumeAccountImport queues a failed import again, it continues after the last committed chunk
+func (s *AccountService) ResumeAccountImport(ctx context.Context, id types.AccountImportID) (*models.AccountImport, error) {
+	result := s.db.Model(&models.AccountImport{}).
+		Where("id = ? AND status = ?", id, types.AccountImportStatusFailed).
+		Updates(map[string]any{
+			"status":      types.AccountImportStatusPending,
+			"last_error":  "",
+			"lease_until": time.Now().UTC(),
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to resume account import: %w", result.Error)
+	}
+
+	accountImport, err := s.GetAccountImport(id)
+	if err != nil {
+		return nil, err
+	}
+	if result.RowsAffected == 0 {
+		return nil, apperr.ErrImportNotResumable.WithMessage("account import %d is %s, only failed imports can be resumed", id, accountImport.Status)
+	}
+
+	auth.Audit(ctx).WithField("import_id", id).Info("account import resumed")
+	return accountImport, nil
+}
+
+// StartImportWorker processes queued imports until ctx is done
+func (s *AccountService) StartImportWorker(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(importPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.processDueImport(ctx); err != nil {
+					log.WithError(err).Error("failed to process account import")
+				}
+			}
+		}
+	}()
+}
+
+// processDueImport claims one queued import, or one whose replica stopped renewing its lease, and processes it
+func (s *AccountService) processDueImport(ctx context.Context) error {
+	var accountImport models.AccountImport
+
+	now := time.Now().UTC()
+	tx := s.db.Begin()
+	if err := tx.Error; err != nil {
+		return err
+	}
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("status IN ? AND lease_until <= ?", []types.AccountImportStatus{types.AccountImportStatusPending, types.AccountImportStatusRunning}, now).
+		Order("lease_until").
+		First(&accountImport).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Rollback()
+		return nil
+	}
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	accountImport.Status = types.AccountImportStatusRunning
+	accountImport.LeaseUntil = now.Add(importClaimLease)
+	if err := tx.Model(&accountImport).Updates(map[string]any{
+		"status":      accountImport.Status,
+		"lease_until": accountImport.LeaseUntil,
+	}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	return s.runAccountImport(ctx, &accountImport)
+}
+
+// runAccountImport processes the remaining chunks of a claimed import, a failed chunk fails the import so it can be resumed
+func (s *AccountService) runAccountImport(ctx context.Context, accountImport *models.AccountImport) error {
+	logger := log.WithField("import_id", accountImport.ID)
+
+	rows, err := parseImportFile(accountImport.Format, accountImport.Data)
+	if err != nil {
+		return s.failAccountImport(accountImport, err)
+	}
+
+	// a repeated account ID is imported from its first row only
+	firstLines := make(map[types.AccountID]int, len(rows))
+	for _, row := range rows {
+		if _, ok := firstLines[row.Account.ID]; !ok && len(row.Problems) == 0 {
+			firstLines[row.Account.ID] = row.Line
+		}
+	}
+
+	for accountImport.ProcessedRows < len(rows) {
+		if ctx.Err() != nil {
+			// the lease expires and the import is picked up again
+			return nil
+		}
+
+		end := min(accountImport.ProcessedRows+accountImport.ChunkSize, len(rows))
+		err := s.importChunk(accountImport, rows[accountImport.ProcessedRows:end], firstLines)
+		if errors.Is(err, errImportLeaseLost) {
+			logger.Warn("account import taken over by another replica")
+			return nil
+		}
+		if err != nil {
+			return s.failAccountImport(accountImport, err)
+		}
+	}
+
+	completedAt := time.Now().UTC()
+	if err := s.db.Model(accountImport).Updates(map[string]any{
+		"status":       types.AccountImportStatusCompleted,
+		"completed_at": completedAt,
+		"data":         nil,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to complete account import: %w", err)
+	}
+
+	logger.WithFields(log.Fields{
+		"created": accountImport.CreatedRows,
+		"skipped": accountImport.SkippedRows,
+		"failed":  accountImport.FailedRows,
+		"dry_run": accountImport.DryRun,
+	}).Info("account import completed")
+	return nil
+}
+
+// importChunk creates the accounts of a chunk of rows, records its rejected rows and advances the checkpoint in one transaction
+func (s *AccountService) importChunk(accountImport *models.AccountImport, rows []importRow, firstLines map[types.AccountID]int) error {
+	ids := make([]types.AccountID, 0, len(rows))
+	for _, row := range rows {
+		if len(row.Problems) == 0 {
+			ids = append(ids, row.Account.ID)
+		}
+	}
+
+	processed := *accountImport
+	processed.ProcessedRows += len(rows)
+	processed.LeaseUntil = time.Now().UTC().Add(importClaimLease)
+
+	var recorded []events.Event
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		existing := make(map[types.AccountID]*models.Account, len(ids))
+		if len(ids) > 0 {
+			var accounts []models.Account
+			if err := tx.Where("id IN ?", ids).Find(&accounts).Error; err != nil {
+				return fmt.Errorf("failed to load existing accounts: %w", err)
+			}
+			for i := range accounts {
+				existing[accounts[i].ID] = &accounts[i]
+			}
+		}
+
+		var importErrors []models.AccountImportError
+		reject := func(row importRow, message string) {
+			importErrors = append(importErrors, models.AccountImportError{
+				ImportID:  accountImport.ID,
+				Line:      row.Line,
+				AccountID: row.Account.ID,
+				Message:   message,
+			})
+		}
+
+		for _, row := range rows {
+			if len(row.Problems) > 0 {
+				reject(row, strings.Join(row.Problems, "; "))
+				continue
+			}
+			if firstLine := firstLines[row.Account.ID]; firstLine != row.Line {
+				reject(row, fmt.Sprintf("account_id: duplicate of line %d", firstLine))
+				continue
+			}
+			if account, ok := existing[row.Account.ID]; ok {
+				if !row.sameAttributes(account) {
+					reject(row, "account_id: account already exists with different attributes")
+					continue
+				}
+				processed.SkippedRows++
+				continue
+			}
+
+			processed.CreatedRows++
+			if accountImport.DryRun {
+				continue
+			}
+
+			account := row.Account
+			if err := tx.Create(&account).Error; err != nil {
+				return fmt.Errorf("failed to create account %d of line %d: %w", account.ID, row.Line, err)
+			}
+			if err := s.recordOpeningActivity(tx, &account); err != nil {
+				return err
+			}
+			accountEvents, err := s.recordAccountCreated(tx, &account)
+			if err != nil {
+				return err
+			}
+			recorded = append(recorded, accountEvents...)
+		}
+
+		if len(importErrors) > 0 {
+			processed.FailedRows += len(importErrors)
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&importErrors).Error; err != nil {
+				return fmt.Errorf("failed to record account import errors: %w", err)
+			}
+		}
+
+		// the checkpoint only advances from the one this replica read, otherwise another replica owns the import
+		result := tx.Model(&models.AccountImport{}).
+			Where("id = ? AND processed_rows = ?", accountImport.ID, accountImport.ProcessedRows).
+			Updates(map[string]any{
+				"processed_rows": processed.ProcessedRows,
+				"created_rows":   processed.CreatedRows,
+				"skipped_rows":   processed.SkippedRows,
+				"failed_rows":    processed.FailedRows,
+				"lease_until":    processed.LeaseUntil,
+			})
+		if result.Error != nil {
+			return fmt.Errorf("failed to advance account import: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return errImportLeaseLost
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	*accountImport = processed
+	s.publishEvents(recorded)
+	return nil
+}
+
+// failAccountImport stops an import after an unexpected error, ResumeAccountImport queues it again
+func (s *AccountService) failAccountImport(accountImport *models.AccountImport, cause error) error {
+	log.WithError(cause).WithField("import_id", accountImport.ID).Error("account import failed")
+
+	if err := s.db.Model(accountImport).Updates(map[string]any{
+		"status":     types.AccountImportStatusFailed,
+		"last_error": cause.Error(),
+	}).Error; err != nil {
+		return fmt.Errorf("failed to mark account import as failed: %w", err)
+	}
+	return nil
+}
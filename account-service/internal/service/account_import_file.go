@@ -0,0 +1,231 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/models"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"github.com/danielkhtse/supreme-adventure/common/validation"
+)
+
+// Columns of an import file, named like the fields of the create account request
+const (
+	importColumnAccountID      = "account_id"
+	importColumnCurrency       = "currency"
+	importColumnInitialBalance = "initial_balance"
+	importColumnOwner          = "owner"
+)
+
+var importColumns = []string{importColumnAccountID, importColumnCurrency, importColumnInitialBalance, importColumnOwner}
+
+// importRow is one account of an import file
+type importRow struct {
+	// Line of the row in the file, the CSV header is line 1
+	Line int
+
+	Account models.Account
+
+	// Problems found while parsing or validating the row, a row with problems is not imported
+	Problems []string
+}
+
+// jsonlImportRecord is one line of a JSONL import file
+type jsonlImportRecord struct {
+	AccountID      types.AccountID      `json:"account_id"`
+	Currency       string               `json:"currency"`
+	InitialBalance types.AccountBalance `json:"initial_balance"`
+	Owner          string               `json:"owner"`
+}
+
+// parseImportFile reads every row of an import file and validates it against the account rules.
+// Malformed rows are returned with their problems, an error is only returned when the file as a whole is unreadable.
+func parseImportFile(format types.AccountImportFormat, data []byte) ([]importRow, error) {
+	var rows []importRow
+	var err error
+	switch format {
+	case types.AccountImportFormatCSV:
+		rows, err = parseCSVImport(data)
+	case types.AccountImportFormatJSONL:
+		rows, err = parseJSONLImport(data)
+	default:
+		return nil, apperr.Invalid("unsupported import format %q, expected csv or jsonl", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rows {
+		rows[i].validate()
+	}
+	return rows, nil
+}
+
+func parseCSVImport(data []byte) ([]importRow, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, apperr.Invalid("import file is empty")
+	}
+	if err != nil {
+		return nil, apperr.Invalid("invalid CSV header: %s", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff") //byte order mark written by spreadsheet exports
+		}
+		if !slices.Contains(importColumns, name) {
+			return nil, apperr.Invalid("unknown CSV column %q, expected %s", name, strings.Join(importColumns, ", "))
+		}
+		if _, ok := columns[name]; ok {
+			return nil, apperr.Invalid("duplicate CSV column %q", name)
+		}
+		columns[name] = i
+	}
+	if _, ok := columns[importColumnAccountID]; !ok {
+		return nil, apperr.Invalid("CSV header is missing the %s column", importColumnAccountID)
+	}
+
+	var rows []importRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil && !errors.Is(err, csv.ErrFieldCount) {
+			return nil, apperr.Invalid("invalid CSV: %s", err)
+		}
+		line, _ := reader.FieldPos(0)
+
+		row := importRow{Line: line}
+		if err != nil {
+			row.Problems = append(row.Problems, fmt.Sprintf("expected %d columns, got %d", len(header), len(record)))
+			rows = append(rows, row)
+			continue
+		}
+
+		value := func(column string) string {
+			if i, ok := columns[column]; ok {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		if id := value(importColumnAccountID); id != "" {
+			parsed, err := strconv.ParseUint(id, 10, 64)
+			if err != nil {
+				row.Problems = append(row.Problems, importColumnAccountID+": must be an unsigned integer")
+			}
+			row.Account.ID = types.AccountID(parsed)
+		}
+		if balance := value(importColumnInitialBalance); balance != "" {
+			parsed, err := strconv.ParseInt(balance, 10, 64)
+			if err != nil {
+				row.Problems = append(row.Problems, importColumnInitialBalance+": must be an integer")
+			}
+			row.Account.InitialBalance = types.AccountBalance(parsed)
+		}
+		row.Account.Currency = value(importColumnCurrency)
+		row.Account.Owner = value(importColumnOwner)
+
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil, apperr.Invalid("import file has no rows")
+	}
+	return rows, nil
+}
+
+func parseJSONLImport(data []byte) ([]importRow, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var rows []importRow
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		row := importRow{Line: line}
+
+		var record jsonlImportRecord
+		decoder := json.NewDecoder(bytes.NewReader(text))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&record); err != nil {
+			row.Problems = append(row.Problems, "invalid JSON: "+err.Error())
+			rows = append(rows, row)
+			continue
+		}
+
+		row.Account = models.Account{
+			ID:             record.AccountID,
+			InitialBalance: record.InitialBalance,
+			Currency:       strings.TrimSpace(record.Currency),
+			Owner:          strings.TrimSpace(record.Owner),
+		}
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, apperr.Invalid("invalid JSONL: %s", err)
+	}
+	if len(rows) == 0 {
+		return nil, apperr.Invalid("import file has no rows")
+	}
+	return rows, nil
+}
+
+// validate fills in the defaults of a new account and records every account rule the row violates
+func (r *importRow) validate() {
+	if len(r.Problems) > 0 {
+		return
+	}
+
+	if r.Account.Currency == "" {
+		r.Account.Currency = types.DefaultCurrency
+	}
+	r.Account.Balance = r.Account.InitialBalance
+	r.Account.Status = types.AccountStatusActive
+	r.Account.Version = 1
+
+	err := validation.ValidateStruct(&r.Account)
+	var fieldErrors validation.FieldErrors
+	switch {
+	case err == nil:
+	case errors.As(err, &fieldErrors):
+		for _, fieldError := range fieldErrors {
+			field := fieldError.Field
+			switch field {
+			case "id":
+				field = importColumnAccountID
+			case "balance":
+				//the balance of a new account is its initial balance, already reported
+				continue
+			}
+			r.Problems = append(r.Problems, field+": "+fieldError.Message)
+		}
+	default:
+		r.Problems = append(r.Problems, err.Error())
+	}
+}
+
+// sameAttributes reports whether an existing account was created from this row, making a re-import a no-op
+func (r *importRow) sameAttributes(account *models.Account) bool {
+	return account.InitialBalance == r.Account.InitialBalance &&
+		account.Currency == r.Account.Currency &&
+		account.Owner == r.Account.Owner
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/idgen"
+	"github.com/danielkhtse/supreme-adventure/common/models"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitParseImportFile(t *testing.T) {
+	t.Run("CSV", func(t *testing.T) {
+		data := "\ufeffaccount_id,initial_balance,currency,owner\n" +
+			"1,100,USD,cust-1\n" +
+			"2,,,\n" +
+			"abc,-5,EUR,\n" +
+			"3,10\n" +
+			"\"4\",\"0\",\"USD\",\"cust-4\"\n"
+
+		rows, err := parseImportFile(types.AccountImportFormatCSV, []byte(data))
+		require.NoError(t, err)
+		require.Len(t, rows, 5)
+
+		assert.Equal(t, 2, rows[0].Line)
+		assert.Empty(t, rows[0].Problems)
+		assert.Equal(t, types.AccountID(1), rows[0].Account.ID)
+		assert.Equal(t, types.AccountBalance(100), rows[0].Account.Balance)
+		assert.Equal(t, "cust-1", rows[0].Account.Owner)
+
+		assert.Empty(t, rows[1].Problems)
+		assert.Equal(t, "USD", rows[1].Account.Currency)
+
+		assert.Equal(t, []string{"account_id: must be an unsigned integer"}, rows[2].Problems)
+		assert.Equal(t, []string{"expected 4 columns, got 2"}, rows[3].Problems)
+
+		assert.Equal(t, 6, rows[4].Line)
+		assert.Empty(t, rows[4].Problems)
+	})
+
+	t.Run("CSV validation", func(t *testing.T) {
+		rows, err := parseImportFile(types.AccountImportFormatCSV, []byte("account_id,initial_balance,currency\n0,-5,EUR\n"))
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		assert.Equal(t, []string{
+			"account_id: is required",
+			"initial_balance: must be at least 0",
+			"currency: must be one of USD",
+		}, rows[0].Problems)
+	})
+
+	t.Run("CSV header", func(t *testing.T) {
+		for data, message := range map[string]string{
+			"":                             "import file is empty",
+			"account_id,balance\n1,2\n":    `unknown CSV column "balance", expected account_id, currency, initial_balance, owner`,
+			"account_id,account_id\n1,2\n": `duplicate CSV column "account_id"`,
+			"owner\ncust-1\n":              "CSV header is missing the account_id column",
+			"account_id\n":                 "import file has no rows",
+		} {
+			_, err := parseImportFile(types.AccountImportFormatCSV, []byte(data))
+			assert.ErrorIs(t, err, apperr.ErrInvalidArgument)
+			assert.EqualError(t, err, message)
+		}
+	})
+
+	t.Run("JSONL", func(t *testing.T) {
+		data := `{"account_id":1,"initial_balance":100,"owner":"cust-1"}` + "\n" +
+			"\n" +
+			`{"account_id":2,"balance":5}` + "\n" +
+			`not json` + "\n" +
+			`{"account_id":3,"currency":"EUR"}`
+
+		rows, err := parseImportFile(types.AccountImportFormatJSONL, []byte(data))
+		require.NoError(t, err)
+		require.Len(t, rows, 4)
+
+		assert.Equal(t, 1, rows[0].Line)
+		assert.Empty(t, rows[0].Problems)
+		assert.Equal(t, "USD", rows[0].Account.Currency)
+
+		assert.Equal(t, 3, rows[1].Line)
+		assert.Equal(t, []string{`invalid JSON: json: unknown field "balance"`}, rows[1].Problems)
+
+		assert.Equal(t, 4, rows[2].Line)
+		assert.Len(t, rows[2].Problems, 1)
+
+		assert.Equal(t, 5, rows[3].Line)
+		assert.Equal(t, []string{"currency: must be one of USD"}, rows[3].Problems)
+	})
+
+	t.Run("Unsupported format", func(t *testing.T) {
+		_, err := parseImportFile("xml", []byte("<accounts/>"))
+		assert.ErrorIs(t, err, apperr.ErrInvalidArgument)
+	})
+}
+
+func TestUnitImportChunk(t *testing.T) {
+	mockDB, mock, db := setupMockDB(t)
+	defer mockDB.Close()
+
+	idGenerator, err := idgen.NewSnowflake(1)
+	require.NoError(t, err)
+
+	service := &AccountService{
+		db:          db,
+		idGenerator: idGenerator,
+	}
+
+	data := "account_id,initial_balance,owner\n" +
+		"1,100,cust-1\n" + // created
+		"2,50,cust-2\n" + // exists with the same attributes
+		"3,70,cust-3\n" + // exists with a different initial balance
+		"1,100,cust-1\n" + // duplicate of line 2
+		"4,-1,cust-4\n" // invalid
+	rows, err := parseImportFile(types.AccountImportFormatCSV, []byte(data))
+	require.NoError(t, err)
+	firstLines := map[types.AccountID]int{1: 2, 2: 3, 3: 4}
+
+	accountRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "balance", "initial_balance", "currency", "status", "owner", "version"}).
+			AddRow(2, 20, 50, "USD", "active", "cust-2", 4).
+			AddRow(3, 70, 60, "USD", "active", "cust-3", 1)
+	}
+
+	t.Run("Import", func(t *testing.T) {
+		accountImport := &models.AccountImport{ID: 9, Status: types.AccountImportStatusRunning, ChunkSize: 5, TotalRows: 5}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT \* FROM "accounts" WHERE id IN \(\$1,\$2,\$3,\$4\)`).
+			WithArgs(1, 2, 3, 1).
+			WillReturnRows(accountRows())
+		mock.ExpectQuery(`INSERT INTO "accounts" \("balance","initial_balance","currency","status","owner","version","created_at","updated_at","id"\) VALUES \(\$1,\$2,\$3,\$4,\$5,\$6,\$7,\$8,\$9\) RETURNING "id"`).
+			WithArgs(100, 100, "USD", "active", "cust-1", 1, sqlmock.AnyArg(), sqlmock.AnyArg(), 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectExec(`INSERT INTO "account_activities"`).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO "account_import_errors" \("import_id","line","account_id","message"\) VALUES \(\$1,\$2,\$3,\$4\),\(\$5,\$6,\$7,\$8\),\(\$9,\$10,\$11,\$12\) ON CONFLICT DO NOTHING`).
+			WithArgs(
+				9, 4, 3, "account_id: account already exists with different attributes",
+				9, 5, 1, "account_id: duplicate of line 2",
+				9, 6, 4, "initial_balance: must be at least 0",
+			).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectExec(`UPDATE "account_imports" SET "created_rows"=\$1,"failed_rows"=\$2,"lease_until"=\$3,"processed_rows"=\$4,"skipped_rows"=\$5,"updated_at"=\$6 WHERE id = \$7 AND processed_rows = \$8`).
+			WithArgs(1, 3, sqlmock.AnyArg(), 5, 1, sqlmock.AnyArg(), 9, 0).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := service.importChunk(accountImport, rows, firstLines)
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		assert.Equal(t, 5, accountImport.ProcessedRows)
+		assert.Equal(t, 1, accountImport.CreatedRows)
+		assert.Equal(t, 1, accountImport.SkippedRows)
+		assert.Equal(t, 3, accountImport.FailedRows)
+	})
+
+	t.Run("Dry run", func(t *testing.T) {
+		accountImport := &models.AccountImport{ID: 9, Status: types.AccountImportStatusRunning, DryRun: true, ChunkSize: 2, TotalRows: 5}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT \* FROM "accounts" WHERE id IN \(\$1,\$2\)`).
+			WithArgs(1, 2).
+			WillReturnRows(accountRows())
+		mock.ExpectExec(`UPDATE "account_imports"`).
+			WithArgs(1, 0, sqlmock.AnyArg(), 2, 1, sqlmock.AnyArg(), 9, 0).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := service.importChunk(accountImport, rows[:2], firstLines)
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		assert.Equal(t, 2, accountImport.ProcessedRows)
+		assert.Equal(t, 1, accountImport.CreatedRows)
+	})
+
+	t.Run("Lease lost", func(t *testing.T) {
+		accountImport := &models.AccountImport{ID: 9, Status: types.AccountImportStatusRunning, DryRun: true, ChunkSize: 1, TotalRows: 5, ProcessedRows: 4}
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO "account_import_errors"`).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`UPDATE "account_imports"`).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		err := service.importChunk(accountImport, rows[4:], firstLines)
+		assert.ErrorIs(t, err, errImportLeaseLost)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		assert.Equal(t, 4, accountImport.ProcessedRows)
+	})
+}
+
+func TestUnitResumeAccountImport(t *testing.T) {
+	mockDB, mock, db := setupMockDB(t)
+	defer mockDB.Close()
+
+	service := &AccountService{db: db}
+
+	importRows := func(status string) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "status", "processed_rows"}).AddRow(9, status, 500)
+	}
+
+	t.Run("Failed import", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "account_imports" SET "last_error"=\$1,"lease_until"=\$2,"status"=\$3,"updated_at"=\$4 WHERE id = \$5 AND status = \$6`).
+			WithArgs("", sqlmock.AnyArg(), "pending", sqlmock.AnyArg(), 9, "failed").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		mock.ExpectQuery(`SELECT .* FROM "account_imports" WHERE id = \$1`).
+			WithArgs(9, 1).
+			WillReturnRows(importRows("pending"))
+
+		accountImport, err := service.ResumeAccountImport(context.Background(), 9)
+		require.NoError(t, err)
+		assert.Equal(t, types.AccountImportStatusPending, accountImport.Status)
+		assert.Equal(t, 500, accountImport.ProcessedRows)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Completed import", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "account_imports"`).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+		mock.ExpectQuery(`SELECT .* FROM "account_imports" WHERE id = \$1`).
+			WithArgs(9, 1).
+			WillReturnRows(importRows("completed"))
+
+		_, err := service.ResumeAccountImport(context.Background(), 9)
+		assert.ErrorIs(t, err, apperr.ErrImportNotResumable)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
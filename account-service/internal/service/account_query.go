@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/models"
+	"github.com/danielkhtse/supreme-adventure/common/pagination"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+)
+
+// AccountSort is the sort order of ListAccounts, a leading "-" sorts descending
+type AccountSort string
+
+const (
+	AccountSortCreatedAt     AccountSort = "created_at"
+	AccountSortCreatedAtDesc AccountSort = "-created_at"
+	AccountSortBalance       AccountSort = "balance"
+	AccountSortBalanceDesc   AccountSort = "-balance"
+
+	// exportBatchSize is the page size ExportAccounts reads with
+	exportBatchSize = 1000
+)
+
+// ParseAccountSort validates a sort parameter, the empty string sorts newest first
+func ParseAccountSort(value string) (AccountSort, error) {
+	switch sort := AccountSort(value); sort {
+	case "":
+		return AccountSortCreatedAtDesc, nil
+	case AccountSortCreatedAt, AccountSortCreatedAtDesc, AccountSortBalance, AccountSortBalanceDesc:
+		return sort, nil
+	}
+	return "", apperr.Invalid("sort must be one of created_at, -created_at, balance, -balance")
+}
+
+func (s AccountSort) column() string {
+	return strings.TrimPrefix(string(s), "-")
+}
+
+func (s AccountSort) descending() bool {
+	return strings.HasPrefix(string(s), "-")
+}
+
+// AccountFilter selects the accounts returned by ListAccounts, zero values do not filter
+type AccountFilter struct {
+	Status   types.AccountStatus
+	Currency string
+	Owner    string
+
+	// Inclusive balance range, nil does not filter
+	MinBalance *types.AccountBalance
+	MaxBalance *types.AccountBalance
+
+	// CreatedFrom is inclusive, CreatedTo is exclusive
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+
+	Sort AccountSort
+
+	// Cursor returned as next_cursor by the previous page
+	Cursor string
+	Limit  int
+}
+
+// AccountPage is one page of an account listing
+type AccountPage struct {
+	Accounts []models.Account `json:"accounts"`
+
+	// Opaque cursor of the next page, empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+
+	// Whether more accounts match the filter
+	HasMore bool `json:"has_more"`
+}
+
+// ListAccounts returns a page of accounts matching the filter
+func (s *AccountService) ListAccounts(ctx context.Context, filter AccountFilter) (*AccountPage, error) {
+	return s.listAccounts(ctx, filter, pagination.ClampLimit(filter.Limit))
+}
+
+// ExportAccounts calls fn for every account matching the filter in sort order, reading in batches so
+// large result sets are streamed rather than loaded at once. Cursor and Limit of the filter are ignored.
+func (s *AccountService) ExportAccounts(ctx context.Context, filter AccountFilter, fn func(account *models.Account) error) error {
+	filter.Cursor = ""
+	for {
+		page, err := s.listAccounts(ctx, filter, exportBatchSize)
+		if err != nil {
+			return err
+		}
+		for i := range page.Accounts {
+			if err := fn(&page.Accounts[i]); err != nil {
+				return err
+			}
+		}
+		if !page.HasMore {
+			return nil
+		}
+		filter.Cursor = page.NextCursor
+	}
+}
+
+func (s *AccountService) listAccounts(ctx context.Context, filter AccountFilter, limit int) (*AccountPage, error) {
+	if filter.Sort == "" {
+		filter.Sort = AccountSortCreatedAtDesc
+	}
+	if _, err := ParseAccountSort(string(filter.Sort)); err != nil {
+		return nil, err
+	}
+
+	cursor, err := pagination.Decode(filter.Cursor, string(filter.Sort))
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.MinBalance != nil && filter.MaxBalance != nil && *filter.MinBalance > *filter.MaxBalance {
+		return nil, apperr.Invalid("min_balance must not exceed max_balance")
+	}
+	if !filter.CreatedFrom.IsZero() && !filter.CreatedTo.IsZero() && !filter.CreatedFrom.Before(filter.CreatedTo) {
+		return nil, apperr.Invalid("created_from must be before created_to")
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.Account{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Currency != "" {
+		query = query.Where("currency = ?", filter.Currency)
+	}
+	if filter.Owner != "" {
+		query = query.Where("owner = ?", filter.Owner)
+	}
+	if filter.MinBalance != nil {
+		query = query.Where("balance >= ?", *filter.MinBalance)
+	}
+	if filter.MaxBalance != nil {
+		query = query.Where("balance <= ?", *filter.MaxBalance)
+	}
+	if !filter.CreatedFrom.IsZero() {
+		query = query.Where("created_at >= ?", filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		query = query.Where("created_at < ?", filter.CreatedTo)
+	}
+
+	column, direction, comparison := filter.Sort.column(), "ASC", ">"
+	if filter.Sort.descending() {
+		direction, comparison = "DESC", "<"
+	}
+	if cursor != nil {
+		value, err := parseAccountCursorValue(filter.Sort, cursor.Value)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(fmt.Sprintf("(%s, id) %s (?, ?)", column, comparison), value, cursor.ID)
+	}
+
+	var accounts []models.Account
+	if err := query.
+		Order(column + " " + direction).
+		Order("id " + direction).
+		Limit(limit + 1).
+		Find(&accounts).Error; err != nil {
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
+	}
+
+	page := &AccountPage{Accounts: accounts}
+	if len(accounts) > limit {
+		page.HasMore = true
+		page.Accounts = accounts[:limit]
+		last := page.Accounts[limit-1]
+		page.NextCursor = pagination.Cursor{
+			Sort:  string(filter.Sort),
+			Value: formatAccountCursorValue(filter.Sort, &last),
+			ID:    uint64(last.ID),
+		}.Encode()
+	}
+	if page.Accounts == nil {
+		page.Accounts = []models.Account{}
+	}
+	return page, nil
+}
+
+func formatAccountCursorValue(sort AccountSort, account *models.Account) string {
+	if sort.column() == "balance" {
+		return strconv.FormatInt(int64(account.Balance), 10)
+	}
+	return account.CreatedAt.UTC().Format(time.RFC3339Nano)
+}
+
+func parseAccountCursorValue(sort AccountSort, value string) (any, error) {
+	if sort.column() == "balance" {
+		balance, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, pagination.ErrInvalidCursor
+		}
+		return balance, nil
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return nil, pagination.ErrInvalidCursor
+	}
+	return createdAt, nil
+}
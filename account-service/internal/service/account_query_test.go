@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/danielkhtse/supreme-adventure/common/models"
+	"github.com/danielkhtse/supreme-adventure/common/pagination"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitListAccounts(t *testing.T) {
+	mockDB, mock, db := setupMockDB(t)
+	defer mockDB.Close()
+
+	service := &AccountService{
+		db: db,
+	}
+
+	createdAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	columns := []string{"id", "balance", "currency", "status", "owner", "created_at"}
+
+	t.Run("Filters and returns next cursor", func(t *testing.T) {
+		minBalance := types.AccountBalance(10)
+
+		mock.ExpectQuery(`SELECT \* FROM "accounts" WHERE status = \$1 AND owner = \$2 AND balance >= \$3 ORDER BY balance DESC,id DESC LIMIT \$4`).
+			WithArgs("active", "cust-1", 10, 3).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(3, 300, "USD", "active", "cust-1", createdAt).
+				AddRow(2, 200, "USD", "active", "cust-1", createdAt).
+				AddRow(1, 100, "USD", "active", "cust-1", createdAt))
+
+		page, err := service.ListAccounts(context.Background(), AccountFilter{
+			Status:     types.AccountStatusActive,
+			Owner:      "cust-1",
+			MinBalance: &minBalance,
+			Sort:       AccountSortBalanceDesc,
+			Limit:      2,
+		})
+		require.NoError(t, err)
+		assert.Len(t, page.Accounts, 2)
+		assert.True(t, page.HasMore)
+
+		cursor, err := pagination.Decode(page.NextCursor, string(AccountSortBalanceDesc))
+		require.NoError(t, err)
+		assert.Equal(t, "200", cursor.Value)
+		assert.Equal(t, uint64(2), cursor.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Continues after balance cursor", func(t *testing.T) {
+		cursor := pagination.Cursor{Sort: string(AccountSortBalanceDesc), Value: "200", ID: 2}.Encode()
+
+		mock.ExpectQuery(`SELECT \* FROM "accounts" WHERE \(balance, id\) < \(\$1, \$2\) ORDER BY balance DESC,id DESC LIMIT \$3`).
+			WithArgs(200, 2, 51).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(1, 100, "USD", "active", "cust-1", createdAt))
+
+		page, err := service.ListAccounts(context.Background(), AccountFilter{Sort: AccountSortBalanceDesc, Cursor: cursor})
+		require.NoError(t, err)
+		assert.Len(t, page.Accounts, 1)
+		assert.False(t, page.HasMore)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Cursor from another sort", func(t *testing.T) {
+		cursor := pagination.Cursor{Sort: string(AccountSortBalanceDesc), Value: "200", ID: 2}.Encode()
+
+		_, err := service.ListAccounts(context.Background(), AccountFilter{Cursor: cursor})
+		assert.ErrorIs(t, err, pagination.ErrInvalidCursor)
+	})
+
+	t.Run("Invalid sort", func(t *testing.T) {
+		_, err := service.ListAccounts(context.Background(), AccountFilter{Sort: "owner"})
+		assert.ErrorContains(t, err, "sort must be")
+	})
+}
+
+func TestUnitExportAccounts(t *testing.T) {
+	mockDB, mock, db := setupMockDB(t)
+	defer mockDB.Close()
+
+	service := &AccountService{
+		db: db,
+	}
+
+	createdAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`SELECT \* FROM "accounts" WHERE currency = \$1 ORDER BY created_at ASC,id ASC LIMIT \$2`).
+		WithArgs("USD", exportBatchSize+1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "created_at"}).
+			AddRow(1, 100, createdAt).
+			AddRow(2, 200, createdAt.Add(time.Second)))
+
+	var exported []types.AccountID
+	err := service.ExportAccounts(context.Background(), AccountFilter{Currency: "USD", Sort: AccountSortCreatedAt, Limit: 1}, func(account *models.Account) error {
+		exported = append(exported, account.ID)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []types.AccountID{1, 2}, exported)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
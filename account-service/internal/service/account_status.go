@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/auth"
+	"github.com/danielkhtse/supreme-adventure/common/models"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"gorm.io/gorm"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// UpdateAccountStatus freezes (inactive) or unfreezes (active) an account and returns the updated account.
+// When ifMatch is set the current version must equal one of the values. Setting the current status again
+// succeeds without bumping the version.
+func (s *AccountService) UpdateAccountStatus(ctx context.Context, id types.AccountID, status types.AccountStatus, ifMatch []types.AccountVersion) (*models.Account, error) {
+	if status != types.AccountStatusActive && status != types.AccountStatusInactive {
+		return nil, apperr.Invalid("status must be active or inactive")
+	}
+
+	var account models.Account
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := setLockTimeout(tx); err != nil {
+			return err
+		}
+		if err := lockAccount(tx, &account, id); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return apperr.ErrAccountNotFound
+			}
+			if isLockTimeout(err) {
+				return apperr.ErrLockTimeout
+			}
+			return err
+		}
+
+		if len(ifMatch) > 0 && !matchesVersion(account.Version, ifMatch) {
+			return apperr.ErrVersionMismatch
+		}
+
+		if account.Status == status {
+			return nil
+		}
+
+		result := tx.Model(&account).Where("version = ?", account.Version).Updates(map[string]any{
+			"status":  status,
+			"version": gorm.Expr("version + 1"),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return apperr.ErrVersionMismatch
+		}
+
+		account.Status = status
+		account.Version++
+		return nil
+	})
+	if err != nil {
+		log.WithError(err).WithField("account_id", id).Error("failed to update account status")
+		return nil, err
+	}
+
+	auth.Audit(ctx).WithFields(log.Fields{
+		"account_id": id,
+		"status":     status,
+	}).Info("account status updated")
+	return &account, nil
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestUnitUpdateAccountStatus(t *testing.T) {
+	mockDB, mock, db := setupMockDB(t)
+	defer mockDB.Close()
+
+	service := &AccountService{
+		db: db,
+	}
+
+	accountRows := func(status string, version int) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "balance", "initial_balance", "currency", "status", "version", "created_at", "updated_at"}).
+			AddRow(1, 100, 100, "USD", status, version, time.Time{}, time.Time{})
+	}
+
+	t.Run("Freeze", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(lockTimeoutStatement).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(lockedAccountQuery).
+			WithArgs(1, 1).
+			WillReturnRows(accountRows("active", 3))
+		mock.ExpectExec(`UPDATE "accounts" SET "status"=\$1,"version"=version \+ 1,"updated_at"=\$2 WHERE version = \$3 AND "id" = \$4`).
+			WithArgs("inactive", sqlmock.AnyArg(), 3, 1).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		account, err := service.UpdateAccountStatus(context.Background(), 1, types.AccountStatusInactive, []types.AccountVersion{3})
+		assert.NoError(t, err)
+		assert.Equal(t, types.AccountStatusInactive, account.Status)
+		assert.Equal(t, types.AccountVersion(4), account.Version)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Unchanged status", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(lockTimeoutStatement).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(lockedAccountQuery).
+			WithArgs(1, 1).
+			WillReturnRows(accountRows("inactive", 4))
+		mock.ExpectCommit()
+
+		account, err := service.UpdateAccountStatus(context.Background(), 1, types.AccountStatusInactive, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, types.AccountVersion(4), account.Version)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Version mismatch", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(lockTimeoutStatement).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(lockedAccountQuery).
+			WithArgs(1, 1).
+			WillReturnRows(accountRows("inactive", 4))
+		mock.ExpectRollback()
+
+		_, err := service.UpdateAccountStatus(context.Background(), 1, types.AccountStatusActive, []types.AccountVersion{3})
+		assert.ErrorIs(t, err, apperr.ErrVersionMismatch)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Account not found", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(lockTimeoutStatement).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(lockedAccountQuery).
+			WithArgs(999, 1).
+			WillReturnError(gorm.ErrRecordNotFound)
+		mock.ExpectRollback()
+
+		_, err := service.UpdateAccountStatus(context.Background(), 999, types.AccountStatusInactive, nil)
+		assert.ErrorIs(t, err, apperr.ErrAccountNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Lock timeout", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectExec(lockTimeoutStatement).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(lockedAccountQuery).
+			WithArgs(1, 1).
+			WillReturnError(&pgconn.PgError{Code: "55P03", Message: "canceling statement due to lock timeout"})
+		mock.ExpectRollback()
+
+		_, err := service.UpdateAccountStatus(context.Background(), 1, types.AccountStatusInactive, nil)
+		assert.ErrorIs(t, err, apperr.ErrLockTimeout)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Invalid status", func(t *testing.T) {
+		_, err := service.UpdateAccountStatus(context.Background(), 1, "closed", nil)
+		assert.ErrorIs(t, err, apperr.ErrInvalidArgument)
+	})
+}
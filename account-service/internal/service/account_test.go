@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"testing"
 
 	"database/sql"
@@ -11,6 +12,10 @@ import (
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 
+	"github.com/danielkhtse/supreme-adventure/common/auth"
+	"github.com/danielkhtse/supreme-adventure/common/events"
+	"github.com/danielkhtse/supreme-adventure/common/feed"
+	"github.com/danielkhtse/supreme-adventure/common/idgen"
 	"github.com/danielkhtse/supreme-adventure/common/models"
 	"github.com/danielkhtse/supreme-adventure/common/types"
 )
@@ -39,8 +44,12 @@ func TestUnitCreateAccount(t *testing.T) {
 	mockDB, mock, db := setupMockDB(t)
 	defer mockDB.Close()
 
+	idGenerator, err := idgen.NewSnowflake(1)
+	require.NoError(t, err)
+
 	service := &AccountService{
-		db: db,
+		db:          db,
+		idGenerator: idGenerator,
 	}
 
 	t.Run("Success", func(t *testing.T) {
@@ -59,13 +68,19 @@ func TestUnitCreateAccount(t *testing.T) {
 
 		// Expect account creation
 		mock.ExpectBegin()
-		mock.ExpectQuery(`INSERT INTO "accounts" \("balance","initial_balance","currency","status","created_at","updated_at","id"\) VALUES \(\$1,\$2,\$3,\$4,\$5,\$6,\$7\) RETURNING "id"`).
-			WithArgs(account.Balance, account.InitialBalance, account.Currency, account.Status, sqlmock.AnyArg(), sqlmock.AnyArg(), account.ID).
+		mock.ExpectQuery(`INSERT INTO "accounts" \("balance","initial_balance","currency","status","owner","version","created_at","updated_at","id"\) VALUES \(\$1,\$2,\$3,\$4,\$5,\$6,\$7,\$8,\$9\) RETURNING "id"`).
+			WithArgs(account.Balance, account.InitialBalance, account.Currency, account.Status, "", 1, sqlmock.AnyArg(), sqlmock.AnyArg(), account.ID).
 			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+		// Expect the opening balance activity
+		mock.ExpectExec(`INSERT INTO "account_activities"`).
+			WithArgs(sqlmock.AnyArg(), account.ID, "opening", "credit", 0, 100, 100, 0, "", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
 		mock.ExpectCommit()
 
-		err := service.CreateAccount(account)
+		err := service.CreateAccount(context.Background(), account)
 		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
 	t.Run("Account already exists", func(t *testing.T) {
@@ -79,16 +94,99 @@ func TestUnitCreateAccount(t *testing.T) {
 			WithArgs(account.ID, 1).
 			WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}).AddRow(1, 100.0))
 
-		err := service.CreateAccount(account)
+		err := service.CreateAccount(context.Background(), account)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "already exists")
 	})
 
 	t.Run("Nil account", func(t *testing.T) {
-		err := service.CreateAccount(nil)
+		err := service.CreateAccount(context.Background(), nil)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "cannot be nil")
 	})
+
+	t.Run("Generates ID when missing", func(t *testing.T) {
+		account := &models.Account{
+			InitialBalance: 100,
+		}
+
+		mock.ExpectQuery(`SELECT \* FROM "accounts" WHERE id = \$1 ORDER BY "accounts"."id" LIMIT \$2`).
+			WithArgs(sqlmock.AnyArg(), 1).
+			WillReturnError(gorm.ErrRecordNotFound)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO "accounts"`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectExec(`INSERT INTO "account_activities"`).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := service.CreateAccount(context.Background(), account)
+		assert.NoError(t, err)
+		assert.NotZero(t, account.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Caller becomes owner", func(t *testing.T) {
+		ctx := auth.NewContext(context.Background(), &auth.Identity{Subject: "alice"})
+
+		mock.ExpectQuery(`SELECT \* FROM "accounts" WHERE id = \$1 ORDER BY "accounts"."id" LIMIT \$2`).
+			WithArgs(7, 1).
+			WillReturnError(gorm.ErrRecordNotFound)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO "accounts"`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectExec(`INSERT INTO "account_activities"`).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO "account_grants" \("account_id","principal","role","granted_by","created_at","updated_at"\)`).
+			WithArgs(7, "alice", "owner", "alice", sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, service.CreateAccount(ctx, &models.Account{ID: 7, InitialBalance: 100}))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUnitCreateAccountRecordsEvent(t *testing.T) {
+	mockDB, mock, db := setupMockDB(t)
+	defer mockDB.Close()
+
+	idGenerator, err := idgen.NewSnowflake(1)
+	require.NoError(t, err)
+
+	broker := events.NewBroker()
+	service := &AccountService{
+		db:          db,
+		idGenerator: idGenerator,
+		broker:      broker,
+		feed:        feed.NewStore(db, models.AccountEventTableName, broker, 0),
+	}
+
+	eventCh, unsubscribe := broker.Subscribe(1)
+	defer unsubscribe()
+
+	mock.ExpectQuery(`SELECT \* FROM "accounts" WHERE id = \$1`).
+		WillReturnError(gorm.ErrRecordNotFound)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO "accounts"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectExec(`INSERT INTO "account_activities"`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`SELECT pg_advisory_xact_lock`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`INSERT INTO "account_events"`).
+		WillReturnRows(sqlmock.NewRows([]string{"position"}).AddRow(1))
+	mock.ExpectCommit()
+
+	account := &models.Account{ID: 5, InitialBalance: 100}
+	require.NoError(t, service.CreateAccount(context.Background(), account))
+
+	event := <-eventCh
+	assert.Equal(t, events.TypeAccountCreated, event.Type)
+	assert.True(t, event.AffectsAccount(5))
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestUnitGetAccount(t *testing.T) {
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/danielkhtse/supreme-adventure/common/models"
+	"github.com/danielkhtse/supreme-adventure/common/pagination"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"gorm.io/gorm"
+)
+
+// Activity IDs are time-ordered, so paging by ID pages by time
+const activitySortNewestFirst = "-id"
+
+// ActivityPage is one page of an account activity history, newest first
+type ActivityPage struct {
+	Activities []models.AccountActivity `json:"activities"`
+
+	// Opaque cursor of the next (older) page, empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+
+	// Whether older activity exists
+	HasMore bool `json:"has_more"`
+}
+
+// ListAccountActivity returns the balance movements of an account, newest first
+func (s *AccountService) ListAccountActivity(ctx context.Context, accountID types.AccountID, cursor string, limit int) (*ActivityPage, error) {
+	after, err := pagination.Decode(cursor, activitySortNewestFirst)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.GetAccount(accountID); err != nil {
+		return nil, err
+	}
+
+	limit = pagination.ClampLimit(limit)
+
+	query := s.db.WithContext(ctx).Where("account_id = ?", accountID)
+	if after != nil {
+		query = query.Where("id < ?", after.ID)
+	}
+
+	var activities []models.AccountActivity
+	if err := query.Order("id DESC").Limit(limit + 1).Find(&activities).Error; err != nil {
+		return nil, fmt.Errorf("failed to list account activity: %w", err)
+	}
+
+	page := &ActivityPage{Activities: activities}
+	if len(activities) > limit {
+		page.HasMore = true
+		page.Activities = activities[:limit]
+		page.NextCursor = pagination.Cursor{
+			Sort: activitySortNewestFirst,
+			ID:   uint64(page.Activities[limit-1].ID),
+		}.Encode()
+	}
+	if page.Activities == nil {
+		page.Activities = []models.AccountActivity{}
+	}
+	return page, nil
+}
+
+// recordOpeningActivity stores the opening balance of a new account within tx
+func (s *AccountService) recordOpeningActivity(tx *gorm.DB, account *models.Account) error {
+	if account.Balance <= 0 {
+		return nil
+	}
+
+	id, err := s.idGenerator.NextID()
+	if err != nil {
+		return fmt.Errorf("failed to generate activity ID: %w", err)
+	}
+
+	return tx.Create(&models.AccountActivity{
+		ID:           types.AccountActivityID(id),
+		AccountID:    account.ID,
+		Type:         types.ActivityTypeOpening,
+		Direction:    types.ActivityDirectionCredit,
+		Amount:       account.Balance,
+		BalanceAfter: account.Balance,
+	}).Error
+}
+
+// recordTransferActivity stores the debit of the source and the credit of the destination account within tx,
+// the accounts hold their balances after the transfer
+func (s *AccountService) recordTransferActivity(tx *gorm.DB, sourceAccount *models.Account, destAccount *models.Account, amount types.AccountBalance, opts TransferOptions) error {
+	activities := make([]models.AccountActivity, 0, 2)
+	for _, side := range []struct {
+		account      *models.Account
+		counterparty types.AccountID
+		direction    types.ActivityDirection
+	}{
+		{sourceAccount, destAccount.ID, types.ActivityDirectionDebit},
+		{destAccount, sourceAccount.ID, types.ActivityDirectionCredit},
+	} {
+		id, err := s.idGenerator.NextID()
+		if err != nil {
+			return fmt.Errorf("failed to generate activity ID: %w", err)
+		}
+		activities = append(activities, models.AccountActivity{
+			ID:                    types.AccountActivityID(id),
+			AccountID:             side.account.ID,
+			Type:                  types.ActivityTypeTransfer,
+			Direction:             side.direction,
+			CounterpartyAccountID: side.counterparty,
+			Amount:                amount,
+			BalanceAfter:          side.account.Balance,
+			TransactionID:         opts.TransactionID,
+			TransferID:            opts.TransferID,
+		})
+	}
+
+	return tx.Create(&activities).Error
+}
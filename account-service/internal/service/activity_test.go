@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/danielkhtse/supreme-adventure/common/pagination"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestUnitListAccountActivity(t *testing.T) {
+	mockDB, mock, db := setupMockDB(t)
+	defer mockDB.Close()
+
+	service := &AccountService{
+		db: db,
+	}
+
+	createdAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	columns := []string{"id", "account_id", "type", "direction", "counterparty_account_id", "amount", "balance_after", "transaction_id", "created_at"}
+
+	t.Run("Returns newest first with next cursor", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT \* FROM "accounts" WHERE id = \$1`).
+			WithArgs(1, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}).AddRow(1, 70))
+
+		mock.ExpectQuery(`SELECT \* FROM "account_activities" WHERE account_id = \$1 ORDER BY id DESC LIMIT \$2`).
+			WithArgs(1, 3).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(30, 1, "transfer", "credit", 2, 20, 70, 9, createdAt).
+				AddRow(20, 1, "transfer", "debit", 2, 50, 50, 8, createdAt).
+				AddRow(10, 1, "opening", "credit", 0, 100, 100, 0, createdAt))
+
+		page, err := service.ListAccountActivity(context.Background(), 1, "", 2)
+		require.NoError(t, err)
+		require.Len(t, page.Activities, 2)
+		assert.Equal(t, types.ActivityDirectionCredit, page.Activities[0].Direction)
+		assert.Equal(t, types.AccountBalance(70), page.Activities[0].BalanceAfter)
+		assert.Equal(t, types.TransactionID(9), page.Activities[0].TransactionID)
+		assert.True(t, page.HasMore)
+
+		cursor, err := pagination.Decode(page.NextCursor, activitySortNewestFirst)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(20), cursor.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Continues after cursor", func(t *testing.T) {
+		cursor := pagination.Cursor{Sort: activitySortNewestFirst, ID: 20}.Encode()
+
+		mock.ExpectQuery(`SELECT \* FROM "accounts" WHERE id = \$1`).
+			WithArgs(1, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance"}).AddRow(1, 70))
+
+		mock.ExpectQuery(`SELECT \* FROM "account_activities" WHERE account_id = \$1 AND id < \$2 ORDER BY id DESC LIMIT \$3`).
+			WithArgs(1, 20, 51).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(10, 1, "opening", "credit", 0, 100, 100, 0, createdAt))
+
+		page, err := service.ListAccountActivity(context.Background(), 1, cursor, 0)
+		require.NoError(t, err)
+		assert.Len(t, page.Activities, 1)
+		assert.False(t, page.HasMore)
+		assert.Empty(t, page.NextCursor)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Account not found", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT \* FROM "accounts" WHERE id = \$1`).
+			WithArgs(999, 1).
+			WillReturnError(gorm.ErrRecordNotFound)
+
+		_, err := service.ListAccountActivity(context.Background(), 999, "", 0)
+		assert.ErrorContains(t, err, "account not found")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Invalid cursor", func(t *testing.T) {
+		_, err := service.ListAccountActivity(context.Background(), 1, "garbage", 0)
+		assert.ErrorIs(t, err, pagination.ErrInvalidCursor)
+	})
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/danielkhtse/supreme-adventure/common/events"
+	"github.com/danielkhtse/supreme-adventure/common/feed"
+	"github.com/danielkhtse/supreme-adventure/common/models"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"gorm.io/gorm"
+)
+
+// Events returns the broker receiving the account events published by this service
+func (s *AccountService) Events() *events.Broker {
+	return s.broker
+}
+
+// ListEvents returns the change feed after the cursor, only the events affecting the account unless accountID is
+// zero, waiting up to wait for new events when none are available
+func (s *AccountService) ListEvents(ctx context.Context, after string, accountID types.AccountID, limit int, wait time.Duration) (*feed.Page, error) {
+	return s.feed.List(ctx, after, accountID, limit, wait)
+}
+
+// EventCursor returns the change feed cursor after the event with the given ID, retained is false when the event
+// is no longer in the feed
+func (s *AccountService) EventCursor(ctx context.Context, eventID uint64) (cursor string, retained bool, err error) {
+	return s.feed.EventCursor(ctx, eventID)
+}
+
+// StartEventRetention prunes events older than the configured retention until ctx is done
+func (s *AccountService) StartEventRetention(ctx context.Context) {
+	s.feed.StartRetention(ctx)
+}
+
+// recordAccountCreated stores the account.created event within tx
+func (s *AccountService) recordAccountCreated(tx *gorm.DB, account *models.Account) ([]events.Event, error) {
+	if s.feed == nil {
+		return nil, nil
+	}
+
+	event, err := s.newEvent(events.TypeAccountCreated, account, account.ID)
+	if err != nil {
+		return nil, err
+	}
+	return []events.Event{event}, s.feed.Append(tx, event)
+}
+
+// recordTransferEvents stores the transfer.applied event and the balance change of both accounts within tx
+func (s *AccountService) recordTransferEvents(tx *gorm.DB, sourceAccount *models.Account, destAccount *models.Account, amount types.AccountBalance, transactionID types.TransactionID) ([]events.Event, error) {
+	if s.feed == nil {
+		return nil, nil
+	}
+
+	transferApplied, err := s.newEvent(events.TypeTransferApplied, events.TransferApplied{
+		SourceAccountID: sourceAccount.ID,
+		DestAccountID:   destAccount.ID,
+		Amount:          amount,
+		SourceBalance:   sourceAccount.Balance,
+		DestBalance:     destAccount.Balance,
+		Currency:        sourceAccount.Currency,
+		TransactionID:   transactionID,
+	}, sourceAccount.ID, destAccount.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceChanged, err := s.newBalanceChange(sourceAccount, -amount, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	destChanged, err := s.newBalanceChange(destAccount, amount, transactionID)
+	if err != nil {
+		return nil, err
+	}
+
+	recorded := []events.Event{transferApplied, sourceChanged, destChanged}
+	return recorded, s.feed.Append(tx, recorded...)
+}
+
+// publishEvents notifies stream subscribers of events whose transaction has committed
+func (s *AccountService) publishEvents(recorded []events.Event) {
+	if s.feed == nil {
+		return
+	}
+	s.feed.Publish(recorded...)
+}
+
+func (s *AccountService) newBalanceChange(account *models.Account, amount types.AccountBalance, transactionID types.TransactionID) (events.Event, error) {
+	balance := account.Balance
+	return s.newEvent(events.TypeAccountBalanceChanged, events.BalanceChange{
+		AccountID:     account.ID,
+		Amount:        amount,
+		Balance:       &balance,
+		Currency:      account.Currency,
+		TransactionID: transactionID,
+	}, account.ID)
+}
+
+func (s *AccountService) newEvent(eventType events.Type, data any, accountIDs ...types.AccountID) (events.Event, error) {
+	id, err := s.idGenerator.NextID()
+	if err != nil {
+		return events.Event{}, err
+	}
+	return events.New(id, eventType, data, accountIDs...)
+}
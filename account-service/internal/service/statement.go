@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/iso20022"
+	"github.com/danielkhtse/supreme-adventure/common/models"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	log "github.com/sirupsen/logrus"
+)
+
+// MaxNotificationPeriod bounds the period of a camt.054 notification, the entries are built in memory
+const MaxNotificationPeriod = 31 * 24 * time.Hour
+
+// transactionLead is how long before its balance movement a transaction may have been created, transactions
+// are listed from this long before the reported period
+const transactionLead = time.Hour
+
+// TransactionSource looks up the transactions behind account activity, for the remittance information of
+// statements and notifications
+type TransactionSource interface {
+	Transactions(ctx context.Context, accountID types.AccountID, ids []types.TransactionID, from, to time.Time) (map[types.TransactionID]models.Transaction, error)
+}
+
+// AccountStatement returns the camt.053 end-of-day statement of an account for a past UTC day
+func (s *AccountService) AccountStatement(ctx context.Context, accountID types.AccountID, date time.Time) (*iso20022.StatementDocument, error) {
+	date = date.UTC()
+	from := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+	if to.After(time.Now()) {
+		return nil, apperr.Invalid("statements are only available for past days")
+	}
+
+	account, err := s.GetAccount(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	//the opening balance is the balance after the last movement before the day
+	var previous []models.AccountActivity
+	err = s.db.WithContext(ctx).
+		Where("account_id = ? AND created_at < ?", accountID, from).
+		Order("id DESC").Limit(1).
+		Find(&previous).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get opening balance: %w", err)
+	}
+	var opening types.AccountBalance
+	if len(previous) > 0 {
+		opening = previous[0].BalanceAfter
+	}
+
+	activities, err := s.listActivityBetween(ctx, accountID, from, to, "")
+	if err != nil {
+		return nil, err
+	}
+	closing := opening
+	if len(activities) > 0 {
+		closing = activities[len(activities)-1].BalanceAfter
+	}
+
+	messageID := fmt.Sprintf("STMT-%d-%s", accountID, from.Format("20060102"))
+	return iso20022.NewStatement(iso20022.Statement{
+		Report:         s.newReport(ctx, account, messageID, from, to, activities),
+		OpeningBalance: opening,
+		ClosingBalance: closing,
+	}), nil
+}
+
+// AccountNotification returns a camt.054 notification of the balance movements of an account in [from, to),
+// of one direction only when direction is set
+func (s *AccountService) AccountNotification(ctx context.Context, accountID types.AccountID, from, to time.Time, direction types.ActivityDirection) (*iso20022.NotificationDocument, error) {
+	if !from.Before(to) {
+		return nil, apperr.Invalid("from must be before to")
+	}
+	if to.Sub(from) > MaxNotificationPeriod {
+		return nil, apperr.Invalid("notifications cover at most %d days", int(MaxNotificationPeriod/(24*time.Hour)))
+	}
+
+	account, err := s.GetAccount(accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	activities, err := s.listActivityBetween(ctx, accountID, from, to, direction)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := s.idGenerator.NextID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate notification ID: %w", err)
+	}
+
+	report := s.newReport(ctx, account, "NTFCTN-"+strconv.FormatUint(id, 10), from, to, activities)
+	return iso20022.NewNotification(report), nil
+}
+
+// listActivityBetween returns the balance movements of an account in [from, to) in booking order
+func (s *AccountService) listActivityBetween(ctx context.Context, accountID types.AccountID, from, to time.Time, direction types.ActivityDirection) ([]models.AccountActivity, error) {
+	query := s.db.WithContext(ctx).Where("account_id = ? AND created_at >= ? AND created_at < ?", accountID, from, to)
+	if direction != "" {
+		query = query.Where("direction = ?", direction)
+	}
+
+	var activities []models.AccountActivity
+	if err := query.Order("id").Find(&activities).Error; err != nil {
+		return nil, fmt.Errorf("failed to list account activity: %w", err)
+	}
+	return activities, nil
+}
+
+// newReport collects the data of a statement or notification. A failed transaction lookup only costs the
+// entries their remittance information, the booked amounts and balances come from the ledger.
+func (s *AccountService) newReport(ctx context.Context, account *models.Account, messageID string, from, to time.Time, activities []models.AccountActivity) iso20022.Report {
+	report := iso20022.Report{
+		MessageID:  messageID,
+		CreatedAt:  time.Now(),
+		Account:    *account,
+		From:       from,
+		To:         to,
+		Activities: activities,
+	}
+
+	var ids []types.TransactionID
+	for _, activity := range activities {
+		if activity.TransactionID != 0 {
+			ids = append(ids, activity.TransactionID)
+		}
+	}
+	if s.transactions == nil || len(ids) == 0 {
+		return report
+	}
+
+	transactions, err := s.transactions.Transactions(ctx, account.ID, ids, from.Add(-transactionLead), to)
+	if err != nil {
+		log.WithError(err).WithField("account_id", account.ID).Warn("failed to look up transactions, reporting without remittance information")
+		return report
+	}
+	report.Transactions = transactions
+	return report
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/idgen"
+	"github.com/danielkhtse/supreme-adventure/common/models"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTransactionSource struct {
+	transactions map[types.TransactionID]models.Transaction
+	err          error
+	ids          []types.TransactionID
+}
+
+func (f *fakeTransactionSource) Transactions(ctx context.Context, accountID types.AccountID, ids []types.TransactionID, from, to time.Time) (map[types.TransactionID]models.Transaction, error) {
+	f.ids = ids
+	return f.transactions, f.err
+}
+
+func TestUnitAccountStatement(t *testing.T) {
+	mockDB, mock, db := setupMockDB(t)
+	defer mockDB.Close()
+
+	transactions := &fakeTransactionSource{transactions: map[types.TransactionID]models.Transaction{
+		9: {ID: 9, Description: "Invoice 118"},
+	}}
+	service := &AccountService{db: db, transactions: transactions}
+
+	date := time.Date(2026, 3, 1, 15, 0, 0, 0, time.UTC)
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+	columns := []string{"id", "account_id", "type", "direction", "counterparty_account_id", "amount", "balance_after", "transaction_id", "created_at"}
+
+	expectAccount := func() {
+		mock.ExpectQuery(`SELECT \* FROM "accounts" WHERE id = \$1`).
+			WithArgs(1, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency"}).AddRow(1, 70, "USD"))
+	}
+
+	t.Run("Balances and entries", func(t *testing.T) {
+		expectAccount()
+		mock.ExpectQuery(`SELECT \* FROM "account_activities" WHERE account_id = \$1 AND created_at < \$2 ORDER BY id DESC LIMIT \$3`).
+			WithArgs(1, from, 1).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(10, 1, "opening", "credit", 0, 100, 100, 0, from.Add(-time.Hour)))
+		mock.ExpectQuery(`SELECT \* FROM "account_activities" WHERE account_id = \$1 AND created_at >= \$2 AND created_at < \$3 ORDER BY id`).
+			WithArgs(1, from, to).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(20, 1, "transfer", "debit", 2, 50, 50, 9, from.Add(time.Hour)).
+				AddRow(30, 1, "transfer", "credit", 2, 20, 70, 11, from.Add(2*time.Hour)))
+
+		document, err := service.AccountStatement(context.Background(), 1, date)
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		assert.Equal(t, []types.TransactionID{9, 11}, transactions.ids)
+
+		statement := document.BkToCstmrStmt.Stmt[0]
+		assert.Equal(t, "STMT-1-20260301", statement.Id)
+		assert.Equal(t, "1.00", statement.Bal[0].Amt.Value)
+		assert.Equal(t, "0.70", statement.Bal[1].Amt.Value)
+		require.Len(t, statement.Ntry, 2)
+		assert.Equal(t, []string{"Invoice 118"}, statement.Ntry[0].NtryDtls.TxDtls[0].RmtInf.Ustrd)
+		assert.Nil(t, statement.Ntry[1].NtryDtls.TxDtls[0].RmtInf)
+	})
+
+	t.Run("Day without activity", func(t *testing.T) {
+		transactions.err = errors.New("transaction-service unavailable")
+		defer func() { transactions.err = nil }()
+
+		expectAccount()
+		mock.ExpectQuery(`SELECT \* FROM "account_activities" WHERE account_id = \$1 AND created_at < \$2`).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(30, 1, "transfer", "credit", 2, 20, 70, 11, from.Add(-time.Hour)))
+		mock.ExpectQuery(`SELECT \* FROM "account_activities" WHERE account_id = \$1 AND created_at >= \$2`).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		document, err := service.AccountStatement(context.Background(), 1, date)
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+
+		statement := document.BkToCstmrStmt.Stmt[0]
+		assert.Equal(t, "0.70", statement.Bal[0].Amt.Value)
+		assert.Equal(t, "0.70", statement.Bal[1].Amt.Value)
+		assert.Empty(t, statement.Ntry)
+	})
+
+	t.Run("Today", func(t *testing.T) {
+		_, err := service.AccountStatement(context.Background(), 1, time.Now())
+		assert.ErrorIs(t, err, apperr.ErrInvalidArgument)
+	})
+}
+
+func TestUnitAccountNotification(t *testing.T) {
+	mockDB, mock, db := setupMockDB(t)
+	defer mockDB.Close()
+
+	idGenerator, err := idgen.NewSnowflake(1)
+	require.NoError(t, err)
+
+	service := &AccountService{db: db, idGenerator: idGenerator}
+
+	from := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+
+	t.Run("Credits only", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT \* FROM "accounts" WHERE id = \$1`).
+			WithArgs(1, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "balance", "currency"}).AddRow(1, 70, "USD"))
+		mock.ExpectQuery(`SELECT \* FROM "account_activities" WHERE \(account_id = \$1 AND created_at >= \$2 AND created_at < \$3\) AND direction = \$4 ORDER BY id`).
+			WithArgs(1, from, to, "credit").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "account_id", "type", "direction", "counterparty_account_id", "amount", "balance_after", "transaction_id", "created_at"}).
+				AddRow(30, 1, "transfer", "credit", 2, 20, 70, 11, from.Add(time.Minute)))
+
+		document, err := service.AccountNotification(context.Background(), 1, from, to, types.ActivityDirectionCredit)
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+
+		notification := document.BkToCstmrDbtCdtNtfctn.Ntfctn[0]
+		assert.Regexp(t, `^NTFCTN-\d+$`, notification.Id)
+		require.Len(t, notification.Ntry, 1)
+		assert.Equal(t, "CRDT", notification.Ntry[0].CdtDbtInd)
+	})
+
+	t.Run("Invalid period", func(t *testing.T) {
+		_, err := service.AccountNotification(context.Background(), 1, to, from, "")
+		assert.ErrorIs(t, err, apperr.ErrInvalidArgument)
+
+		_, err = service.AccountNotification(context.Background(), 1, from, from.Add(MaxNotificationPeriod+time.Hour), "")
+		assert.ErrorIs(t, err, apperr.ErrInvalidArgument)
+	})
+}
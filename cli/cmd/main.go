@@ -0,0 +1,11 @@
+package main
+
+import (
+	"os"
+
+	"github.com/danielkhtse/supreme-adventure/cli/internal/command"
+)
+
+func main() {
+	os.Exit(command.Execute())
+}
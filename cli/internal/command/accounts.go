@@ -0,0 +1,211 @@
+package command
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/danielkhtse/supreme-adventure/cli/internal/output"
+	"github.com/danielkhtse/supreme-adventure/sdk"
+	"github.com/spf13/cobra"
+)
+
+func newAccountsCommand(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "accounts",
+		Aliases: []string{"account"},
+		Short:   "Create, show, list and freeze accounts",
+	}
+	cmd.AddCommand(
+		newAccountsCreateCommand(a),
+		newAccountsShowCommand(a),
+		newAccountsListCommand(a),
+		newAccountsStatusCommand(a, "freeze", sdk.AccountStatusInactive),
+		newAccountsStatusCommand(a, "unfreeze", sdk.AccountStatusActive),
+	)
+	return cmd
+}
+
+func newAccountsCreateCommand(a *app) *cobra.Command {
+	var req sdk.CreateAccountRequest
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create an account",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			account, err := a.accounts.CreateAccount(cmd.Context(), req)
+			if err != nil {
+				return err
+			}
+			return a.printAccountSummary(account)
+		},
+	}
+	cmd.Flags().Uint64Var(&req.AccountID, "id", 0, "account ID, generated when omitted")
+	cmd.Flags().Int64Var(&req.InitialBalance, "initial-balance", 0, "initial balance in the smallest currency units")
+	cmd.Flags().StringVar(&req.Owner, "owner", "", "reference to the customer owning the account")
+	return cmd
+}
+
+func newAccountsShowCommand(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "show ACCOUNT_ID",
+		Short: "Show the balance and version of an account",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0])
+			if err != nil {
+				return err
+			}
+			account, err := a.accounts.GetAccount(cmd.Context(), id)
+			if err != nil {
+				return err
+			}
+			return a.printAccountSummary(account)
+		},
+	}
+}
+
+func newAccountsListCommand(a *app) *cobra.Command {
+	var params sdk.ListAccountsParams
+	var status, sort, createdFrom, createdTo string
+	var minBalance, maxBalance int64
+	var limit int
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List accounts, newest first unless --sort is given",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			params.Status = sdk.AccountStatus(status)
+			params.Sort = sdk.AccountSort(sort)
+			if cmd.Flags().Changed("min-balance") {
+				params.MinBalance = &minBalance
+			}
+			if cmd.Flags().Changed("max-balance") {
+				params.MaxBalance = &maxBalance
+			}
+			var err error
+			if params.CreatedFrom, err = parseTime("created-from", createdFrom); err != nil {
+				return err
+			}
+			if params.CreatedTo, err = parseTime("created-to", createdTo); err != nil {
+				return err
+			}
+
+			accounts := []sdk.Account{}
+			for account, err := range a.accounts.Accounts(cmd.Context(), params) {
+				if err != nil {
+					return err
+				}
+				accounts = append(accounts, account)
+				if !all && len(accounts) >= limit {
+					break
+				}
+			}
+			return a.printer.Print(accounts, func() output.Table { return accountsTable(accounts) })
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&status, "status", "", "only accounts with this status, active or inactive")
+	flags.StringVar(&params.Currency, "currency", "", "only accounts in this currency")
+	flags.StringVar(&params.Owner, "owner", "", "only accounts of this owner")
+	flags.Int64Var(&minBalance, "min-balance", 0, "minimum balance, inclusive")
+	flags.Int64Var(&maxBalance, "max-balance", 0, "maximum balance, inclusive")
+	flags.StringVar(&createdFrom, "created-from", "", "only accounts created at or after this RFC 3339 time")
+	flags.StringVar(&createdTo, "created-to", "", "only accounts created before this RFC 3339 time")
+	flags.StringVar(&sort, "sort", "", "created_at, -created_at, balance or -balance")
+	flags.IntVar(&limit, "limit", 50, "maximum number of accounts to print")
+	flags.BoolVar(&all, "all", false, "print every matching account, ignoring --limit")
+
+	cmd.RegisterFlagCompletionFunc("status", cobra.FixedCompletions([]string{"active", "inactive"}, cobra.ShellCompDirectiveNoFileComp))
+	cmd.RegisterFlagCompletionFunc("sort", cobra.FixedCompletions([]string{"created_at", "-created_at", "balance", "-balance"}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+// newAccountsStatusCommand returns the freeze or unfreeze command
+func newAccountsStatusCommand(a *app, use string, status sdk.AccountStatus) *cobra.Command {
+	var ifMatch []uint
+	cmd := &cobra.Command{
+		Use:   use + " ACCOUNT_ID",
+		Short: fmt.Sprintf("Set the status of an account to %s", status),
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0])
+			if err != nil {
+				return err
+			}
+			versions := make([]uint64, len(ifMatch))
+			for i, version := range ifMatch {
+				versions[i] = uint64(version)
+			}
+			account, err := a.accounts.UpdateAccountStatus(cmd.Context(), id, status, versions...)
+			if err != nil {
+				return err
+			}
+			return a.printAccountSummary(account)
+		},
+	}
+	cmd.Flags().UintSliceVar(&ifMatch, "if-version", nil, "only update when the account still has this version")
+	return cmd
+}
+
+// accountSummary holds the account fields returned by the create, show and status endpoints
+type accountSummary struct {
+	ID      uint64 `json:"id,string"`
+	Balance int64  `json:"balance"`
+	Version uint64 `json:"version"`
+}
+
+// printAccountSummary prints the account returned by the create, show and status endpoints
+func (a *app) printAccountSummary(account *sdk.Account) error {
+	summary := accountSummary{ID: account.ID, Balance: account.Balance, Version: account.Version}
+	return a.printer.Print(summary, func() output.Table {
+		return output.Table{
+			Header: []string{"ID", "BALANCE", "VERSION"},
+			Rows:   [][]string{{formatUint(summary.ID), formatInt(summary.Balance), formatUint(summary.Version)}},
+		}
+	})
+}
+
+func accountsTable(accounts []sdk.Account) output.Table {
+	table := output.Table{Header: []string{"ID", "BALANCE", "CURRENCY", "STATUS", "OWNER", "VERSION", "CREATED"}}
+	for _, account := range accounts {
+		table.Rows = append(table.Rows, []string{
+			formatUint(account.ID),
+			formatInt(account.Balance),
+			account.Currency,
+			string(account.Status),
+			account.Owner,
+			formatUint(account.Version),
+			formatTime(account.CreatedAt),
+		})
+	}
+	return table
+}
+
+func parseTime(flag string, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --%s, expected an RFC 3339 time: %w", flag, err)
+	}
+	return t, nil
+}
+
+func formatUint(value uint64) string {
+	return strconv.FormatUint(value, 10)
+}
+
+func formatInt(value int64) string {
+	return strconv.FormatInt(value, 10)
+}
+
+func formatTime(value time.Time) string {
+	if value.IsZero() {
+		return ""
+	}
+	return value.UTC().Format(time.RFC3339)
+}
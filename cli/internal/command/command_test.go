@@ -0,0 +1,138 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// run executes ledgerctl with args against a temporary config file
+func run(t *testing.T, configPath string, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	cmd := NewRootCommand(&out)
+	cmd.SetArgs(append([]string{"--config", configPath}, args...))
+	cmd.SetErr(&bytes.Buffer{})
+	err := cmd.ExecuteContext(context.Background())
+	return out.String(), err
+}
+
+func TestUnitProfiles(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+
+	out, err := run(t, configPath, "profile", "list")
+	require.NoError(t, err)
+	assert.Contains(t, out, "*        local")
+
+	_, err = run(t, configPath, "profile", "set", "staging",
+		"--account-url", "https://accounts.staging",
+		"--transaction-url", "https://transactions.staging",
+		"--default-output", "json",
+		"--header", "Authorization=Bearer token")
+	require.NoError(t, err)
+
+	_, err = run(t, configPath, "profile", "use", "staging")
+	require.NoError(t, err)
+
+	out, err = run(t, configPath, "profile", "list", "-o", "json")
+	require.NoError(t, err)
+	var config struct {
+		CurrentProfile string `json:"current_profile"`
+		Profiles       map[string]struct {
+			AccountURL string            `json:"account_url"`
+			Output     string            `json:"output"`
+			Headers    map[string]string `json:"headers"`
+		} `json:"profiles"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &config))
+	assert.Equal(t, "staging", config.CurrentProfile)
+	assert.Equal(t, "https://accounts.staging", config.Profiles["staging"].AccountURL)
+	assert.Equal(t, "json", config.Profiles["staging"].Output)
+	assert.Equal(t, map[string]string{"Authorization": "Bearer token"}, config.Profiles["staging"].Headers)
+	assert.Contains(t, config.Profiles, "local")
+
+	_, err = run(t, configPath, "profile", "use", "production")
+	assert.ErrorContains(t, err, `profile "production" not found`)
+
+	_, err = run(t, configPath, "accounts", "show", "1", "--profile", "production")
+	assert.ErrorContains(t, err, `profile "production" not found`)
+}
+
+func TestUnitAccountCommands(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/v1/accounts/7":
+			w.Write([]byte(`{"account_id":"7","balance":1500,"version":3}`))
+		case r.Method == "PUT" && r.URL.Path == "/v1/accounts/7/status":
+			var body map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "inactive", body["status"])
+			assert.Equal(t, `"3"`, r.Header.Get("If-Match"))
+			w.Write([]byte(`{"account_id":"7","balance":1500,"version":4}`))
+		case r.Method == "GET" && r.URL.Path == "/v1/accounts/8":
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"status":404,"code":"account_not_found","detail":"account not found"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	_, err := run(t, configPath, "profile", "set", "test",
+		"--account-url", server.URL,
+		"--transaction-url", server.URL,
+		"--header", "Authorization=Bearer token")
+	require.NoError(t, err)
+
+	out, err := run(t, configPath, "--profile", "test", "accounts", "show", "7")
+	require.NoError(t, err)
+	assert.Equal(t, "ID  BALANCE  VERSION\n7   1500     3\n", out)
+	assert.Equal(t, "Bearer token", authorization)
+
+	out, err = run(t, configPath, "--profile", "test", "-o", "json", "accounts", "freeze", "7", "--if-version", "3")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"7","balance":1500,"version":4}`, out)
+
+	_, err = run(t, configPath, "--profile", "test", "accounts", "show", "8")
+	assert.EqualError(t, err, "account not found")
+
+	_, err = run(t, configPath, "--profile", "test", "accounts", "show", "abc")
+	assert.EqualError(t, err, `invalid ID "abc"`)
+}
+
+func TestUnitReconcileCommand(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/accounts":
+			w.Write([]byte(`{"accounts":[{"id":"1","balance":90,"initial_balance":100}],"has_more":false}`))
+		case "/v1/accounts/1/activity":
+			w.Write([]byte(`{"activities":[{"id":"1","account_id":"1","type":"opening","direction":"credit","amount":100,"balance_after":100}],"has_more":false}`))
+		case "/v1/transactions":
+			w.Write([]byte(`{"transactions":[],"has_more":false}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	out, err := run(t, configPath, "--account-url", server.URL, "--transaction-url", server.URL, "reconcile")
+	assert.ErrorIs(t, err, errDiscrepancies)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 5)
+	assert.True(t, strings.HasPrefix(lines[1], "ledger_mismatch"))
+	assert.True(t, strings.HasPrefix(lines[2], "last_balance_mismatch"))
+	assert.Equal(t, "1 accounts, 0 transactions, 2 discrepancies", lines[4])
+}
@@ -0,0 +1,195 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/danielkhtse/supreme-adventure/cli/internal/config"
+	"github.com/danielkhtse/supreme-adventure/cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+func newProfileCommand(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "profile",
+		Aliases: []string{"profiles"},
+		Short:   "Manage the profiles holding the service URLs of each environment",
+		Long: `Manage the profiles holding the service URLs of each environment.
+
+Profiles are stored in the config file, selected with --profile, $LEDGERCTL_PROFILE or
+"ledgerctl profile use". Without a config file a "local" profile points at localhost:8080
+and localhost:8081.`,
+	}
+	cmd.AddCommand(
+		newProfileListCommand(a),
+		newProfileSetCommand(a),
+		newProfileUseCommand(a),
+		newProfileDeleteCommand(a),
+	)
+	return cmd
+}
+
+func newProfileListCommand(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List profiles, the current one is marked with *",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(a.configPath)
+			if err != nil {
+				return err
+			}
+			parsed, err := output.ParseFormat(a.outputFormat)
+			if err != nil {
+				return err
+			}
+
+			current := cfg.ProfileName(a.profileName)
+			return output.NewPrinter(a.out, parsed).Print(cfg, func() output.Table {
+				table := output.Table{Header: []string{"CURRENT", "NAME", "ACCOUNT URL", "TRANSACTION URL", "OUTPUT", "TIMEOUT"}}
+				for _, name := range cfg.ProfileNames() {
+					profile := cfg.Profiles[name]
+					marker := ""
+					if name == current {
+						marker = "*"
+					}
+					table.Rows = append(table.Rows, []string{marker, name, profile.AccountURL, profile.TransactionURL, profile.Output, profile.Timeout})
+				}
+				return table
+			})
+		},
+	}
+}
+
+func newProfileSetCommand(a *app) *cobra.Command {
+	var accountURL, transactionURL, format, timeout string
+	var headers []string
+
+	cmd := &cobra.Command{
+		Use:   "set NAME",
+		Short: "Create or update a profile",
+		Example: `  ledgerctl profile set staging --account-url https://accounts.staging.example.com \
+    --transaction-url https://transactions.staging.example.com --header "Authorization=Bearer $TOKEN"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(a.configPath)
+			if err != nil {
+				return err
+			}
+
+			profile := cfg.Profiles[args[0]]
+			flags := cmd.Flags()
+			if flags.Changed("account-url") {
+				profile.AccountURL = accountURL
+			}
+			if flags.Changed("transaction-url") {
+				profile.TransactionURL = transactionURL
+			}
+			if flags.Changed("default-output") {
+				if _, err := output.ParseFormat(format); err != nil {
+					return err
+				}
+				profile.Output = format
+			}
+			if flags.Changed("timeout") {
+				profile.Timeout = timeout
+				if _, err := profile.RequestTimeout(); err != nil {
+					return err
+				}
+			}
+			for _, header := range headers {
+				name, value, ok := strings.Cut(header, "=")
+				if !ok || name == "" {
+					return fmt.Errorf("invalid header %q, expected NAME=VALUE", header)
+				}
+				if profile.Headers == nil {
+					profile.Headers = map[string]string{}
+				}
+				if value == "" {
+					delete(profile.Headers, name)
+					continue
+				}
+				profile.Headers[name] = value
+			}
+			if profile.AccountURL == "" || profile.TransactionURL == "" {
+				return fmt.Errorf("profile %q needs --account-url and --transaction-url", args[0])
+			}
+
+			cfg.Profiles[args[0]] = profile
+			if cfg.CurrentProfile == "" {
+				cfg.CurrentProfile = args[0]
+			}
+			if err := cfg.Save(a.configPath); err != nil {
+				return err
+			}
+			fmt.Fprintf(a.out, "Profile %q saved to %s\n", args[0], a.configPath)
+			return nil
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&accountURL, "account-url", "", "account-service base URL")
+	flags.StringVar(&transactionURL, "transaction-url", "", "transaction-service base URL")
+	flags.StringVar(&format, "default-output", "", "default output format, table or json")
+	flags.StringVar(&timeout, "timeout", "", "per-attempt request timeout, e.g. 5s")
+	flags.StringArrayVar(&headers, "header", nil, "header sent with every request as NAME=VALUE, an empty value removes it")
+	return cmd
+}
+
+func newProfileUseCommand(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:               "use NAME",
+		Short:             "Make a profile the current one",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: a.completeProfileArg,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(a.configPath)
+			if err != nil {
+				return err
+			}
+			if _, ok := cfg.Profiles[args[0]]; !ok {
+				return fmt.Errorf("profile %q not found, available: %s", args[0], strings.Join(cfg.ProfileNames(), ", "))
+			}
+			cfg.CurrentProfile = args[0]
+			if err := cfg.Save(a.configPath); err != nil {
+				return err
+			}
+			fmt.Fprintf(a.out, "Using profile %q\n", args[0])
+			return nil
+		},
+	}
+}
+
+func newProfileDeleteCommand(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:               "delete NAME",
+		Short:             "Delete a profile",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: a.completeProfileArg,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.Load(a.configPath)
+			if err != nil {
+				return err
+			}
+			if _, ok := cfg.Profiles[args[0]]; !ok {
+				return fmt.Errorf("profile %q not found", args[0])
+			}
+			delete(cfg.Profiles, args[0])
+			if cfg.CurrentProfile == args[0] {
+				cfg.CurrentProfile = ""
+			}
+			if err := cfg.Save(a.configPath); err != nil {
+				return err
+			}
+			fmt.Fprintf(a.out, "Profile %q deleted\n", args[0])
+			return nil
+		},
+	}
+}
+
+// completeProfileArg completes the NAME argument with the existing profiles
+func (a *app) completeProfileArg(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return a.completeProfiles(cmd, args, toComplete)
+}
@@ -0,0 +1,81 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/danielkhtse/supreme-adventure/cli/internal/output"
+	"github.com/danielkhtse/supreme-adventure/cli/internal/reconcile"
+	"github.com/spf13/cobra"
+)
+
+// errDiscrepancies makes reconcile exit non-zero when it found discrepancies, after printing the report
+var errDiscrepancies = errors.New("reconciliation found discrepancies")
+
+func newReconcileCommand(a *app) *cobra.Command {
+	var accountIDs []uint
+
+	cmd := &cobra.Command{
+		Use:   "reconcile",
+		Short: "Cross-check balances, the account activity ledger and transactions",
+		Long: `Cross-check every account (or the --account accounts) against its activity ledger, and every
+completed transaction against the debit and credit it applied on account-service.
+
+Checks per account:
+  ledger_mismatch         credits minus debits of the activity differ from the balance
+  last_balance_mismatch   the newest activity left a different balance
+  opening_mismatch        the opening activity differs from the initial balance
+
+Checks per transaction:
+  missing_transfer        a completed transaction has no debit or credit
+  amount_mismatch         the debit or credit moved a different amount
+  unexpected_transfer     funds moved for a pending or failed transaction
+  unknown_transaction     the activity references a transaction unknown to transaction-service
+
+A pending transaction may be reported while its transfer is in flight, run the report again to confirm.
+The command exits with status 1 when discrepancies are found.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := reconcile.Options{}
+			for _, id := range accountIDs {
+				opts.AccountIDs = append(opts.AccountIDs, uint64(id))
+			}
+
+			report, err := reconcile.Run(cmd.Context(), a.accounts, a.transactions, opts)
+			if err != nil {
+				return err
+			}
+			if err := a.printer.Print(report, func() output.Table { return reconcileTable(report) }); err != nil {
+				return err
+			}
+			if a.printer.Format() == output.FormatTable {
+				fmt.Fprintf(a.out, "\n%d accounts, %d transactions, %d discrepancies\n", report.Accounts, report.Transactions, len(report.Findings))
+			}
+			if len(report.Findings) > 0 {
+				return errDiscrepancies
+			}
+			return nil
+		},
+	}
+	cmd.Flags().UintSliceVar(&accountIDs, "account", nil, "only reconcile these accounts and their transactions")
+	return cmd
+}
+
+func reconcileTable(report *reconcile.Report) output.Table {
+	table := output.Table{Header: []string{"KIND", "ACCOUNT", "TRANSACTION", "EXPECTED", "ACTUAL", "DETAIL"}}
+	for _, finding := range report.Findings {
+		transactionID := ""
+		if finding.TransactionID != 0 {
+			transactionID = formatUint(finding.TransactionID)
+		}
+		table.Rows = append(table.Rows, []string{
+			string(finding.Kind),
+			formatUint(finding.AccountID),
+			transactionID,
+			formatInt(finding.Expected),
+			formatInt(finding.Actual),
+			finding.Detail,
+		})
+	}
+	return table
+}
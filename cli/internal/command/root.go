@@ -0,0 +1,168 @@
+// Package command implements the ledgerctl commands
+package command
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+
+	"github.com/danielkhtse/supreme-adventure/cli/internal/config"
+	"github.com/danielkhtse/supreme-adventure/cli/internal/output"
+	"github.com/danielkhtse/supreme-adventure/sdk"
+	"github.com/spf13/cobra"
+)
+
+// app holds the global flags and the clients built from the selected profile
+type app struct {
+	configPath     string
+	profileName    string
+	outputFormat   string
+	accountURL     string
+	transactionURL string
+
+	out          io.Writer
+	printer      *output.Printer
+	accounts     *sdk.AccountClient
+	transactions *sdk.TransactionClient
+}
+
+// Execute runs ledgerctl with the process arguments and returns the exit code
+func Execute() int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := NewRootCommand(os.Stdout).ExecuteContext(ctx); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return 1
+	}
+	return 0
+}
+
+// NewRootCommand returns the ledgerctl command tree writing results to out
+func NewRootCommand(out io.Writer) *cobra.Command {
+	a := &app{out: out}
+
+	root := &cobra.Command{
+		Use:   "ledgerctl",
+		Short: "Operate account-service and transaction-service",
+		Long: `ledgerctl calls the v1 REST APIs of account-service and transaction-service.
+
+Service URLs come from the selected profile (see "ledgerctl profile --help") and can be
+overridden per call with --account-url and --transaction-url.`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return a.init(cmd)
+		},
+	}
+
+	flags := root.PersistentFlags()
+	flags.StringVar(&a.configPath, "config", "", "config file (default $LEDGERCTL_CONFIG or ledgerctl/config.json in the user config directory)")
+	flags.StringVarP(&a.profileName, "profile", "p", "", "profile to use (default $LEDGERCTL_PROFILE or the current profile)")
+	flags.StringVarP(&a.outputFormat, "output", "o", "", "output format, table or json (default from the profile, else table)")
+	flags.StringVar(&a.accountURL, "account-url", "", "account-service base URL, overrides the profile")
+	flags.StringVar(&a.transactionURL, "transaction-url", "", "transaction-service base URL, overrides the profile")
+
+	root.RegisterFlagCompletionFunc("output", cobra.FixedCompletions([]string{"table", "json"}, cobra.ShellCompDirectiveNoFileComp))
+	root.RegisterFlagCompletionFunc("profile", a.completeProfiles)
+
+	root.AddCommand(
+		newAccountsCommand(a),
+		newTransferCommand(a),
+		newTransactionsCommand(a),
+		newReconcileCommand(a),
+		newProfileCommand(a),
+	)
+	return root
+}
+
+// init resolves the profile and builds the printer and clients, profile and completion commands only need the config path
+func (a *app) init(cmd *cobra.Command) error {
+	if a.configPath == "" {
+		path, err := config.Path()
+		if err != nil {
+			return err
+		}
+		a.configPath = path
+	}
+	for c := cmd; c.HasParent(); c = c.Parent() {
+		if c.Name() == "profile" || c.Name() == "completion" || c.Name() == cobra.ShellCompRequestCmd {
+			return nil
+		}
+	}
+
+	cfg, err := config.Load(a.configPath)
+	if err != nil {
+		return err
+	}
+	_, profile, err := cfg.Profile(a.profileName)
+	if err != nil && (a.accountURL == "" || a.transactionURL == "") {
+		return err
+	}
+
+	format := a.outputFormat
+	if format == "" {
+		format = profile.Output
+	}
+	parsed, err := output.ParseFormat(format)
+	if err != nil {
+		return err
+	}
+	a.printer = output.NewPrinter(a.out, parsed)
+
+	sdkConfig := sdk.DefaultConfig()
+	timeout, err := profile.RequestTimeout()
+	if err != nil {
+		return err
+	}
+	if timeout > 0 {
+		sdkConfig.Timeout = timeout
+	}
+	sdkConfig.UserAgent = "ledgerctl"
+	if len(profile.Headers) > 0 {
+		sdkConfig.Headers = http.Header{}
+		for name, value := range profile.Headers {
+			sdkConfig.Headers.Set(name, value)
+		}
+	}
+
+	accountURL := firstNonEmpty(a.accountURL, profile.AccountURL)
+	transactionURL := firstNonEmpty(a.transactionURL, profile.TransactionURL)
+	a.accounts = sdk.NewAccountClientWithConfig(accountURL, sdkConfig)
+	a.transactions = sdk.NewTransactionClientWithConfig(transactionURL, sdkConfig)
+	return nil
+}
+
+func (a *app) completeProfiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	path := a.configPath
+	if path == "" {
+		path, _ = config.Path()
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return cfg.ProfileNames(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// parseID parses a positional account or transaction ID
+func parseID(value string) (uint64, error) {
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || id == 0 {
+		return 0, fmt.Errorf("invalid ID %q", value)
+	}
+	return id, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,198 @@
+package command
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/danielkhtse/supreme-adventure/cli/internal/output"
+	"github.com/danielkhtse/supreme-adventure/sdk"
+	"github.com/spf13/cobra"
+)
+
+// tailWait is how long each change feed request waits for new events, below the 30 second maximum. The SDK adds it
+// to the timeout of each attempt.
+const tailWait = 25 * time.Second
+
+func newTransferCommand(a *app) *cobra.Command {
+	var req sdk.CreateTransactionRequest
+	cmd := &cobra.Command{
+		Use:   "transfer",
+		Short: "Transfer funds between two accounts through transaction-service",
+		Long: `Transfer funds between two accounts through transaction-service, which records the transfer
+as a transaction. The request is not retried, check "ledgerctl transactions list" before running
+it again after a timeout.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			transaction, err := a.transactions.CreateTransaction(cmd.Context(), req)
+			if err != nil {
+				return err
+			}
+			return a.printer.Print(transaction, func() output.Table { return transactionsTable([]sdk.Transaction{*transaction}) })
+		},
+	}
+	cmd.Flags().Uint64Var(&req.SourceAccountID, "from", 0, "source account ID")
+	cmd.Flags().Uint64Var(&req.DestAccountID, "to", 0, "destination account ID")
+	cmd.Flags().Int64Var(&req.Amount, "amount", 0, "amount in the smallest currency units")
+	for _, flag := range []string{"from", "to", "amount"} {
+		cmd.MarkFlagRequired(flag)
+	}
+	return cmd
+}
+
+func newTransactionsCommand(a *app) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "transactions",
+		Aliases: []string{"transaction", "tx"},
+		Short:   "Show, list and tail transactions",
+	}
+	cmd.AddCommand(
+		newTransactionsShowCommand(a),
+		newTransactionsListCommand(a),
+		newTransactionsTailCommand(a),
+	)
+	return cmd
+}
+
+func newTransactionsShowCommand(a *app) *cobra.Command {
+	return &cobra.Command{
+		Use:   "show TRANSACTION_ID",
+		Short: "Show a transaction",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id, err := parseID(args[0])
+			if err != nil {
+				return err
+			}
+			transaction, err := a.transactions.GetTransaction(cmd.Context(), id)
+			if err != nil {
+				return err
+			}
+			return a.printer.Print(transaction, func() output.Table { return transactionsTable([]sdk.Transaction{*transaction}) })
+		},
+	}
+}
+
+func newTransactionsListCommand(a *app) *cobra.Command {
+	var params sdk.ListTransactionsParams
+	var status, createdFrom, createdTo string
+	var limit int
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List transactions, newest first unless --ascending is given",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			params.Status = sdk.TransactionStatus(status)
+			var err error
+			if params.CreatedFrom, err = parseTime("created-from", createdFrom); err != nil {
+				return err
+			}
+			if params.CreatedTo, err = parseTime("created-to", createdTo); err != nil {
+				return err
+			}
+
+			transactions := []sdk.Transaction{}
+			for transaction, err := range a.transactions.Transactions(cmd.Context(), params) {
+				if err != nil {
+					return err
+				}
+				transactions = append(transactions, transaction)
+				if !all && len(transactions) >= limit {
+					break
+				}
+			}
+			return a.printer.Print(transactions, func() output.Table { return transactionsTable(transactions) })
+		},
+	}
+	flags := cmd.Flags()
+	flags.Uint64Var(&params.AccountID, "account", 0, "only transactions with this account on either side")
+	flags.StringVar(&status, "status", "", "only transactions with this status, pending, completed or failed")
+	flags.Int64Var(&params.MinAmount, "min-amount", 0, "minimum amount, inclusive")
+	flags.Int64Var(&params.MaxAmount, "max-amount", 0, "maximum amount, inclusive")
+	flags.StringVar(&createdFrom, "created-from", "", "only transactions created at or after this RFC 3339 time")
+	flags.StringVar(&createdTo, "created-to", "", "only transactions created before this RFC 3339 time")
+	flags.BoolVar(&params.Ascending, "ascending", false, "oldest first")
+	flags.IntVar(&limit, "limit", 50, "maximum number of transactions to print")
+	flags.BoolVar(&all, "all", false, "print every matching transaction, ignoring --limit")
+
+	cmd.RegisterFlagCompletionFunc("status", cobra.FixedCompletions([]string{"pending", "completed", "failed"}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+func newTransactionsTailCommand(a *app) *cobra.Command {
+	var accountID uint64
+	var after string
+	var fromStart bool
+
+	cmd := &cobra.Command{
+		Use:   "tail",
+		Short: "Print transaction status changes as they happen",
+		Long: `Print transaction status changes from the transaction-service change feed until interrupted.
+
+Only changes after the command starts are printed, unless --from-start or --after is given.
+Table output prints one row per change, JSON output one object per line.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			if after == "" && !fromStart {
+				after = sdk.LatestCursor
+			}
+
+			stream := a.printer.Stream("TIME", "ID", "STATUS", "SOURCE", "DESTINATION", "AMOUNT")
+			for {
+				page, err := a.transactions.ListEvents(ctx, sdk.ListEventsParams{After: after, AccountID: accountID, Limit: 1000, Wait: tailWait})
+				if err != nil {
+					if ctx.Err() != nil {
+						return nil
+					}
+					return err
+				}
+				after = page.NextCursor
+
+				for _, event := range page.Events {
+					if event.Type != sdk.EventTypeTransactionStatusChanged {
+						continue
+					}
+					var transaction sdk.Transaction
+					if err := json.Unmarshal(event.Data, &transaction); err != nil {
+						return fmt.Errorf("failed to decode event %d: %w", event.ID, err)
+					}
+					err := stream.Write(transaction, []string{
+						formatTime(event.OccurredAt),
+						formatUint(transaction.ID),
+						string(transaction.Status),
+						formatUint(transaction.SourceAccountID),
+						formatUint(transaction.DestAccountID),
+						formatInt(transaction.Amount),
+					})
+					if err != nil {
+						return err
+					}
+				}
+			}
+		},
+	}
+	cmd.Flags().Uint64Var(&accountID, "account", 0, "only transactions with this account on either side")
+	cmd.Flags().StringVar(&after, "after", "", "change feed cursor to resume from")
+	cmd.Flags().BoolVar(&fromStart, "from-start", false, "start from the oldest retained change")
+	cmd.MarkFlagsMutuallyExclusive("after", "from-start")
+	return cmd
+}
+
+func transactionsTable(transactions []sdk.Transaction) output.Table {
+	table := output.Table{Header: []string{"ID", "STATUS", "SOURCE", "DESTINATION", "AMOUNT", "CURRENCY", "CREATED"}}
+	for _, transaction := range transactions {
+		table.Rows = append(table.Rows, []string{
+			formatUint(transaction.ID),
+			string(transaction.Status),
+			formatUint(transaction.SourceAccountID),
+			formatUint(transaction.DestAccountID),
+			formatInt(transaction.Amount),
+			transaction.Currency,
+			formatTime(transaction.CreatedAt),
+		})
+	}
+	return table
+}
@@ -0,0 +1,153 @@
+// Package config loads the ledgerctl profiles, each naming the service endpoints of one environment
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const (
+	// ConfigEnv overrides the path of the config file
+	ConfigEnv = "LEDGERCTL_CONFIG"
+
+	// ProfileEnv selects the profile when --profile is not given
+	ProfileEnv = "LEDGERCTL_PROFILE"
+
+	// DefaultProfile is used when neither --profile, LEDGERCTL_PROFILE nor current_profile select one
+	DefaultProfile = "local"
+)
+
+// Profile holds the endpoints and defaults of one environment
+type Profile struct {
+	// Base URL of account-service, e.g. http://localhost:8080
+	AccountURL string `json:"account_url"`
+
+	// Base URL of transaction-service, e.g. http://localhost:8081
+	TransactionURL string `json:"transaction_url"`
+
+	// Default output format, json or table
+	Output string `json:"output,omitempty"`
+
+	// Per-attempt request timeout as a Go duration, the SDK default when empty
+	Timeout string `json:"timeout,omitempty"`
+
+	// Extra headers sent with every request, e.g. Authorization
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// RequestTimeout parses Timeout, zero when unset
+func (p Profile) RequestTimeout() (time.Duration, error) {
+	if p.Timeout == "" {
+		return 0, nil
+	}
+	timeout, err := time.ParseDuration(p.Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout %q: %w", p.Timeout, err)
+	}
+	return timeout, nil
+}
+
+// Config is the content of the config file
+type Config struct {
+	// Profile used when none is selected by flag or environment
+	CurrentProfile string `json:"current_profile,omitempty"`
+
+	Profiles map[string]Profile `json:"profiles"`
+}
+
+// Default returns the config used when no config file exists, pointing at the docker-compose ports
+func Default() *Config {
+	return &Config{
+		CurrentProfile: DefaultProfile,
+		Profiles: map[string]Profile{
+			DefaultProfile: {
+				AccountURL:     "http://localhost:8080",
+				TransactionURL: "http://localhost:8081",
+			},
+		},
+	}
+}
+
+// Path returns the config file path, LEDGERCTL_CONFIG or ledgerctl/config.json in the user config directory
+func Path() (string, error) {
+	if path := os.Getenv(ConfigEnv); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "ledgerctl", "config.json"), nil
+}
+
+// Load reads the config file at path, returning Default when it does not exist
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Default(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	if config.Profiles == nil {
+		config.Profiles = map[string]Profile{}
+	}
+	return &config, nil
+}
+
+// Save writes the config file at path, creating its directory
+func (c *Config) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	// profiles may carry credentials in their headers
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+// ProfileName returns the selected profile: name when set, then LEDGERCTL_PROFILE, then current_profile
+func (c *Config) ProfileName(name string) string {
+	if name != "" {
+		return name
+	}
+	if name := os.Getenv(ProfileEnv); name != "" {
+		return name
+	}
+	if c.CurrentProfile != "" {
+		return c.CurrentProfile
+	}
+	return DefaultProfile
+}
+
+// Profile returns the profile selected by ProfileName
+func (c *Config) Profile(name string) (string, Profile, error) {
+	name = c.ProfileName(name)
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return name, Profile{}, fmt.Errorf("profile %q not found", name)
+	}
+	return name, profile, nil
+}
+
+// ProfileNames returns the names of all profiles in order
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,115 @@
+// Package output renders command results as indented JSON or as aligned tables
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// Format is an output format
+type Format string
+
+const (
+	FormatTable Format = "table"
+	FormatJSON  Format = "json"
+)
+
+// ParseFormat validates an output format, an empty value selects FormatTable
+func ParseFormat(value string) (Format, error) {
+	switch Format(value) {
+	case "", FormatTable:
+		return FormatTable, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("unknown output format %q, expected table or json", value)
+	}
+}
+
+// Table is the tabular rendering of a result
+type Table struct {
+	Header []string
+	Rows   [][]string
+}
+
+// Printer writes results in one format
+type Printer struct {
+	w      io.Writer
+	format Format
+}
+
+// NewPrinter returns a printer writing to w
+func NewPrinter(w io.Writer, format Format) *Printer {
+	return &Printer{w: w, format: format}
+}
+
+// Format returns the format of the printer
+func (p *Printer) Format() Format {
+	return p.format
+}
+
+// Print writes value as indented JSON, or the table built by table in table format
+func (p *Printer) Print(value any, table func() Table) error {
+	if p.format == FormatJSON {
+		encoder := json.NewEncoder(p.w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+
+	t := table()
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	if len(t.Header) > 0 {
+		fmt.Fprintln(tw, strings.Join(t.Header, "\t"))
+	}
+	for _, row := range t.Rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// Stream writes results one at a time as they arrive, one JSON object per line or one table row per line
+type Stream struct {
+	w       io.Writer
+	format  Format
+	header  []string
+	started bool
+}
+
+// streamColumnWidth pads streamed table cells, rows cannot be aligned to values that have not arrived yet
+const streamColumnWidth = 20
+
+// Stream returns a stream with the given table header
+func (p *Printer) Stream(header ...string) *Stream {
+	return &Stream{w: p.w, format: p.format, header: header}
+}
+
+// Write writes value as one line of JSON, or row in table format
+func (s *Stream) Write(value any, row []string) error {
+	if s.format == FormatJSON {
+		return json.NewEncoder(s.w).Encode(value)
+	}
+
+	if !s.started {
+		s.started = true
+		if err := s.writeRow(s.header); err != nil {
+			return err
+		}
+	}
+	return s.writeRow(row)
+}
+
+func (s *Stream) writeRow(row []string) error {
+	var line strings.Builder
+	for i, cell := range row {
+		if i < len(row)-1 {
+			fmt.Fprintf(&line, "%-*s  ", streamColumnWidth, cell)
+			continue
+		}
+		line.WriteString(cell)
+	}
+	_, err := fmt.Fprintln(s.w, line.String())
+	return err
+}
@@ -0,0 +1,283 @@
+// Package reconcile cross-checks account balances, the account-service activity ledger and the
+// transaction-service transactions, reporting every discrepancy it finds
+package reconcile
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"iter"
+	"slices"
+
+	"github.com/danielkhtse/supreme-adventure/sdk"
+)
+
+// Kind is the kind of a discrepancy
+type Kind string
+
+const (
+	// The credits minus the debits of the activity ledger differ from the account balance
+	KindLedgerMismatch Kind = "ledger_mismatch"
+
+	// The balance after the newest activity entry differs from the account balance
+	KindLastBalanceMismatch Kind = "last_balance_mismatch"
+
+	// The opening activity differs from the initial balance of the account
+	KindOpeningMismatch Kind = "opening_mismatch"
+
+	// A completed transaction has no matching debit or credit in the activity ledger
+	KindMissingTransfer Kind = "missing_transfer"
+
+	// The activity ledger moved a different amount than the transaction
+	KindAmountMismatch Kind = "amount_mismatch"
+
+	// The activity ledger holds a transfer of a pending or failed transaction
+	KindUnexpectedTransfer Kind = "unexpected_transfer"
+
+	// The activity ledger holds a transfer of a transaction unknown to transaction-service
+	KindUnknownTransaction Kind = "unknown_transaction"
+)
+
+// Finding is one discrepancy
+type Finding struct {
+	Kind          Kind   `json:"kind"`
+	AccountID     uint64 `json:"account_id,string"`
+	TransactionID uint64 `json:"transaction_id,string,omitempty"`
+	Expected      int64  `json:"expected"`
+	Actual        int64  `json:"actual"`
+	Detail        string `json:"detail"`
+}
+
+// Report is the result of a reconciliation run
+type Report struct {
+	// Number of accounts checked
+	Accounts int `json:"accounts"`
+
+	// Number of transactions checked
+	Transactions int `json:"transactions"`
+
+	Findings []Finding `json:"findings"`
+}
+
+// AccountSource reads accounts and their activity, implemented by *sdk.AccountClient
+type AccountSource interface {
+	Accounts(ctx context.Context, params sdk.ListAccountsParams) iter.Seq2[sdk.Account, error]
+	Activity(ctx context.Context, accountID uint64, params sdk.ListActivityParams) iter.Seq2[sdk.Activity, error]
+}
+
+// TransactionSource reads transactions, implemented by *sdk.TransactionClient
+type TransactionSource interface {
+	Transactions(ctx context.Context, params sdk.ListTransactionsParams) iter.Seq2[sdk.Transaction, error]
+}
+
+// Options narrows a reconciliation run
+type Options struct {
+	// Only check these accounts and the transactions touching them, every account when empty
+	AccountIDs []uint64
+}
+
+// transfer holds the ledger entries of one transaction
+type transfer struct {
+	debit  *sdk.Activity
+	credit *sdk.Activity
+}
+
+// Run reconciles the accounts and transactions selected by opts. Transfers applied directly on
+// account-service without a transaction ID are covered by the ledger checks only.
+func Run(ctx context.Context, accounts AccountSource, transactions TransactionSource, opts Options) (*Report, error) {
+	report := &Report{Findings: []Finding{}}
+	inScope := func(accountID uint64) bool {
+		return len(opts.AccountIDs) == 0 || slices.Contains(opts.AccountIDs, accountID)
+	}
+
+	transfers := map[uint64]*transfer{}
+	for account, err := range accounts.Accounts(ctx, sdk.ListAccountsParams{Sort: sdk.AccountSortCreatedAtAsc}) {
+		if err != nil {
+			return nil, fmt.Errorf("failed to list accounts: %w", err)
+		}
+		if !inScope(account.ID) {
+			continue
+		}
+		report.Accounts++
+		if err := checkLedger(ctx, accounts, account, transfers, report); err != nil {
+			return nil, err
+		}
+	}
+
+	seen := map[uint64]bool{}
+	check := func(transaction sdk.Transaction) {
+		if seen[transaction.ID] {
+			return
+		}
+		seen[transaction.ID] = true
+		report.Transactions++
+		checkTransaction(transaction, transfers[transaction.ID], inScope, report)
+	}
+	if len(opts.AccountIDs) == 0 {
+		for transaction, err := range transactions.Transactions(ctx, sdk.ListTransactionsParams{Ascending: true}) {
+			if err != nil {
+				return nil, fmt.Errorf("failed to list transactions: %w", err)
+			}
+			check(transaction)
+		}
+	} else {
+		for _, accountID := range opts.AccountIDs {
+			params := sdk.ListTransactionsParams{AccountID: accountID, Ascending: true}
+			for transaction, err := range transactions.Transactions(ctx, params) {
+				if err != nil {
+					return nil, fmt.Errorf("failed to list transactions of account %d: %w", accountID, err)
+				}
+				check(transaction)
+			}
+		}
+	}
+
+	for transactionID, t := range transfers {
+		if seen[transactionID] {
+			continue
+		}
+		for _, entry := range []*sdk.Activity{t.debit, t.credit} {
+			if entry != nil {
+				report.add(Finding{
+					Kind:          KindUnknownTransaction,
+					AccountID:     entry.AccountID,
+					TransactionID: transactionID,
+					Actual:        entry.Amount,
+					Detail:        fmt.Sprintf("%s of %d references a transaction unknown to transaction-service", entry.Direction, entry.Amount),
+				})
+			}
+		}
+	}
+
+	slices.SortStableFunc(report.Findings, func(a, b Finding) int {
+		if a.AccountID != b.AccountID {
+			return cmp.Compare(a.AccountID, b.AccountID)
+		}
+		return cmp.Compare(a.TransactionID, b.TransactionID)
+	})
+	return report, nil
+}
+
+// checkLedger compares the activity of an account with its balance and collects its transaction transfers
+func checkLedger(ctx context.Context, accounts AccountSource, account sdk.Account, transfers map[uint64]*transfer, report *Report) error {
+	var net, opening int64
+	var newest *sdk.Activity
+	for activity, err := range accounts.Activity(ctx, account.ID, sdk.ListActivityParams{}) {
+		if err != nil {
+			return fmt.Errorf("failed to list activity of account %d: %w", account.ID, err)
+		}
+		if newest == nil {
+			newest = &activity
+		}
+
+		if activity.Direction == sdk.ActivityDirectionDebit {
+			net -= activity.Amount
+		} else {
+			net += activity.Amount
+		}
+		if activity.Type == sdk.ActivityTypeOpening {
+			opening += activity.Amount
+		}
+
+		if activity.TransactionID != 0 {
+			t, ok := transfers[activity.TransactionID]
+			if !ok {
+				t = &transfer{}
+				transfers[activity.TransactionID] = t
+			}
+			if activity.Direction == sdk.ActivityDirectionDebit {
+				t.debit = &activity
+			} else {
+				t.credit = &activity
+			}
+		}
+	}
+
+	if net != account.Balance {
+		report.add(Finding{
+			Kind:      KindLedgerMismatch,
+			AccountID: account.ID,
+			Expected:  account.Balance,
+			Actual:    net,
+			Detail:    "credits minus debits differ from the balance",
+		})
+	}
+	if newest != nil && newest.BalanceAfter != account.Balance {
+		report.add(Finding{
+			Kind:      KindLastBalanceMismatch,
+			AccountID: account.ID,
+			Expected:  account.Balance,
+			Actual:    newest.BalanceAfter,
+			Detail:    fmt.Sprintf("activity %d left a different balance", newest.ID),
+		})
+	}
+	if opening != account.InitialBalance {
+		report.add(Finding{
+			Kind:      KindOpeningMismatch,
+			AccountID: account.ID,
+			Expected:  account.InitialBalance,
+			Actual:    opening,
+			Detail:    "opening activity differs from the initial balance",
+		})
+	}
+	return nil
+}
+
+// checkTransaction compares a transaction with the ledger entries carrying its ID
+func checkTransaction(transaction sdk.Transaction, t *transfer, inScope func(uint64) bool, report *Report) {
+	if t == nil {
+		t = &transfer{}
+	}
+	sides := []struct {
+		accountID uint64
+		entry     *sdk.Activity
+		direction sdk.ActivityDirection
+	}{
+		{transaction.SourceAccountID, t.debit, sdk.ActivityDirectionDebit},
+		{transaction.DestAccountID, t.credit, sdk.ActivityDirectionCredit},
+	}
+
+	for _, side := range sides {
+		if !inScope(side.accountID) {
+			continue
+		}
+
+		if transaction.Status != sdk.TransactionStatusCompleted {
+			if side.entry != nil {
+				report.add(Finding{
+					Kind:          KindUnexpectedTransfer,
+					AccountID:     side.accountID,
+					TransactionID: transaction.ID,
+					Actual:        side.entry.Amount,
+					Detail:        fmt.Sprintf("%s applied for a %s transaction", side.direction, transaction.Status),
+				})
+			}
+			continue
+		}
+
+		if side.entry == nil {
+			report.add(Finding{
+				Kind:          KindMissingTransfer,
+				AccountID:     side.accountID,
+				TransactionID: transaction.ID,
+				Expected:      transaction.Amount,
+				Detail:        fmt.Sprintf("no %s recorded for the completed transaction", side.direction),
+			})
+			continue
+		}
+		if side.entry.Amount != transaction.Amount || side.entry.AccountID != side.accountID {
+			report.add(Finding{
+				Kind:          KindAmountMismatch,
+				AccountID:     side.accountID,
+				TransactionID: transaction.ID,
+				Expected:      transaction.Amount,
+				Actual:        side.entry.Amount,
+				Detail:        fmt.Sprintf("%s of account %d differs from the transaction", side.direction, side.entry.AccountID),
+			})
+		}
+	}
+}
+
+func (r *Report) add(finding Finding) {
+	r.Findings = append(r.Findings, finding)
+}
@@ -0,0 +1,215 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"slices"
+	"testing"
+
+	"github.com/danielkhtse/supreme-adventure/sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAccounts struct {
+	accounts []sdk.Account
+
+	// activity per account, newest first like the API
+	activity map[uint64][]sdk.Activity
+}
+
+func (f *fakeAccounts) Accounts(ctx context.Context, params sdk.ListAccountsParams) iter.Seq2[sdk.Account, error] {
+	return seq(f.accounts, nil)
+}
+
+func (f *fakeAccounts) Activity(ctx context.Context, accountID uint64, params sdk.ListActivityParams) iter.Seq2[sdk.Activity, error] {
+	return seq(f.activity[accountID], nil)
+}
+
+type fakeTransactions struct {
+	transactions []sdk.Transaction
+	err          error
+}
+
+func (f *fakeTransactions) Transactions(ctx context.Context, params sdk.ListTransactionsParams) iter.Seq2[sdk.Transaction, error] {
+	var matching []sdk.Transaction
+	for _, transaction := range f.transactions {
+		if params.AccountID == 0 || transaction.SourceAccountID == params.AccountID || transaction.DestAccountID == params.AccountID {
+			matching = append(matching, transaction)
+		}
+	}
+	return seq(matching, f.err)
+}
+
+func seq[T any](items []T, err error) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for _, item := range items {
+			if !yield(item, nil) {
+				return
+			}
+		}
+		if err != nil {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
+
+func opening(id, accountID uint64, amount int64) sdk.Activity {
+	return sdk.Activity{ID: id, AccountID: accountID, Type: sdk.ActivityTypeOpening, Direction: sdk.ActivityDirectionCredit, Amount: amount, BalanceAfter: amount}
+}
+
+func transferEntry(id, accountID uint64, direction sdk.ActivityDirection, amount, balanceAfter int64, transactionID uint64) sdk.Activity {
+	return sdk.Activity{
+		ID:            id,
+		AccountID:     accountID,
+		Type:          sdk.ActivityTypeTransfer,
+		Direction:     direction,
+		Amount:        amount,
+		BalanceAfter:  balanceAfter,
+		TransactionID: transactionID,
+	}
+}
+
+// balancedLedger has accounts 1 and 2 opened with 100 and 0 and transaction 10 moving 30 from 1 to 2
+func balancedLedger() (*fakeAccounts, *fakeTransactions) {
+	accounts := &fakeAccounts{
+		accounts: []sdk.Account{
+			{ID: 1, Balance: 70, InitialBalance: 100},
+			{ID: 2, Balance: 30},
+		},
+		activity: map[uint64][]sdk.Activity{
+			1: {transferEntry(3, 1, sdk.ActivityDirectionDebit, 30, 70, 10), opening(1, 1, 100)},
+			2: {transferEntry(4, 2, sdk.ActivityDirectionCredit, 30, 30, 10)},
+		},
+	}
+	transactions := &fakeTransactions{
+		transactions: []sdk.Transaction{
+			{ID: 10, SourceAccountID: 1, DestAccountID: 2, Amount: 30, Status: sdk.TransactionStatusCompleted},
+			{ID: 11, SourceAccountID: 2, DestAccountID: 1, Amount: 5, Status: sdk.TransactionStatusFailed},
+		},
+	}
+	return accounts, transactions
+}
+
+func kinds(report *Report) []Kind {
+	var result []Kind
+	for _, finding := range report.Findings {
+		result = append(result, finding.Kind)
+	}
+	return result
+}
+
+func TestUnitRun(t *testing.T) {
+	t.Run("Balanced", func(t *testing.T) {
+		accounts, transactions := balancedLedger()
+
+		report, err := Run(context.Background(), accounts, transactions, Options{})
+		require.NoError(t, err)
+		assert.Equal(t, 2, report.Accounts)
+		assert.Equal(t, 2, report.Transactions)
+		assert.Empty(t, report.Findings)
+	})
+
+	t.Run("Balance drifted from the ledger", func(t *testing.T) {
+		accounts, transactions := balancedLedger()
+		accounts.accounts[0].Balance = 75
+
+		report, err := Run(context.Background(), accounts, transactions, Options{})
+		require.NoError(t, err)
+		assert.Equal(t, []Kind{KindLedgerMismatch, KindLastBalanceMismatch}, kinds(report))
+		assert.Equal(t, Finding{
+			Kind:      KindLedgerMismatch,
+			AccountID: 1,
+			Expected:  75,
+			Actual:    70,
+			Detail:    "credits minus debits differ from the balance",
+		}, report.Findings[0])
+	})
+
+	t.Run("Opening differs from the initial balance", func(t *testing.T) {
+		accounts, transactions := balancedLedger()
+		accounts.accounts[1].InitialBalance = 10
+
+		report, err := Run(context.Background(), accounts, transactions, Options{})
+		require.NoError(t, err)
+		assert.Equal(t, []Kind{KindOpeningMismatch}, kinds(report))
+	})
+
+	t.Run("Completed transaction without credit", func(t *testing.T) {
+		accounts, transactions := balancedLedger()
+		accounts.accounts[1].Balance = 0
+		accounts.activity[2] = nil
+
+		report, err := Run(context.Background(), accounts, transactions, Options{})
+		require.NoError(t, err)
+		require.Equal(t, []Kind{KindMissingTransfer}, kinds(report))
+		assert.Equal(t, uint64(2), report.Findings[0].AccountID)
+		assert.Equal(t, uint64(10), report.Findings[0].TransactionID)
+		assert.Equal(t, int64(30), report.Findings[0].Expected)
+	})
+
+	t.Run("Transfer of a failed transaction", func(t *testing.T) {
+		accounts, transactions := balancedLedger()
+		accounts.accounts[0].Balance = 75
+		accounts.accounts[1].Balance = 25
+		accounts.activity[1] = append([]sdk.Activity{transferEntry(6, 1, sdk.ActivityDirectionCredit, 5, 75, 11)}, accounts.activity[1]...)
+		accounts.activity[2] = append([]sdk.Activity{transferEntry(5, 2, sdk.ActivityDirectionDebit, 5, 25, 11)}, accounts.activity[2]...)
+
+		report, err := Run(context.Background(), accounts, transactions, Options{})
+		require.NoError(t, err)
+		assert.Equal(t, []Kind{KindUnexpectedTransfer, KindUnexpectedTransfer}, kinds(report))
+	})
+
+	t.Run("Transfer of an unknown transaction", func(t *testing.T) {
+		accounts, transactions := balancedLedger()
+		transactions.transactions = transactions.transactions[1:]
+
+		report, err := Run(context.Background(), accounts, transactions, Options{})
+		require.NoError(t, err)
+		assert.Equal(t, []Kind{KindUnknownTransaction, KindUnknownTransaction}, kinds(report))
+	})
+
+	t.Run("Amount differs", func(t *testing.T) {
+		accounts, transactions := balancedLedger()
+		transactions.transactions[0].Amount = 40
+
+		report, err := Run(context.Background(), accounts, transactions, Options{})
+		require.NoError(t, err)
+		assert.Equal(t, []Kind{KindAmountMismatch, KindAmountMismatch}, kinds(report))
+	})
+
+	t.Run("Restricted to accounts", func(t *testing.T) {
+		accounts, transactions := balancedLedger()
+		accounts.accounts[1].Balance = 0
+		accounts.activity[2] = nil
+
+		// the missing credit of account 2 is out of scope
+		report, err := Run(context.Background(), accounts, transactions, Options{AccountIDs: []uint64{1}})
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Accounts)
+		assert.Equal(t, 2, report.Transactions)
+		assert.Empty(t, report.Findings)
+	})
+
+	t.Run("List error", func(t *testing.T) {
+		accounts, transactions := balancedLedger()
+		transactions.err = errors.New("unavailable")
+
+		_, err := Run(context.Background(), accounts, transactions, Options{})
+		assert.ErrorContains(t, err, "failed to list transactions: unavailable")
+	})
+}
+
+func TestUnitRunSortsFindings(t *testing.T) {
+	accounts, transactions := balancedLedger()
+	accounts.accounts[0].Balance = 75
+	accounts.accounts[1].InitialBalance = 10
+
+	report, err := Run(context.Background(), accounts, transactions, Options{})
+	require.NoError(t, err)
+	assert.True(t, slices.IsSortedFunc(report.Findings, func(a, b Finding) int {
+		return int(a.AccountID) - int(b.AccountID)
+	}))
+}
@@ -0,0 +1,83 @@
+// Package apiversion serves the versions of a REST API side by side and marks the deprecated ones
+// with Deprecation (RFC 9745), Sunset (RFC 8594) and successor-version Link headers.
+package apiversion
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Headers sent by deprecated versions, exposed to browsers through CORS
+const (
+	DeprecationHeader = "Deprecation"
+	SunsetHeader      = "Sunset"
+	LinkHeader        = "Link"
+)
+
+// The unversioned routes predate /v1, they are served as deprecated aliases of v1 until LegacySunset
+var (
+	LegacyDeprecation = time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)
+	LegacySunset      = time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC)
+)
+
+// Version is one version of an API
+type Version struct {
+	// Path prefix of the version (e.g. "/v1"), empty for the unversioned routes
+	Prefix string
+
+	// Register adds the routes of the version. Versions register their own handlers, so a version
+	// can change its request and response types without affecting the others.
+	Register func(r *mux.Router)
+
+	// When the version was deprecated, zero while the version is current
+	Deprecation time.Time
+
+	// When the version stops being served, zero when not scheduled
+	Sunset time.Time
+
+	// Path prefix of the version replacing it, linked as successor-version
+	Successor string
+}
+
+// Mount registers every version on r, versions with a prefix must come before the unversioned routes
+func Mount(r *mux.Router, versions ...Version) {
+	for _, version := range versions {
+		var sub *mux.Router
+		if version.Prefix == "" {
+			sub = r.NewRoute().Subrouter()
+		} else {
+			sub = r.PathPrefix(version.Prefix).Subrouter()
+		}
+		if !version.Deprecation.IsZero() {
+			sub.Use(Deprecate(version))
+		}
+		version.Register(sub)
+	}
+}
+
+// Deprecate returns a middleware announcing the deprecation of version on every response
+func Deprecate(version Version) mux.MiddlewareFunc {
+	deprecation := fmt.Sprintf("@%d", version.Deprecation.Unix())
+	sunset := ""
+	if !version.Sunset.IsZero() {
+		sunset = version.Sunset.UTC().Format(http.TimeFormat)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(DeprecationHeader, deprecation)
+			if sunset != "" {
+				w.Header().Set(SunsetHeader, sunset)
+			}
+			if version.Successor != "" {
+				path := version.Successor + strings.TrimPrefix(r.URL.Path, version.Prefix)
+				w.Header().Add(LinkHeader, fmt.Sprintf(`<%s>; rel="successor-version"`, path))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package apiversion
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnitMount(t *testing.T) {
+	register := func(name string) func(r *mux.Router) {
+		return func(r *mux.Router) {
+			r.HandleFunc("/accounts/{account_id}", func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(name + ":" + mux.Vars(r)["account_id"]))
+			}).Methods("GET")
+		}
+	}
+
+	r := mux.NewRouter()
+	Mount(r,
+		Version{Prefix: "/v2", Register: register("v2")},
+		Version{
+			Prefix:      "/v1",
+			Register:    register("v1"),
+			Deprecation: time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+			Sunset:      time.Date(2026, time.July, 1, 0, 0, 0, 0, time.UTC),
+			Successor:   "/v2",
+		},
+		Version{Register: register("legacy"), Deprecation: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC), Successor: "/v2"},
+	)
+
+	t.Run("Current version", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/v2/accounts/7", nil))
+		assert.Equal(t, "v2:7", w.Body.String())
+		assert.Empty(t, w.Header().Get(DeprecationHeader))
+		assert.Empty(t, w.Header().Get(SunsetHeader))
+	})
+
+	t.Run("Deprecated version", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/v1/accounts/7", nil))
+		assert.Equal(t, "v1:7", w.Body.String())
+		assert.Equal(t, "@1767225600", w.Header().Get(DeprecationHeader))
+		assert.Equal(t, "Wed, 01 Jul 2026 00:00:00 GMT", w.Header().Get(SunsetHeader))
+		assert.Equal(t, `</v2/accounts/7>; rel="successor-version"`, w.Header().Get(LinkHeader))
+	})
+
+	t.Run("Unversioned routes", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/accounts/7", nil))
+		assert.Equal(t, "legacy:7", w.Body.String())
+		assert.Equal(t, "@1735689600", w.Header().Get(DeprecationHeader))
+		assert.Empty(t, w.Header().Get(SunsetHeader))
+		assert.Equal(t, `</v2/accounts/7>; rel="successor-version"`, w.Header().Get(LinkHeader))
+	})
+}
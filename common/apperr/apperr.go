@@ -0,0 +1,183 @@
+// Package apperr defines the domain errors shared by the services. Every error carries a stable
+// machine-readable code, callers match errors with errors.Is on the code rather than on the message.
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/danielkhtse/supreme-adventure/common/types"
+)
+
+// Code identifies the kind of an error, it is part of the API and never changes once published
+type Code string
+
+// Codes of the domain errors
+const (
+	CodeAccountNotFound      Code = "account_not_found"
+	CodeAccountAlreadyExists Code = "account_already_exists"
+	CodeAccountFrozen        Code = "account_frozen"
+	CodeInsufficientFunds    Code = "insufficient_funds"
+	CodeSameAccount          Code = "same_account"
+	CodeTransferIDConflict   Code = "transfer_id_conflict"
+	CodeVersionMismatch      Code = "version_mismatch"
+	CodeLockTimeout          Code = "lock_timeout"
+	CodeTransactionNotFound  Code = "transaction_not_found"
+	CodeWebhookNotFound      Code = "webhook_not_found"
+	CodeDeliveryNotFound     Code = "webhook_delivery_not_found"
+	CodeInvalidCursor        Code = "invalid_cursor"
+	CodeCursorExpired        Code = "cursor_expired"
+	CodeImportNotFound       Code = "account_import_not_found"
+	CodeImportNotResumable   Code = "account_import_not_resumable"
+	CodePaymentFileNotFound  Code = "payment_file_not_found"
+	CodePaymentFileRunning   Code = "payment_file_running"
+	CodeCurrencyMismatch     Code = "currency_mismatch"
+	CodeInsufficientScope    Code = "insufficient_scope"
+	CodeAddressNotAllowed    Code = "address_not_allowed"
+	CodeAPIKeyNotFound       Code = "api_key_not_found"
+	CodeAPIKeyRevoked        Code = "api_key_revoked"
+	CodeAccountAccessDenied  Code = "account_access_denied"
+)
+
+// Generic codes of errors without a more specific kind, derived from the HTTP status
+const (
+	CodeInvalidArgument    Code = "invalid_argument"
+	CodeUnauthenticated    Code = "unauthenticated"
+	CodeForbidden          Code = "forbidden"
+	CodeNotFound           Code = "not_found"
+	CodeConflict           Code = "conflict"
+	CodePreconditionFailed Code = "precondition_failed"
+	CodeRateLimited        Code = "rate_limited"
+	CodeUnavailable        Code = "unavailable"
+	CodeInternal           Code = "internal"
+)
+
+// Error is a domain error with a stable code and the HTTP status it is reported with
+type Error struct {
+	Code    Code
+	Status  int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is reports whether target is an *Error with the same code, so errors differing only in message match
+func (e *Error) Is(target error) bool {
+	var t *Error
+	return errors.As(target, &t) && t.Code == e.Code
+}
+
+// WithMessage returns a copy of the error with a more specific message
+func (e *Error) WithMessage(format string, args ...any) *Error {
+	return &Error{Code: e.Code, Status: e.Status, Message: fmt.Sprintf(format, args...)}
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[Code]int{}
+)
+
+// New defines an error kind, the status is remembered to rebuild errors of the code received from another service
+func New(code Code, status int, message string) *Error {
+	registryMu.Lock()
+	registry[code] = status
+	registryMu.Unlock()
+	return &Error{Code: code, Status: status, Message: message}
+}
+
+// Domain errors
+var (
+	ErrAccountNotFound      = New(CodeAccountNotFound, http.StatusNotFound, "account not found")
+	ErrAccountAlreadyExists = New(CodeAccountAlreadyExists, http.StatusBadRequest, "account already exists")
+	ErrAccountFrozen        = New(CodeAccountFrozen, http.StatusConflict, "account is frozen")
+	ErrInsufficientFunds    = New(CodeInsufficientFunds, http.StatusBadRequest, "insufficient balance")
+	ErrSameAccount          = New(CodeSameAccount, http.StatusBadRequest, "cannot transfer to same account")
+	ErrTransferIDConflict   = New(CodeTransferIDConflict, http.StatusConflict, "transfer ID already used for a different transfer")
+	ErrVersionMismatch      = New(CodeVersionMismatch, http.StatusPreconditionFailed, "account version mismatch")
+	ErrLockTimeout          = New(CodeLockTimeout, http.StatusConflict, "failed to acquire lock - timeout after 5 seconds")
+	ErrTransactionNotFound  = New(CodeTransactionNotFound, http.StatusNotFound, "transaction not found")
+	ErrWebhookNotFound      = New(CodeWebhookNotFound, http.StatusNotFound, "webhook endpoint not found")
+	ErrDeliveryNotFound     = New(CodeDeliveryNotFound, http.StatusNotFound, "webhook delivery not found")
+	ErrInvalidCursor        = New(CodeInvalidCursor, http.StatusBadRequest, "invalid cursor")
+	ErrCursorExpired        = New(CodeCursorExpired, http.StatusGone, "events after the cursor were deleted by the retention period")
+	ErrImportNotFound       = New(CodeImportNotFound, http.StatusNotFound, "account import not found")
+	ErrImportNotResumable   = New(CodeImportNotResumable, http.StatusConflict, "only failed account imports can be resumed")
+	ErrPaymentFileNotFound  = New(CodePaymentFileNotFound, http.StatusNotFound, "payment file not found")
+	ErrPaymentFileRunning   = New(CodePaymentFileRunning, http.StatusConflict, "payment file is still being processed")
+	ErrCurrencyMismatch     = New(CodeCurrencyMismatch, http.StatusBadRequest, "currencies do not match")
+	ErrInsufficientScope    = New(CodeInsufficientScope, http.StatusForbidden, "the credentials do not grant the required scope")
+	ErrAddressNotAllowed    = New(CodeAddressNotAllowed, http.StatusForbidden, "the API key is not allowed from this address")
+	ErrAPIKeyNotFound       = New(CodeAPIKeyNotFound, http.StatusNotFound, "API key not found")
+	ErrAPIKeyRevoked        = New(CodeAPIKeyRevoked, http.StatusConflict, "API key has been revoked")
+	ErrAccountAccessDenied  = New(CodeAccountAccessDenied, http.StatusForbidden, "the caller holds no role on the account permitting the action")
+
+	ErrInvalidArgument = New(CodeInvalidArgument, http.StatusBadRequest, "invalid argument")
+	ErrUnauthenticated = New(CodeUnauthenticated, http.StatusUnauthorized, "authentication required")
+	ErrInternal        = New(CodeInternal, http.StatusInternalServerError, "internal error")
+)
+
+// Invalid returns an invalid argument error with the given message
+func Invalid(format string, args ...any) *Error {
+	return ErrInvalidArgument.WithMessage(format, args...)
+}
+
+// Money converts an error of types.Money arithmetic or parsing, amounts of different currencies are a currency
+// mismatch and any other error an invalid argument
+func Money(err error) *Error {
+	if errors.Is(err, types.ErrCurrencyMismatch) {
+		return ErrCurrencyMismatch.WithMessage("%v", err)
+	}
+	return Invalid("%v", err)
+}
+
+// As returns the first *Error in the chain of err
+func As(err error) (*Error, bool) {
+	var e *Error
+	if errors.As(err, &e) {
+		return e, true
+	}
+	return nil, false
+}
+
+// FromCode rebuilds an error received from another service. The status of a known code wins over status,
+// which is used for codes this build does not know.
+func FromCode(code Code, status int, message string) *Error {
+	registryMu.RLock()
+	known, ok := registry[code]
+	registryMu.RUnlock()
+	if ok {
+		status = known
+	}
+	return &Error{Code: code, Status: status, Message: message}
+}
+
+// CodeForStatus returns the generic code of an HTTP status
+func CodeForStatus(status int) Code {
+	switch status {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return CodeInvalidArgument
+	case http.StatusUnauthorized:
+		return CodeUnauthenticated
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusPreconditionFailed:
+		return CodePreconditionFailed
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	default:
+		if status >= 400 && status < 500 {
+			return CodeInvalidArgument
+		}
+		return CodeInternal
+	}
+}
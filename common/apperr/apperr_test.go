@@ -0,0 +1,57 @@
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestUnitError(t *testing.T) {
+	t.Run("Matches on code", func(t *testing.T) {
+		err := fmt.Errorf("failed to transfer funds: %w", ErrAccountNotFound.WithMessage("source account not found"))
+		assert.ErrorIs(t, err, ErrAccountNotFound)
+		assert.NotErrorIs(t, err, ErrTransactionNotFound)
+		assert.Equal(t, "account not found", ErrAccountNotFound.Message)
+
+		e, ok := As(err)
+		require.True(t, ok)
+		assert.Equal(t, CodeAccountNotFound, e.Code)
+		assert.Equal(t, http.StatusNotFound, e.Status)
+
+		_, ok = As(errors.New("connection refused"))
+		assert.False(t, ok)
+	})
+
+	t.Run("From code", func(t *testing.T) {
+		e := FromCode(CodeInsufficientFunds, http.StatusTeapot, "insufficient balance in source account 1")
+		assert.ErrorIs(t, e, ErrInsufficientFunds)
+		assert.Equal(t, http.StatusBadRequest, e.Status)
+
+		e = FromCode("quota_exceeded", http.StatusTooManyRequests, "too many accounts")
+		assert.Equal(t, Code("quota_exceeded"), e.Code)
+		assert.Equal(t, http.StatusTooManyRequests, e.Status)
+	})
+
+	t.Run("gRPC round trip", func(t *testing.T) {
+		st, ok := GRPCStatus(fmt.Errorf("transfer: %w", ErrVersionMismatch))
+		require.True(t, ok)
+		assert.Equal(t, codes.Aborted, st.Code())
+
+		e, ok := FromGRPC(st.Err())
+		require.True(t, ok)
+		assert.ErrorIs(t, e, ErrVersionMismatch)
+		assert.Equal(t, "account version mismatch", e.Message)
+
+		st, ok = GRPCStatus(ErrWebhookNotFound)
+		require.True(t, ok)
+		assert.Equal(t, codes.NotFound, st.Code())
+
+		_, ok = GRPCStatus(errors.New("connection refused"))
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,92 @@
+package apperr
+
+import (
+	"net/http"
+	"strconv"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Domain of the ErrorInfo detail attached to gRPC statuses
+const errorInfoDomain = "ledger"
+
+// grpcCodes maps codes whose gRPC code does not follow from their HTTP status
+var grpcCodes = map[Code]codes.Code{
+	CodeAccountAlreadyExists: codes.AlreadyExists,
+	CodeTransferIDConflict:   codes.AlreadyExists,
+	CodeInsufficientFunds:    codes.FailedPrecondition,
+	CodeAccountFrozen:        codes.FailedPrecondition,
+	CodeVersionMismatch:      codes.Aborted,
+	CodeLockTimeout:          codes.Aborted,
+	CodeImportNotResumable:   codes.FailedPrecondition,
+	CodePaymentFileRunning:   codes.FailedPrecondition,
+	CodeCurrencyMismatch:     codes.FailedPrecondition,
+	CodeAPIKeyRevoked:        codes.FailedPrecondition,
+}
+
+// GRPCStatus converts an *Error in the chain of err to a gRPC status carrying the code as ErrorInfo reason.
+// ok is false when err holds no *Error.
+func GRPCStatus(err error) (st *status.Status, ok bool) {
+	e, ok := As(err)
+	if !ok {
+		return nil, false
+	}
+
+	code, mapped := grpcCodes[e.Code]
+	if !mapped {
+		code = grpcCodeForStatus(e.Status)
+	}
+
+	st = status.New(code, e.Message)
+	detailed, detailErr := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   string(e.Code),
+		Domain:   errorInfoDomain,
+		Metadata: map[string]string{"status": strconv.Itoa(e.Status)},
+	})
+	if detailErr != nil {
+		return st, true
+	}
+	return detailed, true
+}
+
+// FromGRPC rebuilds the *Error carried by a gRPC status error, ok is false for statuses without ErrorInfo
+func FromGRPC(err error) (*Error, bool) {
+	st, ok := status.FromError(err)
+	if !ok {
+		return nil, false
+	}
+	for _, detail := range st.Details() {
+		info, isInfo := detail.(*errdetails.ErrorInfo)
+		if !isInfo || info.GetDomain() != errorInfoDomain {
+			continue
+		}
+		httpStatus, _ := strconv.Atoi(info.GetMetadata()["status"])
+		return FromCode(Code(info.GetReason()), httpStatus, st.Message()), true
+	}
+	return nil, false
+}
+
+func grpcCodeForStatus(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.Aborted
+	case http.StatusGone, http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	default:
+		return codes.Internal
+	}
+}
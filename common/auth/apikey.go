@@ -0,0 +1,181 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/models"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	// APIKeyPrefix starts every API key, telling keys apart from JWTs and making leaked keys easy to scan for
+	APIKeyPrefix = "ldg_"
+
+	// apiKeyIDLength is the number of random hex characters following APIKeyPrefix, the two form the stored
+	// prefix a key is looked up by
+	apiKeyIDLength = 12
+
+	apiKeySecretLength = 32
+
+	// lastUsedResolution limits how often a key in constant use records its last use
+	lastUsedResolution = time.Minute
+)
+
+var errInvalidAPIKey = apperr.ErrUnauthenticated.WithMessage("invalid API key")
+
+// APIKeyStore verifies the API keys stored in the api_keys table. The service managing the keys and the services
+// accepting them share the table.
+type APIKeyStore struct {
+	db  *gorm.DB
+	now func() time.Time
+}
+
+// NewAPIKeyStore creates an APIKeyStore reading the api_keys table of db
+func NewAPIKeyStore(db *gorm.DB) *APIKeyStore {
+	return &APIKeyStore{db: db, now: time.Now}
+}
+
+// Migrate creates or updates the api_keys table
+func (s *APIKeyStore) Migrate() error {
+	return s.db.AutoMigrate(&models.APIKey{})
+}
+
+// GenerateAPIKey returns a new random API key, the prefix identifying it and the hash to store
+func GenerateAPIKey() (key string, prefix string, hash string, err error) {
+	id := make([]byte, apiKeyIDLength/2)
+	secret := make([]byte, apiKeySecretLength)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", "", err
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", "", err
+	}
+
+	prefix = APIKeyPrefix + hex.EncodeToString(id)
+	key = prefix + "_" + base64.RawURLEncoding.EncodeToString(secret)
+	return key, prefix, HashAPIKey(key), nil
+}
+
+// HashAPIKey returns the stored hash of key. Keys are random 256 bit secrets, a fast hash is enough to keep
+// them from being recovered from the table.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// ParseAllowedIPs parses an IP allowlist of addresses and CIDR ranges, returning the normalized entries to store
+func ParseAllowedIPs(entries []string) ([]string, error) {
+	normalized := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		prefix, err := parseAllowedIP(entry)
+		if err != nil {
+			return nil, err
+		}
+		if prefix.IsSingleIP() {
+			normalized = append(normalized, prefix.Addr().String())
+		} else {
+			normalized = append(normalized, prefix.String())
+		}
+	}
+	return normalized, nil
+}
+
+func parseAllowedIP(entry string) (netip.Prefix, error) {
+	entry = strings.TrimSpace(entry)
+	if strings.Contains(entry, "/") {
+		prefix, err := netip.ParsePrefix(entry)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid CIDR range %q", entry)
+		}
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(entry)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid IP address %q", entry)
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// Verify returns the identity of a caller presenting key from clientIP. An unknown client address is the zero
+// netip.Addr, which keys with an IP allowlist reject.
+func (s *APIKeyStore) Verify(ctx context.Context, key string, clientIP netip.Addr) (*Identity, error) {
+	prefixLength := len(APIKeyPrefix) + apiKeyIDLength
+	if !strings.HasPrefix(key, APIKeyPrefix) || len(key) <= prefixLength+1 || key[prefixLength] != '_' {
+		return nil, errInvalidAPIKey
+	}
+
+	var apiKey models.APIKey
+	if err := s.db.WithContext(ctx).First(&apiKey, "prefix = ?", key[:prefixLength]).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errInvalidAPIKey
+		}
+		return nil, fmt.Errorf("failed to find API key: %w", err)
+	}
+	if subtle.ConstantTimeCompare([]byte(HashAPIKey(key)), []byte(apiKey.Hash)) != 1 {
+		return nil, errInvalidAPIKey
+	}
+
+	now := s.now()
+	if apiKey.RevokedAt != nil {
+		return nil, apperr.ErrUnauthenticated.WithMessage("the API key has been revoked")
+	}
+	if apiKey.ExpiresAt != nil && !now.Before(*apiKey.ExpiresAt) {
+		return nil, apperr.ErrUnauthenticated.WithMessage("the API key has expired")
+	}
+	if !addressAllowed(apiKey.AllowedIPs, clientIP) {
+		return nil, apperr.ErrAddressNotAllowed
+	}
+
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= lastUsedResolution {
+		//a failure to record the use must not fail the request
+		err := s.db.WithContext(ctx).Model(&models.APIKey{}).
+			Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", apiKey.ID, now.Add(-lastUsedResolution)).
+			UpdateColumn("last_used_at", now).Error
+		if err != nil {
+			logrus.WithError(err).WithField("api_key_id", apiKey.ID).Warn("failed to record API key use")
+		}
+	}
+
+	return &Identity{
+		Subject: APIKeySubject(apiKey.ID),
+		Scheme:  SchemeAPIKey,
+		Scopes:  apiKey.Scopes,
+	}, nil
+}
+
+// APIKeySubject is the subject callers presenting the API key act as, it is kept when the key is rotated
+func APIKeySubject(id types.APIKeyID) string {
+	return "api-key:" + strconv.FormatUint(uint64(id), 10)
+}
+
+// addressAllowed reports whether clientIP matches the allowlist, an empty allowlist accepts any address
+func addressAllowed(allowlist []string, clientIP netip.Addr) bool {
+	if len(allowlist) == 0 {
+		return true
+	}
+	if !clientIP.IsValid() {
+		return false
+	}
+	clientIP = clientIP.Unmap()
+	for _, entry := range allowlist {
+		if prefix, err := parseAllowedIP(entry); err == nil && prefix.Contains(clientIP) {
+			return true
+		}
+	}
+	return false
+}
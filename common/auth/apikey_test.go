@@ -0,0 +1,271 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/db"
+	"github.com/danielkhtse/supreme-adventure/common/requestmeta"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var apiKeyColumns = []string{"id", "name", "prefix", "hash", "scopes", "allowed_ips", "expires_at", "last_used_at", "revoked_at"}
+
+// apiKeyRow is a stored API key, with nil times left NULL
+type apiKeyRow struct {
+	allowedIPs string
+	expiresAt  *time.Time
+	lastUsedAt *time.Time
+	revokedAt  *time.Time
+}
+
+func newTestAPIKeyStore(t *testing.T, now time.Time) (*APIKeyStore, sqlmock.Sqlmock) {
+	mockDB, err := db.NewMockDB()
+	require.NoError(t, err)
+	store := NewAPIKeyStore(mockDB.GetDB())
+	store.now = func() time.Time { return now }
+	return store, mockDB.Mock
+}
+
+func expectAPIKey(mock sqlmock.Sqlmock, prefix string, hash string, row apiKeyRow) {
+	if row.allowedIPs == "" {
+		row.allowedIPs = "[]"
+	}
+	mock.ExpectQuery(`SELECT \* FROM "api_keys" WHERE prefix = \$1`).
+		WithArgs(prefix, 1).
+		WillReturnRows(sqlmock.NewRows(apiKeyColumns).AddRow(
+			42, "partner", prefix, hash, `["transactions:write"]`, row.allowedIPs, row.expiresAt, row.lastUsedAt, row.revokedAt,
+		))
+}
+
+func TestUnitGenerateAPIKey(t *testing.T) {
+	key, prefix, hash, err := GenerateAPIKey()
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(key, prefix+"_"))
+	assert.Regexp(t, `^ldg_[0-9a-f]{12}$`, prefix)
+	assert.Len(t, key, len(prefix)+1+43)
+	assert.Equal(t, HashAPIKey(key), hash)
+	assert.NotContains(t, hash, key)
+
+	other, _, _, err := GenerateAPIKey()
+	require.NoError(t, err)
+	assert.NotEqual(t, key, other)
+}
+
+func TestUnitParseAllowedIPs(t *testing.T) {
+	allowed, err := ParseAllowedIPs([]string{"203.0.113.7", " 10.1.2.3/8", "2001:db8::/32", "::ffff:192.0.2.1"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"203.0.113.7", "10.0.0.0/8", "2001:db8::/32", "192.0.2.1"}, allowed)
+
+	_, err = ParseAllowedIPs([]string{"example.com"})
+	assert.EqualError(t, err, `invalid IP address "example.com"`)
+
+	_, err = ParseAllowedIPs([]string{"10.0.0.0/33"})
+	assert.EqualError(t, err, `invalid CIDR range "10.0.0.0/33"`)
+}
+
+func TestUnitAPIKeyStoreVerify(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	key, prefix, hash, err := GenerateAPIKey()
+	require.NoError(t, err)
+	client := netip.MustParseAddr("203.0.113.7")
+
+	t.Run("Valid key", func(t *testing.T) {
+		store, mock := newTestAPIKeyStore(t, now)
+		expectAPIKey(mock, prefix, hash, apiKeyRow{})
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "api_keys" SET "last_used_at"=\$1 WHERE id = \$2 AND \(last_used_at IS NULL OR last_used_at < \$3\)`).
+			WithArgs(now, 42, now.Add(-lastUsedResolution)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		identity, err := store.Verify(context.Background(), key, client)
+		require.NoError(t, err)
+		assert.Equal(t, &Identity{Subject: "api-key:42", Scheme: SchemeAPIKey, Scopes: []string{ScopeTransactionsWrite}}, identity)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Recently used key", func(t *testing.T) {
+		store, mock := newTestAPIKeyStore(t, now)
+		lastUsed := now.Add(-10 * time.Second)
+		expectAPIKey(mock, prefix, hash, apiKeyRow{lastUsedAt: &lastUsed})
+
+		_, err := store.Verify(context.Background(), key, client)
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failure to record use", func(t *testing.T) {
+		store, mock := newTestAPIKeyStore(t, now)
+		expectAPIKey(mock, prefix, hash, apiKeyRow{})
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "api_keys"`).WillReturnError(assert.AnError)
+		mock.ExpectRollback()
+
+		_, err := store.Verify(context.Background(), key, client)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Malformed key", func(t *testing.T) {
+		store, mock := newTestAPIKeyStore(t, now)
+		for _, malformed := range []string{"ldg_", "ldg_short_secret", prefix, prefix + "_", "other_" + key} {
+			_, err := store.Verify(context.Background(), malformed, client)
+			assert.ErrorIs(t, err, apperr.ErrUnauthenticated, malformed)
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Unknown key", func(t *testing.T) {
+		store, mock := newTestAPIKeyStore(t, now)
+		mock.ExpectQuery(`SELECT \* FROM "api_keys"`).WillReturnRows(sqlmock.NewRows(apiKeyColumns))
+
+		_, err := store.Verify(context.Background(), key, client)
+		assert.ErrorIs(t, err, apperr.ErrUnauthenticated)
+	})
+
+	t.Run("Wrong secret", func(t *testing.T) {
+		store, mock := newTestAPIKeyStore(t, now)
+		expectAPIKey(mock, prefix, hash, apiKeyRow{})
+
+		_, err := store.Verify(context.Background(), prefix+"_"+strings.Repeat("A", 43), client)
+		assert.EqualError(t, err, "invalid API key")
+	})
+
+	t.Run("Revoked key", func(t *testing.T) {
+		store, mock := newTestAPIKeyStore(t, now)
+		revoked := now.Add(-time.Hour)
+		expectAPIKey(mock, prefix, hash, apiKeyRow{revokedAt: &revoked})
+
+		_, err := store.Verify(context.Background(), key, client)
+		assert.EqualError(t, err, "the API key has been revoked")
+		assert.ErrorIs(t, err, apperr.ErrUnauthenticated)
+	})
+
+	t.Run("Expired key", func(t *testing.T) {
+		store, mock := newTestAPIKeyStore(t, now)
+		expired := now
+		expectAPIKey(mock, prefix, hash, apiKeyRow{expiresAt: &expired})
+
+		_, err := store.Verify(context.Background(), key, client)
+		assert.EqualError(t, err, "the API key has expired")
+	})
+
+	t.Run("IP allowlist", func(t *testing.T) {
+		allowlist := `["198.51.100.0/24","203.0.113.7"]`
+
+		store, mock := newTestAPIKeyStore(t, now)
+		expectAPIKey(mock, prefix, hash, apiKeyRow{allowedIPs: allowlist})
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "api_keys"`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		_, err := store.Verify(context.Background(), key, netip.MustParseAddr("198.51.100.20"))
+		assert.NoError(t, err)
+
+		for _, address := range []netip.Addr{netip.MustParseAddr("203.0.113.8"), {}} {
+			store, mock := newTestAPIKeyStore(t, now)
+			expectAPIKey(mock, prefix, hash, apiKeyRow{allowedIPs: allowlist})
+			_, err := store.Verify(context.Background(), key, address)
+			assert.ErrorIs(t, err, apperr.ErrAddressNotAllowed, address.String())
+		}
+	})
+}
+
+func TestUnitClientIP(t *testing.T) {
+	authenticator := (&Authenticator{}).WithAPIKeys(nil, []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")})
+
+	tests := []struct {
+		name         string
+		forwardedFor string
+		want         string
+	}{
+		{"Direct", "203.0.113.7", "203.0.113.7"},
+		{"Untrusted peer", "198.51.100.1, 203.0.113.7", "203.0.113.7"},
+		{"Trusted proxy", "198.51.100.1, 203.0.113.7, 10.0.0.2", "203.0.113.7"},
+		{"Trusted proxies only", "10.0.0.3, 10.0.0.2", "10.0.0.3"},
+		{"IPv4 mapped", "::ffff:203.0.113.7", "203.0.113.7"},
+		{"Malformed", "unknown, 10.0.0.2", "invalid IP"},
+		{"Empty", "", "invalid IP"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, authenticator.clientIP(tt.forwardedFor).String())
+		})
+	}
+}
+
+func TestUnitRequireAPIKey(t *testing.T) {
+	now := time.Now()
+	key, prefix, hash, err := GenerateAPIKey()
+	require.NoError(t, err)
+
+	keys := newTestKeys(t)
+	keySet, err := ParseKeySet(keys.jwks)
+	require.NoError(t, err)
+
+	serve := func(t *testing.T, authenticator *Authenticator, scope string, remoteAddr string, forwardedFor string) (*httptest.ResponseRecorder, *Identity) {
+		var served *Identity
+		handler := requestmeta.Middleware(authenticator.Require(scope, func(w http.ResponseWriter, r *http.Request) {
+			served, _ = FromContext(r.Context())
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		r := httptest.NewRequest(http.MethodPost, "/transactions", nil)
+		r.Header.Set(requestmeta.AuthorizationHeader, "Bearer "+key)
+		r.RemoteAddr = remoteAddr
+		if forwardedFor != "" {
+			r.Header.Set(requestmeta.ForwardedForHeader, forwardedFor)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w, served
+	}
+
+	t.Run("Authorized behind trusted proxy", func(t *testing.T) {
+		store, mock := newTestAPIKeyStore(t, now)
+		expectAPIKey(mock, prefix, hash, apiKeyRow{allowedIPs: `["203.0.113.7"]`, lastUsedAt: &now})
+		authenticator := NewAuthenticator(keySet, testIssuer, testAudience).
+			WithAPIKeys(store, []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")})
+
+		w, served := serve(t, authenticator, ScopeTransactionsWrite, "10.0.0.2:40000", "203.0.113.7")
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		require.NotNil(t, served)
+		assert.Equal(t, "api-key:42", served.Subject)
+		assert.Equal(t, SchemeAPIKey, served.Scheme)
+	})
+
+	t.Run("Forwarded address from untrusted peer", func(t *testing.T) {
+		store, mock := newTestAPIKeyStore(t, now)
+		expectAPIKey(mock, prefix, hash, apiKeyRow{allowedIPs: `["203.0.113.7"]`, lastUsedAt: &now})
+		authenticator := NewAuthenticator(keySet, testIssuer, testAudience).WithAPIKeys(store, nil)
+
+		w, served := serve(t, authenticator, ScopeTransactionsWrite, "10.0.0.2:40000", "203.0.113.7")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"address_not_allowed"`)
+		assert.Nil(t, served)
+	})
+
+	t.Run("Missing scope", func(t *testing.T) {
+		store, mock := newTestAPIKeyStore(t, now)
+		expectAPIKey(mock, prefix, hash, apiKeyRow{lastUsedAt: &now})
+		authenticator := NewAuthenticator(keySet, testIssuer, testAudience).WithAPIKeys(store, nil)
+
+		w, _ := serve(t, authenticator, ScopeAccountsWrite, "203.0.113.7:40000", "")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"insufficient_scope"`)
+	})
+
+	t.Run("API keys not accepted", func(t *testing.T) {
+		w, _ := serve(t, NewAuthenticator(keySet, testIssuer, testAudience), ScopeTransactionsWrite, "203.0.113.7:40000", "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, `Bearer realm="ledger", error="invalid_token"`, w.Header().Get("WWW-Authenticate"))
+	})
+}
@@ -0,0 +1,87 @@
+// Package auth authenticates callers with JWT bearer tokens verified against a JWKS or with API keys, checks
+// the scopes they were granted and carries their identity through the context for auditing.
+package auth
+
+import (
+	"context"
+	"slices"
+
+	"github.com/danielkhtse/supreme-adventure/common/requestmeta"
+	"github.com/sirupsen/logrus"
+)
+
+// Scopes granted to callers, each route requires one of them
+const (
+	ScopeAccountsRead      = "accounts:read"
+	ScopeAccountsWrite     = "accounts:write"
+	ScopeTransactionsRead  = "transactions:read"
+	ScopeTransactionsWrite = "transactions:write"
+	ScopeWebhooksRead      = "webhooks:read"
+	ScopeWebhooksWrite     = "webhooks:write"
+	ScopeEventsRead        = "events:read"
+	ScopeAPIKeysRead       = "api-keys:read"
+	ScopeAPIKeysWrite      = "api-keys:write"
+)
+
+// Scopes lists every scope, in the order of the constants
+var Scopes = []string{
+	ScopeAccountsRead, ScopeAccountsWrite,
+	ScopeTransactionsRead, ScopeTransactionsWrite,
+	ScopeWebhooksRead, ScopeWebhooksWrite,
+	ScopeEventsRead,
+	ScopeAPIKeysRead, ScopeAPIKeysWrite,
+}
+
+// Authentication schemes an identity was established with
+const (
+	SchemeJWT    = "jwt"
+	SchemeAPIKey = "api_key"
+)
+
+// Identity is the authenticated caller of a request
+type Identity struct {
+	// Subject of the token, or the API key the caller presented, the principal the caller acts as
+	Subject string
+
+	// Scheme the caller authenticated with
+	Scheme string
+
+	// Scopes granted to the caller
+	Scopes []string
+}
+
+// HasScope reports whether the identity was granted scope
+func (i *Identity) HasScope(scope string) bool {
+	return slices.Contains(i.Scopes, scope)
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the identity
+func NewContext(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, contextKey{}, identity)
+}
+
+// FromContext returns the identity carried by ctx, ok is false for requests served without authentication
+func FromContext(ctx context.Context) (identity *Identity, ok bool) {
+	identity, ok = ctx.Value(contextKey{}).(*Identity)
+	return identity, ok
+}
+
+// Audit returns a log entry naming the caller and request of ctx, for the service layer to record the changes
+// it makes on behalf of the caller
+func Audit(ctx context.Context) *logrus.Entry {
+	fields := logrus.Fields{
+		"audit":      true,
+		"request_id": requestmeta.FromContext(ctx).RequestID,
+		"subject":    "anonymous",
+	}
+	if identity, ok := FromContext(ctx); ok {
+		fields["subject"] = identity.Subject
+		fields["auth_scheme"] = identity.Scheme
+	}
+	if service, ok := ServiceFromContext(ctx); ok {
+		fields["service"] = service
+	}
+	return logrus.WithFields(fields)
+}
@@ -0,0 +1,357 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/requestmeta"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	testIssuer   = "https://issuer.example.com"
+	testAudience = "ledger"
+)
+
+// testKeys are signing keys minted for the tests, with their JWKS
+type testKeys struct {
+	rsa     *rsa.PrivateKey
+	ec      *ecdsa.PrivateKey
+	ed25519 ed25519.PrivateKey
+	jwks    []byte
+}
+
+func newTestKeys(t *testing.T) *testKeys {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	encode := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+	jwks, err := json.Marshal(map[string]any{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa-1", "use": "sig", "alg": "RS256", "n": encode(rsaKey.N.Bytes()), "e": encode(big.NewInt(int64(rsaKey.E)).Bytes())},
+		{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": encode(ecKey.X.FillBytes(make([]byte, 32))), "y": encode(ecKey.Y.FillBytes(make([]byte, 32)))},
+		{"kty": "OKP", "kid": "ed-1", "crv": "Ed25519", "x": encode(edKey.Public().(ed25519.PublicKey))},
+		{"kty": "RSA", "kid": "enc-1", "use": "enc", "n": "AQAB", "e": "AQAB"},
+	}})
+	require.NoError(t, err)
+
+	return &testKeys{rsa: rsaKey, ec: ecKey, ed25519: edKey, jwks: jwks}
+}
+
+// validClaims are the claims of a token accepted by the test authenticator
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":   "user-1",
+		"iss":   testIssuer,
+		"aud":   testAudience,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"scope": ScopeAccountsRead + " " + ScopeTransactionsWrite,
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key any, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return "Bearer " + signed
+}
+
+func TestUnitAuthenticate(t *testing.T) {
+	keys := newTestKeys(t)
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, keys.jwks, 0o600))
+
+	keySet, err := LoadKeySet(context.Background(), path)
+	require.NoError(t, err)
+	authenticator := NewAuthenticator(keySet, testIssuer, testAudience)
+
+	t.Run("Valid tokens", func(t *testing.T) {
+		for name, token := range map[string]string{
+			"RSA":     sign(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, validClaims()),
+			"EC":      sign(t, jwt.SigningMethodES256, "ec-1", keys.ec, validClaims()),
+			"Ed25519": sign(t, jwt.SigningMethodEdDSA, "ed-1", keys.ed25519, validClaims()),
+		} {
+			identity, err := authenticator.Authenticate(context.Background(), token, netip.Addr{})
+			require.NoError(t, err, name)
+			assert.Equal(t, "user-1", identity.Subject, name)
+			assert.Equal(t, SchemeJWT, identity.Scheme, name)
+			assert.True(t, identity.HasScope(ScopeAccountsRead), name)
+			assert.True(t, identity.HasScope(ScopeTransactionsWrite), name)
+			assert.False(t, identity.HasScope(ScopeAccountsWrite), name)
+		}
+	})
+
+	t.Run("Scopes as scp", func(t *testing.T) {
+		claims := validClaims()
+		delete(claims, "scope")
+		claims["scp"] = []string{ScopeWebhooksRead, ScopeEventsRead}
+		identity, err := authenticator.Authenticate(context.Background(), sign(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, claims), netip.Addr{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{ScopeWebhooksRead, ScopeEventsRead}, identity.Scopes)
+
+		claims["scp"] = ScopeWebhooksWrite + " " + ScopeEventsRead
+		identity, err = authenticator.Authenticate(context.Background(), sign(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, claims), netip.Addr{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{ScopeWebhooksWrite, ScopeEventsRead}, identity.Scopes)
+	})
+
+	t.Run("Rejected tokens", func(t *testing.T) {
+		otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+
+		expired := validClaims()
+		expired["exp"] = time.Now().Add(-time.Hour).Unix()
+		withoutExpiry := validClaims()
+		delete(withoutExpiry, "exp")
+		otherIssuer := validClaims()
+		otherIssuer["iss"] = "https://other.example.com"
+		otherAudience := validClaims()
+		otherAudience["aud"] = "other"
+		withoutSubject := validClaims()
+		delete(withoutSubject, "sub")
+		notYetValid := validClaims()
+		notYetValid["nbf"] = time.Now().Add(time.Hour).Unix()
+
+		for name, token := range map[string]string{
+			"Missing":           "",
+			"Basic":             "Basic dXNlcjpwYXNz",
+			"Malformed":         "Bearer not-a-token",
+			"Expired":           sign(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, expired),
+			"Without expiry":    sign(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, withoutExpiry),
+			"Not yet valid":     sign(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, notYetValid),
+			"Other issuer":      sign(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, otherIssuer),
+			"Other audience":    sign(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, otherAudience),
+			"Without subject":   sign(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, withoutSubject),
+			"Unknown key":       sign(t, jwt.SigningMethodRS256, "rsa-2", keys.rsa, validClaims()),
+			"Encryption key":    sign(t, jwt.SigningMethodRS256, "enc-1", keys.rsa, validClaims()),
+			"Other signer":      sign(t, jwt.SigningMethodRS256, "rsa-1", otherKey, validClaims()),
+			"Restricted alg":    sign(t, jwt.SigningMethodPS256, "rsa-1", keys.rsa, validClaims()),
+			"Alg of other type": sign(t, jwt.SigningMethodES256, "rsa-1", keys.ec, validClaims()),
+			"HMAC":              sign(t, jwt.SigningMethodHS256, "rsa-1", keys.jwks, validClaims()),
+			"None":              sign(t, jwt.SigningMethodNone, "rsa-1", jwt.UnsafeAllowNoneSignatureType, validClaims()),
+			"Without key ID":    sign(t, jwt.SigningMethodRS256, "", keys.rsa, validClaims()),
+		} {
+			_, err := authenticator.Authenticate(context.Background(), token, netip.Addr{})
+			assert.ErrorIs(t, err, apperr.ErrUnauthenticated, name)
+		}
+	})
+
+	t.Run("Single key without key ID", func(t *testing.T) {
+		encode := func(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+		jwks, err := json.Marshal(map[string]any{"keys": []map[string]string{
+			{"kty": "EC", "crv": "P-256", "x": encode(keys.ec.X.FillBytes(make([]byte, 32))), "y": encode(keys.ec.Y.FillBytes(make([]byte, 32)))},
+		}})
+		require.NoError(t, err)
+		keySet, err := ParseKeySet(jwks)
+		require.NoError(t, err)
+
+		_, err = NewAuthenticator(keySet, "", "").Authenticate(context.Background(), sign(t, jwt.SigningMethodES256, "", keys.ec, validClaims()), netip.Addr{})
+		assert.NoError(t, err)
+	})
+}
+
+func TestUnitParseKeySet(t *testing.T) {
+	for name, jwks := range map[string]string{
+		"Not JSON":        `keys`,
+		"Empty":           `{"keys": []}`,
+		"Only encryption": `{"keys": [{"kty": "RSA", "use": "enc", "n": "AQAB", "e": "AQAB"}]}`,
+		"Short RSA key":   `{"keys": [{"kty": "RSA", "n": "AQAB", "e": "AQAB"}]}`,
+		"Unknown curve":   `{"keys": [{"kty": "EC", "crv": "P-192", "x": "AQAB", "y": "AQAB"}]}`,
+		"Not on curve":    `{"keys": [{"kty": "EC", "crv": "P-256", "x": "AQAB", "y": "AQAB"}]}`,
+		"Bad Ed25519":     `{"keys": [{"kty": "OKP", "crv": "Ed25519", "x": "AQAB"}]}`,
+	} {
+		_, err := ParseKeySet([]byte(jwks))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestUnitLoadKeySetFromURL(t *testing.T) {
+	keys := newTestKeys(t)
+	rotated := newTestKeys(t)
+
+	var jwks atomic.Value
+	jwks.Store(keys.jwks)
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		w.Write(jwks.Load().([]byte))
+	}))
+	defer server.Close()
+
+	keySet, err := LoadKeySet(context.Background(), server.URL)
+	require.NoError(t, err)
+	authenticator := NewAuthenticator(keySet, testIssuer, testAudience)
+
+	_, err = authenticator.Authenticate(context.Background(), sign(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, validClaims()), netip.Addr{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), fetches.Load())
+
+	//keys are fetched again at most once per refresh interval for tokens naming an unknown key
+	_, err = authenticator.Authenticate(context.Background(), sign(t, jwt.SigningMethodRS256, "rsa-2", keys.rsa, validClaims()), netip.Addr{})
+	assert.ErrorIs(t, err, apperr.ErrUnauthenticated)
+	assert.Equal(t, int32(1), fetches.Load())
+
+	setFetched := func(age time.Duration) {
+		keySet.mu.Lock()
+		keySet.fetched = time.Now().Add(-age)
+		keySet.mu.Unlock()
+	}
+	setFetched(2 * minRefreshInterval)
+	_, err = authenticator.Authenticate(context.Background(), sign(t, jwt.SigningMethodRS256, "rsa-2", keys.rsa, validClaims()), netip.Addr{})
+	assert.ErrorIs(t, err, apperr.ErrUnauthenticated)
+	assert.Equal(t, int32(2), fetches.Load())
+
+	//rotated keys are picked up once the key set is too old
+	jwks.Store(rotated.jwks)
+	_, err = authenticator.Authenticate(context.Background(), sign(t, jwt.SigningMethodRS256, "rsa-1", rotated.rsa, validClaims()), netip.Addr{})
+	assert.ErrorIs(t, err, apperr.ErrUnauthenticated)
+
+	setFetched(2 * keySetMaxAge)
+	_, err = authenticator.Authenticate(context.Background(), sign(t, jwt.SigningMethodRS256, "rsa-1", rotated.rsa, validClaims()), netip.Addr{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), fetches.Load())
+
+	//the current keys are kept when the key set cannot be fetched
+	server.Config.Handler = http.NotFoundHandler()
+	setFetched(2 * keySetMaxAge)
+	_, err = authenticator.Authenticate(context.Background(), sign(t, jwt.SigningMethodRS256, "rsa-1", rotated.rsa, validClaims()), netip.Addr{})
+	require.NoError(t, err)
+
+	_, err = LoadKeySet(context.Background(), server.URL)
+	assert.Error(t, err)
+}
+
+func TestUnitRequire(t *testing.T) {
+	keys := newTestKeys(t)
+	keySet, err := ParseKeySet(keys.jwks)
+	require.NoError(t, err)
+	authenticator := NewAuthenticator(keySet, testIssuer, testAudience)
+
+	var served *Identity
+	handler := requestmeta.Middleware(authenticator.Require(ScopeAccountsRead, func(w http.ResponseWriter, r *http.Request) {
+		served, _ = FromContext(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	serve := func(authorization string) *httptest.ResponseRecorder {
+		served = nil
+		r := httptest.NewRequest(http.MethodGet, "/accounts/1", nil)
+		if authorization != "" {
+			r.Header.Set(requestmeta.AuthorizationHeader, authorization)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("Authorized", func(t *testing.T) {
+		w := serve(sign(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, validClaims()))
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		require.NotNil(t, served)
+		assert.Equal(t, "user-1", served.Subject)
+	})
+
+	t.Run("Missing token", func(t *testing.T) {
+		w := serve("")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, `Bearer realm="ledger"`, w.Header().Get("WWW-Authenticate"))
+		assert.Contains(t, w.Body.String(), `"code":"unauthenticated"`)
+		assert.Nil(t, served)
+	})
+
+	t.Run("Invalid token", func(t *testing.T) {
+		w := serve("Bearer not-a-token")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, `Bearer realm="ledger", error="invalid_token"`, w.Header().Get("WWW-Authenticate"))
+		assert.Nil(t, served)
+	})
+
+	t.Run("Missing scope", func(t *testing.T) {
+		claims := validClaims()
+		claims["scope"] = ScopeTransactionsRead
+		w := serve(sign(t, jwt.SigningMethodRS256, "rsa-1", keys.rsa, claims))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Equal(t, `Bearer realm="ledger", error="insufficient_scope", scope="accounts:read"`, w.Header().Get("WWW-Authenticate"))
+		assert.Contains(t, w.Body.String(), `"code":"insufficient_scope"`)
+		assert.Nil(t, served)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		var disabled *Authenticator
+		w := httptest.NewRecorder()
+		disabled.Require(ScopeAccountsRead, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/accounts/1", nil))
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+}
+
+func TestUnitUnaryServerInterceptor(t *testing.T) {
+	keys := newTestKeys(t)
+	keySet, err := ParseKeySet(keys.jwks)
+	require.NoError(t, err)
+	authenticator := NewAuthenticator(keySet, testIssuer, testAudience)
+
+	interceptor := authenticator.UnaryServerInterceptor(map[string]string{
+		"/ledger.v1.AccountService/GetAccount": ScopeAccountsRead,
+		"/ledger.v1.AccountService/Transfer":   ScopeAccountsWrite,
+	})
+	call := func(method string, authorization string) (*Identity, error) {
+		ctx := requestmeta.NewContext(context.Background(), requestmeta.Metadata{Authorization: authorization})
+		var served *Identity
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req any) (any, error) {
+			served, _ = FromContext(ctx)
+			return nil, nil
+		})
+		return served, err
+	}
+	token := sign(t, jwt.SigningMethodES256, "ec-1", keys.ec, validClaims())
+
+	identity, err := call("/ledger.v1.AccountService/GetAccount", token)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", identity.Subject)
+
+	_, err = call("/ledger.v1.AccountService/GetAccount", "")
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = call("/ledger.v1.AccountService/Transfer", token)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	identity, err = call("/grpc.health.v1.Health/Check", "")
+	assert.NoError(t, err)
+	assert.Nil(t, identity)
+
+	identity, err = call("/grpc.reflection.v1.ServerReflection/ServerReflectionInfo", "")
+	assert.NoError(t, err)
+	assert.Nil(t, identity)
+
+	_, err = call("/ledger.v1.AccountService/DeleteAccount", token)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
@@ -0,0 +1,211 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// JWKSEnv is the JWKS verifying bearer tokens, an http(s) URL or the path of a local file
+	JWKSEnv = "AUTH_JWKS"
+
+	// IssuerEnv is the issuer tokens must be issued by, any issuer is accepted when unset
+	IssuerEnv = "AUTH_ISSUER"
+
+	// AudienceEnv is the audience tokens must be issued for, any audience is accepted when unset
+	AudienceEnv = "AUTH_AUDIENCE"
+
+	// DisabledEnv set to true serves every request without authentication, for local development only
+	DisabledEnv = "AUTH_DISABLED"
+
+	// TrustedProxiesEnv lists the comma separated addresses and CIDR ranges of the proxies and services whose
+	// X-Forwarded-For entries are trusted to name the client address API key allowlists are checked against
+	TrustedProxiesEnv = "AUTH_TRUSTED_PROXIES"
+
+	// leeway tolerates clock skew between the token issuer and the services
+	leeway = 30 * time.Second
+)
+
+// errMissingToken is returned to callers sending no bearer token, who are only challenged to send one
+var errMissingToken = apperr.ErrUnauthenticated.WithMessage("a bearer token is required")
+
+// signingMethods are the asymmetric algorithms accepted, keys of a JWKS cannot verify HMAC signatures
+var signingMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// Authenticator verifies bearer tokens and API keys and checks the scopes they grant. A nil *Authenticator serves
+// every request without authentication.
+type Authenticator struct {
+	keys   *KeySet
+	parser *jwt.Parser
+
+	// apiKeys verifies API keys, they are rejected when nil
+	apiKeys *APIKeyStore
+
+	trustedProxies []netip.Prefix
+}
+
+// NewAuthenticator creates an Authenticator verifying tokens with keys, empty issuer and audience are not checked
+func NewAuthenticator(keys *KeySet, issuer string, audience string) *Authenticator {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(leeway),
+	}
+	if issuer != "" {
+		options = append(options, jwt.WithIssuer(issuer))
+	}
+	if audience != "" {
+		options = append(options, jwt.WithAudience(audience))
+	}
+	return &Authenticator{keys: keys, parser: jwt.NewParser(options...)}
+}
+
+// WithAPIKeys returns a copy of the Authenticator also accepting the API keys of store, from clients behind the
+// trusted proxies
+func (a *Authenticator) WithAPIKeys(store *APIKeyStore, trustedProxies []netip.Prefix) *Authenticator {
+	if a == nil {
+		return nil
+	}
+	copied := *a
+	copied.apiKeys = store
+	copied.trustedProxies = trustedProxies
+	return &copied
+}
+
+// FromEnv creates the Authenticator configured by AUTH_JWKS, AUTH_ISSUER, AUTH_AUDIENCE and AUTH_TRUSTED_PROXIES,
+// accepting the API keys of apiKeys. It returns nil when AUTH_DISABLED is true, and an error when no JWKS is
+// configured so a service never starts unprotected by accident.
+func FromEnv(ctx context.Context, apiKeys *APIKeyStore) (*Authenticator, error) {
+	if os.Getenv(DisabledEnv) == "true" {
+		return nil, nil
+	}
+
+	source := os.Getenv(JWKSEnv)
+	if source == "" {
+		return nil, fmt.Errorf("%s environment variable not set, set %s=true to serve requests without authentication", JWKSEnv, DisabledEnv)
+	}
+	keys, err := LoadKeySet(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+
+	var trustedProxies []netip.Prefix
+	for _, entry := range strings.Split(os.Getenv(TrustedProxiesEnv), ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		prefix, err := parseAllowedIP(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", TrustedProxiesEnv, err)
+		}
+		trustedProxies = append(trustedProxies, prefix)
+	}
+
+	return NewAuthenticator(keys, os.Getenv(IssuerEnv), os.Getenv(AudienceEnv)).WithAPIKeys(apiKeys, trustedProxies), nil
+}
+
+// claims of the tokens, scopes are granted as a space separated scope claim (RFC 8693) or as a scp list
+type claims struct {
+	jwt.RegisteredClaims
+	Scope string   `json:"scope"`
+	Scp   scpClaim `json:"scp"`
+}
+
+// scpClaim accepts scp as a list or as a space separated string, issuers differ
+type scpClaim []string
+
+func (s *scpClaim) UnmarshalJSON(data []byte) error {
+	var list []string
+	if err := json.Unmarshal(data, &list); err == nil {
+		*s = list
+		return nil
+	}
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*s = strings.Fields(value)
+	return nil
+}
+
+// Authenticate verifies the value of an Authorization header, a JWT or an API key sent from clientIP, and returns
+// the identity of the caller
+func (a *Authenticator) Authenticate(ctx context.Context, authorization string, clientIP netip.Addr) (*Identity, error) {
+	scheme, token, _ := strings.Cut(authorization, " ")
+	token = strings.TrimSpace(token)
+	if !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return nil, errMissingToken
+	}
+
+	if strings.HasPrefix(token, APIKeyPrefix) {
+		if a.apiKeys == nil {
+			return nil, errInvalidAPIKey
+		}
+		return a.apiKeys.Verify(ctx, token, clientIP)
+	}
+
+	var c claims
+	if _, err := a.parser.ParseWithClaims(token, &c, a.keys.Keyfunc); err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, apperr.ErrUnauthenticated.WithMessage("the bearer token has expired")
+		}
+		return nil, apperr.ErrUnauthenticated.WithMessage("invalid bearer token")
+	}
+	if c.Subject == "" {
+		return nil, apperr.ErrUnauthenticated.WithMessage("the bearer token has no subject")
+	}
+
+	return &Identity{
+		Subject: c.Subject,
+		Scheme:  SchemeJWT,
+		Scopes:  append(strings.Fields(c.Scope), c.Scp...),
+	}, nil
+}
+
+// authorize authenticates the caller of a request with the forwarding chain of requestmeta.Metadata and checks it
+// was granted scope
+func (a *Authenticator) authorize(ctx context.Context, authorization string, forwardedFor string, scope string) (*Identity, error) {
+	identity, err := a.Authenticate(ctx, authorization, a.clientIP(forwardedFor))
+	if err != nil {
+		return nil, err
+	}
+	if !identity.HasScope(scope) {
+		return nil, apperr.ErrInsufficientScope.WithMessage("the %s scope is required", scope)
+	}
+	return identity, nil
+}
+
+// clientIP returns the client address of a forwarding chain, the last address not added by a trusted proxy. It
+// returns the zero netip.Addr when that entry is not an address.
+func (a *Authenticator) clientIP(forwardedFor string) netip.Addr {
+	hops := strings.Split(forwardedFor, ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			return netip.Addr{}
+		}
+		addr = addr.Unmap()
+		if i == 0 || !a.trustedProxy(addr) {
+			return addr
+		}
+	}
+	return netip.Addr{}
+}
+
+func (a *Authenticator) trustedProxy(addr netip.Addr) bool {
+	for _, prefix := range a.trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
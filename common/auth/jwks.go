@@ -0,0 +1,295 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// keySetMaxAge is how long keys fetched from a URL are used before being fetched again
+	keySetMaxAge = time.Hour
+
+	// minRefreshInterval limits how often tokens naming an unknown key can trigger a fetch
+	minRefreshInterval = time.Minute
+
+	maxKeySetSize = 1 << 20
+)
+
+// jwk is a JSON Web Key, RFC 7517
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+
+	// EC and OKP
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type publicKey struct {
+	key crypto.PublicKey
+
+	// Algorithm the key is restricted to, empty when the JWK names none
+	alg string
+}
+
+// KeySet holds the public signing keys of a JWKS by key ID. A key set loaded from a URL is fetched again once an
+// hour, and when a token names a key it does not hold so that keys can be rotated without a restart.
+type KeySet struct {
+	url    string
+	client *http.Client
+
+	mu      sync.RWMutex
+	keys    map[string]publicKey
+	fetched time.Time
+}
+
+// ParseKeySet parses a JWKS document, RFC 7517. RSA, EC and Ed25519 signing keys are kept, other keys are skipped.
+func ParseKeySet(data []byte) (*KeySet, error) {
+	keys, err := parseKeys(data)
+	if err != nil {
+		return nil, err
+	}
+	return &KeySet{keys: keys}, nil
+}
+
+// LoadKeySet reads the JWKS at source, an http(s) URL or the path of a local file
+func LoadKeySet(ctx context.Context, source string) (*KeySet, error) {
+	if !strings.HasPrefix(source, "https://") && !strings.HasPrefix(source, "http://") {
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWKS: %w", err)
+		}
+		return ParseKeySet(data)
+	}
+
+	ks := &KeySet{url: source, client: &http.Client{Timeout: 10 * time.Second}}
+	if err := ks.fetch(ctx); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+// Keyfunc returns the key verifying token, it is a jwt.Keyfunc
+func (ks *KeySet) Keyfunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	if ks.url != "" {
+		ks.mu.RLock()
+		_, known := ks.keys[kid]
+		age := time.Since(ks.fetched)
+		ks.mu.RUnlock()
+
+		if age > keySetMaxAge || (!known && age > minRefreshInterval) {
+			//keep serving the current keys when the JWKS cannot be fetched
+			if err := ks.fetch(context.Background()); err != nil {
+				logrus.WithError(err).Warn("failed to refresh JWKS")
+			}
+		}
+	}
+
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	key, ok := ks.keys[kid]
+	if !ok && kid == "" && len(ks.keys) == 1 {
+		//a token without key ID is accepted when there is a single key to verify it with
+		for _, only := range ks.keys {
+			key, ok = only, true
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	alg := token.Method.Alg()
+	if key.alg != "" && key.alg != alg {
+		return nil, fmt.Errorf("signing key %q is restricted to %s, token is signed with %s", kid, key.alg, alg)
+	}
+	if !algorithmMatchesKey(alg, key.key) {
+		return nil, fmt.Errorf("signing key %q cannot verify %s", kid, alg)
+	}
+	return key.key, nil
+}
+
+func (ks *KeySet) fetch(ctx context.Context) error {
+	//claim the refresh first so concurrent requests keep using the current keys
+	ks.mu.Lock()
+	if !ks.fetched.IsZero() && time.Since(ks.fetched) < minRefreshInterval {
+		ks.mu.Unlock()
+		return nil
+	}
+	ks.fetched = time.Now()
+	ks.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ks.url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create JWKS request: %w", err)
+	}
+	resp, err := ks.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch JWKS: status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxKeySetSize))
+	if err != nil {
+		return fmt.Errorf("failed to read JWKS: %w", err)
+	}
+	keys, err := parseKeys(data)
+	if err != nil {
+		return err
+	}
+
+	ks.mu.Lock()
+	ks.keys = keys
+	ks.mu.Unlock()
+	return nil
+}
+
+func parseKeys(data []byte) (map[string]publicKey, error) {
+	var document struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", err)
+	}
+
+	keys := make(map[string]publicKey, len(document.Keys))
+	for _, k := range document.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWKS key %q: %w", k.Kid, err)
+		}
+		if key == nil {
+			continue
+		}
+		if _, duplicate := keys[k.Kid]; duplicate {
+			return nil, fmt.Errorf("invalid JWKS: duplicate key ID %q", k.Kid)
+		}
+		keys[k.Kid] = publicKey{key: key, alg: k.Alg}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS holds no signing key")
+	}
+	return keys, nil
+}
+
+// publicKey decodes the key, nil for key types which cannot verify signatures
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeInt(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("modulus: %w", err)
+		}
+		e, err := decodeInt(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("exponent: %w", err)
+		}
+		if !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("exponent out of range")
+		}
+		if n.BitLen() < 2048 {
+			return nil, fmt.Errorf("RSA keys must have at least 2048 bits, got %d", n.BitLen())
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeInt(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("x: %w", err)
+		}
+		y, err := decodeInt(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("y: %w", err)
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 public key")
+		}
+		return ed25519.PublicKey(x), nil
+
+	default:
+		return nil, nil
+	}
+}
+
+func decodeInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(data) == 0 {
+		return nil, errors.New("not a base64url encoded integer")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+// algorithmMatchesKey reports whether alg is a signature algorithm of the key type, so a token cannot pick an
+// algorithm the key was not meant for
+func algorithmMatchesKey(alg string, key crypto.PublicKey) bool {
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		return strings.HasPrefix(alg, "RS") || strings.HasPrefix(alg, "PS")
+	case *ecdsa.PublicKey:
+		switch key.Curve {
+		case elliptic.P256():
+			return alg == "ES256"
+		case elliptic.P384():
+			return alg == "ES384"
+		case elliptic.P521():
+			return alg == "ES512"
+		}
+	case ed25519.PublicKey:
+		return alg == "EdDSA"
+	}
+	return false
+}
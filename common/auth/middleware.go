@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/requestmeta"
+	"github.com/danielkhtse/supreme-adventure/common/response"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+)
+
+// realm of the WWW-Authenticate challenges
+const realm = "ledger"
+
+// publicServices are the gRPC services served without authentication, health checks and reflection. Every other
+// method must be listed with its scope.
+var publicServices = map[string]bool{
+	"grpc.health.v1.Health":                    true,
+	"grpc.reflection.v1.ServerReflection":      true,
+	"grpc.reflection.v1alpha.ServerReflection": true,
+}
+
+var errUnknownMethod = apperr.New(apperr.CodeForbidden, http.StatusForbidden, "the method is not served to callers")
+
+// Require returns a handler serving next only to callers with a valid bearer token or API key granting scope, the
+// identity of the caller is stored in the request context. Other callers get 401, or 403 when the scope is missing
+// or the API key is not allowed from their address.
+func (a *Authenticator) Require(scope string, next http.HandlerFunc) http.Handler {
+	if a == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwardedFor := requestmeta.ForwardedFor(r.Header.Values(requestmeta.ForwardedForHeader), r.RemoteAddr)
+		identity, err := a.authorize(r.Context(), r.Header.Get(requestmeta.AuthorizationHeader), forwardedFor, scope)
+		if err != nil {
+			logDenied(r.Context(), r.Method+" "+r.URL.Path, err)
+			w.Header().Set("WWW-Authenticate", challenge(err, scope))
+			response.SendProblem(w, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), identity)))
+	})
+}
+
+// UnaryServerInterceptor authenticates gRPC calls to the methods of scopes, keyed by full method name, and checks
+// the caller was granted the scope of the method. Health checks and reflection are served without authentication,
+// calls to methods missing from scopes are denied so a new method is never public by accident. It relies on
+// requestmeta.UnaryServerInterceptor running first.
+func (a *Authenticator) UnaryServerInterceptor(scopes map[string]string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := a.authorizeCall(ctx, info.FullMethod, scopes)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is UnaryServerInterceptor for streams, it relies on requestmeta.StreamServerInterceptor
+// running first
+func (a *Authenticator) StreamServerInterceptor(scopes map[string]string) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authorizeCall(ss.Context(), info.FullMethod, scopes)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func (a *Authenticator) authorizeCall(ctx context.Context, method string, scopes map[string]string) (context.Context, error) {
+	if a == nil || publicServices[serviceName(method)] {
+		return ctx, nil
+	}
+	scope, ok := scopes[method]
+	if !ok {
+		logDenied(ctx, method, errUnknownMethod)
+		st, _ := apperr.GRPCStatus(errUnknownMethod)
+		return nil, st.Err()
+	}
+
+	md := requestmeta.FromContext(ctx)
+	identity, err := a.authorize(ctx, md.Authorization, md.ForwardedFor, scope)
+	if err != nil {
+		logDenied(ctx, method, err)
+		st, _ := apperr.GRPCStatus(err)
+		return nil, st.Err()
+	}
+	return NewContext(ctx, identity), nil
+}
+
+// serviceName returns the service of a full method name, "/package.Service/Method"
+func serviceName(method string) string {
+	service, _, _ := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+	return service
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// challenge returns the WWW-Authenticate header of a rejected request, RFC 6750
+func challenge(err error, scope string) string {
+	switch {
+	case errors.Is(err, apperr.ErrInsufficientScope):
+		return fmt.Sprintf(`Bearer realm=%q, error="insufficient_scope", scope=%q`, realm, scope)
+	case err == errMissingToken:
+		return fmt.Sprintf(`Bearer realm=%q`, realm)
+	case errors.Is(err, apperr.ErrUnauthenticated):
+		return fmt.Sprintf(`Bearer realm=%q, error="invalid_token"`, realm)
+	default:
+		return fmt.Sprintf(`Bearer realm=%q`, realm)
+	}
+}
+
+func logDenied(ctx context.Context, operation string, err error) {
+	logrus.WithFields(logrus.Fields{
+		"request_id": requestmeta.FromContext(ctx).RequestID,
+		"operation":  operation,
+	}).WithError(err).Warn("request denied")
+}
@@ -0,0 +1,204 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/response"
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// ServiceTokenHeader carries the token of the service calling an internal endpoint, next to the forwarded
+	// credentials of the caller the service acts for
+	ServiceTokenHeader = "X-Service-Token"
+
+	// serviceTokenKey is the gRPC metadata key of the service token, keys are lower case
+	serviceTokenKey = "x-service-token"
+
+	// ServiceSecretsEnv lists the comma separated secrets shared by the services. The first signs service tokens
+	// and every one verifies them, so a secret is rotated by prepending the new one and removing the old one once
+	// every service signs with the new one.
+	ServiceSecretsEnv = "AUTH_SERVICE_SECRETS"
+
+	// serviceAudience is the audience of service tokens, bearer tokens issued for callers never carry it
+	serviceAudience = "ledger-internal"
+
+	// serviceTokenTTL bounds how long an intercepted service token can be replayed
+	serviceTokenTTL = time.Minute
+
+	minServiceSecretLength = 32
+)
+
+var errServiceToken = apperr.ErrUnauthenticated.WithMessage("a valid service token is required")
+
+// ServiceAuth signs the tokens a service presents to the internal endpoints of other services, and verifies the
+// tokens presented to its own. Tokens are short-lived HS256 JWTs signed with a secret the services share, naming
+// the calling service as subject. A nil *ServiceAuth neither sends nor requires service tokens.
+type ServiceAuth struct {
+	name    string
+	secrets [][]byte
+	parser  *jwt.Parser
+	now     func() time.Time
+}
+
+// NewServiceAuth creates a ServiceAuth signing as the service name with the first of secrets and verifying with
+// any of them
+func NewServiceAuth(name string, secrets []string) (*ServiceAuth, error) {
+	if len(secrets) == 0 {
+		return nil, errors.New("at least one service secret is required")
+	}
+	keys := make([][]byte, 0, len(secrets))
+	for _, secret := range secrets {
+		if len(secret) < minServiceSecretLength {
+			return nil, fmt.Errorf("service secrets must be at least %d characters", minServiceSecretLength)
+		}
+		keys = append(keys, []byte(secret))
+	}
+
+	s := &ServiceAuth{name: name, secrets: keys, now: time.Now}
+	s.parser = jwt.NewParser(
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithAudience(serviceAudience),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(leeway),
+		jwt.WithTimeFunc(func() time.Time { return s.now() }),
+	)
+	return s, nil
+}
+
+// ServiceAuthFromEnv creates the ServiceAuth of the service name configured by AUTH_SERVICE_SECRETS. It returns
+// nil when no secret is set and AUTH_DISABLED is true, and an error when no secret is set otherwise so internal
+// endpoints are never served unprotected by accident.
+func ServiceAuthFromEnv(name string) (*ServiceAuth, error) {
+	var secrets []string
+	for _, secret := range strings.Split(os.Getenv(ServiceSecretsEnv), ",") {
+		if secret = strings.TrimSpace(secret); secret != "" {
+			secrets = append(secrets, secret)
+		}
+	}
+	if len(secrets) == 0 {
+		if os.Getenv(DisabledEnv) == "true" {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%s environment variable not set, set %s=true to serve internal endpoints without service tokens", ServiceSecretsEnv, DisabledEnv)
+	}
+
+	serviceAuth, err := NewServiceAuth(name, secrets)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ServiceSecretsEnv, err)
+	}
+	return serviceAuth, nil
+}
+
+// Token returns a new service token naming the service
+func (s *ServiceAuth) Token() (string, error) {
+	now := s.now()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		Subject:   s.name,
+		Audience:  jwt.ClaimStrings{serviceAudience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(serviceTokenTTL)),
+	})
+	return token.SignedString(s.secrets[0])
+}
+
+// Verify returns the name of the service which signed token
+func (s *ServiceAuth) Verify(token string) (string, error) {
+	for _, secret := range s.secrets {
+		var c jwt.RegisteredClaims
+		_, err := s.parser.ParseWithClaims(token, &c, func(*jwt.Token) (any, error) { return secret, nil })
+		if err == nil && c.Subject != "" {
+			return c.Subject, nil
+		}
+	}
+	return "", errServiceToken
+}
+
+// SetHeader adds a service token to the headers of an outgoing request, nothing is added by a nil ServiceAuth
+func (s *ServiceAuth) SetHeader(header http.Header) error {
+	if s == nil {
+		return nil
+	}
+	token, err := s.Token()
+	if err != nil {
+		return fmt.Errorf("failed to sign service token: %w", err)
+	}
+	header.Set(ServiceTokenHeader, token)
+	return nil
+}
+
+// OutgoingContext returns a copy of ctx sending a service token with outgoing gRPC calls, ctx is returned
+// unchanged by a nil ServiceAuth
+func (s *ServiceAuth) OutgoingContext(ctx context.Context) (context.Context, error) {
+	if s == nil {
+		return ctx, nil
+	}
+	token, err := s.Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign service token: %w", err)
+	}
+	return metadata.AppendToOutgoingContext(ctx, serviceTokenKey, token), nil
+}
+
+// Require returns a handler serving next only to requests carrying a valid service token, others get 401. The
+// credentials of the caller the service acts for are checked separately, by Authenticator.Require.
+func (s *ServiceAuth) Require(next http.Handler) http.Handler {
+	if s == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		service, err := s.Verify(r.Header.Get(ServiceTokenHeader))
+		if err != nil {
+			logDenied(r.Context(), r.Method+" "+r.URL.Path, err)
+			response.SendProblem(w, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(NewServiceContext(r.Context(), service)))
+	})
+}
+
+// UnaryServerInterceptor requires a valid service token on calls to the internal methods, keyed by full method
+// name. Calls to other methods are served without one.
+func (s *ServiceAuth) UnaryServerInterceptor(methods map[string]bool) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if s == nil || !methods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		var token string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(serviceTokenKey); len(values) > 0 {
+				token = values[0]
+			}
+		}
+		service, err := s.Verify(token)
+		if err != nil {
+			logDenied(ctx, info.FullMethod, err)
+			st, _ := apperr.GRPCStatus(err)
+			return nil, st.Err()
+		}
+		return handler(NewServiceContext(ctx, service), req)
+	}
+}
+
+type serviceContextKey struct{}
+
+// NewServiceContext returns a copy of ctx carrying the name of the service calling on behalf of the caller
+func NewServiceContext(ctx context.Context, service string) context.Context {
+	return context.WithValue(ctx, serviceContextKey{}, service)
+}
+
+// ServiceFromContext returns the name of the service carried by ctx, ok is false for requests not made by a service
+func ServiceFromContext(ctx context.Context) (service string, ok bool) {
+	service, ok = ctx.Value(serviceContextKey{}).(string)
+	return service, ok
+}
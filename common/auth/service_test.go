@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	testServiceSecret    = strings.Repeat("s", 32)
+	testOldServiceSecret = strings.Repeat("o", 32)
+)
+
+func TestUnitServiceAuth(t *testing.T) {
+	signer, err := NewServiceAuth("transaction-service", []string{testServiceSecret})
+	require.NoError(t, err)
+	verifier, err := NewServiceAuth("account-service", []string{testServiceSecret, testOldServiceSecret})
+	require.NoError(t, err)
+
+	t.Run("Valid token", func(t *testing.T) {
+		token, err := signer.Token()
+		require.NoError(t, err)
+		service, err := verifier.Verify(token)
+		require.NoError(t, err)
+		assert.Equal(t, "transaction-service", service)
+	})
+
+	t.Run("Token signed with a previous secret", func(t *testing.T) {
+		old, err := NewServiceAuth("transaction-service", []string{testOldServiceSecret})
+		require.NoError(t, err)
+		token, err := old.Token()
+		require.NoError(t, err)
+		_, err = verifier.Verify(token)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Token signed with an unknown secret", func(t *testing.T) {
+		other, err := NewServiceAuth("transaction-service", []string{strings.Repeat("x", 32)})
+		require.NoError(t, err)
+		token, err := other.Token()
+		require.NoError(t, err)
+		_, err = verifier.Verify(token)
+		assert.ErrorIs(t, err, errServiceToken)
+	})
+
+	t.Run("Expired token", func(t *testing.T) {
+		expired, err := NewServiceAuth("transaction-service", []string{testServiceSecret})
+		require.NoError(t, err)
+		expired.now = func() time.Time { return time.Now().Add(-time.Hour) }
+		token, err := expired.Token()
+		require.NoError(t, err)
+		_, err = verifier.Verify(token)
+		assert.ErrorIs(t, err, errServiceToken)
+	})
+
+	t.Run("Missing token", func(t *testing.T) {
+		_, err := verifier.Verify("")
+		assert.ErrorIs(t, err, errServiceToken)
+	})
+
+	t.Run("Short secret", func(t *testing.T) {
+		_, err := NewServiceAuth("transaction-service", []string{"secret"})
+		assert.Error(t, err)
+		_, err = NewServiceAuth("transaction-service", nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestUnitServiceAuthRequire(t *testing.T) {
+	serviceAuth, err := NewServiceAuth("transaction-service", []string{testServiceSecret})
+	require.NoError(t, err)
+
+	var served string
+	handler := serviceAuth.Require(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served, _ = ServiceFromContext(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	serve := func(header http.Header) *httptest.ResponseRecorder {
+		served = ""
+		r := httptest.NewRequest(http.MethodPut, "/internal/accounts/1/balance/transfer", nil)
+		for key, values := range header {
+			r.Header[key] = values
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("Authorized", func(t *testing.T) {
+		header := http.Header{}
+		require.NoError(t, serviceAuth.SetHeader(header))
+		w := serve(header)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "transaction-service", served)
+	})
+
+	t.Run("Missing token", func(t *testing.T) {
+		w := serve(nil)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"unauthenticated"`)
+		assert.Empty(t, served)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		var disabled *ServiceAuth
+		header := http.Header{}
+		require.NoError(t, disabled.SetHeader(header))
+		assert.Empty(t, header)
+
+		w := httptest.NewRecorder()
+		disabled.Require(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})).ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/internal/accounts/1/balance/transfer", nil))
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+}
+
+func TestUnitServiceAuthUnaryServerInterceptor(t *testing.T) {
+	serviceAuth, err := NewServiceAuth("transaction-service", []string{testServiceSecret})
+	require.NoError(t, err)
+
+	interceptor := serviceAuth.UnaryServerInterceptor(map[string]bool{"/ledger.v1.AccountService/TransferFunds": true})
+	call := func(method string, ctx context.Context) (string, error) {
+		// the metadata sent by the client is received as incoming metadata
+		outgoing, _ := metadata.FromOutgoingContext(ctx)
+		ctx = metadata.NewIncomingContext(context.Background(), outgoing)
+		var served string
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req any) (any, error) {
+			served, _ = ServiceFromContext(ctx)
+			return nil, nil
+		})
+		return served, err
+	}
+	ctx, err := serviceAuth.OutgoingContext(context.Background())
+	require.NoError(t, err)
+
+	service, err := call("/ledger.v1.AccountService/TransferFunds", ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "transaction-service", service)
+
+	_, err = call("/ledger.v1.AccountService/TransferFunds", context.Background())
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	service, err = call("/ledger.v1.AccountService/GetAccount", context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, service)
+}
+
+func TestUnitServiceAuthFromEnv(t *testing.T) {
+	t.Setenv(ServiceSecretsEnv, "")
+	t.Setenv(DisabledEnv, "")
+	_, err := ServiceAuthFromEnv("transaction-service")
+	assert.Error(t, err)
+
+	t.Setenv(DisabledEnv, "true")
+	serviceAuth, err := ServiceAuthFromEnv("transaction-service")
+	require.NoError(t, err)
+	assert.Nil(t, serviceAuth)
+
+	t.Setenv(ServiceSecretsEnv, testServiceSecret+", "+testOldServiceSecret)
+	serviceAuth, err = ServiceAuthFromEnv("transaction-service")
+	require.NoError(t, err)
+	require.NotNil(t, serviceAuth)
+	assert.Len(t, serviceAuth.secrets, 2)
+
+	t.Setenv(ServiceSecretsEnv, "short")
+	_, err = ServiceAuthFromEnv("transaction-service")
+	assert.Error(t, err)
+}
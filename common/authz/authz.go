@@ -0,0 +1,116 @@
+// Package authz decides which accounts an authenticated caller may act on. Principals are mapped to accounts
+// through roles, a Policy answers whether the role a principal holds permits an action on an account.
+package authz
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/auth"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"github.com/sirupsen/logrus"
+)
+
+// Action is something a caller does with an account
+type Action string
+
+const (
+	// ActionView reads the account and its balance
+	ActionView Action = "view"
+
+	// ActionDebit moves money out of the account
+	ActionDebit Action = "debit"
+
+	// ActionManage grants and revokes the roles of other principals on the account
+	ActionManage Action = "manage"
+)
+
+// permissions are the actions each role permits
+var permissions = map[types.AccountRole][]Action{
+	types.AccountRoleOwner:    {ActionView, ActionDebit, ActionManage},
+	types.AccountRoleOperator: {ActionView, ActionDebit},
+	types.AccountRoleAuditor:  {ActionView},
+	types.AccountRoleAdmin:    {ActionView, ActionDebit, ActionManage},
+}
+
+// Permits reports whether role permits action
+func Permits(role types.AccountRole, action Action) bool {
+	return slices.Contains(permissions[role], action)
+}
+
+// ValidRole reports whether role is a known role
+func ValidRole(role types.AccountRole) bool {
+	_, ok := permissions[role]
+	return ok
+}
+
+// AllAccounts stands for every account in checks of actions not scoped to one account, like listings across
+// accounts. Only the roles principals hold on every account permit actions on it.
+const AllAccounts types.AccountID = 0
+
+// Policy authorizes principals to act on accounts
+type Policy interface {
+	// Authorize returns nil when principal may perform action on the account, and an error matching
+	// apperr.ErrAccountAccessDenied when it may not
+	Authorize(ctx context.Context, principal string, action Action, accountID types.AccountID) error
+}
+
+// Check authorizes the caller of ctx to perform action on the account. Requests served without authentication,
+// and services without a policy, are allowed. Denials are logged as audit entries.
+func Check(ctx context.Context, policy Policy, action Action, accountID types.AccountID) error {
+	identity, ok := auth.FromContext(ctx)
+	if policy == nil || !ok {
+		return nil
+	}
+
+	err := policy.Authorize(ctx, identity.Subject, action, accountID)
+	if errors.Is(err, apperr.ErrAccountAccessDenied) {
+		auth.Audit(ctx).WithFields(logrus.Fields{
+			"action":     action,
+			"account_id": accountID,
+		}).Warn("account access denied")
+	}
+	return err
+}
+
+// CheckAll authorizes the caller of ctx to perform action on every account, like Check
+func CheckAll(ctx context.Context, policy Policy, action Action) error {
+	return Check(ctx, policy, action, AllAccounts)
+}
+
+// CheckAny authorizes the caller of ctx to perform action on at least one of the accounts, e.g. to view a transfer
+// from or to an account it may view. The denial of the last account is returned when no account permits action.
+func CheckAny(ctx context.Context, policy Policy, action Action, accountIDs ...types.AccountID) error {
+	if _, ok := auth.FromContext(ctx); policy == nil || !ok {
+		return nil
+	}
+
+	err := denied(action, AllAccounts)
+	for _, accountID := range accountIDs {
+		err = Check(ctx, policy, action, accountID)
+		if !errors.Is(err, apperr.ErrAccountAccessDenied) {
+			return err
+		}
+	}
+	return err
+}
+
+// StreamAuthorizer lets callers stream the events of the accounts Policy permits them to view, it satisfies
+// stream.Authorizer
+type StreamAuthorizer struct {
+	Policy Policy
+}
+
+func (a StreamAuthorizer) AuthorizeAccount(ctx context.Context, accountID types.AccountID) error {
+	return Check(ctx, a.Policy, ActionView, accountID)
+}
+
+// denied returns the error of a principal not permitted to perform action on the account
+func denied(action Action, accountID types.AccountID) error {
+	if accountID == AllAccounts {
+		return apperr.ErrAccountAccessDenied.WithMessage("the caller may not %s every account", action)
+	}
+	return apperr.ErrAccountAccessDenied.WithMessage("the caller may not %s account %d", action, accountID)
+}
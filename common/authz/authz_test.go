@@ -0,0 +1,230 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/auth"
+	"github.com/danielkhtse/supreme-adventure/common/db"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// policyFunc adapts a function to the Policy interface
+type policyFunc func(ctx context.Context, principal string, action Action, accountID types.AccountID) error
+
+func (f policyFunc) Authorize(ctx context.Context, principal string, action Action, accountID types.AccountID) error {
+	return f(ctx, principal, action, accountID)
+}
+
+func TestUnitPermits(t *testing.T) {
+	tests := []struct {
+		role    types.AccountRole
+		allowed []Action
+	}{
+		{types.AccountRoleOwner, []Action{ActionView, ActionDebit, ActionManage}},
+		{types.AccountRoleOperator, []Action{ActionView, ActionDebit}},
+		{types.AccountRoleAuditor, []Action{ActionView}},
+		{types.AccountRoleAdmin, []Action{ActionView, ActionDebit, ActionManage}},
+		{"customer", nil},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.role), func(t *testing.T) {
+			for _, action := range []Action{ActionView, ActionDebit, ActionManage} {
+				assert.Equal(t, slices.Contains(test.allowed, action), Permits(test.role, action), action)
+			}
+		})
+	}
+}
+
+func TestUnitCheck(t *testing.T) {
+	var calls []string
+	policy := policyFunc(func(ctx context.Context, principal string, action Action, accountID types.AccountID) error {
+		calls = append(calls, principal)
+		if principal == "alice" {
+			return nil
+		}
+		return denied(action, accountID)
+	})
+	withSubject := func(subject string) context.Context {
+		return auth.NewContext(context.Background(), &auth.Identity{Subject: subject})
+	}
+
+	t.Run("Allowed", func(t *testing.T) {
+		assert.NoError(t, Check(withSubject("alice"), policy, ActionDebit, 1))
+	})
+
+	t.Run("Denied", func(t *testing.T) {
+		err := Check(withSubject("mallory"), policy, ActionDebit, 1)
+		assert.ErrorIs(t, err, apperr.ErrAccountAccessDenied)
+		assert.EqualError(t, err, "the caller may not debit account 1")
+	})
+
+	t.Run("Without authentication", func(t *testing.T) {
+		calls = nil
+		assert.NoError(t, Check(context.Background(), policy, ActionDebit, 1))
+		assert.Empty(t, calls)
+	})
+
+	t.Run("Without policy", func(t *testing.T) {
+		assert.NoError(t, Check(withSubject("mallory"), nil, ActionDebit, 1))
+	})
+
+	t.Run("Policy failure", func(t *testing.T) {
+		failing := policyFunc(func(context.Context, string, Action, types.AccountID) error {
+			return errors.New("connection refused")
+		})
+		err := Check(withSubject("alice"), failing, ActionView, 1)
+		assert.EqualError(t, err, "connection refused")
+		assert.NotErrorIs(t, err, apperr.ErrAccountAccessDenied)
+	})
+}
+
+func TestUnitCheckAll(t *testing.T) {
+	policy := policyFunc(func(ctx context.Context, principal string, action Action, accountID types.AccountID) error {
+		if principal == "ops" && accountID == AllAccounts {
+			return nil
+		}
+		return denied(action, accountID)
+	})
+
+	assert.NoError(t, CheckAll(auth.NewContext(context.Background(), &auth.Identity{Subject: "ops"}), policy, ActionView))
+
+	err := CheckAll(auth.NewContext(context.Background(), &auth.Identity{Subject: "alice"}), policy, ActionView)
+	assert.ErrorIs(t, err, apperr.ErrAccountAccessDenied)
+	assert.EqualError(t, err, "the caller may not view every account")
+}
+
+func TestUnitCheckAny(t *testing.T) {
+	var checked []types.AccountID
+	policy := policyFunc(func(ctx context.Context, principal string, action Action, accountID types.AccountID) error {
+		checked = append(checked, accountID)
+		if accountID == 2 {
+			return nil
+		}
+		return denied(action, accountID)
+	})
+	ctx := auth.NewContext(context.Background(), &auth.Identity{Subject: "alice"})
+
+	t.Run("One account permits action", func(t *testing.T) {
+		checked = nil
+		assert.NoError(t, CheckAny(ctx, policy, ActionView, 1, 2, 3))
+		assert.Equal(t, []types.AccountID{1, 2}, checked)
+	})
+
+	t.Run("No account permits action", func(t *testing.T) {
+		err := CheckAny(ctx, policy, ActionView, 1, 3)
+		assert.ErrorIs(t, err, apperr.ErrAccountAccessDenied)
+		assert.EqualError(t, err, "the caller may not view account 3")
+	})
+
+	t.Run("No accounts", func(t *testing.T) {
+		assert.ErrorIs(t, CheckAny(ctx, policy, ActionView), apperr.ErrAccountAccessDenied)
+	})
+
+	t.Run("Without authentication", func(t *testing.T) {
+		assert.NoError(t, CheckAny(context.Background(), policy, ActionView, 1))
+	})
+}
+
+func TestUnitStreamAuthorizer(t *testing.T) {
+	var actions []Action
+	authorizer := StreamAuthorizer{Policy: policyFunc(func(ctx context.Context, principal string, action Action, accountID types.AccountID) error {
+		actions = append(actions, action)
+		if principal == "alice" && accountID == 1 {
+			return nil
+		}
+		return denied(action, accountID)
+	})}
+	ctx := auth.NewContext(context.Background(), &auth.Identity{Subject: "alice"})
+
+	assert.NoError(t, authorizer.AuthorizeAccount(ctx, 1))
+	assert.ErrorIs(t, authorizer.AuthorizeAccount(ctx, 2), apperr.ErrAccountAccessDenied)
+	assert.Equal(t, []Action{ActionView, ActionView}, actions)
+}
+
+func TestUnitGrantPolicy(t *testing.T) {
+	mockDB, err := db.NewMockDB()
+	require.NoError(t, err)
+	mock := mockDB.Mock
+	policy := NewGrantPolicy(mockDB.GetDB(), map[string]types.AccountRole{
+		"ops":        types.AccountRoleAdmin,
+		"compliance": types.AccountRoleAuditor,
+	})
+	ctx := context.Background()
+	grantQuery := `SELECT \* FROM "account_grants" WHERE account_id = \$1 AND principal = \$2`
+	grantColumns := []string{"account_id", "principal", "role"}
+
+	t.Run("Global role", func(t *testing.T) {
+		assert.NoError(t, policy.Authorize(ctx, "ops", ActionDebit, 1))
+		assert.NoError(t, policy.Authorize(ctx, "compliance", ActionView, 1))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Global role falls back to grants", func(t *testing.T) {
+		mock.ExpectQuery(grantQuery).WithArgs(1, "compliance", 1).
+			WillReturnRows(sqlmock.NewRows(grantColumns).AddRow(1, "compliance", "operator"))
+
+		assert.NoError(t, policy.Authorize(ctx, "compliance", ActionDebit, 1))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Granted", func(t *testing.T) {
+		mock.ExpectQuery(grantQuery).WithArgs(1, "alice", 1).
+			WillReturnRows(sqlmock.NewRows(grantColumns).AddRow(1, "alice", "owner"))
+
+		assert.NoError(t, policy.Authorize(ctx, "alice", ActionManage, 1))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Role does not permit action", func(t *testing.T) {
+		mock.ExpectQuery(grantQuery).WithArgs(1, "bob", 1).
+			WillReturnRows(sqlmock.NewRows(grantColumns).AddRow(1, "bob", "auditor"))
+
+		assert.ErrorIs(t, policy.Authorize(ctx, "bob", ActionDebit, 1), apperr.ErrAccountAccessDenied)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("No grant", func(t *testing.T) {
+		mock.ExpectQuery(grantQuery).WithArgs(2, "alice", 1).WillReturnRows(sqlmock.NewRows(grantColumns))
+
+		assert.ErrorIs(t, policy.Authorize(ctx, "alice", ActionView, 2), apperr.ErrAccountAccessDenied)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Every account requires a global role", func(t *testing.T) {
+		assert.NoError(t, policy.Authorize(ctx, "compliance", ActionView, AllAccounts))
+		assert.ErrorIs(t, policy.Authorize(ctx, "compliance", ActionManage, AllAccounts), apperr.ErrAccountAccessDenied)
+		assert.ErrorIs(t, policy.Authorize(ctx, "alice", ActionView, AllAccounts), apperr.ErrAccountAccessDenied)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Database error", func(t *testing.T) {
+		mock.ExpectQuery(grantQuery).WillReturnError(errors.New("connection refused"))
+
+		err := policy.Authorize(ctx, "alice", ActionView, 1)
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, apperr.ErrAccountAccessDenied)
+	})
+}
+
+func TestUnitParseRoles(t *testing.T) {
+	roles, err := ParseRoles(" ops = admin, compliance=auditor,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]types.AccountRole{"ops": types.AccountRoleAdmin, "compliance": types.AccountRoleAuditor}, roles)
+
+	roles, err = ParseRoles("")
+	require.NoError(t, err)
+	assert.Empty(t, roles)
+
+	for _, value := range []string{"ops", "=admin", "ops=superuser"} {
+		_, err := ParseRoles(value)
+		assert.Error(t, err, value)
+	}
+}
@@ -0,0 +1,86 @@
+package authz
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/danielkhtse/supreme-adventure/common/models"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"gorm.io/gorm"
+)
+
+// RolesEnv lists the principals holding a role on every account, as comma separated principal=role pairs, e.g.
+// "ops-team=admin,compliance=auditor"
+const RolesEnv = "AUTHZ_ROLES"
+
+// GrantPolicy authorizes principals with the roles granted to them in the account_grants table, which the
+// services share, and with the roles they hold on every account
+type GrantPolicy struct {
+	db *gorm.DB
+
+	// global are the roles held on every account, by principal
+	global map[string]types.AccountRole
+}
+
+// NewGrantPolicy creates a GrantPolicy reading the account_grants table of db, global are the roles principals
+// hold on every account
+func NewGrantPolicy(db *gorm.DB, global map[string]types.AccountRole) *GrantPolicy {
+	return &GrantPolicy{db: db, global: global}
+}
+
+// Migrate creates or updates the account_grants table
+func (p *GrantPolicy) Migrate() error {
+	return p.db.AutoMigrate(&models.AccountGrant{})
+}
+
+// Authorize allows action when the role of principal on every account, or its role on the account, permits it.
+// Actions on AllAccounts are only permitted by the role on every account.
+func (p *GrantPolicy) Authorize(ctx context.Context, principal string, action Action, accountID types.AccountID) error {
+	if role, ok := p.global[principal]; ok && Permits(role, action) {
+		return nil
+	}
+	if accountID == AllAccounts {
+		return denied(action, accountID)
+	}
+
+	var grant models.AccountGrant
+	err := p.db.WithContext(ctx).First(&grant, "account_id = ? AND principal = ?", accountID, principal).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return denied(action, accountID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find account grant: %w", err)
+	}
+
+	if !Permits(grant.Role, action) {
+		return denied(action, accountID)
+	}
+	return nil
+}
+
+// RolesFromEnv parses the roles held on every account from AUTHZ_ROLES
+func RolesFromEnv() (map[string]types.AccountRole, error) {
+	return ParseRoles(os.Getenv(RolesEnv))
+}
+
+// ParseRoles parses comma separated principal=role pairs
+func ParseRoles(value string) (map[string]types.AccountRole, error) {
+	roles := map[string]types.AccountRole{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		principal, role, ok := strings.Cut(entry, "=")
+		principal = strings.TrimSpace(principal)
+		role = strings.TrimSpace(role)
+		if !ok || principal == "" || !ValidRole(types.AccountRole(role)) {
+			return nil, fmt.Errorf("invalid %s entry %q", RolesEnv, entry)
+		}
+		roles[principal] = types.AccountRole(role)
+	}
+	return roles, nil
+}
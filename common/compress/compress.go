@@ -0,0 +1,23 @@
+// Package compress encodes HTTP responses for clients that accept gzip or deflate, except on routes that
+// negotiate Content-Encoding themselves.
+package compress
+
+import (
+	"net/http"
+
+	"github.com/gorilla/handlers"
+)
+
+// Handler compresses the responses of next like handlers.CompressHandler. Requests for which selfEncoded
+// returns true reach next untouched, with their Accept-Encoding header, so the handler can encode the body
+// itself or leave an already compressed body alone.
+func Handler(next http.Handler, selfEncoded func(r *http.Request) bool) http.Handler {
+	compressed := handlers.CompressHandler(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if selfEncoded(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		compressed.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,50 @@
+package compress
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitHandler(t *testing.T) {
+	var acceptEncoding string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		acceptEncoding = r.Header.Get("Accept-Encoding")
+		io.WriteString(w, "payload")
+	})
+	handler := Handler(next, func(r *http.Request) bool {
+		return strings.HasSuffix(r.URL.Path, "/export")
+	})
+
+	t.Run("Compressed", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/transactions", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+		assert.Empty(t, acceptEncoding)
+		reader, err := gzip.NewReader(rec.Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		assert.Equal(t, "payload", string(body))
+	})
+
+	t.Run("Self encoded", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/v1/transactions/export", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, "gzip", acceptEncoding)
+		assert.Equal(t, "payload", rec.Body.String())
+	})
+}
@@ -0,0 +1,91 @@
+package events
+
+import (
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultHistorySize is the number of recent events kept for subscribers resuming after a disconnect
+	DefaultHistorySize = 1024
+)
+
+// Broker fans out published events to in-process subscribers and keeps a short history for resumption
+type Broker struct {
+	mu          sync.RWMutex
+	subscribers map[chan Event]struct{}
+
+	history     []Event
+	historySize int
+}
+
+// NewBroker creates a new Broker instance
+func NewBroker() *Broker {
+	return &Broker{
+		subscribers: make(map[chan Event]struct{}),
+		historySize: DefaultHistorySize,
+	}
+}
+
+// Subscribe returns a channel receiving every published event and a function to unsubscribe
+func (b *Broker) Subscribe(buffer int) (<-chan Event, func()) {
+	ch := make(chan Event, buffer)
+
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, ch)
+			b.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Publish delivers the event to all subscribers.
+// A subscriber whose buffer is full misses the event rather than blocking the publisher.
+func (b *Broker) Publish(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.history = append(b.history, event)
+	if len(b.history) > b.historySize {
+		b.history = b.history[len(b.history)-b.historySize:]
+	}
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			logrus.WithFields(logrus.Fields{
+				"event_id":   event.ID,
+				"event_type": event.Type,
+			}).Warn("subscriber buffer full, dropping event")
+		}
+	}
+}
+
+// Since returns the retained events published after the event with the given ID.
+// complete is false when that event is no longer retained, meaning events may have been missed.
+func (b *Broker) Since(lastID uint64) (events []Event, complete bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for i, event := range b.history {
+		if event.ID == lastID {
+			return append([]Event(nil), b.history[i+1:]...), true
+		}
+	}
+
+	for _, event := range b.history {
+		if event.ID > lastID {
+			events = append(events, event)
+		}
+	}
+	return events, len(b.history) < b.historySize
+}
@@ -0,0 +1,57 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnitBrokerPublish(t *testing.T) {
+	broker := NewBroker()
+	eventCh, unsubscribe := broker.Subscribe(1)
+
+	broker.Publish(Event{ID: 1})
+	broker.Publish(Event{ID: 2}) // buffer full, dropped for this subscriber
+
+	assert.Equal(t, uint64(1), (<-eventCh).ID)
+	assert.Len(t, eventCh, 0)
+
+	unsubscribe()
+	unsubscribe()
+	_, ok := <-eventCh
+	assert.False(t, ok)
+}
+
+func TestUnitBrokerSince(t *testing.T) {
+	broker := NewBroker()
+	broker.historySize = 3
+	for id := uint64(1); id <= 5; id++ {
+		broker.Publish(Event{ID: id})
+	}
+
+	t.Run("Retained event", func(t *testing.T) {
+		events, complete := broker.Since(3)
+		assert.True(t, complete)
+		assert.Equal(t, []uint64{4, 5}, ids(events))
+	})
+
+	t.Run("Latest event", func(t *testing.T) {
+		events, complete := broker.Since(5)
+		assert.True(t, complete)
+		assert.Empty(t, events)
+	})
+
+	t.Run("Evicted event", func(t *testing.T) {
+		events, complete := broker.Since(1)
+		assert.False(t, complete)
+		assert.Equal(t, []uint64{3, 4, 5}, ids(events))
+	})
+}
+
+func ids(events []Event) []uint64 {
+	result := make([]uint64, 0, len(events))
+	for _, event := range events {
+		result = append(result, event.ID)
+	}
+	return result
+}
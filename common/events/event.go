@@ -0,0 +1,91 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/danielkhtse/supreme-adventure/common/types"
+)
+
+// Type identifies the kind of domain event
+type Type string
+
+const (
+	TypeAccountCreated           Type = "account.created"
+	TypeAccountBalanceChanged    Type = "account.balance_changed"
+	TypeTransferApplied          Type = "transfer.applied"
+	TypeTransactionStatusChanged Type = "transaction.status_changed"
+)
+
+// Event is a domain event published by a service after a state change has been committed
+type Event struct {
+	// Time-ordered unique identifier of the event
+	ID uint64 `json:"id,string"`
+
+	// The kind of event
+	Type Type `json:"type"`
+
+	// The accounts affected by the event
+	AccountIDs types.AccountIDs `json:"account_ids" swaggertype:"array,string"`
+
+	// Event specific payload
+	Data types.RawJSON `json:"data" swaggertype:"object"`
+
+	// When the change happened
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// New creates an event with the payload marshaled to JSON
+func New(id uint64, eventType Type, data any, accountIDs ...types.AccountID) (Event, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, fmt.Errorf("failed to marshal %s event payload: %w", eventType, err)
+	}
+
+	return Event{
+		ID:         id,
+		Type:       eventType,
+		AccountIDs: accountIDs,
+		Data:       payload,
+		OccurredAt: time.Now().UTC(),
+	}, nil
+}
+
+// AffectsAccount reports whether the event concerns the given account
+func (e Event) AffectsAccount(accountID types.AccountID) bool {
+	for _, id := range e.AccountIDs {
+		if id == accountID {
+			return true
+		}
+	}
+	return false
+}
+
+// BalanceChange is the payload of an account.balance_changed event
+type BalanceChange struct {
+	AccountID types.AccountID `json:"account_id,string"`
+
+	// Signed change of the balance in smallest currency units, negative for debits
+	Amount types.AccountBalance `json:"amount"`
+
+	// Balance after the change, only set by the service owning the balance
+	Balance *types.AccountBalance `json:"balance,omitempty"`
+
+	Currency      string              `json:"currency"`
+	TransactionID types.TransactionID `json:"transaction_id,string,omitempty"`
+}
+
+// TransferApplied is the payload of a transfer.applied event
+type TransferApplied struct {
+	SourceAccountID types.AccountID      `json:"source_account_id,string"`
+	DestAccountID   types.AccountID      `json:"destination_account_id,string"`
+	Amount          types.AccountBalance `json:"amount"`
+
+	// Balances after the transfer
+	SourceBalance types.AccountBalance `json:"source_balance"`
+	DestBalance   types.AccountBalance `json:"destination_balance"`
+
+	Currency      string              `json:"currency"`
+	TransactionID types.TransactionID `json:"transaction_id,string,omitempty"`
+}
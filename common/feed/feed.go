@@ -0,0 +1,353 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/events"
+	"github.com/danielkhtse/supreme-adventure/common/idgen"
+	"github.com/danielkhtse/supreme-adventure/common/models"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+)
+
+const (
+	// RetentionEnv configures how long events are kept, as a Go duration (e.g. 720h)
+	RetentionEnv = "EVENT_RETENTION"
+
+	DefaultRetention = 30 * 24 * time.Hour
+	DefaultLimit     = 100
+	MaxLimit         = 1000
+	MaxWait          = 30 * time.Second
+
+	// LatestCursor is accepted as after to start from the newest event, the page returns its cursor
+	LatestCursor = "latest"
+
+	pollInterval    = 250 * time.Millisecond
+	pruneInterval   = time.Hour
+	pruneBatchLimit = 10000
+)
+
+var (
+	// ErrInvalidCursor is returned for a cursor which was not issued by the feed
+	ErrInvalidCursor = apperr.ErrInvalidCursor
+
+	// ErrCursorExpired is returned when events after a cursor have been pruned
+	ErrCursorExpired = apperr.ErrCursorExpired
+)
+
+// Page is one page of the change feed
+type Page struct {
+	// Events in commit order
+	Events []events.Event `json:"events"`
+
+	// Cursor to pass as after to continue reading, equal to the request cursor when no events were returned
+	NextCursor string `json:"next_cursor"`
+
+	// Whether more events were available than the limit allowed
+	HasMore bool `json:"has_more"`
+}
+
+// Store persists the events of one service in its own table and serves them as an ordered feed
+type Store struct {
+	db        *gorm.DB
+	table     string
+	broker    *events.Broker
+	retention time.Duration
+	now       func() time.Time
+}
+
+// NewStore creates a Store writing to the given table and publishing committed events to broker
+func NewStore(db *gorm.DB, table string, broker *events.Broker, retention time.Duration) *Store {
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+	return &Store{
+		db:        db,
+		table:     table,
+		broker:    broker,
+		retention: retention,
+		now:       time.Now,
+	}
+}
+
+// RetentionFromEnv reads EVENT_RETENTION, falling back to DefaultRetention when unset
+func RetentionFromEnv() (time.Duration, error) {
+	value := os.Getenv(RetentionEnv)
+	if value == "" {
+		return DefaultRetention, nil
+	}
+	retention, err := time.ParseDuration(value)
+	if err != nil || retention <= 0 {
+		return 0, fmt.Errorf("invalid %s: %q", RetentionEnv, value)
+	}
+	return retention, nil
+}
+
+// Migrate creates or updates the event table and the table of pruned positions
+func (s *Store) Migrate() error {
+	if err := s.db.Table(s.table).AutoMigrate(&models.Event{}); err != nil {
+		return err
+	}
+	return s.db.AutoMigrate(&models.EventFeedHorizon{})
+}
+
+// Append stores events within tx so they commit atomically with the change they describe. The feed lock is
+// taken before the events get their positions and held until tx ends, so positions are assigned in commit
+// order and a reader never passes a position which a concurrent transaction could still commit below.
+func (s *Store) Append(tx *gorm.DB, evts ...events.Event) error {
+	if len(evts) == 0 {
+		return nil
+	}
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", s.table).Error; err != nil {
+		return fmt.Errorf("failed to lock event feed: %w", err)
+	}
+
+	rows := make([]models.Event, 0, len(evts))
+	for _, event := range evts {
+		rows = append(rows, models.Event{
+			ID:         event.ID,
+			Type:       event.Type,
+			AccountIDs: event.AccountIDs,
+			Data:       event.Data,
+			OccurredAt: event.OccurredAt,
+		})
+	}
+	if err := tx.Table(s.table).Create(&rows).Error; err != nil {
+		return fmt.Errorf("failed to store events: %w", err)
+	}
+	return nil
+}
+
+// Publish notifies in-process subscribers, call it once the transaction storing the events has committed
+func (s *Store) Publish(evts ...events.Event) {
+	if s.broker == nil {
+		return
+	}
+	for _, event := range evts {
+		s.broker.Publish(event)
+	}
+}
+
+// List returns up to limit events after the cursor, only those affecting the account unless accountID is zero.
+// When none are available it waits up to wait for new ones.
+func (s *Store) List(ctx context.Context, after string, accountID types.AccountID, limit int, wait time.Duration) (*Page, error) {
+	afterPosition, err := s.cursorPosition(ctx, after)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if limit > MaxLimit {
+		limit = MaxLimit
+	}
+	if wait > MaxWait {
+		wait = MaxWait
+	}
+
+	deadline := s.now().Add(wait)
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		page, err := s.list(ctx, afterPosition, accountID, limit)
+		if err != nil {
+			return nil, err
+		}
+		if len(page.Events) > 0 || !s.now().Before(deadline) {
+			return page, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return page, nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Store) list(ctx context.Context, afterPosition uint64, accountID types.AccountID, limit int) (*Page, error) {
+	query := s.db.WithContext(ctx).Table(s.table).Where("position > ?", afterPosition)
+	if accountID != 0 {
+		query = query.Where("account_ids @> ?::jsonb", fmt.Sprintf("[%d]", accountID))
+	}
+
+	var rows []models.Event
+	if err := query.
+		Order("position").
+		Limit(limit + 1).
+		Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+
+	page := &Page{
+		Events:     make([]events.Event, 0, len(rows)),
+		NextCursor: FormatCursor(afterPosition),
+	}
+	if len(rows) > limit {
+		page.HasMore = true
+		rows = rows[:limit]
+	}
+	for _, row := range rows {
+		page.Events = append(page.Events, events.Event{
+			ID:         row.ID,
+			Type:       row.Type,
+			AccountIDs: row.AccountIDs,
+			Data:       row.Data,
+			OccurredAt: row.OccurredAt,
+		})
+	}
+	if len(rows) > 0 {
+		page.NextCursor = FormatCursor(rows[len(rows)-1].Position)
+	}
+	return page, nil
+}
+
+// cursorPosition resolves the cursor to the position to read after, failing when events after it were pruned
+func (s *Store) cursorPosition(ctx context.Context, after string) (uint64, error) {
+	if after == LatestCursor {
+		return s.headPosition(ctx)
+	}
+
+	position, err := ParseCursor(after)
+	if err != nil || position == 0 {
+		return position, err
+	}
+	pruned, err := s.prunedPosition(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if position < pruned {
+		return 0, ErrCursorExpired
+	}
+	return position, nil
+}
+
+// EventCursor returns the cursor after the event with the given ID. When the event is no longer retained, or
+// unknown, retained is false and the cursor is before the oldest retained event with a greater ID, IDs being
+// ordered by creation time, or after the newest event when there is none.
+func (s *Store) EventCursor(ctx context.Context, eventID uint64) (cursor string, retained bool, err error) {
+	var position uint64
+	if err := s.db.WithContext(ctx).Table(s.table).Select("position").Where("id = ?", eventID).Limit(1).Scan(&position).Error; err != nil {
+		return "", false, fmt.Errorf("failed to find event: %w", err)
+	}
+	if position != 0 {
+		return FormatCursor(position), true, nil
+	}
+
+	if err := s.db.WithContext(ctx).Table(s.table).Select("COALESCE(MIN(position), 0)").Where("id > ?", eventID).Scan(&position).Error; err != nil {
+		return "", false, fmt.Errorf("failed to find event: %w", err)
+	}
+	if position == 0 {
+		cursor, err := s.Head(ctx)
+		return cursor, false, err
+	}
+	return FormatCursor(position - 1), false, nil
+}
+
+// Head returns the cursor after the newest event, empty when the feed has no events
+func (s *Store) Head(ctx context.Context) (string, error) {
+	position, err := s.headPosition(ctx)
+	if err != nil {
+		return "", err
+	}
+	return FormatCursor(position), nil
+}
+
+func (s *Store) headPosition(ctx context.Context) (uint64, error) {
+	var head uint64
+	if err := s.db.WithContext(ctx).Table(s.table).Select("COALESCE(MAX(position), 0)").Scan(&head).Error; err != nil {
+		return 0, fmt.Errorf("failed to read event feed head: %w", err)
+	}
+	return head, nil
+}
+
+// prunedPosition returns the highest position pruned from the feed, zero when nothing was pruned
+func (s *Store) prunedPosition(ctx context.Context) (uint64, error) {
+	var horizon models.EventFeedHorizon
+	if err := s.db.WithContext(ctx).Where("feed = ?", s.table).Limit(1).Find(&horizon).Error; err != nil {
+		return 0, fmt.Errorf("failed to read event feed horizon: %w", err)
+	}
+	return horizon.Position, nil
+}
+
+// StartRetention deletes events older than the retention period every hour until ctx is done
+func (s *Store) StartRetention(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(pruneInterval)
+		defer ticker.Stop()
+		for {
+			if _, err := s.Prune(ctx); err != nil {
+				logrus.WithError(err).WithField("table", s.table).Error("failed to prune events")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Prune deletes events older than the retention period and returns how many were removed. The highest deleted
+// position is recorded with each batch, so only cursors which missed a deleted event expire.
+func (s *Store) Prune(ctx context.Context) (int64, error) {
+	horizon := idgen.FirstIDAt(s.now().Add(-s.retention))
+
+	var total int64
+	for {
+		var positions []uint64
+		err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Raw(
+				fmt.Sprintf(`DELETE FROM %q WHERE id IN (SELECT id FROM %q WHERE id < ? ORDER BY id LIMIT ?) RETURNING position`, s.table, s.table),
+				horizon, pruneBatchLimit,
+			).Scan(&positions).Error; err != nil {
+				return err
+			}
+			if len(positions) == 0 {
+				return nil
+			}
+			return tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "feed"}},
+				DoUpdates: clause.Assignments(map[string]any{"position": gorm.Expr("GREATEST(event_feed_horizons.position, excluded.position)")}),
+			}).Create(&models.EventFeedHorizon{Feed: s.table, Position: slices.Max(positions)}).Error
+		})
+		if err != nil {
+			return total, err
+		}
+		total += int64(len(positions))
+		if len(positions) < pruneBatchLimit {
+			return total, nil
+		}
+	}
+}
+
+// ParseCursor decodes a feed cursor, the empty cursor starts from the oldest retained event
+func ParseCursor(cursor string) (uint64, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	position, err := strconv.ParseUint(cursor, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+	return position, nil
+}
+
+// FormatCursor encodes the feed position of the last event read
+func FormatCursor(position uint64) string {
+	if position == 0 {
+		return ""
+	}
+	return strconv.FormatUint(position, 10)
+}
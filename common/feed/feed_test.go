@@ -0,0 +1,230 @@
+package feed
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/danielkhtse/supreme-adventure/common/db"
+	"github.com/danielkhtse/supreme-adventure/common/events"
+	"github.com/danielkhtse/supreme-adventure/common/idgen"
+)
+
+func newTestStore(t *testing.T) (*Store, sqlmock.Sqlmock, time.Time) {
+	mockDB, err := db.NewMockDB()
+	require.NoError(t, err)
+
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	store := NewStore(mockDB.GetDB(), "account_events", events.NewBroker(), 24*time.Hour)
+	store.now = func() time.Time { return now }
+	return store, mockDB.Mock, now
+}
+
+func expectPrunedPosition(mock sqlmock.Sqlmock, position uint64) {
+	rows := sqlmock.NewRows([]string{"feed", "position"})
+	if position != 0 {
+		rows.AddRow("account_events", position)
+	}
+	mock.ExpectQuery(`SELECT \* FROM "event_feed_horizons" WHERE feed = \$1 LIMIT \$2`).
+		WithArgs("account_events", 1).
+		WillReturnRows(rows)
+}
+
+func TestUnitStoreList(t *testing.T) {
+	t.Run("Returns page and next cursor", func(t *testing.T) {
+		store, mock, now := newTestStore(t)
+
+		expectPrunedPosition(mock, 0)
+		mock.ExpectQuery(`SELECT \* FROM "account_events" WHERE position > \$1 ORDER BY position LIMIT \$2`).
+			WithArgs(10, 3).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "position", "type", "account_ids", "data", "occurred_at"}).
+				AddRow(idgen.FirstIDAt(now)+2, 11, "account.created", "[1]", `{}`, now).
+				AddRow(idgen.FirstIDAt(now)+1, 12, "account.created", "[2]", `{}`, now).
+				AddRow(idgen.FirstIDAt(now)+3, 13, "account.created", "[3]", `{}`, now))
+
+		page, err := store.List(context.Background(), FormatCursor(10), 0, 2, 0)
+		require.NoError(t, err)
+		require.Len(t, page.Events, 2)
+		assert.Equal(t, idgen.FirstIDAt(now)+2, page.Events[0].ID)
+		assert.True(t, page.HasMore)
+		assert.Equal(t, FormatCursor(12), page.NextCursor)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Filters by account", func(t *testing.T) {
+		store, mock, now := newTestStore(t)
+
+		expectPrunedPosition(mock, 0)
+		mock.ExpectQuery(`SELECT \* FROM "account_events" WHERE position > \$1 AND account_ids @> \$2::jsonb ORDER BY position LIMIT \$3`).
+			WithArgs(10, "[2]", 11).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "position", "type", "account_ids", "data", "occurred_at"}).
+				AddRow(idgen.FirstIDAt(now)+1, 12, "account.created", "[2]", `{}`, now))
+
+		page, err := store.List(context.Background(), FormatCursor(10), 2, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, page.Events, 1)
+		assert.Equal(t, FormatCursor(12), page.NextCursor)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Empty page keeps cursor", func(t *testing.T) {
+		store, mock, _ := newTestStore(t)
+
+		expectPrunedPosition(mock, 0)
+		mock.ExpectQuery(`SELECT \* FROM "account_events"`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		page, err := store.List(context.Background(), FormatCursor(10), 0, 10, 0)
+		require.NoError(t, err)
+		assert.Empty(t, page.Events)
+		assert.False(t, page.HasMore)
+		assert.Equal(t, FormatCursor(10), page.NextCursor)
+	})
+
+	t.Run("Empty cursor starts from the oldest retained event", func(t *testing.T) {
+		store, mock, _ := newTestStore(t)
+
+		mock.ExpectQuery(`SELECT \* FROM "account_events" WHERE position > \$1`).
+			WithArgs(0, 11).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		_, err := store.List(context.Background(), "", 0, 10, 0)
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Latest cursor starts after the newest event", func(t *testing.T) {
+		store, mock, _ := newTestStore(t)
+
+		mock.ExpectQuery(`SELECT COALESCE\(MAX\(position\), 0\) FROM "account_events"`).
+			WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(42))
+		mock.ExpectQuery(`SELECT \* FROM "account_events" WHERE position > \$1`).
+			WithArgs(42, 11).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		page, err := store.List(context.Background(), LatestCursor, 0, 10, 0)
+		require.NoError(t, err)
+		assert.Empty(t, page.Events)
+		assert.Equal(t, FormatCursor(42), page.NextCursor)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Invalid cursor", func(t *testing.T) {
+		store, _, _ := newTestStore(t)
+
+		_, err := store.List(context.Background(), "not-a-cursor", 0, 10, 0)
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+
+	t.Run("Expired cursor", func(t *testing.T) {
+		store, mock, _ := newTestStore(t)
+
+		expectPrunedPosition(mock, 20)
+
+		_, err := store.List(context.Background(), FormatCursor(10), 0, 10, 0)
+		assert.ErrorIs(t, err, ErrCursorExpired)
+	})
+
+	t.Run("Cursor at the pruned position", func(t *testing.T) {
+		store, mock, _ := newTestStore(t)
+
+		expectPrunedPosition(mock, 20)
+		mock.ExpectQuery(`SELECT \* FROM "account_events" WHERE position > \$1`).
+			WithArgs(20, 11).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		_, err := store.List(context.Background(), FormatCursor(20), 0, 10, 0)
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUnitStoreAppend(t *testing.T) {
+	store, mock, now := newTestStore(t)
+	eventCh, unsubscribe := store.broker.Subscribe(1)
+	defer unsubscribe()
+
+	event := events.Event{ID: idgen.FirstIDAt(now), Type: events.TypeAccountCreated, Data: []byte(`{}`), OccurredAt: now}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT pg_advisory_xact_lock\(hashtext\(\$1\)\)`).
+		WithArgs("account_events").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`INSERT INTO "account_events" \("id","type","account_ids","data","occurred_at"\) .* RETURNING "position"`).
+		WillReturnRows(sqlmock.NewRows([]string{"position"}).AddRow(1))
+	mock.ExpectCommit()
+
+	tx := store.db.Begin()
+	require.NoError(t, store.Append(tx, event))
+	require.NoError(t, tx.Commit().Error)
+	store.Publish(event)
+
+	assert.Equal(t, event.ID, (<-eventCh).ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUnitStorePrune(t *testing.T) {
+	store, mock, now := newTestStore(t)
+	horizon := idgen.FirstIDAt(now.Add(-24 * time.Hour))
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`DELETE FROM "account_events" WHERE id IN \(SELECT id FROM "account_events" WHERE id < \$1 ORDER BY id LIMIT \$2\) RETURNING position`).
+		WithArgs(horizon, pruneBatchLimit).
+		WillReturnRows(sqlmock.NewRows([]string{"position"}).AddRow(7).AddRow(9).AddRow(8))
+	mock.ExpectExec(`INSERT INTO "event_feed_horizons" \("feed","position"\) VALUES \(\$1,\$2\) ON CONFLICT \("feed"\) DO UPDATE SET "position"=GREATEST\(event_feed_horizons.position, excluded.position\)`).
+		WithArgs("account_events", 9).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	deleted, err := store.Prune(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUnitStoreEventCursor(t *testing.T) {
+	positionQuery := `SELECT position FROM "account_events" WHERE id = \$1 LIMIT \$2`
+	nextQuery := `SELECT COALESCE\(MIN\(position\), 0\) FROM "account_events" WHERE id > \$1`
+
+	t.Run("Retained event", func(t *testing.T) {
+		store, mock, _ := newTestStore(t)
+		mock.ExpectQuery(positionQuery).WithArgs(100, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"position"}).AddRow(12))
+
+		cursor, retained, err := store.EventCursor(context.Background(), 100)
+		require.NoError(t, err)
+		assert.True(t, retained)
+		assert.Equal(t, FormatCursor(12), cursor)
+	})
+
+	t.Run("Pruned event", func(t *testing.T) {
+		store, mock, _ := newTestStore(t)
+		mock.ExpectQuery(positionQuery).WithArgs(100, 1).WillReturnRows(sqlmock.NewRows([]string{"position"}))
+		mock.ExpectQuery(nextQuery).WithArgs(100).
+			WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(40))
+
+		cursor, retained, err := store.EventCursor(context.Background(), 100)
+		require.NoError(t, err)
+		assert.False(t, retained)
+		assert.Equal(t, FormatCursor(39), cursor)
+	})
+
+	t.Run("Unknown event", func(t *testing.T) {
+		store, mock, _ := newTestStore(t)
+		mock.ExpectQuery(positionQuery).WithArgs(100, 1).WillReturnRows(sqlmock.NewRows([]string{"position"}))
+		mock.ExpectQuery(nextQuery).WithArgs(100).
+			WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(0))
+		mock.ExpectQuery(`SELECT COALESCE\(MAX\(position\), 0\) FROM "account_events"`).
+			WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(50))
+
+		cursor, retained, err := store.EventCursor(context.Background(), 100)
+		require.NoError(t, err)
+		assert.False(t, retained)
+		assert.Equal(t, FormatCursor(50), cursor)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,33 @@
+package idgen
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+const (
+	// NodeIDEnv is the environment variable holding the node ID of the running replica
+	NodeIDEnv = "ID_GENERATOR_NODE_ID"
+)
+
+// Generator produces unique 64-bit identifiers
+type Generator interface {
+	// NextID returns the next identifier, IDs are sortable by creation time
+	NextID() (uint64, error)
+}
+
+// NewGeneratorFromEnv creates a Snowflake generator using the node ID from ID_GENERATOR_NODE_ID.
+// Every replica writing to the same table must be configured with a different node ID.
+func NewGeneratorFromEnv() (Generator, error) {
+	nodeID := int64(0)
+	if value := os.Getenv(NodeIDEnv); value != "" {
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", NodeIDEnv, err)
+		}
+		nodeID = parsed
+	}
+
+	return NewSnowflake(nodeID)
+}
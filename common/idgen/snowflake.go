@@ -0,0 +1,90 @@
+package idgen
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Snowflake layout: 1 unused sign bit | 41 bits milliseconds since Epoch | 10 bits node ID | 12 bits sequence
+const (
+	nodeBits     = 10
+	sequenceBits = 12
+
+	MaxNodeID   = int64(1)<<nodeBits - 1
+	maxSequence = int64(1)<<sequenceBits - 1
+
+	timestampShift = nodeBits + sequenceBits
+	nodeShift      = sequenceBits
+)
+
+// Epoch is the custom epoch of the timestamp part (2025-01-01T00:00:00Z)
+var Epoch = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Snowflake generates time-ordered 64-bit IDs which are unique across nodes
+type Snowflake struct {
+	mu       sync.Mutex
+	nodeID   int64
+	lastTime int64
+	sequence int64
+	now      func() time.Time
+}
+
+// NewSnowflake creates a Snowflake generator for the given node ID (0-1023)
+func NewSnowflake(nodeID int64) (*Snowflake, error) {
+	if nodeID < 0 || nodeID > MaxNodeID {
+		return nil, fmt.Errorf("node ID must be between 0 and %d, got %d", MaxNodeID, nodeID)
+	}
+
+	return &Snowflake{
+		nodeID: nodeID,
+		now:    time.Now,
+	}, nil
+}
+
+// NextID returns the next ID, blocking until the next millisecond if the sequence is exhausted
+func (s *Snowflake) NextID() (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := s.now().Sub(Epoch).Milliseconds()
+	if current < 0 {
+		return 0, errors.New("system clock is before the ID epoch")
+	}
+
+	// Clock moved backwards, keep issuing IDs from the last known time to preserve ordering
+	if current < s.lastTime {
+		current = s.lastTime
+	}
+
+	if current == s.lastTime {
+		s.sequence = (s.sequence + 1) & maxSequence
+		if s.sequence == 0 {
+			for current <= s.lastTime {
+				time.Sleep(100 * time.Microsecond)
+				current = s.now().Sub(Epoch).Milliseconds()
+			}
+		}
+	} else {
+		s.sequence = 0
+	}
+
+	s.lastTime = current
+
+	return uint64(current<<timestampShift | s.nodeID<<nodeShift | s.sequence), nil
+}
+
+// Time returns the creation time encoded in an ID generated by Snowflake
+func Time(id uint64) time.Time {
+	return Epoch.Add(time.Duration(id>>timestampShift) * time.Millisecond)
+}
+
+// FirstIDAt returns the smallest ID a Snowflake generator can issue at the given time
+func FirstIDAt(t time.Time) uint64 {
+	ms := t.Sub(Epoch).Milliseconds()
+	if ms < 0 {
+		return 0
+	}
+	return uint64(ms) << timestampShift
+}
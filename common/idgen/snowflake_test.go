@@ -0,0 +1,96 @@
+package idgen
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitNewSnowflake(t *testing.T) {
+	t.Run("Valid node ID", func(t *testing.T) {
+		generator, err := NewSnowflake(MaxNodeID)
+		assert.NoError(t, err)
+		assert.NotNil(t, generator)
+	})
+
+	t.Run("Node ID out of range", func(t *testing.T) {
+		_, err := NewSnowflake(MaxNodeID + 1)
+		assert.Error(t, err)
+
+		_, err = NewSnowflake(-1)
+		assert.Error(t, err)
+	})
+}
+
+func TestUnitSnowflakeNextID(t *testing.T) {
+	t.Run("IDs are unique and increasing", func(t *testing.T) {
+		generator, err := NewSnowflake(1)
+		require.NoError(t, err)
+
+		var last uint64
+		for i := 0; i < 10000; i++ {
+			id, err := generator.NextID()
+			require.NoError(t, err)
+			assert.Greater(t, id, last)
+			last = id
+		}
+	})
+
+	t.Run("Different nodes never collide", func(t *testing.T) {
+		fixed := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+		first, _ := NewSnowflake(1)
+		second, _ := NewSnowflake(2)
+		first.now = func() time.Time { return fixed }
+		second.now = func() time.Time { return fixed }
+
+		a, err := first.NextID()
+		require.NoError(t, err)
+		b, err := second.NextID()
+		require.NoError(t, err)
+		assert.NotEqual(t, a, b)
+	})
+
+	t.Run("Concurrent calls", func(t *testing.T) {
+		generator, _ := NewSnowflake(3)
+
+		var mu sync.Mutex
+		seen := make(map[uint64]struct{})
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 1000; j++ {
+					id, err := generator.NextID()
+					assert.NoError(t, err)
+					mu.Lock()
+					seen[id] = struct{}{}
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Len(t, seen, 8000)
+	})
+
+	t.Run("Clock before epoch", func(t *testing.T) {
+		generator, _ := NewSnowflake(1)
+		generator.now = func() time.Time { return Epoch.Add(-time.Hour) }
+
+		_, err := generator.NextID()
+		assert.Error(t, err)
+	})
+
+	t.Run("Encoded time", func(t *testing.T) {
+		fixed := time.Date(2025, 6, 1, 12, 30, 0, 0, time.UTC)
+		generator, _ := NewSnowflake(1)
+		generator.now = func() time.Time { return fixed }
+
+		id, err := generator.NextID()
+		require.NoError(t, err)
+		assert.True(t, fixed.Equal(Time(id)))
+	})
+}
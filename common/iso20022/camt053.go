@@ -0,0 +1,83 @@
+package iso20022
+
+import (
+	"encoding/xml"
+
+	"github.com/danielkhtse/supreme-adventure/common/types"
+)
+
+// StatementDocument is a camt.053.001.08 bank to customer statement
+type StatementDocument struct {
+	XMLName       xml.Name                   `xml:"urn:iso:std:iso:20022:tech:xsd:camt.053.001.08 Document"`
+	BkToCstmrStmt BankToCustomerStatementV08 `xml:"BkToCstmrStmt"`
+}
+
+type BankToCustomerStatementV08 struct {
+	GrpHdr GroupHeader81       `xml:"GrpHdr"`
+	Stmt   []AccountStatement9 `xml:"Stmt"`
+}
+
+// AccountStatement9 reports the booked entries of an account between its opening and closing balance
+type AccountStatement9 struct {
+	Id        string              `xml:"Id"`
+	CreDtTm   string              `xml:"CreDtTm"`
+	FrToDt    *DateTimePeriod1    `xml:"FrToDt"`
+	Acct      CashAccount39       `xml:"Acct"`
+	Bal       []CashBalance8      `xml:"Bal"`
+	TxsSummry *TotalTransactions6 `xml:"TxsSummry"`
+	Ntry      []ReportEntry10     `xml:"Ntry"`
+}
+
+// CashBalance8 is a balance of the account at a date
+type CashBalance8 struct {
+	Tp        BalanceType13                     `xml:"Tp"`
+	Amt       ActiveOrHistoricCurrencyAndAmount `xml:"Amt"`
+	CdtDbtInd string                            `xml:"CdtDbtInd"`
+	Dt        DateAndDateTime2Choice            `xml:"Dt"`
+}
+
+type BalanceType13 struct {
+	CdOrPrtry BalanceType10Choice `xml:"CdOrPrtry"`
+}
+
+type BalanceType10Choice struct {
+	Cd string `xml:"Cd"`
+}
+
+// Statement is a Report with the balances of the account at the start and the end of the period
+type Statement struct {
+	Report
+
+	OpeningBalance types.AccountBalance
+	ClosingBalance types.AccountBalance
+}
+
+// NewStatement builds a camt.053 statement with an opening booked (OPBD) and a closing booked (CLBD) balance
+func NewStatement(statement Statement) *StatementDocument {
+	report := statement.Report
+	return &StatementDocument{BkToCstmrStmt: BankToCustomerStatementV08{
+		GrpHdr: newGroupHeader(report),
+		Stmt: []AccountStatement9{{
+			Id:      truncate(report.MessageID, max35Text),
+			CreDtTm: formatDateTime(report.CreatedAt),
+			FrToDt:  newPeriod(report),
+			Acct:    newAccount(report.Account),
+			Bal: []CashBalance8{
+				newBalance("OPBD", statement.OpeningBalance, report.Account.Currency, formatDate(report.From)),
+				newBalance("CLBD", statement.ClosingBalance, report.Account.Currency, formatDate(report.To.Add(-1))),
+			},
+			TxsSummry: newSummary(report),
+			Ntry:      newEntries(report),
+		}},
+	}}
+}
+
+func newBalance(code string, balance types.AccountBalance, currency string, date string) CashBalance8 {
+	amount, indicator := signedAmount(int64(balance))
+	return CashBalance8{
+		Tp:        BalanceType13{CdOrPrtry: BalanceType10Choice{Cd: code}},
+		Amt:       ActiveOrHistoricCurrencyAndAmount{Ccy: currency, Value: formatAmount(amount, currency)},
+		CdtDbtInd: indicator,
+		Dt:        DateAndDateTime2Choice{Dt: date},
+	}
+}
@@ -0,0 +1,39 @@
+package iso20022
+
+import "encoding/xml"
+
+// NotificationDocument is a camt.054.001.08 bank to customer debit/credit notification
+type NotificationDocument struct {
+	XMLName               xml.Name                                 `xml:"urn:iso:std:iso:20022:tech:xsd:camt.054.001.08 Document"`
+	BkToCstmrDbtCdtNtfctn BankToCustomerDebitCreditNotificationV08 `xml:"BkToCstmrDbtCdtNtfctn"`
+}
+
+type BankToCustomerDebitCreditNotificationV08 struct {
+	GrpHdr GroupHeader81           `xml:"GrpHdr"`
+	Ntfctn []AccountNotification17 `xml:"Ntfctn"`
+}
+
+// AccountNotification17 notifies the booked debit and credit entries of an account
+type AccountNotification17 struct {
+	Id        string              `xml:"Id"`
+	CreDtTm   string              `xml:"CreDtTm"`
+	FrToDt    *DateTimePeriod1    `xml:"FrToDt"`
+	Acct      CashAccount39       `xml:"Acct"`
+	TxsSummry *TotalTransactions6 `xml:"TxsSummry"`
+	Ntry      []ReportEntry10     `xml:"Ntry"`
+}
+
+// NewNotification builds a camt.054 notification of the activities of the report
+func NewNotification(report Report) *NotificationDocument {
+	return &NotificationDocument{BkToCstmrDbtCdtNtfctn: BankToCustomerDebitCreditNotificationV08{
+		GrpHdr: newGroupHeader(report),
+		Ntfctn: []AccountNotification17{{
+			Id:        truncate(report.MessageID, max35Text),
+			CreDtTm:   formatDateTime(report.CreatedAt),
+			FrToDt:    newPeriod(report),
+			Acct:      newAccount(report.Account),
+			TxsSummry: newSummary(report),
+			Ntry:      newEntries(report),
+		}},
+	}}
+}
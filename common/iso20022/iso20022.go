@@ -0,0 +1,359 @@
+// Package iso20022 builds ISO 20022 cash management messages from account activity: camt.053 end-of-day
+// statements and camt.054 debit/credit notifications, version 08 of both, for the treasury systems of corporate
+// clients. Only the elements the ledger has data for are emitted.
+package iso20022
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/danielkhtse/supreme-adventure/common/models"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+)
+
+// ContentType is the media type of the encoded messages
+const ContentType = "application/xml"
+
+// Values of the CreditDebitCode
+const (
+	credit = "CRDT"
+	debit  = "DBIT"
+)
+
+// Lengths of the MaxNText types
+const (
+	max35Text  = 35
+	max140Text = 140
+)
+
+// endToEndIDNotProvided is the conventional EndToEndId of payments initiated without one
+const endToEndIDNotProvided = "NOTPROVIDED"
+
+// Encode writes a message document as an indented XML file
+func Encode(w io.Writer, document any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// Report holds what statements and notifications are built from: the account, the reported period and the
+// balance movements of the account in that period
+type Report struct {
+	// MessageID identifies the message, at most 35 characters
+	MessageID string
+
+	CreatedAt time.Time
+	Account   models.Account
+
+	// From is inclusive, To is exclusive
+	From time.Time
+	To   time.Time
+
+	// Activities in booking order
+	Activities []models.AccountActivity
+
+	// Transactions behind the activities, by ID, for the remittance information of the entries. Entries
+	// without a known transaction carry no remittance information.
+	Transactions map[types.TransactionID]models.Transaction
+}
+
+// GroupHeader81 identifies the message
+type GroupHeader81 struct {
+	MsgId   string `xml:"MsgId"`
+	CreDtTm string `xml:"CreDtTm"`
+}
+
+// ActiveOrHistoricCurrencyAndAmount is a non-negative amount in major units, e.g. 12.34 for 1234 cents
+type ActiveOrHistoricCurrencyAndAmount struct {
+	Ccy   string `xml:"Ccy,attr"`
+	Value string `xml:",chardata"`
+}
+
+// DateTimePeriod1 is an inclusive period
+type DateTimePeriod1 struct {
+	FrDtTm string `xml:"FrDtTm"`
+	ToDtTm string `xml:"ToDtTm"`
+}
+
+// CashAccount39 is the account a report is about
+type CashAccount39 struct {
+	Id  AccountIdentification4Choice `xml:"Id"`
+	Ccy string                       `xml:"Ccy,omitempty"`
+}
+
+// CashAccount38 is the account of a related party
+type CashAccount38 struct {
+	Id AccountIdentification4Choice `xml:"Id"`
+}
+
+// AccountIdentification4Choice identifies accounts by their ledger ID under Othr, ledger accounts have no IBAN
+type AccountIdentification4Choice struct {
+	IBAN string                        `xml:"IBAN,omitempty"`
+	Othr GenericAccountIdentification1 `xml:"Othr"`
+}
+
+type GenericAccountIdentification1 struct {
+	Id string `xml:"Id"`
+}
+
+// TotalTransactions6 summarises the entries of a report
+type TotalTransactions6 struct {
+	TtlNtries    NumberAndSumOfTransactions4 `xml:"TtlNtries"`
+	TtlCdtNtries NumberAndSumOfTransactions1 `xml:"TtlCdtNtries"`
+	TtlDbtNtries NumberAndSumOfTransactions1 `xml:"TtlDbtNtries"`
+}
+
+type NumberAndSumOfTransactions4 struct {
+	NbOfNtries string               `xml:"NbOfNtries"`
+	Sum        string               `xml:"Sum"`
+	TtlNetNtry AmountAndDirection35 `xml:"TtlNetNtry"`
+}
+
+type NumberAndSumOfTransactions1 struct {
+	NbOfNtries string `xml:"NbOfNtries"`
+	Sum        string `xml:"Sum"`
+}
+
+type AmountAndDirection35 struct {
+	Amt       string `xml:"Amt"`
+	CdtDbtInd string `xml:"CdtDbtInd"`
+}
+
+// ReportEntry10 is one booked balance movement
+type ReportEntry10 struct {
+	NtryRef      string                            `xml:"NtryRef"`
+	Amt          ActiveOrHistoricCurrencyAndAmount `xml:"Amt"`
+	CdtDbtInd    string                            `xml:"CdtDbtInd"`
+	Sts          EntryStatus1Choice                `xml:"Sts"`
+	BookgDt      DateAndDateTime2Choice            `xml:"BookgDt"`
+	ValDt        DateAndDateTime2Choice            `xml:"ValDt"`
+	AcctSvcrRef  string                            `xml:"AcctSvcrRef"`
+	BkTxCd       BankTransactionCodeStructure4     `xml:"BkTxCd"`
+	NtryDtls     *EntryDetails9                    `xml:"NtryDtls"`
+	AddtlNtryInf string                            `xml:"AddtlNtryInf,omitempty"`
+}
+
+type EntryStatus1Choice struct {
+	Cd string `xml:"Cd"`
+}
+
+// DateAndDateTime2Choice holds either a date or a date and time
+type DateAndDateTime2Choice struct {
+	Dt   string `xml:"Dt,omitempty"`
+	DtTm string `xml:"DtTm,omitempty"`
+}
+
+// BankTransactionCodeStructure4 classifies an entry with the external bank transaction codes
+type BankTransactionCodeStructure4 struct {
+	Domn BankTransactionCodeStructure5 `xml:"Domn"`
+}
+
+type BankTransactionCodeStructure5 struct {
+	Cd   string                        `xml:"Cd"`
+	Fmly BankTransactionCodeStructure6 `xml:"Fmly"`
+}
+
+type BankTransactionCodeStructure6 struct {
+	Cd        string `xml:"Cd"`
+	SubFmlyCd string `xml:"SubFmlyCd"`
+}
+
+type EntryDetails9 struct {
+	TxDtls []EntryTransaction10 `xml:"TxDtls"`
+}
+
+// EntryTransaction10 is the transaction behind an entry
+type EntryTransaction10 struct {
+	Refs      TransactionReferences6            `xml:"Refs"`
+	Amt       ActiveOrHistoricCurrencyAndAmount `xml:"Amt"`
+	CdtDbtInd string                            `xml:"CdtDbtInd"`
+	RltdPties *TransactionParties6              `xml:"RltdPties"`
+	RmtInf    *RemittanceInformation16          `xml:"RmtInf"`
+}
+
+type TransactionReferences6 struct {
+	AcctSvcrRef string `xml:"AcctSvcrRef"`
+	EndToEndId  string `xml:"EndToEndId"`
+	TxId        string `xml:"TxId,omitempty"`
+}
+
+// TransactionParties6 holds the counterparty account, the debtor account of credits and the creditor account of debits
+type TransactionParties6 struct {
+	DbtrAcct *CashAccount38 `xml:"DbtrAcct"`
+	CdtrAcct *CashAccount38 `xml:"CdtrAcct"`
+}
+
+type RemittanceInformation16 struct {
+	Ustrd []string `xml:"Ustrd"`
+}
+
+func newGroupHeader(report Report) GroupHeader81 {
+	return GroupHeader81{MsgId: truncate(report.MessageID, max35Text), CreDtTm: formatDateTime(report.CreatedAt)}
+}
+
+// newPeriod returns the reported period, whose end is inclusive in the message
+func newPeriod(report Report) *DateTimePeriod1 {
+	return &DateTimePeriod1{
+		FrDtTm: formatDateTime(report.From),
+		ToDtTm: formatDateTime(report.To.Add(-time.Second)),
+	}
+}
+
+func newAccount(account models.Account) CashAccount39 {
+	return CashAccount39{Id: accountID(account.ID), Ccy: account.Currency}
+}
+
+func accountID(id types.AccountID) AccountIdentification4Choice {
+	return AccountIdentification4Choice{Othr: GenericAccountIdentification1{Id: strconv.FormatUint(uint64(id), 10)}}
+}
+
+// newSummary totals the entries of a report
+func newSummary(report Report) *TotalTransactions6 {
+	var credits, debits int64
+	var creditCount, debitCount int
+	for _, activity := range report.Activities {
+		if activity.Direction == types.ActivityDirectionDebit {
+			debits += int64(activity.Amount)
+			debitCount++
+		} else {
+			credits += int64(activity.Amount)
+			creditCount++
+		}
+	}
+
+	currency := report.Account.Currency
+	net, direction := signedAmount(credits - debits)
+	return &TotalTransactions6{
+		TtlNtries: NumberAndSumOfTransactions4{
+			NbOfNtries: strconv.Itoa(creditCount + debitCount),
+			Sum:        formatAmount(credits+debits, currency),
+			TtlNetNtry: AmountAndDirection35{Amt: formatAmount(net, currency), CdtDbtInd: direction},
+		},
+		TtlCdtNtries: NumberAndSumOfTransactions1{NbOfNtries: strconv.Itoa(creditCount), Sum: formatAmount(credits, currency)},
+		TtlDbtNtries: NumberAndSumOfTransactions1{NbOfNtries: strconv.Itoa(debitCount), Sum: formatAmount(debits, currency)},
+	}
+}
+
+// newEntries returns a booked entry per activity of the report
+func newEntries(report Report) []ReportEntry10 {
+	entries := make([]ReportEntry10, 0, len(report.Activities))
+	for _, activity := range report.Activities {
+		entries = append(entries, newEntry(report, activity))
+	}
+	return entries
+}
+
+func newEntry(report Report, activity models.AccountActivity) ReportEntry10 {
+	reference := strconv.FormatUint(uint64(activity.ID), 10)
+	amount := ActiveOrHistoricCurrencyAndAmount{
+		Ccy:   report.Account.Currency,
+		Value: formatAmount(int64(activity.Amount), report.Account.Currency),
+	}
+	indicator := credit
+	if activity.Direction == types.ActivityDirectionDebit {
+		indicator = debit
+	}
+
+	entry := ReportEntry10{
+		NtryRef:     reference,
+		Amt:         amount,
+		CdtDbtInd:   indicator,
+		Sts:         EntryStatus1Choice{Cd: "BOOK"},
+		BookgDt:     DateAndDateTime2Choice{DtTm: formatDateTime(activity.CreatedAt)},
+		ValDt:       DateAndDateTime2Choice{Dt: formatDate(activity.CreatedAt)},
+		AcctSvcrRef: reference,
+		BkTxCd:      bankTransactionCode(activity),
+	}
+
+	if activity.Type == types.ActivityTypeOpening {
+		entry.AddtlNtryInf = "Opening balance"
+		return entry
+	}
+
+	details := EntryTransaction10{
+		Refs: TransactionReferences6{
+			AcctSvcrRef: reference,
+			EndToEndId:  endToEndIDNotProvided,
+		},
+		Amt:       amount,
+		CdtDbtInd: indicator,
+	}
+	if activity.TransferID != "" {
+		details.Refs.EndToEndId = truncate(activity.TransferID, max35Text)
+	}
+	if activity.TransactionID != 0 {
+		details.Refs.TxId = strconv.FormatUint(uint64(activity.TransactionID), 10)
+	}
+	if activity.CounterpartyAccountID != 0 {
+		counterparty := &CashAccount38{Id: accountID(activity.CounterpartyAccountID)}
+		if activity.Direction == types.ActivityDirectionDebit {
+			details.RltdPties = &TransactionParties6{CdtrAcct: counterparty}
+		} else {
+			details.RltdPties = &TransactionParties6{DbtrAcct: counterparty}
+		}
+	}
+	if transaction, ok := report.Transactions[activity.TransactionID]; ok && transaction.Description != "" {
+		details.RmtInf = &RemittanceInformation16{Ustrd: []string{truncate(transaction.Description, max140Text)}}
+	}
+	entry.NtryDtls = &EntryDetails9{TxDtls: []EntryTransaction10{details}}
+	return entry
+}
+
+// bankTransactionCode classifies transfers as issued or received book transfers and the opening balance as an
+// account opening
+func bankTransactionCode(activity models.AccountActivity) BankTransactionCodeStructure4 {
+	switch {
+	case activity.Type == types.ActivityTypeOpening:
+		return bankTransactionCodeOf("ACMT", "OPCL", "ACCO")
+	case activity.Direction == types.ActivityDirectionDebit:
+		return bankTransactionCodeOf("PMNT", "ICDT", "BOOK")
+	default:
+		return bankTransactionCodeOf("PMNT", "RCDT", "BOOK")
+	}
+}
+
+func bankTransactionCodeOf(domain, family, subFamily string) BankTransactionCodeStructure4 {
+	return BankTransactionCodeStructure4{Domn: BankTransactionCodeStructure5{
+		Cd:   domain,
+		Fmly: BankTransactionCodeStructure6{Cd: family, SubFmlyCd: subFamily},
+	}}
+}
+
+// signedAmount returns the magnitude of an amount and whether it is a credit or a debit
+func signedAmount(amount int64) (int64, string) {
+	if amount < 0 {
+		return -amount, debit
+	}
+	return amount, credit
+}
+
+// formatAmount formats an amount of smallest currency units in major units, e.g. 1234 cents as 12.34
+func formatAmount(amount int64, currency string) string {
+	return types.Money{Amount: types.AccountBalance(amount), Currency: currency}.Format()
+}
+
+func formatDateTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05Z")
+}
+
+func formatDate(t time.Time) string {
+	return t.UTC().Format(time.DateOnly)
+}
+
+// truncate shortens a text to the length limit of its element, counted in characters
+func truncate(text string, limit int) string {
+	if utf8.RuneCountInString(text) <= limit {
+		return text
+	}
+	return string([]rune(text)[:limit])
+}
@@ -0,0 +1,148 @@
+package iso20022
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielkhtse/supreme-adventure/common/models"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testFrom = time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	testTo   = testFrom.AddDate(0, 0, 1)
+)
+
+// testReport has an opening credit, a debit with a known transaction and a credit whose transaction is unknown
+func testReport() Report {
+	return Report{
+		MessageID: "STMT-42-20261018",
+		CreatedAt: time.Date(2026, 10, 19, 1, 0, 0, 0, time.UTC),
+		Account:   models.Account{ID: 42, Currency: "USD"},
+		From:      testFrom,
+		To:        testTo,
+		Activities: []models.AccountActivity{
+			{ID: 1, AccountID: 42, Type: types.ActivityTypeOpening, Direction: types.ActivityDirectionCredit, Amount: 10000, BalanceAfter: 10000, CreatedAt: testFrom.Add(time.Hour)},
+			{ID: 2, AccountID: 42, Type: types.ActivityTypeTransfer, Direction: types.ActivityDirectionDebit, CounterpartyAccountID: 7, Amount: 2550, BalanceAfter: 7450, TransactionID: 900, TransferID: "invoice-2026-118", CreatedAt: testFrom.Add(2 * time.Hour)},
+			{ID: 3, AccountID: 42, Type: types.ActivityTypeTransfer, Direction: types.ActivityDirectionCredit, CounterpartyAccountID: 8, Amount: 5, BalanceAfter: 7455, TransactionID: 901, CreatedAt: testFrom.Add(3 * time.Hour)},
+		},
+		Transactions: map[types.TransactionID]models.Transaction{
+			900: {ID: 900, Description: "Invoice 2026-118 " + strings.Repeat("x", 200)},
+		},
+	}
+}
+
+func TestUnitFormatAmount(t *testing.T) {
+	for amount, expected := range map[int64]string{
+		0:      "0.00",
+		5:      "0.05",
+		99:     "0.99",
+		100:    "1.00",
+		123456: "1234.56",
+	} {
+		assert.Equal(t, expected, formatAmount(amount, "USD"))
+	}
+}
+
+func TestUnitNewStatement(t *testing.T) {
+	document := NewStatement(Statement{Report: testReport(), ClosingBalance: 7455})
+
+	statement := document.BkToCstmrStmt.Stmt[0]
+	assert.Equal(t, "STMT-42-20261018", document.BkToCstmrStmt.GrpHdr.MsgId)
+	assert.Equal(t, "2026-10-18T23:59:59Z", statement.FrToDt.ToDtTm)
+	require.Len(t, statement.Bal, 2)
+	assert.Equal(t, "0.00", statement.Bal[0].Amt.Value)
+	assert.Equal(t, "2026-10-18", statement.Bal[0].Dt.Dt)
+	assert.Equal(t, "CLBD", statement.Bal[1].Tp.CdOrPrtry.Cd)
+	assert.Equal(t, "74.55", statement.Bal[1].Amt.Value)
+	assert.Equal(t, "2026-10-18", statement.Bal[1].Dt.Dt)
+
+	assert.Equal(t, "3", statement.TxsSummry.TtlNtries.NbOfNtries)
+	assert.Equal(t, "125.55", statement.TxsSummry.TtlNtries.Sum)
+	assert.Equal(t, AmountAndDirection35{Amt: "74.55", CdtDbtInd: "CRDT"}, statement.TxsSummry.TtlNtries.TtlNetNtry)
+	assert.Equal(t, "25.50", statement.TxsSummry.TtlDbtNtries.Sum)
+
+	require.Len(t, statement.Ntry, 3)
+	assert.Nil(t, statement.Ntry[0].NtryDtls)
+	assert.Equal(t, "OPCL", statement.Ntry[0].BkTxCd.Domn.Fmly.Cd)
+
+	debit := statement.Ntry[1]
+	assert.Equal(t, "DBIT", debit.CdtDbtInd)
+	assert.Equal(t, "ICDT", debit.BkTxCd.Domn.Fmly.Cd)
+	details := debit.NtryDtls.TxDtls[0]
+	assert.Equal(t, TransactionReferences6{AcctSvcrRef: "2", EndToEndId: "invoice-2026-118", TxId: "900"}, details.Refs)
+	assert.Equal(t, "7", details.RltdPties.CdtrAcct.Id.Othr.Id)
+	assert.Nil(t, details.RltdPties.DbtrAcct)
+	assert.Len(t, details.RmtInf.Ustrd[0], max140Text)
+
+	credit := statement.Ntry[2].NtryDtls.TxDtls[0]
+	assert.Equal(t, "NOTPROVIDED", credit.Refs.EndToEndId)
+	assert.Equal(t, "8", credit.RltdPties.DbtrAcct.Id.Othr.Id)
+	assert.Nil(t, credit.RmtInf)
+
+	var buf bytes.Buffer
+	require.NoError(t, Encode(&buf, document))
+	assert.Contains(t, buf.String(), `<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.08">`)
+	assert.Contains(t, buf.String(), `<Amt Ccy="USD">25.50</Amt>`)
+	validateXML(t, "camt.053.001.08.xsd", buf.Bytes())
+}
+
+func TestUnitNewNotification(t *testing.T) {
+	report := testReport()
+	report.Activities = report.Activities[1:2]
+	document := NewNotification(report)
+
+	notification := document.BkToCstmrDbtCdtNtfctn.Ntfctn[0]
+	assert.Equal(t, "42", notification.Acct.Id.Othr.Id)
+	assert.Equal(t, "USD", notification.Acct.Ccy)
+	require.Len(t, notification.Ntry, 1)
+	assert.Equal(t, AmountAndDirection35{Amt: "25.50", CdtDbtInd: "DBIT"}, notification.TxsSummry.TtlNtries.TtlNetNtry)
+
+	var buf bytes.Buffer
+	require.NoError(t, Encode(&buf, document))
+	validateXML(t, "camt.054.001.08.xsd", buf.Bytes())
+
+	t.Run("Empty", func(t *testing.T) {
+		report.Activities = nil
+		buf.Reset()
+		require.NoError(t, Encode(&buf, NewNotification(report)))
+		validateXML(t, "camt.054.001.08.xsd", buf.Bytes())
+	})
+
+	t.Run("Invalid document", func(t *testing.T) {
+		invalid := bytes.Replace(buf.Bytes(), []byte("<CdtDbtInd>CRDT</CdtDbtInd>"), []byte("<CdtDbtInd>CREDIT</CdtDbtInd>"), 1)
+		require.NotEqual(t, buf.Bytes(), invalid)
+		assert.Error(t, xmllint(t, "camt.054.001.08.xsd", invalid))
+	})
+}
+
+// validateXML validates a document against an official ISO 20022 schema of testdata with xmllint, the test fails
+// without it
+func validateXML(t *testing.T, schema string, document []byte) {
+	t.Helper()
+	assert.NoError(t, xmllint(t, schema, document))
+}
+
+func xmllint(t *testing.T, schema string, document []byte) error {
+	t.Helper()
+	path, err := exec.LookPath("xmllint")
+	if err != nil {
+		t.Fatal("xmllint is required to validate ISO 20022 documents against their schemas")
+	}
+
+	file := filepath.Join(t.TempDir(), "document.xml")
+	require.NoError(t, os.WriteFile(file, document, 0o600))
+
+	output, err := exec.Command(path, "--noout", "--schema", filepath.Join("testdata", schema), file).CombinedOutput()
+	if err != nil {
+		t.Log(string(output))
+	}
+	return err
+}
@@ -0,0 +1,342 @@
+package iso20022
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielkhtse/supreme-adventure/common/types"
+)
+
+// PaymentInitiationMessage is the message name of the supported payment initiations
+const PaymentInitiationMessage = "pain.001.001.10"
+
+const paymentInitiationNamespace = "urn:iso:std:iso:20022:tech:xsd:" + PaymentInitiationMessage
+
+// paymentMethodTransfer is the PmtMtd of credit transfers
+const paymentMethodTransfer = "TRF"
+
+// Status codes of payment status reports
+const (
+	StatusAcceptedSettlementCompleted = "ACSC"
+	StatusRejected                    = "RJCT"
+	StatusPartiallyAccepted           = "PART"
+	StatusPending                     = "PDNG"
+)
+
+// Reason codes of rejected payments, from the ISO 20022 external status reason code set
+const (
+	ReasonIncorrectAccountNumber = "AC01"
+	ReasonBlockedAccount         = "AC06"
+	ReasonTransactionForbidden   = "AG01"
+	ReasonNotAllowedCurrency     = "AM03"
+	ReasonInsufficientFunds      = "AM04"
+	ReasonDuplication            = "AM05"
+	ReasonInvalidControlSum      = "AM10"
+	ReasonInvalidAmount          = "AM12"
+	ReasonInvalidNumberOfTxs     = "AM18"
+	ReasonInvalidDate            = "DT01"
+	ReasonDuplicateMessageID     = "DU01"
+	ReasonInvalidFileFormat      = "FF01"
+	ReasonNarrative              = "NARR"
+)
+
+// StatusReason is why a payment initiation or one of its transfers was not accepted
+type StatusReason struct {
+	Code string
+
+	// AdditionalInformation explains the reason in words, at most 105 characters are reported
+	AdditionalInformation string
+}
+
+// PaymentInitiation is a parsed pain.001 customer credit transfer initiation
+type PaymentInitiation struct {
+	MessageID            string
+	CreatedAt            string
+	NumberOfTransactions string
+	ControlSum           string
+
+	PaymentInstructions []PaymentInstruction
+
+	// Rejection is set when the initiation is rejected as a whole, none of its transfers is executed then
+	Rejection *StatusReason
+}
+
+// PaymentInstruction is a batch of credit transfers from one debtor account
+type PaymentInstruction struct {
+	ID                   string
+	NumberOfTransactions string
+	ControlSum           string
+	Transfers            []CreditTransfer
+}
+
+// CreditTransfer is one credit transfer of a payment initiation and its outcome
+type CreditTransfer struct {
+	InstructionID string
+	EndToEndID    string
+
+	DebtorAccountID   types.AccountID
+	CreditorAccountID types.AccountID
+
+	// Amount in the smallest units of the currency
+	Amount   types.AccountBalance
+	Currency string
+
+	// RequestedExecutionDate is the day the transfer is to be executed on, in UTC
+	RequestedExecutionDate time.Time
+
+	RemittanceInformation string
+
+	// Status is StatusRejected with a Reason for transfers found invalid by ParsePaymentInitiation, and
+	// empty for the others until they are executed
+	Status string
+	Reason *StatusReason
+
+	// TransactionID of the executed transfer
+	TransactionID types.TransactionID
+}
+
+// Reject sets the status of the transfer to rejected for the given reason
+func (t *CreditTransfer) Reject(code string, format string, args ...any) {
+	t.Status = StatusRejected
+	t.Reason = &StatusReason{Code: code, AdditionalInformation: fmt.Sprintf(format, args...)}
+}
+
+// paymentInitiationDocument is the subset of a pain.001.001.10 document read by ParsePaymentInitiation
+type paymentInitiationDocument struct {
+	XMLName          xml.Name `xml:"Document"`
+	CstmrCdtTrfInitn struct {
+		GrpHdr struct {
+			MsgId   string `xml:"MsgId"`
+			CreDtTm string `xml:"CreDtTm"`
+			NbOfTxs string `xml:"NbOfTxs"`
+			CtrlSum string `xml:"CtrlSum"`
+		} `xml:"GrpHdr"`
+		PmtInf []struct {
+			PmtInfId    string                 `xml:"PmtInfId"`
+			PmtMtd      string                 `xml:"PmtMtd"`
+			NbOfTxs     string                 `xml:"NbOfTxs"`
+			CtrlSum     string                 `xml:"CtrlSum"`
+			ReqdExctnDt DateAndDateTime2Choice `xml:"ReqdExctnDt"`
+			DbtrAcct    *CashAccount38         `xml:"DbtrAcct"`
+			CdtTrfTxInf []struct {
+				PmtId struct {
+					InstrId    string `xml:"InstrId"`
+					EndToEndId string `xml:"EndToEndId"`
+				} `xml:"PmtId"`
+				Amt struct {
+					InstdAmt *ActiveOrHistoricCurrencyAndAmount `xml:"InstdAmt"`
+				} `xml:"Amt"`
+				CdtrAcct *CashAccount38           `xml:"CdtrAcct"`
+				RmtInf   *RemittanceInformation16 `xml:"RmtInf"`
+			} `xml:"CdtTrfTxInf"`
+		} `xml:"PmtInf"`
+	} `xml:"CstmrCdtTrfInitn"`
+}
+
+// ParsePaymentInitiation reads a pain.001.001.10 file. Files that cannot be reported on, such as malformed XML,
+// other messages and files without a message ID, are an error. Inconsistent files are returned with a Rejection
+// and invalid transfers with a rejected status, all other transfers are left to be executed.
+func ParsePaymentInitiation(data []byte) (*PaymentInitiation, error) {
+	var document paymentInitiationDocument
+	if err := xml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("invalid XML: %w", err)
+	}
+	if document.XMLName.Space != paymentInitiationNamespace {
+		return nil, fmt.Errorf("unsupported message %q, expected a %s document", document.XMLName.Space, PaymentInitiationMessage)
+	}
+
+	message := document.CstmrCdtTrfInitn
+	initiation := &PaymentInitiation{
+		MessageID:            strings.TrimSpace(message.GrpHdr.MsgId),
+		CreatedAt:            strings.TrimSpace(message.GrpHdr.CreDtTm),
+		NumberOfTransactions: strings.TrimSpace(message.GrpHdr.NbOfTxs),
+		ControlSum:           strings.TrimSpace(message.GrpHdr.CtrlSum),
+	}
+	if initiation.MessageID == "" {
+		return nil, errors.New("the group header has no MsgId")
+	}
+	if len(initiation.MessageID) > max35Text {
+		return nil, errors.New("MsgId is longer than 35 characters")
+	}
+
+	reject := func(code string, format string, args ...any) (*PaymentInitiation, error) {
+		initiation.Rejection = &StatusReason{Code: code, AdditionalInformation: fmt.Sprintf(format, args...)}
+		return initiation, nil
+	}
+	if len(message.PmtInf) == 0 {
+		return reject(ReasonInvalidFileFormat, "the file has no payment information")
+	}
+
+	endToEndIDs := map[string]bool{}
+	count := 0
+	sum := new(big.Rat)
+	for _, payment := range message.PmtInf {
+		instruction := PaymentInstruction{
+			ID:                   strings.TrimSpace(payment.PmtInfId),
+			NumberOfTransactions: strings.TrimSpace(payment.NbOfTxs),
+			ControlSum:           strings.TrimSpace(payment.CtrlSum),
+		}
+		if instruction.ID == "" {
+			return reject(ReasonInvalidFileFormat, "payment information without PmtInfId")
+		}
+		if payment.PmtMtd != paymentMethodTransfer {
+			return reject(ReasonInvalidFileFormat, "payment information %s: PmtMtd must be TRF", instruction.ID)
+		}
+		if len(payment.CdtTrfTxInf) == 0 {
+			return reject(ReasonInvalidFileFormat, "payment information %s has no credit transfer", instruction.ID)
+		}
+
+		executionDate, dateErr := parseRequestedExecutionDate(payment.ReqdExctnDt)
+		debtor, debtorErr := ledgerAccountID(payment.DbtrAcct)
+
+		instructionSum := new(big.Rat)
+		for _, transaction := range payment.CdtTrfTxInf {
+			transfer := CreditTransfer{
+				InstructionID:          strings.TrimSpace(transaction.PmtId.InstrId),
+				EndToEndID:             strings.TrimSpace(transaction.PmtId.EndToEndId),
+				DebtorAccountID:        debtor,
+				RequestedExecutionDate: executionDate,
+			}
+			if transaction.RmtInf != nil {
+				transfer.RemittanceInformation = strings.TrimSpace(strings.Join(transaction.RmtInf.Ustrd, " "))
+			}
+			if transaction.Amt.InstdAmt != nil {
+				if value, ok := new(big.Rat).SetString(strings.TrimSpace(transaction.Amt.InstdAmt.Value)); ok {
+					instructionSum.Add(instructionSum, value)
+				}
+			}
+
+			creditor, creditorErr := ledgerAccountID(transaction.CdtrAcct)
+			transfer.CreditorAccountID = creditor
+
+			switch {
+			case transfer.EndToEndID == "":
+				transfer.Reject(ReasonInvalidFileFormat, "EndToEndId is required")
+			case endToEndIDs[transfer.EndToEndID]:
+				transfer.Reject(ReasonDuplication, "duplicate EndToEndId %s", transfer.EndToEndID)
+			case dateErr != nil:
+				transfer.Reject(ReasonInvalidDate, "ReqdExctnDt: %v", dateErr)
+			case debtorErr != nil:
+				transfer.Reject(ReasonIncorrectAccountNumber, "DbtrAcct: %v", debtorErr)
+			case creditorErr != nil:
+				transfer.Reject(ReasonIncorrectAccountNumber, "CdtrAcct: %v", creditorErr)
+			case transaction.Amt.InstdAmt == nil:
+				transfer.Reject(ReasonInvalidAmount, "only instructed amounts (InstdAmt) are supported")
+			default:
+				transfer.Currency = transaction.Amt.InstdAmt.Ccy
+				amount, err := parseAmount(transaction.Amt.InstdAmt.Value, transfer.Currency)
+				switch {
+				case errors.Is(err, errUnsupportedCurrency):
+					transfer.Reject(ReasonNotAllowedCurrency, "currency %s is not supported", transfer.Currency)
+				case err != nil:
+					transfer.Reject(ReasonInvalidAmount, "InstdAmt: %v", err)
+				default:
+					transfer.Amount = types.AccountBalance(amount)
+				}
+			}
+			if transfer.EndToEndID != "" {
+				endToEndIDs[transfer.EndToEndID] = true
+			}
+			instruction.Transfers = append(instruction.Transfers, transfer)
+		}
+
+		if reason := checkTotals(instruction.NumberOfTransactions, instruction.ControlSum, len(instruction.Transfers), instructionSum); reason != nil {
+			reason.AdditionalInformation = "payment information " + instruction.ID + ": " + reason.AdditionalInformation
+			initiation.Rejection = reason
+			return initiation, nil
+		}
+		count += len(instruction.Transfers)
+		sum.Add(sum, instructionSum)
+		initiation.PaymentInstructions = append(initiation.PaymentInstructions, instruction)
+	}
+
+	if initiation.NumberOfTransactions == "" {
+		return reject(ReasonInvalidNumberOfTxs, "the group header has no NbOfTxs")
+	}
+	initiation.Rejection = checkTotals(initiation.NumberOfTransactions, initiation.ControlSum, count, sum)
+	return initiation, nil
+}
+
+// Transfers returns every transfer of the initiation, in file order
+func (p *PaymentInitiation) Transfers() []*CreditTransfer {
+	var transfers []*CreditTransfer
+	for i := range p.PaymentInstructions {
+		for j := range p.PaymentInstructions[i].Transfers {
+			transfers = append(transfers, &p.PaymentInstructions[i].Transfers[j])
+		}
+	}
+	return transfers
+}
+
+// checkTotals compares the declared number of transactions and control sum, when given, with the actual ones
+func checkTotals(numberOfTransactions string, controlSum string, count int, sum *big.Rat) *StatusReason {
+	if numberOfTransactions != "" && numberOfTransactions != strconv.Itoa(count) {
+		return &StatusReason{Code: ReasonInvalidNumberOfTxs, AdditionalInformation: fmt.Sprintf("NbOfTxs is %s, the file has %d transactions", numberOfTransactions, count)}
+	}
+	if controlSum == "" {
+		return nil
+	}
+	declared, ok := new(big.Rat).SetString(controlSum)
+	if !ok || declared.Cmp(sum) != 0 {
+		return &StatusReason{Code: ReasonInvalidControlSum, AdditionalInformation: fmt.Sprintf("CtrlSum is %s, the amounts add up to %s", controlSum, sum.FloatString(2))}
+	}
+	return nil
+}
+
+// ledgerAccountID reads the ledger account ID of an account identification, IBANs are not ledger accounts
+func ledgerAccountID(account *CashAccount38) (types.AccountID, error) {
+	switch {
+	case account == nil:
+		return 0, errors.New("account is required")
+	case account.Id.IBAN != "":
+		return 0, errors.New("IBAN accounts are not supported, identify the account by its ID under Othr")
+	}
+	id, err := strconv.ParseUint(strings.TrimSpace(account.Id.Othr.Id), 10, 64)
+	if err != nil || id == 0 {
+		return 0, fmt.Errorf("%q is not an account ID", account.Id.Othr.Id)
+	}
+	return types.AccountID(id), nil
+}
+
+func parseRequestedExecutionDate(date DateAndDateTime2Choice) (time.Time, error) {
+	switch {
+	case date.Dt != "":
+		return time.Parse(time.DateOnly, strings.TrimSpace(date.Dt))
+	case date.DtTm != "":
+		//ISODateTime may omit the time zone, it is then taken as UTC
+		t, err := time.Parse(time.RFC3339, strings.TrimSpace(date.DtTm))
+		if err != nil {
+			if t, err = time.Parse("2006-01-02T15:04:05", strings.TrimSpace(date.DtTm)); err != nil {
+				return time.Time{}, err
+			}
+		}
+		t = t.UTC()
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+	default:
+		return time.Time{}, errors.New("date is required")
+	}
+}
+
+var errUnsupportedCurrency = errors.New("unsupported currency")
+
+// parseAmount converts a positive decimal amount in major units to the smallest units of the currency, amounts
+// with more decimals than the currency has are rejected rather than rounded
+func parseAmount(value string, currency string) (int64, error) {
+	if currency != types.DefaultCurrency {
+		return 0, errUnsupportedCurrency
+	}
+
+	money, err := types.ParseMoney(value, currency)
+	if err != nil {
+		return 0, err
+	}
+	if money.Amount <= 0 {
+		return 0, errors.New("the amount must be positive")
+	}
+	return int64(money.Amount), nil
+}
@@ -0,0 +1,153 @@
+package iso20022
+
+import (
+	"encoding/xml"
+	"strconv"
+	"time"
+)
+
+// max105Text is the length of the additional information of a status reason
+const max105Text = 105
+
+// StatusReportDocument is a pain.002.001.11 customer payment status report
+type StatusReportDocument struct {
+	XMLName        xml.Name                       `xml:"urn:iso:std:iso:20022:tech:xsd:pain.002.001.11 Document"`
+	CstmrPmtStsRpt CustomerPaymentStatusReportV11 `xml:"CstmrPmtStsRpt"`
+}
+
+type CustomerPaymentStatusReportV11 struct {
+	GrpHdr            GroupHeader86                  `xml:"GrpHdr"`
+	OrgnlGrpInfAndSts OriginalGroupHeader17          `xml:"OrgnlGrpInfAndSts"`
+	OrgnlPmtInfAndSts []OriginalPaymentInstruction38 `xml:"OrgnlPmtInfAndSts"`
+}
+
+type GroupHeader86 struct {
+	MsgId   string `xml:"MsgId"`
+	CreDtTm string `xml:"CreDtTm"`
+}
+
+// OriginalGroupHeader17 is the status of the payment initiation as a whole
+type OriginalGroupHeader17 struct {
+	OrgnlMsgId    string                           `xml:"OrgnlMsgId"`
+	OrgnlMsgNmId  string                           `xml:"OrgnlMsgNmId"`
+	OrgnlCreDtTm  string                           `xml:"OrgnlCreDtTm,omitempty"`
+	OrgnlNbOfTxs  string                           `xml:"OrgnlNbOfTxs,omitempty"`
+	OrgnlCtrlSum  string                           `xml:"OrgnlCtrlSum,omitempty"`
+	GrpSts        string                           `xml:"GrpSts"`
+	StsRsnInf     []StatusReasonInformation12      `xml:"StsRsnInf"`
+	NbOfTxsPerSts []NumberOfTransactionsPerStatus5 `xml:"NbOfTxsPerSts"`
+}
+
+type StatusReasonInformation12 struct {
+	Rsn      StatusReason6Choice `xml:"Rsn"`
+	AddtlInf string              `xml:"AddtlInf,omitempty"`
+}
+
+type StatusReason6Choice struct {
+	Cd string `xml:"Cd"`
+}
+
+type NumberOfTransactionsPerStatus5 struct {
+	DtldNbOfTxs string `xml:"DtldNbOfTxs"`
+	DtldSts     string `xml:"DtldSts"`
+}
+
+// OriginalPaymentInstruction38 is the status of a payment information block and of each of its transfers
+type OriginalPaymentInstruction38 struct {
+	OrgnlPmtInfId string                  `xml:"OrgnlPmtInfId"`
+	OrgnlNbOfTxs  string                  `xml:"OrgnlNbOfTxs,omitempty"`
+	OrgnlCtrlSum  string                  `xml:"OrgnlCtrlSum,omitempty"`
+	PmtInfSts     string                  `xml:"PmtInfSts"`
+	TxInfAndSts   []PaymentTransaction126 `xml:"TxInfAndSts"`
+}
+
+// PaymentTransaction126 is the status of a transfer, executed transfers reference their transaction as AcctSvcrRef
+type PaymentTransaction126 struct {
+	OrgnlInstrId    string                      `xml:"OrgnlInstrId,omitempty"`
+	OrgnlEndToEndId string                      `xml:"OrgnlEndToEndId,omitempty"`
+	TxSts           string                      `xml:"TxSts"`
+	StsRsnInf       []StatusReasonInformation12 `xml:"StsRsnInf"`
+	AcctSvcrRef     string                      `xml:"AcctSvcrRef,omitempty"`
+}
+
+// NewStatusReport builds the pain.002 report of a payment initiation. A rejected initiation is reported with its
+// group status only, otherwise every transfer is reported with its status.
+func NewStatusReport(messageID string, createdAt time.Time, initiation *PaymentInitiation) *StatusReportDocument {
+	group := OriginalGroupHeader17{
+		OrgnlMsgId:   initiation.MessageID,
+		OrgnlMsgNmId: PaymentInitiationMessage,
+		OrgnlCreDtTm: initiation.CreatedAt,
+		OrgnlNbOfTxs: initiation.NumberOfTransactions,
+		OrgnlCtrlSum: initiation.ControlSum,
+	}
+	document := &StatusReportDocument{CstmrPmtStsRpt: CustomerPaymentStatusReportV11{
+		GrpHdr: GroupHeader86{MsgId: truncate(messageID, max35Text), CreDtTm: formatDateTime(createdAt)},
+	}}
+
+	if initiation.Rejection != nil {
+		group.GrpSts = StatusRejected
+		group.StsRsnInf = []StatusReasonInformation12{newStatusReason(initiation.Rejection)}
+		document.CstmrPmtStsRpt.OrgnlGrpInfAndSts = group
+		return document
+	}
+
+	var statuses []string
+	counts := map[string]int{}
+	for _, instruction := range initiation.PaymentInstructions {
+		payment := OriginalPaymentInstruction38{
+			OrgnlPmtInfId: instruction.ID,
+			OrgnlNbOfTxs:  instruction.NumberOfTransactions,
+			OrgnlCtrlSum:  instruction.ControlSum,
+		}
+		var instructionStatuses []string
+		for _, transfer := range instruction.Transfers {
+			transaction := PaymentTransaction126{
+				OrgnlInstrId:    transfer.InstructionID,
+				OrgnlEndToEndId: transfer.EndToEndID,
+				TxSts:           transfer.Status,
+			}
+			if transfer.Reason != nil {
+				transaction.StsRsnInf = []StatusReasonInformation12{newStatusReason(transfer.Reason)}
+			}
+			if transfer.TransactionID != 0 {
+				transaction.AcctSvcrRef = strconv.FormatUint(uint64(transfer.TransactionID), 10)
+			}
+			payment.TxInfAndSts = append(payment.TxInfAndSts, transaction)
+
+			if counts[transfer.Status] == 0 {
+				statuses = append(statuses, transfer.Status)
+			}
+			counts[transfer.Status]++
+			instructionStatuses = append(instructionStatuses, transfer.Status)
+		}
+		payment.PmtInfSts = combinedStatus(instructionStatuses)
+		document.CstmrPmtStsRpt.OrgnlPmtInfAndSts = append(document.CstmrPmtStsRpt.OrgnlPmtInfAndSts, payment)
+	}
+
+	group.GrpSts = combinedStatus(statuses)
+	for _, status := range statuses {
+		group.NbOfTxsPerSts = append(group.NbOfTxsPerSts, NumberOfTransactionsPerStatus5{
+			DtldNbOfTxs: strconv.Itoa(counts[status]),
+			DtldSts:     status,
+		})
+	}
+	document.CstmrPmtStsRpt.OrgnlGrpInfAndSts = group
+	return document
+}
+
+// combinedStatus is the status shared by all transfers, or partially accepted when they differ
+func combinedStatus(statuses []string) string {
+	for _, status := range statuses[1:] {
+		if status != statuses[0] {
+			return StatusPartiallyAccepted
+		}
+	}
+	return statuses[0]
+}
+
+func newStatusReason(reason *StatusReason) StatusReasonInformation12 {
+	return StatusReasonInformation12{
+		Rsn:      StatusReason6Choice{Cd: reason.Code},
+		AddtlInf: truncate(reason.AdditionalInformation, max105Text),
+	}
+}
@@ -0,0 +1,155 @@
+package iso20022
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readPaymentInitiation(t *testing.T) []byte {
+	data, err := os.ReadFile(filepath.Join("testdata", "pain.001.001.10.xml"))
+	require.NoError(t, err)
+	return data
+}
+
+func TestUnitParsePaymentInitiation(t *testing.T) {
+	data := readPaymentInitiation(t)
+
+	initiation, err := ParsePaymentInitiation(data)
+	require.NoError(t, err)
+	assert.Nil(t, initiation.Rejection)
+	assert.Equal(t, "ERP-20261019-0001", initiation.MessageID)
+	require.Len(t, initiation.PaymentInstructions, 2)
+
+	transfers := initiation.Transfers()
+	require.Len(t, transfers, 4)
+
+	assert.Equal(t, CreditTransfer{
+		InstructionID:          "INSTR-1",
+		EndToEndID:             "INV-118",
+		DebtorAccountID:        1001,
+		CreditorAccountID:      2002,
+		Amount:                 100000,
+		Currency:               "USD",
+		RequestedExecutionDate: time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+		RemittanceInformation:  "Invoice 118 October delivery",
+	}, *transfers[0])
+
+	assert.Equal(t, StatusRejected, transfers[1].Status)
+	assert.Equal(t, &StatusReason{Code: ReasonInvalidAmount, AdditionalInformation: "InstdAmt: USD has at most 2 decimals"}, transfers[1].Reason)
+	assert.Equal(t, ReasonIncorrectAccountNumber, transfers[2].Reason.Code)
+	assert.Equal(t, ReasonNotAllowedCurrency, transfers[3].Reason.Code)
+	assert.Equal(t, time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC), transfers[3].RequestedExecutionDate)
+
+	t.Run("Group rejections", func(t *testing.T) {
+		for edit, code := range map[[2]string]string{
+			{"<NbOfTxs>4</NbOfTxs>", "<NbOfTxs>5</NbOfTxs>"}:                                   ReasonInvalidNumberOfTxs,
+			{"<NbOfTxs>3</NbOfTxs>", "<NbOfTxs>2</NbOfTxs>"}:                                   ReasonInvalidNumberOfTxs,
+			{"<CtrlSum>1260.755</CtrlSum>", "<CtrlSum>1260.75</CtrlSum>"}:                      ReasonInvalidControlSum,
+			{"<PmtMtd>TRF</PmtMtd>", "<PmtMtd>CHK</PmtMtd>"}:                                   ReasonInvalidFileFormat,
+			{"<NbOfTxs>4</NbOfTxs>", ""}:                                                       ReasonInvalidNumberOfTxs,
+			{"<PmtInfId>BATCH-2</PmtInfId>", "<PmtInfId></PmtInfId>"}:                          ReasonInvalidFileFormat,
+			{"<CtrlSum>1250.755</CtrlSum>", "<CtrlSum>1250.755</CtrlSum><CtrlSum>1</CtrlSum>"}: ReasonInvalidControlSum,
+		} {
+			initiation, err := ParsePaymentInitiation([]byte(strings.Replace(string(data), edit[0], edit[1], 1)))
+			require.NoError(t, err)
+			require.NotNil(t, initiation.Rejection, edit[1])
+			assert.Equal(t, code, initiation.Rejection.Code, edit[1])
+		}
+	})
+
+	t.Run("Duplicate EndToEndId", func(t *testing.T) {
+		initiation, err := ParsePaymentInitiation([]byte(strings.Replace(string(data), "INV-121", "INV-118", 1)))
+		require.NoError(t, err)
+		assert.Equal(t, ReasonDuplication, initiation.Transfers()[3].Reason.Code)
+	})
+
+	t.Run("Unreadable files", func(t *testing.T) {
+		for _, document := range []string{
+			"not xml",
+			strings.Replace(string(data), "pain.001.001.10", "pain.001.001.03", 1),
+			strings.Replace(string(data), "<MsgId>ERP-20261019-0001</MsgId>", "", 1),
+		} {
+			_, err := ParsePaymentInitiation([]byte(document))
+			assert.Error(t, err)
+		}
+	})
+
+	validateXML(t, "pain.001.001.10.xsd", data)
+}
+
+func TestUnitParseAmount(t *testing.T) {
+	for value, expected := range map[string]int64{
+		"1":         100,
+		"1.5":       150,
+		"+0.05":     5,
+		".5":        50,
+		"12.340000": 1234,
+		"0001.00":   100,
+	} {
+		amount, err := parseAmount(value, "USD")
+		require.NoError(t, err, value)
+		assert.Equal(t, expected, amount, value)
+	}
+
+	for _, value := range []string{"", "0", "0.00", "-1", "1.234", "1,5", "1e3", "99999999999999999999"} {
+		_, err := parseAmount(value, "USD")
+		assert.Error(t, err, value)
+	}
+
+	_, err := parseAmount("1", "EUR")
+	assert.ErrorIs(t, err, errUnsupportedCurrency)
+}
+
+func TestUnitNewStatusReport(t *testing.T) {
+	createdAt := time.Date(2026, 10, 19, 8, 31, 0, 0, time.UTC)
+
+	initiation, err := ParsePaymentInitiation(readPaymentInitiation(t))
+	require.NoError(t, err)
+	transfers := initiation.Transfers()
+	transfers[0].Status = StatusAcceptedSettlementCompleted
+	transfers[0].TransactionID = types.TransactionID(900)
+	transfers[3].Reject(ReasonInvalidDate, "future-dated payments are not supported")
+
+	document := NewStatusReport("PSR-1", createdAt, initiation)
+	report := document.CstmrPmtStsRpt
+	assert.Equal(t, "ERP-20261019-0001", report.OrgnlGrpInfAndSts.OrgnlMsgId)
+	assert.Equal(t, StatusPartiallyAccepted, report.OrgnlGrpInfAndSts.GrpSts)
+	assert.Equal(t, []NumberOfTransactionsPerStatus5{
+		{DtldNbOfTxs: "1", DtldSts: "ACSC"},
+		{DtldNbOfTxs: "3", DtldSts: "RJCT"},
+	}, report.OrgnlGrpInfAndSts.NbOfTxsPerSts)
+
+	require.Len(t, report.OrgnlPmtInfAndSts, 2)
+	assert.Equal(t, StatusPartiallyAccepted, report.OrgnlPmtInfAndSts[0].PmtInfSts)
+	assert.Equal(t, StatusRejected, report.OrgnlPmtInfAndSts[1].PmtInfSts)
+	assert.Equal(t, PaymentTransaction126{
+		OrgnlInstrId:    "INSTR-1",
+		OrgnlEndToEndId: "INV-118",
+		TxSts:           "ACSC",
+		AcctSvcrRef:     "900",
+	}, report.OrgnlPmtInfAndSts[0].TxInfAndSts[0])
+	assert.Equal(t, "DT01", report.OrgnlPmtInfAndSts[1].TxInfAndSts[0].StsRsnInf[0].Rsn.Cd)
+
+	var buf bytes.Buffer
+	require.NoError(t, Encode(&buf, document))
+	validateXML(t, "pain.002.001.11.xsd", buf.Bytes())
+
+	t.Run("Rejected initiation", func(t *testing.T) {
+		initiation.Rejection = &StatusReason{Code: ReasonDuplicateMessageID, AdditionalInformation: strings.Repeat("x", 200)}
+		document := NewStatusReport("PSR-2", createdAt, initiation)
+		assert.Equal(t, StatusRejected, document.CstmrPmtStsRpt.OrgnlGrpInfAndSts.GrpSts)
+		assert.Empty(t, document.CstmrPmtStsRpt.OrgnlPmtInfAndSts)
+
+		buf.Reset()
+		require.NoError(t, Encode(&buf, document))
+		validateXML(t, "pain.002.001.11.xsd", buf.Bytes())
+	})
+}
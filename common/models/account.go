@@ -10,13 +10,16 @@ import (
 )
 
 // Account represents a bank account in the system
+// Account listings page by (sort column, id), hence the composite indexes ending in id
 type Account struct {
-	ID             types.AccountID      `json:"id" gorm:"primaryKey" validate:"required"`
-	Balance        types.AccountBalance `json:"balance" gorm:"default:0" validate:"required,min=0"`          //We will store the smallest units for the currency (e.g. cents for USD)
-	InitialBalance types.AccountBalance `json:"initial_balance" gorm:"default:0" validate:"required,min=0"`  //audit trail for the initial balance. TODO: discussion, reflect from transactions for audit trail
-	Currency       string               `json:"currency" gorm:"default:'USD'" validate:"required,oneof=USD"` //We simply support USD for now
-	Status         types.AccountStatus  `json:"status" gorm:"type:varchar(10);default:'active';check:status IN ('active', 'inactive')" validate:"required,oneof=active inactive"`
-	CreatedAt      time.Time            `json:"createdAt" gorm:"autoCreateTime"`
+	ID             types.AccountID      `json:"id,string" gorm:"primaryKey;index:idx_accounts_created,priority:2;index:idx_accounts_balance,priority:2;index:idx_accounts_status_created,priority:3;index:idx_accounts_owner_created,priority:3" validate:"required"`
+	Balance        types.AccountBalance `json:"balance" gorm:"default:0;index:idx_accounts_balance,priority:1" validate:"min=0"` //We will store the smallest units for the currency (e.g. cents for USD)
+	InitialBalance types.AccountBalance `json:"initial_balance" gorm:"default:0" validate:"min=0"`                               //audit trail for the initial balance. TODO: discussion, reflect from transactions for audit trail
+	Currency       string               `json:"currency" gorm:"default:'USD'" validate:"required,oneof=USD"`                     //We simply support USD for now
+	Status         types.AccountStatus  `json:"status" gorm:"type:varchar(10);default:'active';check:status IN ('active', 'inactive');index:idx_accounts_status_created,priority:1" validate:"required,oneof=active inactive"`
+	Owner          string               `json:"owner,omitempty" gorm:"type:varchar(64);index:idx_accounts_owner_created,priority:1" validate:"max=64"` //opaque reference to the customer owning the account
+	Version        types.AccountVersion `json:"version" gorm:"not null;default:1"`                                                                     //bumped on every balance or status mutation for optimistic concurrency control
+	CreatedAt      time.Time            `json:"createdAt" gorm:"autoCreateTime;index:idx_accounts_created,priority:1;index:idx_accounts_status_created,priority:2;index:idx_accounts_owner_created,priority:2"`
 	UpdatedAt      time.Time            `json:"updatedAt" gorm:"autoUpdateTime"`
 }
 
@@ -28,14 +31,22 @@ func (a *Account) TableName() string {
 	return AccountTableName
 }
 
+// BalanceMoney returns the balance in the currency of the account
+func (a *Account) BalanceMoney() types.Money {
+	return types.Money{Amount: a.Balance, Currency: a.Currency}
+}
+
 func (a *Account) BeforeCreate(tx *gorm.DB) (err error) {
 	//default assignment
 	if a.Currency == "" {
-		a.Currency = "USD"
+		a.Currency = types.DefaultCurrency
 	}
 	if a.Status == "" {
 		a.Status = types.AccountStatusActive
 	}
+	if a.Version == 0 {
+		a.Version = 1
+	}
 
 	//if initial balance is set, set the balance to the initial balance
 	//TODO: discussion, drop this field and use transactions for audit trail
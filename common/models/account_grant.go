@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"github.com/danielkhtse/supreme-adventure/common/validation"
+)
+
+const AccountGrantTableName = "account_grants"
+
+// AccountGrant gives a principal a role on an account, a principal holds at most one role per account
+type AccountGrant struct {
+	AccountID types.AccountID   `gorm:"primaryKey;autoIncrement:false" json:"account_id,string" validate:"required"`
+	Principal string            `gorm:"primaryKey;type:varchar(255);index" json:"principal" validate:"required,max=255"` //subject of the caller, or api-key:<id>
+	Role      types.AccountRole `gorm:"type:varchar(20);not null" json:"role" validate:"required,oneof=owner operator auditor"`
+	GrantedBy string            `json:"granted_by"` //subject which granted the role
+	CreatedAt time.Time         `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time         `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (g *AccountGrant) TableName() string {
+	return AccountGrantTableName
+}
+
+func (g *AccountGrant) BeforeCreate(tx *gorm.DB) error {
+	return validation.ValidateStruct(g)
+}
+
+func (g *AccountGrant) BeforeUpdate(tx *gorm.DB) error {
+	return validation.ValidateStruct(g)
+}
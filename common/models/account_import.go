@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/danielkhtse/supreme-adventure/common/types"
+)
+
+const (
+	AccountImportTableName      = "account_imports"
+	AccountImportErrorTableName = "account_import_errors"
+)
+
+// AccountImport is a bulk account import job. Rows are processed in chunks, each chunk commits its accounts,
+// its row errors and the advanced checkpoint together so an interrupted import resumes after the last chunk.
+type AccountImport struct {
+	ID     types.AccountImportID     `gorm:"primaryKey" json:"id,string"`
+	Format types.AccountImportFormat `gorm:"type:varchar(10)" json:"format"`
+	DryRun bool                      `json:"dry_run"` //validate every row without creating accounts
+	Status types.AccountImportStatus `gorm:"type:varchar(20);index:idx_account_imports_due,priority:1" json:"status"`
+
+	ChunkSize int `json:"chunk_size"`
+	TotalRows int `json:"total_rows"`
+
+	// Checkpoint, the first ProcessedRows rows of the file are done
+	ProcessedRows int `json:"processed_rows"`
+
+	CreatedRows int    `json:"created_rows"` //accounts created, or that would be created by a dry run
+	SkippedRows int    `json:"skipped_rows"` //accounts that already existed with the same attributes
+	FailedRows  int    `json:"failed_rows"`  //rows listed in the error report
+	LastError   string `json:"last_error,omitempty"`
+
+	Data []byte `json:"-"` //uploaded file, kept until the import completes
+
+	// while a replica processes the import, other replicas skip it until the lease expires
+	LeaseUntil time.Time `gorm:"index:idx_account_imports_due,priority:2" json:"-"`
+
+	CreatedAt   time.Time  `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt   time.Time  `json:"updated_at" gorm:"autoUpdateTime"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+func (i *AccountImport) TableName() string {
+	return AccountImportTableName
+}
+
+// AccountImportError is one rejected row of an import, Message lists every problem of the row
+type AccountImportError struct {
+	ImportID types.AccountImportID `gorm:"primaryKey" json:"-"`
+
+	// Line of the row in the file, the CSV header is line 1
+	Line int `gorm:"primaryKey;autoIncrement:false" json:"line"`
+
+	AccountID types.AccountID `json:"account_id,string,omitempty"`
+	Message   string          `json:"message"`
+}
+
+func (e *AccountImportError) TableName() string {
+	return AccountImportErrorTableName
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"github.com/danielkhtse/supreme-adventure/common/validation"
+)
+
+// AccountActivity is one movement of an account balance, written in the same database transaction as the balance change
+type AccountActivity struct {
+	ID                    types.AccountActivityID `gorm:"primaryKey;autoIncrement:false;index:idx_account_activities_account,priority:2" json:"id,string" validate:"required"`
+	AccountID             types.AccountID         `gorm:"not null;index:idx_account_activities_account,priority:1" json:"account_id,string" validate:"required"`
+	Type                  types.ActivityType      `gorm:"type:varchar(20);not null" json:"type" validate:"required,oneof=opening transfer"`
+	Direction             types.ActivityDirection `gorm:"type:varchar(10);not null" json:"direction" validate:"required,oneof=credit debit"`
+	CounterpartyAccountID types.AccountID         `json:"counterparty_account_id,string,omitempty"`                        //zero for the opening balance
+	Amount                types.AccountBalance    `gorm:"not null" json:"amount" validate:"required,min=1"`                //always positive, the direction gives the sign
+	BalanceAfter          types.AccountBalance    `gorm:"not null" json:"balance_after" validate:"min=0"`                  //balance of the account right after the movement
+	TransactionID         types.TransactionID     `gorm:"index" json:"transaction_id,string,omitempty"`                    //transaction-service transaction that caused the movement
+	TransferID            string                  `gorm:"type:varchar(64)" json:"transfer_id,omitempty" validate:"max=64"` //caller supplied transfer ID
+	CreatedAt             time.Time               `json:"created_at" gorm:"autoCreateTime"`
+}
+
+const (
+	AccountActivityTableName = "account_activities"
+)
+
+func (a *AccountActivity) TableName() string {
+	return AccountActivityTableName
+}
+
+func (a *AccountActivity) BeforeCreate(tx *gorm.DB) error {
+	return validation.ValidateStruct(a)
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"github.com/danielkhtse/supreme-adventure/common/validation"
+)
+
+const APIKeyTableName = "api_keys"
+
+// APIKey is a long-lived credential of a server-to-server integrator, only the hash of the key is stored
+type APIKey struct {
+	ID         types.APIKeyID `gorm:"primaryKey" json:"id,string" validate:"required"`
+	Name       string         `json:"name" validate:"required"`
+	Prefix     string         `gorm:"uniqueIndex" json:"prefix" validate:"required"`         //start of the key, identifies it in listings and logs
+	Hash       string         `json:"-" validate:"required"`                                 //hex SHA-256 of the key
+	Scopes     []string       `json:"scopes" gorm:"serializer:json"`                         //scopes granted to callers presenting the key
+	AllowedIPs []string       `json:"allowed_ips" gorm:"column:allowed_ips;serializer:json"` //IP addresses and CIDR ranges the key is accepted from, empty accepts any
+	CreatedBy  string         `json:"created_by"`                                            //subject which created the key
+	ExpiresAt  *time.Time     `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time     `json:"last_used_at,omitempty"`
+	RotatedAt  *time.Time     `json:"rotated_at,omitempty"`
+	RevokedAt  *time.Time     `gorm:"index" json:"revoked_at,omitempty"`
+	CreatedAt  time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (k *APIKey) TableName() string {
+	return APIKeyTableName
+}
+
+func (k *APIKey) BeforeCreate(tx *gorm.DB) error {
+	return validation.ValidateStruct(k)
+}
+
+func (k *APIKey) BeforeUpdate(tx *gorm.DB) error {
+	return validation.ValidateStruct(k)
+}
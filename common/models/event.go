@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/danielkhtse/supreme-adventure/common/events"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+)
+
+const (
+	AccountEventTableName     = "account_events"
+	TransactionEventTableName = "transaction_events"
+)
+
+// Event is a persisted domain event, each service stores the events it publishes in its own table
+type Event struct {
+	ID         uint64            `gorm:"primaryKey;autoIncrement:false" json:"id"`
+	Position   uint64            `gorm:"autoIncrement;uniqueIndex" json:"-"`
+	Type       events.Type       `gorm:"type:varchar(50);index" json:"type"`
+	AccountIDs []types.AccountID `gorm:"serializer:json;type:jsonb;index:,type:gin" json:"account_ids"`
+	Data       types.RawJSON     `gorm:"type:jsonb" json:"data"`
+	OccurredAt time.Time         `gorm:"index" json:"occurred_at"`
+}
+
+// EventFeedHorizon records the highest position pruned from a feed, a cursor before it has missed deleted events
+type EventFeedHorizon struct {
+	Feed     string `gorm:"primaryKey;type:varchar(64)"`
+	Position uint64 `gorm:"not null"`
+}
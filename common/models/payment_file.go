@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/danielkhtse/supreme-adventure/common/types"
+)
+
+const (
+	PaymentFileTableName         = "payment_files"
+	PaymentFileTransferTableName = "payment_file_transfers"
+)
+
+// PaymentFile is an uploaded pain.001 payment initiation. Message IDs are chosen by the initiating party, so they
+// are unique per uploading principal, rejecting files the principal uploads twice.
+type PaymentFile struct {
+	ID        types.PaymentFileID     `gorm:"primaryKey" json:"id,string"`
+	Principal string                  `gorm:"type:varchar(255);uniqueIndex:idx_payment_files_principal_message_id,priority:1" json:"principal"` //subject of the uploader, or api-key:<id>
+	MessageID string                  `gorm:"type:varchar(35);uniqueIndex:idx_payment_files_principal_message_id,priority:2" json:"message_id"`
+	Status    types.PaymentFileStatus `gorm:"type:varchar(20)" json:"status"`
+
+	Document []byte `json:"-"` //uploaded pain.001, read again to report a file abandoned while processing
+	Report   []byte `json:"-"` //pain.002 status report, set once the file is processed
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (f *PaymentFile) TableName() string {
+	return PaymentFileTableName
+}
+
+// PaymentFileTransfer is the outcome of one credit transfer of a payment file, keyed by its EndToEndId. It is
+// recorded with the reserved transaction ID before the transfer executes and completed once it has, so a file
+// abandoned while processing reports each transfer from its record and never executes one twice.
+type PaymentFileTransfer struct {
+	PaymentFileID     types.PaymentFileID `gorm:"primaryKey" json:"payment_file_id,string"`
+	EndToEndID        string              `gorm:"primaryKey;type:varchar(35)" json:"end_to_end_id"`
+	TransactionID     types.TransactionID `json:"transaction_id,string"`
+	Status            string              `gorm:"type:varchar(4)" json:"status"` //pain.002 transaction status, empty while the transfer executes
+	ReasonCode        string              `gorm:"type:varchar(4)" json:"reason_code,omitempty"`
+	ReasonInformation string              `json:"reason_information,omitempty"`
+
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (t *PaymentFileTransfer) TableName() string {
+	return PaymentFileTransferTableName
+}
@@ -8,29 +8,36 @@ import (
 	"gorm.io/gorm"
 )
 
+// Transaction listings page by (created_at, id), the composite indexes lead with each filter column so
+// per-account and per-status listings are served by an index range scan in either sort direction
 type Transaction struct {
-	ID              types.TransactionID     `gorm:"primaryKey" json:"id" validate:"required"`
-	SourceAccountID types.AccountID         `gorm:"index" json:"source_account_id" validate:"required"`
-	DestAccountID   types.AccountID         `gorm:"index" json:"destination_account_id" validate:"required,nefield=SourceAccountID"`
+	ID              types.TransactionID     `gorm:"primaryKey;index:idx_transactions_created,priority:2;index:idx_transactions_source_created,priority:3;index:idx_transactions_dest_created,priority:3;index:idx_transactions_status_created,priority:3" json:"id,string" validate:"required"`
+	SourceAccountID types.AccountID         `gorm:"index:idx_transactions_source_created,priority:1" json:"source_account_id,string" validate:"required"`
+	DestAccountID   types.AccountID         `gorm:"index:idx_transactions_dest_created,priority:1" json:"destination_account_id,string" validate:"required,nefield=SourceAccountID"`
 	Amount          types.AccountBalance    `json:"amount" validate:"required,min=1"`                            //We will store the smallest units for the currency (e.g. cents for USD)
 	Currency        string                  `json:"currency" gorm:"default:'USD'" validate:"required,oneof=USD"` //We simply support USD for now
-	Status          types.TransactionStatus `gorm:"type:varchar(20)" json:"status" validate:"required,transaction_status"`
-	Description     string                  `json:"description" validate:"required"`
-	CreatedAt       time.Time               `json:"created_at" gorm:"autoCreateTime"`
+	Status          types.TransactionStatus `gorm:"type:varchar(20);index:idx_transactions_status_created,priority:1" json:"status" validate:"required,transaction_status"`
+	Description     string                  `json:"description"`
+	CreatedAt       time.Time               `json:"created_at" gorm:"autoCreateTime;index:idx_transactions_created,priority:1;index:idx_transactions_source_created,priority:2;index:idx_transactions_dest_created,priority:2;index:idx_transactions_status_created,priority:2"`
 	UpdatedAt       time.Time               `json:"updated_at" gorm:"autoUpdateTime"`
 }
 
-func (t *Transaction) BeforeCreate(tx *gorm.DB) error {
-	if err := validation.ValidateStruct(t); err != nil {
-		return err
-	}
+// Money returns the transferred amount in the currency of the transaction
+func (t *Transaction) Money() types.Money {
+	return types.Money{Amount: t.Amount, Currency: t.Currency}
+}
 
+func (t *Transaction) BeforeCreate(tx *gorm.DB) error {
 	if t.Status == "" {
 		t.Status = types.TransactionStatusPending
 	}
 
 	if t.Currency == "" {
-		t.Currency = "USD"
+		t.Currency = types.DefaultCurrency
+	}
+
+	if err := validation.ValidateStruct(t); err != nil {
+		return err
 	}
 
 	return nil
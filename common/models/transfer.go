@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"github.com/danielkhtse/supreme-adventure/common/validation"
+)
+
+// AppliedTransfer records a transfer applied under a caller supplied transfer ID, so a retried request is not applied twice
+type AppliedTransfer struct {
+	TransferID      string               `gorm:"primaryKey;type:varchar(64)" json:"transfer_id" validate:"required,max=64"`
+	SourceAccountID types.AccountID      `json:"source_account_id,string" validate:"required"`
+	DestAccountID   types.AccountID      `json:"dest_account_id,string" validate:"required"`
+	Amount          types.AccountBalance `json:"amount" validate:"required,min=1"`
+	CreatedAt       time.Time            `json:"created_at" gorm:"autoCreateTime"`
+}
+
+const (
+	AppliedTransferTableName = "applied_transfers"
+)
+
+func (t *AppliedTransfer) TableName() string {
+	return AppliedTransferTableName
+}
+
+// SameTransfer reports whether the record describes the given transfer
+func (t *AppliedTransfer) SameTransfer(sourceAccountID types.AccountID, destAccountID types.AccountID, amount types.AccountBalance) bool {
+	return t.SourceAccountID == sourceAccountID && t.DestAccountID == destAccountID && t.Amount == amount
+}
+
+func (t *AppliedTransfer) BeforeCreate(tx *gorm.DB) error {
+	return validation.ValidateStruct(t)
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/danielkhtse/supreme-adventure/common/events"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"github.com/danielkhtse/supreme-adventure/common/validation"
+)
+
+// WebhookEndpoint is an integrator registered URL receiving event notifications
+type WebhookEndpoint struct {
+	ID         types.WebhookEndpointID `gorm:"primaryKey" json:"id,string" validate:"required"`
+	URL        string                  `json:"url" validate:"required,url"`
+	Secret     string                  `json:"-" validate:"required"`                    //HMAC key used to sign deliveries, only returned on creation
+	EventTypes []events.Type           `json:"event_types" gorm:"serializer:json"`       //empty subscribes to every event type
+	AccountID  types.AccountID         `gorm:"index" json:"account_id,string,omitempty"` //zero subscribes to every account
+	Active     bool                    `json:"active" gorm:"not null;default:true"`
+	CreatedAt  time.Time               `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time               `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// Matches reports whether the endpoint subscribes to the event
+func (e *WebhookEndpoint) Matches(event events.Event) bool {
+	if !e.Active {
+		return false
+	}
+
+	if e.AccountID != 0 && !event.AffectsAccount(e.AccountID) {
+		return false
+	}
+
+	if len(e.EventTypes) == 0 {
+		return true
+	}
+	for _, eventType := range e.EventTypes {
+		if eventType == event.Type {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *WebhookEndpoint) BeforeCreate(tx *gorm.DB) error {
+	return validation.ValidateStruct(e)
+}
+
+func (e *WebhookEndpoint) BeforeUpdate(tx *gorm.DB) error {
+	return validation.ValidateStruct(e)
+}
+
+// WebhookDispatchCursor is the change feed cursor up to which events have been turned into webhook deliveries
+type WebhookDispatchCursor struct {
+	Name      string    `gorm:"primaryKey;type:varchar(64)"`
+	Cursor    string    `gorm:"type:varchar(64);not null"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// WebhookDelivery is the delivery log entry of one event to one endpoint
+type WebhookDelivery struct {
+	ID                 types.WebhookDeliveryID     `gorm:"primaryKey" json:"id,string" validate:"required"`
+	EndpointID         types.WebhookEndpointID     `gorm:"index" json:"endpoint_id,string" validate:"required"`
+	EventID            uint64                      `json:"event_id,string" validate:"required"`
+	EventType          events.Type                 `gorm:"type:varchar(50)" json:"event_type" validate:"required"`
+	Payload            types.RawJSON               `gorm:"type:jsonb" json:"payload" swaggertype:"object"`
+	Status             types.WebhookDeliveryStatus `gorm:"type:varchar(20);index:idx_webhook_deliveries_due,priority:1" json:"status" validate:"required"`
+	Attempts           int                         `json:"attempts"`
+	NextAttemptAt      time.Time                   `gorm:"index:idx_webhook_deliveries_due,priority:2" json:"next_attempt_at"`
+	ResponseStatusCode int                         `json:"response_status_code,omitempty"`
+	LastError          string                      `json:"last_error,omitempty"`
+	DeliveredAt        *time.Time                  `json:"delivered_at,omitempty"`
+	CreatedAt          time.Time                   `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt          time.Time                   `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+func (d *WebhookDelivery) BeforeCreate(tx *gorm.DB) error {
+	if d.Status == "" {
+		d.Status = types.WebhookDeliveryStatusPending
+	}
+	return validation.ValidateStruct(d)
+}
+
+func (d *WebhookDelivery) BeforeUpdate(tx *gorm.DB) error {
+	return validation.ValidateStruct(d)
+}
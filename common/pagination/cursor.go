@@ -0,0 +1,63 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+)
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 200
+)
+
+// ErrInvalidCursor is returned for a cursor which was not issued for the same listing and sort order
+var ErrInvalidCursor = apperr.ErrInvalidCursor
+
+// Cursor is the keyset position after the last item of a page. Clients treat its encoded form as opaque.
+type Cursor struct {
+	// Sort identifies the sort field and direction the cursor was issued for, e.g. "-created_at"
+	Sort string `json:"s"`
+
+	// Value of the sort field of the last item, formatted by the listing
+	Value string `json:"v"`
+
+	// ID of the last item, breaking ties between equal sort values
+	ID uint64 `json:"id"`
+}
+
+// Encode returns the opaque form of the cursor
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decode parses an opaque cursor issued for the given sort, the empty cursor returns nil
+func Decode(cursor string, sort string) (*Cursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var decoded Cursor
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.Sort != sort || decoded.ID == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &decoded, nil
+}
+
+// ClampLimit applies DefaultLimit to non-positive limits and caps them at MaxLimit
+func ClampLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultLimit
+	}
+	if limit > MaxLimit {
+		return MaxLimit
+	}
+	return limit
+}
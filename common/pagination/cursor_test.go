@@ -0,0 +1,42 @@
+package pagination
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitCursor(t *testing.T) {
+	t.Run("Round trip", func(t *testing.T) {
+		cursor := Cursor{Sort: "-created_at", Value: "2025-01-01T00:00:00Z", ID: 42}
+
+		decoded, err := Decode(cursor.Encode(), "-created_at")
+		require.NoError(t, err)
+		assert.Equal(t, cursor, *decoded)
+	})
+
+	t.Run("Empty cursor", func(t *testing.T) {
+		decoded, err := Decode("", "-created_at")
+		assert.NoError(t, err)
+		assert.Nil(t, decoded)
+	})
+
+	t.Run("Issued for another sort", func(t *testing.T) {
+		cursor := Cursor{Sort: "created_at", Value: "2025-01-01T00:00:00Z", ID: 42}
+
+		_, err := Decode(cursor.Encode(), "-created_at")
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+
+	t.Run("Garbage", func(t *testing.T) {
+		_, err := Decode("not a cursor", "-created_at")
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		assert.Equal(t, DefaultLimit, ClampLimit(0))
+		assert.Equal(t, MaxLimit, ClampLimit(MaxLimit+1))
+		assert.Equal(t, 10, ClampLimit(10))
+	})
+}
@@ -0,0 +1,177 @@
+// Package requestmeta carries the request ID, credentials and forwarding chain of an incoming request
+// through the context, so calls to other services can propagate them.
+package requestmeta
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	// RequestIDHeader identifies a request across services
+	RequestIDHeader = "X-Request-ID"
+
+	// AuthorizationHeader carries the credentials of the caller
+	AuthorizationHeader = "Authorization"
+
+	// ForwardedForHeader lists the addresses a request was forwarded from, the client first
+	ForwardedForHeader = "X-Forwarded-For"
+
+	// gRPC metadata keys, lower case as required by HTTP/2
+	requestIDKey     = "x-request-id"
+	authorizationKey = "authorization"
+	forwardedForKey  = "x-forwarded-for"
+
+	maxRequestIDLength = 128
+)
+
+// Metadata of the request being served
+type Metadata struct {
+	RequestID     string
+	Authorization string
+
+	// ForwardedFor is the X-Forwarded-For chain of the request followed by the address of the peer which sent it,
+	// only the addresses added by trusted proxies can be relied upon
+	ForwardedFor string
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying md
+func NewContext(ctx context.Context, md Metadata) context.Context {
+	return context.WithValue(ctx, contextKey{}, md)
+}
+
+// FromContext returns the metadata carried by ctx, empty when there is none
+func FromContext(ctx context.Context) Metadata {
+	md, _ := ctx.Value(contextKey{}).(Metadata)
+	return md
+}
+
+// SetHeaders sets the request ID, authorization and forwarding headers of an outgoing HTTP request from ctx
+func SetHeaders(ctx context.Context, header http.Header) {
+	md := FromContext(ctx)
+	if md.RequestID != "" {
+		header.Set(RequestIDHeader, md.RequestID)
+	}
+	if md.Authorization != "" {
+		header.Set(AuthorizationHeader, md.Authorization)
+	}
+	if md.ForwardedFor != "" {
+		header.Set(ForwardedForHeader, md.ForwardedFor)
+	}
+}
+
+// OutgoingContext returns a copy of ctx whose outgoing gRPC metadata carries the request ID, authorization and
+// forwarding chain of ctx
+func OutgoingContext(ctx context.Context) context.Context {
+	md := FromContext(ctx)
+	var pairs []string
+	if md.RequestID != "" {
+		pairs = append(pairs, requestIDKey, md.RequestID)
+	}
+	if md.Authorization != "" {
+		pairs = append(pairs, authorizationKey, md.Authorization)
+	}
+	if md.ForwardedFor != "" {
+		pairs = append(pairs, forwardedForKey, md.ForwardedFor)
+	}
+	if len(pairs) == 0 {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, pairs...)
+}
+
+// Middleware stores the request ID, authorization header and forwarding chain of incoming requests in the
+// request context. A request ID is generated when the caller sends none, and echoed in the response.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		md := Metadata{
+			RequestID:     validRequestID(r.Header.Get(RequestIDHeader)),
+			Authorization: r.Header.Get(AuthorizationHeader),
+			ForwardedFor:  ForwardedFor(r.Header.Values(ForwardedForHeader), r.RemoteAddr),
+		}
+		w.Header().Set(RequestIDHeader, md.RequestID)
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), md)))
+	})
+}
+
+// UnaryServerInterceptor stores the request ID, authorization and forwarding chain of incoming gRPC calls in the context
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(incomingContext(ctx), req)
+	}
+}
+
+// StreamServerInterceptor stores the request ID, authorization and forwarding chain of incoming gRPC streams in the context
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &serverStream{ServerStream: ss, ctx: incomingContext(ss.Context())})
+	}
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func incomingContext(ctx context.Context) context.Context {
+	incoming, _ := metadata.FromIncomingContext(ctx)
+	var remoteAddr string
+	if p, ok := peer.FromContext(ctx); ok {
+		remoteAddr = p.Addr.String()
+	}
+	return NewContext(ctx, Metadata{
+		RequestID:     validRequestID(first(incoming.Get(requestIDKey))),
+		Authorization: first(incoming.Get(authorizationKey)),
+		ForwardedFor:  ForwardedFor(incoming.Get(forwardedForKey), remoteAddr),
+	})
+}
+
+// ForwardedFor appends the host of remoteAddr, the peer a request was received from, to the X-Forwarded-For
+// values the request carried
+func ForwardedFor(values []string, remoteAddr string) string {
+	var hops []string
+	for _, value := range values {
+		for _, hop := range strings.Split(value, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		hops = append(hops, host)
+	} else if remoteAddr != "" {
+		hops = append(hops, remoteAddr)
+	}
+	return strings.Join(hops, ", ")
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// validRequestID returns id, or a new random ID when id is empty or too long to log safely
+func validRequestID(id string) string {
+	if id != "" && len(id) <= maxRequestIDLength {
+		return id
+	}
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,74 @@
+package requestmeta
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestUnitMiddleware(t *testing.T) {
+	var got Metadata
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = FromContext(r.Context())
+	}))
+
+	t.Run("Keeps caller request ID", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(RequestIDHeader, "abc")
+		req.Header.Set(AuthorizationHeader, "Bearer token")
+		req.RemoteAddr = "10.0.0.2:51234"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.Equal(t, Metadata{RequestID: "abc", Authorization: "Bearer token", ForwardedFor: "10.0.0.2"}, got)
+		assert.Equal(t, "abc", rec.Header().Get(RequestIDHeader))
+	})
+
+	t.Run("Generates missing request ID", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		assert.Len(t, got.RequestID, 32)
+		assert.Equal(t, got.RequestID, rec.Header().Get(RequestIDHeader))
+	})
+
+	t.Run("Appends peer to forwarding chain", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Add(ForwardedForHeader, "203.0.113.7, 198.51.100.1")
+		req.Header.Add(ForwardedForHeader, "198.51.100.2")
+		req.RemoteAddr = "[2001:db8::1]:443"
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		assert.Equal(t, "203.0.113.7, 198.51.100.1, 198.51.100.2, 2001:db8::1", got.ForwardedFor)
+	})
+}
+
+func TestUnitPropagation(t *testing.T) {
+	ctx := NewContext(context.Background(), Metadata{RequestID: "abc", Authorization: "Bearer token", ForwardedFor: "203.0.113.7"})
+
+	header := http.Header{}
+	SetHeaders(ctx, header)
+	assert.Equal(t, "abc", header.Get(RequestIDHeader))
+	assert.Equal(t, "Bearer token", header.Get(AuthorizationHeader))
+	assert.Equal(t, "203.0.113.7", header.Get(ForwardedForHeader))
+
+	outgoing, _ := metadata.FromOutgoingContext(OutgoingContext(ctx))
+	incoming := peer.NewContext(metadata.NewIncomingContext(context.Background(), outgoing), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.3"), Port: 40000},
+	})
+
+	var got Metadata
+	_, err := UnaryServerInterceptor()(incoming, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req any) (any, error) {
+		got = FromContext(ctx)
+		return nil, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, Metadata{RequestID: "abc", Authorization: "Bearer token", ForwardedFor: "203.0.113.7, 10.0.0.3"}, got)
+}
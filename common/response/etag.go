@@ -0,0 +1,68 @@
+package response
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ETag formats a resource version as a strong entity tag
+func ETag(version uint64) string {
+	return `"` + strconv.FormatUint(version, 10) + `"`
+}
+
+// ParseETag returns the version encoded in a strong entity tag issued by ETag, weak tags are rejected
+func ParseETag(tag string) (uint64, bool) {
+	tag = strings.TrimSpace(tag)
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+	version, err := strconv.ParseUint(tag[1:len(tag)-1], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return version, true
+}
+
+// IfMatchVersions parses the If-Match header of r. wildcard is true when the header is absent or "*",
+// otherwise versions holds the acceptable versions. ok is false when the header is malformed or lists a weak
+// tag, which If-Match compares strongly (RFC 9110 section 13.1.1) so it could never match.
+func IfMatchVersions(r *http.Request) (versions []uint64, wildcard bool, ok bool) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return nil, true, true
+	}
+	for _, tag := range strings.Split(header, ",") {
+		version, valid := ParseETag(tag)
+		if !valid {
+			return nil, false, false
+		}
+		versions = append(versions, version)
+	}
+	return versions, false, true
+}
+
+// NoneMatch reports whether the If-None-Match header of r does not match etag, i.e. the full response should be
+// sent. If-None-Match compares weakly, so weak tags match like their strong counterparts.
+func NoneMatch(r *http.Request, etag string) bool {
+	header := strings.TrimSpace(r.Header.Get("If-None-Match"))
+	if header == "" {
+		return true
+	}
+	if header == "*" {
+		return false
+	}
+	version, _ := ParseETag(etag)
+	for _, tag := range strings.Split(header, ",") {
+		if candidate, valid := ParseETag(strings.TrimPrefix(strings.TrimSpace(tag), "W/")); valid && candidate == version {
+			return false
+		}
+	}
+	return true
+}
+
+// SendNotModified sends a 304 response carrying the current entity tag
+func SendNotModified(w http.ResponseWriter, etag string) {
+	w.Header().Set("ETag", etag)
+	w.WriteHeader(int(StatusNotModified))
+}
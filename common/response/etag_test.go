@@ -0,0 +1,55 @@
+package response
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnitETag(t *testing.T) {
+	t.Run("Round trip", func(t *testing.T) {
+		version, ok := ParseETag(ETag(42))
+		assert.True(t, ok)
+		assert.Equal(t, uint64(42), version)
+
+		_, ok = ParseETag(`W/"7"`)
+		assert.False(t, ok)
+
+		_, ok = ParseETag("7")
+		assert.False(t, ok)
+	})
+
+	t.Run("If-Match", func(t *testing.T) {
+		r := httptest.NewRequest("PUT", "/", nil)
+		_, wildcard, ok := IfMatchVersions(r)
+		assert.True(t, wildcard)
+		assert.True(t, ok)
+
+		r.Header.Set("If-Match", `"3", "4"`)
+		versions, wildcard, ok := IfMatchVersions(r)
+		assert.Equal(t, []uint64{3, 4}, versions)
+		assert.False(t, wildcard)
+		assert.True(t, ok)
+
+		r.Header.Set("If-Match", "garbage")
+		_, _, ok = IfMatchVersions(r)
+		assert.False(t, ok)
+
+		r.Header.Set("If-Match", `"3", W/"4"`)
+		_, _, ok = IfMatchVersions(r)
+		assert.False(t, ok)
+	})
+
+	t.Run("If-None-Match", func(t *testing.T) {
+		r := httptest.NewRequest("GET", "/", nil)
+		assert.True(t, NoneMatch(r, ETag(3)))
+
+		r.Header.Set("If-None-Match", `"2", W/"3"`)
+		assert.False(t, NoneMatch(r, ETag(3)))
+		assert.True(t, NoneMatch(r, ETag(4)))
+
+		r.Header.Set("If-None-Match", "*")
+		assert.False(t, NoneMatch(r, ETag(4)))
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
 	"github.com/sirupsen/logrus"
 )
 
@@ -22,6 +23,57 @@ type ErrorResponse struct {
 	Message string `json:"message" example:"Invalid request parameters"`
 }
 
+// ProblemContentType is the media type of RFC 7807 problem details
+const ProblemContentType = "application/problem+json"
+
+// ProblemResponse is an RFC 7807 problem details object extending ErrorResponse, whose message repeats the detail
+type ProblemResponse struct {
+	ErrorResponse
+
+	// URI reference identifying the problem type, derived from the code
+	Type string `json:"type" example:"urn:supreme-adventure:problem:insufficient_funds"`
+
+	// Short summary of the problem type, the reason phrase of the status
+	Title string `json:"title" example:"Bad Request"`
+
+	// HTTP status code
+	Status int `json:"status" example:"400"`
+
+	// Explanation specific to this occurrence of the problem
+	Detail string `json:"detail,omitempty" example:"insufficient balance"`
+
+	// Stable machine-readable error code
+	Code apperr.Code `json:"code" example:"insufficient_funds" swaggertype:"string"`
+}
+
+// ProblemTypePrefix prefixes the code to form the problem type
+const ProblemTypePrefix = "urn:supreme-adventure:problem:"
+
+// NewProblem creates the problem details of an error
+func NewProblem(status StatusCode, code apperr.Code, message string) ProblemResponse {
+	return ProblemResponse{
+		ErrorResponse: ErrorResponse{Message: message},
+		Type:          ProblemTypePrefix + string(code),
+		Title:         http.StatusText(int(status)),
+		Status:        int(status),
+		Detail:        message,
+		Code:          code,
+	}
+}
+
+// Err returns the problem as *apperr.Error, falling back to the generic code of the status for problems without code
+func (p ProblemResponse) Err() *apperr.Error {
+	code := p.Code
+	if code == "" {
+		code = apperr.CodeForStatus(p.Status)
+	}
+	message := p.Detail
+	if message == "" {
+		message = p.Message
+	}
+	return apperr.FromCode(code, p.Status, message)
+}
+
 // SendSuccess sends a success response with optional data
 //
 // @Summary Send success response
@@ -57,38 +109,47 @@ func SendSuccess[T any](w http.ResponseWriter, status StatusCode, data *T) error
 	return json.NewEncoder(w).Encode(response.Data)
 }
 
-// SendError sends an error response with a message
+// SendError sends a problem details response with a message, coded by the status
 //
 // @Summary Send error response
-// @Description Sends a standardized error response with message
+// @Description Sends a standardized application/problem+json error response with message
 // @Tags response
 // @Accept json
 // @Produce json
 // @Param w body http.ResponseWriter true "HTTP response writer"
 // @Param status path StatusCode true "HTTP status code (400-599)"
 // @Param message body string false "Error message"
-// @Success 400-599 {object} ErrorResponse "Error response"
-// @Failure 500 {object} ErrorResponse "Invalid status code error"
+// @Success 400-599 {object} ProblemResponse "Error response"
+// @Failure 500 {object} ProblemResponse "Invalid status code error"
 // @Return error
 func SendError(w http.ResponseWriter, status StatusCode, message string) error {
+	return sendProblem(w, status, apperr.CodeForStatus(int(status)), message)
+}
+
+// SendProblem sends the problem details of err. Errors other than *apperr.Error are logged and reported
+// as internal errors without exposing their message.
+func SendProblem(w http.ResponseWriter, err error) error {
+	e, ok := apperr.As(err)
+	if !ok {
+		logrus.WithError(err).Error("unexpected error")
+		e = apperr.ErrInternal
+	}
+	return sendProblem(w, StatusCode(e.Status), e.Code, e.Message)
+}
+
+func sendProblem(w http.ResponseWriter, status StatusCode, code apperr.Code, message string) error {
 	if status < 400 || status > 599 {
 		return fmt.Errorf("SendError status code must be between 400-599, got %d", status)
 	}
 
-	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Type", ProblemContentType)
 	w.WriteHeader(int(status))
 
 	logrus.WithFields(logrus.Fields{
 		"status_code": status,
+		"code":        code,
 		"message":     message,
 	}).Error("sending error response")
 
-	if message == "" {
-		return json.NewEncoder(w).Encode(struct{}{})
-	}
-
-	response := ErrorResponse{
-		Message: message,
-	}
-	return json.NewEncoder(w).Encode(response)
+	return json.NewEncoder(w).Encode(NewProblem(status, code, message))
 }
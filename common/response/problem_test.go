@@ -0,0 +1,50 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitSendProblem(t *testing.T) {
+	t.Run("Domain error", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		SendProblem(w, apperr.ErrAccountFrozen.WithMessage("account 2 is inactive"))
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+
+		var problem ProblemResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+		assert.Equal(t, apperr.CodeAccountFrozen, problem.Code)
+		assert.Equal(t, ProblemTypePrefix+"account_frozen", problem.Type)
+		assert.Equal(t, "account 2 is inactive", problem.Detail)
+		assert.Equal(t, "account 2 is inactive", problem.Message)
+		assert.ErrorIs(t, problem.Err(), apperr.ErrAccountFrozen)
+	})
+
+	t.Run("Unexpected error", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		SendProblem(w, errors.New("connection refused"))
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.NotContains(t, w.Body.String(), "connection refused")
+		assert.Contains(t, w.Body.String(), `"code":"internal"`)
+	})
+
+	t.Run("Plain error", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		SendError(w, StatusBadRequest, "invalid request body")
+
+		var problem ProblemResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+		assert.Equal(t, apperr.CodeInvalidArgument, problem.Code)
+		assert.Equal(t, "invalid request body", problem.Message)
+	})
+}
@@ -0,0 +1,343 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/events"
+	"github.com/danielkhtse/supreme-adventure/common/feed"
+	"github.com/danielkhtse/supreme-adventure/common/response"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+)
+
+const (
+	defaultHeartbeat    = 15 * time.Second
+	defaultPollInterval = time.Second
+	subscriberBuffer    = 64
+	writeTimeout        = 10 * time.Second
+
+	// LastEventIDHeader is sent by EventSource clients when reconnecting
+	LastEventIDHeader = "Last-Event-ID"
+
+	// LastEventIDParam resumes a stream for clients which cannot set headers (e.g. browser WebSocket)
+	LastEventIDParam = "last_event_id"
+)
+
+// ErrForbidden is returned by an Authorizer denying access to an account
+var ErrForbidden = apperr.ErrAccountAccessDenied.WithMessage("not allowed to access this account")
+
+// Authorizer decides whether the caller of ctx may stream the events of an account. Denials are returned as
+// *apperr.Error, other errors are failures to decide.
+type Authorizer interface {
+	AuthorizeAccount(ctx context.Context, accountID types.AccountID) error
+}
+
+// AllowAll authorizes every request, used by handlers without an Authorizer
+type AllowAll struct{}
+
+func (AllowAll) AuthorizeAccount(ctx context.Context, accountID types.AccountID) error {
+	return nil
+}
+
+// Feed is the durable change feed streams are read from, so a stream receives the events committed by every
+// instance of the service in commit order and resumes on any instance
+type Feed interface {
+	// ListEvents returns up to limit events affecting the account after the cursor
+	ListEvents(ctx context.Context, after string, accountID types.AccountID, limit int, wait time.Duration) (*feed.Page, error)
+
+	// EventCursor returns the cursor after the event with the given ID, retained is false when the event is no
+	// longer in the feed
+	EventCursor(ctx context.Context, eventID uint64) (cursor string, retained bool, err error)
+}
+
+// Handler streams the events of one account over server-sent events, or WebSocket when the request is an upgrade
+type Handler struct {
+	Feed Feed
+
+	// Broker of the events published by this instance, which wake streams up before the next poll of Feed
+	Broker *events.Broker
+
+	Authorizer Authorizer
+
+	// Event types to stream, empty streams every type
+	Types []events.Type
+
+	// Origin accepted for WebSocket upgrades, "*" accepts any origin
+	AllowedOrigin string
+
+	// Interval of keep-alive messages, defaults to 15 seconds
+	Heartbeat time.Duration
+
+	// Interval of polls of Feed for events committed by other instances, defaults to 1 second
+	PollInterval time.Duration
+}
+
+// Authorize returns nil when the caller of ctx may stream the events of the account
+func (h *Handler) Authorize(ctx context.Context, accountID types.AccountID) error {
+	if h.Authorizer == nil {
+		return AllowAll{}.AuthorizeAccount(ctx, accountID)
+	}
+	return h.Authorizer.AuthorizeAccount(ctx, accountID)
+}
+
+// Serve streams the account's events until the client disconnects
+func (h *Handler) Serve(w http.ResponseWriter, r *http.Request, accountID types.AccountID) {
+	if err := h.Authorize(r.Context(), accountID); err != nil {
+		response.SendProblem(w, err)
+		return
+	}
+
+	lastEventID, err := parseLastEventID(r)
+	if err != nil {
+		response.SendError(w, response.StatusBadRequest, err.Error())
+		return
+	}
+
+	cursor, err := h.startCursor(r.Context(), accountID, lastEventID)
+	if err != nil {
+		response.SendProblem(w, err)
+		return
+	}
+
+	if websocket.IsWebSocketUpgrade(r) {
+		h.serveWebSocket(w, r, accountID, cursor)
+		return
+	}
+	h.serveSSE(w, r, accountID, cursor)
+}
+
+func (h *Handler) serveSSE(w http.ResponseWriter, r *http.Request, accountID types.AccountID, cursor string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		response.SendError(w, response.StatusInternalServerError, "streaming not supported")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	send := func(event events.Event) error {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	}
+
+	heartbeat := time.NewTicker(h.heartbeat())
+	defer heartbeat.Stop()
+
+	h.pumpUntil(r.Context(), nil, accountID, cursor, heartbeat.C, send, func() error {
+		if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	})
+}
+
+func (h *Handler) serveWebSocket(w http.ResponseWriter, r *http.Request, accountID types.AccountID, cursor string) {
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			return origin == "" || h.AllowedOrigin == "*" || origin == h.AllowedOrigin
+		},
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logrus.WithError(err).Error("failed to upgrade stream to websocket")
+		return
+	}
+	defer conn.Close()
+
+	// the client sends nothing, reading only detects the close
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	send := func(event events.Event) error {
+		conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+		return conn.WriteJSON(event)
+	}
+
+	heartbeat := time.NewTicker(h.heartbeat())
+	defer heartbeat.Stop()
+
+	h.pumpUntil(r.Context(), closed, accountID, cursor, heartbeat.C, send, func() error {
+		return conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout))
+	})
+}
+
+// Watch calls send for each of the account's events, first replaying retained events after lastEventID,
+// until ctx is done or send fails. It serves transports other than HTTP, callers check Authorize first.
+func (h *Handler) Watch(ctx context.Context, accountID types.AccountID, lastEventID uint64, send func(events.Event) error) error {
+	cursor, err := h.startCursor(ctx, accountID, lastEventID)
+	if err != nil {
+		return err
+	}
+
+	var sendErr error
+	err = h.pumpUntil(ctx, nil, accountID, cursor, nil, func(event events.Event) error {
+		sendErr = send(event)
+		return sendErr
+	}, nil)
+	if sendErr != nil {
+		return sendErr
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// startCursor returns the feed cursor after lastEventID, or after the newest event when lastEventID is zero. The
+// newest event is resolved before the stream is answered, so a client misses no event committed once connected.
+func (h *Handler) startCursor(ctx context.Context, accountID types.AccountID, lastEventID uint64) (string, error) {
+	if lastEventID == 0 {
+		page, err := h.Feed.ListEvents(ctx, feed.LatestCursor, accountID, 1, 0)
+		if err != nil {
+			return "", err
+		}
+		return page.NextCursor, nil
+	}
+
+	cursor, retained, err := h.Feed.EventCursor(ctx, lastEventID)
+	if err != nil {
+		return "", err
+	}
+	if !retained {
+		logrus.WithFields(logrus.Fields{
+			"account_id":    accountID,
+			"last_event_id": lastEventID,
+		}).Warn("resuming stream from an event no longer retained, events may be missing")
+	}
+	return cursor, nil
+}
+
+// pumpUntil reads the feed after cursor and writes the events matching the account until ctx is done, closed or
+// a read or write fails. Events are read in commit order, so none is skipped whatever the order of their IDs.
+func (h *Handler) pumpUntil(ctx context.Context, closed <-chan struct{}, accountID types.AccountID, cursor string, heartbeat <-chan time.Time, send func(events.Event) error, ping func() error) error {
+	// wake up as soon as this instance publishes an event of the account, other instances are polled
+	var wake <-chan events.Event
+	if h.Broker != nil {
+		eventCh, unsubscribe := h.Broker.Subscribe(subscriberBuffer)
+		defer unsubscribe()
+		wake = eventCh
+	}
+	poll := time.NewTicker(h.pollInterval())
+	defer poll.Stop()
+
+	for {
+		page, err := h.Feed.ListEvents(ctx, cursor, accountID, feed.MaxLimit, 0)
+		if err != nil {
+			if ctx.Err() == nil {
+				logrus.WithError(err).WithField("account_id", accountID).Error("failed to read event feed for stream")
+			}
+			return err
+		}
+		for _, event := range page.Events {
+			if !h.matches(event, accountID) {
+				continue
+			}
+			if err := send(event); err != nil {
+				return err
+			}
+		}
+		cursor = page.NextCursor
+		if page.HasMore {
+			continue
+		}
+
+	wait:
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-closed:
+				return nil
+			case event, ok := <-wake:
+				if !ok {
+					return nil
+				}
+				if h.matches(event, accountID) {
+					break wait
+				}
+			case <-poll.C:
+				break wait
+			case <-heartbeat:
+				if err := ping(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+func (h *Handler) matches(event events.Event, accountID types.AccountID) bool {
+	if !event.AffectsAccount(accountID) {
+		return false
+	}
+	if len(h.Types) == 0 {
+		return true
+	}
+	for _, eventType := range h.Types {
+		if eventType == event.Type {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *Handler) pollInterval() time.Duration {
+	if h.PollInterval > 0 {
+		return h.PollInterval
+	}
+	return defaultPollInterval
+}
+
+func (h *Handler) heartbeat() time.Duration {
+	if h.Heartbeat > 0 {
+		return h.Heartbeat
+	}
+	return defaultHeartbeat
+}
+
+func parseLastEventID(r *http.Request) (uint64, error) {
+	value := r.Header.Get(LastEventIDHeader)
+	if value == "" {
+		value = r.URL.Query().Get(LastEventIDParam)
+	}
+	if value == "" {
+		return 0, nil
+	}
+
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, errors.New("invalid last event ID format")
+	}
+	return id, nil
+}
@@ -0,0 +1,205 @@
+package stream
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/danielkhtse/supreme-adventure/common/events"
+	"github.com/danielkhtse/supreme-adventure/common/stream/streamtest"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+)
+
+type denyAll struct{}
+
+func (denyAll) AuthorizeAccount(ctx context.Context, accountID types.AccountID) error {
+	return ErrForbidden
+}
+
+func newTestServer(handler *Handler) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.Serve(w, r, 1)
+	}))
+}
+
+func TestUnitServeSSE(t *testing.T) {
+	feed := streamtest.NewFeed()
+	feed.Append(
+		events.Event{ID: 1, Type: events.TypeAccountBalanceChanged, AccountIDs: []types.AccountID{1}},
+		events.Event{ID: 2, Type: events.TypeAccountBalanceChanged, AccountIDs: []types.AccountID{2}},
+		events.Event{ID: 3, Type: events.TypeAccountBalanceChanged, AccountIDs: []types.AccountID{1}},
+	)
+
+	server := newTestServer(&Handler{Feed: feed, Broker: feed.Broker})
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set(LastEventIDHeader, "1")
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+
+	// backlog after the last event ID, skipping other accounts
+	assert.Equal(t, "id: 3", readLine(t, reader))
+	assert.Equal(t, "event: account.balance_changed", readLine(t, reader))
+	assert.True(t, strings.HasPrefix(readLine(t, reader), "data: "))
+	assert.Equal(t, "", readLine(t, reader))
+
+	// live event
+	feed.Append(events.Event{ID: 4, Type: events.TypeAccountBalanceChanged, AccountIDs: []types.AccountID{1}})
+	assert.Equal(t, "id: 4", readLine(t, reader))
+}
+
+func TestUnitServeWebSocket(t *testing.T) {
+	feed := streamtest.NewFeed()
+	server := newTestServer(&Handler{Feed: feed, Broker: feed.Broker, AllowedOrigin: "*"})
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// the stream starts after the newest event before the upgrade completes
+	feed.Append(events.Event{ID: 7, Type: events.TypeTransactionStatusChanged, AccountIDs: []types.AccountID{2, 1}})
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var event events.Event
+	require.NoError(t, conn.ReadJSON(&event))
+	assert.Equal(t, uint64(7), event.ID)
+}
+
+func TestUnitServeErrors(t *testing.T) {
+	t.Run("Forbidden", func(t *testing.T) {
+		server := newTestServer(&Handler{Feed: streamtest.NewFeed(), Authorizer: denyAll{}})
+		defer server.Close()
+
+		resp, err := http.Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("Invalid last event ID", func(t *testing.T) {
+		server := newTestServer(&Handler{Feed: streamtest.NewFeed()})
+		defer server.Close()
+
+		resp, err := http.Get(server.URL + "?last_event_id=abc")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func readLine(t *testing.T, reader *bufio.Reader) string {
+	t.Helper()
+
+	type result struct {
+		line string
+		err  error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		line, err := reader.ReadString('\n')
+		ch <- result{strings.TrimSuffix(line, "\n"), err}
+	}()
+
+	select {
+	case r := <-ch:
+		require.NoError(t, r.err)
+		return r.line
+	case <-time.After(5 * time.Second):
+		require.NoError(t, errors.New("timed out waiting for stream"))
+		return ""
+	}
+}
+
+func TestUnitWatch(t *testing.T) {
+	feed := streamtest.NewFeed()
+	feed.Append(
+		events.Event{ID: 1, Type: events.TypeAccountBalanceChanged, AccountIDs: []types.AccountID{1}},
+		events.Event{ID: 2, Type: events.TypeAccountBalanceChanged, AccountIDs: []types.AccountID{2}},
+		events.Event{ID: 3, Type: events.TypeAccountCreated, AccountIDs: []types.AccountID{1}},
+		events.Event{ID: 4, Type: events.TypeAccountBalanceChanged, AccountIDs: []types.AccountID{1}},
+	)
+
+	handler := &Handler{Feed: feed, Broker: feed.Broker, Types: []events.Type{events.TypeAccountBalanceChanged}}
+	errStop := errors.New("stop")
+
+	t.Run("Replays backlog and stops on send error", func(t *testing.T) {
+		var received []uint64
+		err := handler.Watch(context.Background(), 1, 1, func(event events.Event) error {
+			received = append(received, event.ID)
+			if event.ID == 5 {
+				return errStop
+			}
+			if event.ID == 4 {
+				go feed.Append(events.Event{ID: 5, Type: events.TypeAccountBalanceChanged, AccountIDs: []types.AccountID{1}})
+			}
+			return nil
+		})
+		assert.ErrorIs(t, err, errStop)
+		assert.Equal(t, []uint64{4, 5}, received)
+	})
+
+	t.Run("Delivers events committed out of ID order", func(t *testing.T) {
+		// IDs are generated before commit, a transaction committing later may hold the smaller ID
+		feed.Append(
+			events.Event{ID: 101, Type: events.TypeAccountBalanceChanged, AccountIDs: []types.AccountID{1}},
+			events.Event{ID: 100, Type: events.TypeAccountBalanceChanged, AccountIDs: []types.AccountID{1}},
+		)
+
+		var received []uint64
+		err := handler.Watch(context.Background(), 1, 5, func(event events.Event) error {
+			received = append(received, event.ID)
+			if len(received) == 2 {
+				return errStop
+			}
+			return nil
+		})
+		assert.ErrorIs(t, err, errStop)
+		assert.Equal(t, []uint64{101, 100}, received)
+	})
+
+	t.Run("Polls events committed by other instances", func(t *testing.T) {
+		polling := &Handler{Feed: feed, PollInterval: 10 * time.Millisecond}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			feed.AppendSilently(events.Event{ID: 102, Type: events.TypeAccountBalanceChanged, AccountIDs: []types.AccountID{1}})
+		}()
+
+		var received []uint64
+		err := polling.Watch(ctx, 1, 100, func(event events.Event) error {
+			received = append(received, event.ID)
+			return errStop
+		})
+		assert.ErrorIs(t, err, errStop)
+		assert.Equal(t, []uint64{102}, received)
+	})
+
+	t.Run("Stops when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		err := handler.Watch(ctx, 3, 0, func(event events.Event) error {
+			return nil
+		})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
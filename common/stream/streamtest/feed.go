@@ -0,0 +1,82 @@
+// Package streamtest provides an in-memory change feed for tests of stream handlers
+package streamtest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/danielkhtse/supreme-adventure/common/events"
+	"github.com/danielkhtse/supreme-adventure/common/feed"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+)
+
+// Feed is an in-memory stream.Feed, events are read in the order they are appended whatever their IDs
+type Feed struct {
+	// Broker receives the appended events, like the broker of a service receives the events it commits
+	Broker *events.Broker
+
+	mu     sync.Mutex
+	events []events.Event
+}
+
+// NewFeed creates an empty Feed
+func NewFeed() *Feed {
+	return &Feed{Broker: events.NewBroker()}
+}
+
+// Append commits events to the feed, then publishes them to Broker
+func (f *Feed) Append(evts ...events.Event) {
+	f.mu.Lock()
+	f.events = append(f.events, evts...)
+	f.mu.Unlock()
+
+	for _, event := range evts {
+		f.Broker.Publish(event)
+	}
+}
+
+// AppendSilently commits events to the feed without publishing them, like events committed by another instance
+func (f *Feed) AppendSilently(evts ...events.Event) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = append(f.events, evts...)
+}
+
+func (f *Feed) ListEvents(ctx context.Context, after string, accountID types.AccountID, limit int, wait time.Duration) (*feed.Page, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	position := uint64(len(f.events))
+	if after != feed.LatestCursor {
+		var err error
+		if position, err = feed.ParseCursor(after); err != nil {
+			return nil, err
+		}
+	}
+
+	page := &feed.Page{Events: []events.Event{}, NextCursor: feed.FormatCursor(position)}
+	for i := int(position); i < len(f.events); i++ {
+		if len(page.Events) == limit {
+			page.HasMore = true
+			break
+		}
+		if accountID == 0 || f.events[i].AffectsAccount(accountID) {
+			page.Events = append(page.Events, f.events[i])
+		}
+		page.NextCursor = feed.FormatCursor(uint64(i + 1))
+	}
+	return page, nil
+}
+
+func (f *Feed) EventCursor(ctx context.Context, eventID uint64) (string, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, event := range f.events {
+		if event.ID == eventID {
+			return feed.FormatCursor(uint64(i + 1)), true, nil
+		}
+	}
+	return feed.FormatCursor(uint64(len(f.events))), false, nil
+}
@@ -6,7 +6,60 @@ type AccountStatus string
 
 type AccountBalance int64
 
+// AccountVersion increases on every balance or status mutation of an account
+type AccountVersion uint64
+
 const (
 	AccountStatusActive   AccountStatus = "active"
 	AccountStatusInactive AccountStatus = "inactive"
 )
+
+// AccountActivityID identifies one balance movement of an account
+type AccountActivityID uint64
+
+// ActivityType is what caused a balance movement
+type ActivityType string
+
+const (
+	ActivityTypeOpening  ActivityType = "opening"
+	ActivityTypeTransfer ActivityType = "transfer"
+)
+
+// ActivityDirection tells whether a balance movement increased or decreased the balance
+type ActivityDirection string
+
+const (
+	ActivityDirectionCredit ActivityDirection = "credit"
+	ActivityDirectionDebit  ActivityDirection = "debit"
+)
+
+// AccountImportID identifies a bulk account import job
+type AccountImportID uint64
+
+// AccountImportFormat is the file format of a bulk account import
+type AccountImportFormat string
+
+const (
+	AccountImportFormatCSV   AccountImportFormat = "csv"
+	AccountImportFormatJSONL AccountImportFormat = "jsonl"
+)
+
+// AccountImportStatus is the progress of a bulk account import job
+type AccountImportStatus string
+
+const (
+	AccountImportStatusPending   AccountImportStatus = "pending"
+	AccountImportStatusRunning   AccountImportStatus = "running"
+	AccountImportStatusCompleted AccountImportStatus = "completed"
+	AccountImportStatusFailed    AccountImportStatus = "failed"
+)
+
+// AccountRole is a role of a principal on an account, determining the actions it may perform on the account
+type AccountRole string
+
+const (
+	AccountRoleOwner    AccountRole = "owner"
+	AccountRoleOperator AccountRole = "operator"
+	AccountRoleAuditor  AccountRole = "auditor"
+	AccountRoleAdmin    AccountRole = "admin"
+)
@@ -0,0 +1,83 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// RawJSON is an encoded JSON value, stored as-is in jsonb columns and embedded as-is when marshaled
+type RawJSON []byte
+
+func (j RawJSON) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+	return j, nil
+}
+
+func (j *RawJSON) UnmarshalJSON(data []byte) error {
+	if j == nil {
+		return errors.New("types.RawJSON: UnmarshalJSON on nil pointer")
+	}
+	*j = append((*j)[0:0], data...)
+	return nil
+}
+
+func (j RawJSON) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil
+	}
+	return string(j), nil
+}
+
+func (j *RawJSON) Scan(src any) error {
+	switch value := src.(type) {
+	case nil:
+		*j = nil
+	case []byte:
+		*j = append(RawJSON(nil), value...)
+	case string:
+		*j = RawJSON(value)
+	default:
+		return fmt.Errorf("types.RawJSON: cannot scan %T", src)
+	}
+	return nil
+}
+
+// AccountIDs is a list of account IDs encoded as JSON strings, like the ID fields tagged `json:",string"`, so
+// clients decoding numbers as doubles do not round IDs above 2^53
+type AccountIDs []AccountID
+
+func (ids AccountIDs) MarshalJSON() ([]byte, error) {
+	if ids == nil {
+		return []byte("null"), nil
+	}
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = strconv.FormatUint(uint64(id), 10)
+	}
+	return json.Marshal(values)
+}
+
+func (ids *AccountIDs) UnmarshalJSON(data []byte) error {
+	var values []string
+	if err := json.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("types.AccountIDs: %w", err)
+	}
+	if values == nil {
+		*ids = nil
+		return nil
+	}
+	*ids = make(AccountIDs, len(values))
+	for i, value := range values {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("types.AccountIDs: invalid account ID %q", value)
+		}
+		(*ids)[i] = AccountID(id)
+	}
+	return nil
+}
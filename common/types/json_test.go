@@ -0,0 +1,27 @@
+package types
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitAccountIDsJSON(t *testing.T) {
+	data, err := json.Marshal(AccountIDs{1, math.MaxUint64})
+	require.NoError(t, err)
+	assert.JSONEq(t, `["1", "18446744073709551615"]`, string(data))
+
+	var ids AccountIDs
+	require.NoError(t, json.Unmarshal(data, &ids))
+	assert.Equal(t, AccountIDs{1, math.MaxUint64}, ids)
+
+	data, err = json.Marshal(AccountIDs(nil))
+	require.NoError(t, err)
+	assert.Equal(t, "null", string(data))
+
+	assert.Error(t, json.Unmarshal([]byte(`[1]`), &ids))
+	assert.Error(t, json.Unmarshal([]byte(`["-1"]`), &ids))
+}
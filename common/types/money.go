@@ -0,0 +1,241 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is the currency of accounts and transactions created without one, the only one supported for now
+const DefaultCurrency = "USD"
+
+// currencyExponents is the number of decimals of the minor unit of ISO 4217 currencies
+var currencyExponents = map[string]int{
+	"AUD": 2, "BHD": 3, "CAD": 2, "CHF": 2, "CNY": 2, "CZK": 2, "DKK": 2, "EUR": 2, "GBP": 2, "HKD": 2,
+	"HUF": 2, "INR": 2, "JOD": 3, "JPY": 0, "KRW": 0, "KWD": 3, "MXN": 2, "NOK": 2, "NZD": 2, "OMR": 3,
+	"PLN": 2, "SEK": 2, "SGD": 2, "TND": 3, "TWD": 2, "USD": 2, "VND": 0, "ZAR": 2,
+}
+
+var (
+	ErrUnknownCurrency  = errors.New("unknown currency")
+	ErrCurrencyMismatch = errors.New("currencies do not match")
+	ErrMoneyOverflow    = errors.New("amount out of range")
+)
+
+// CurrencyExponent returns the number of decimals of the minor unit of a currency, e.g. 2 for USD cents
+func CurrencyExponent(currency string) (int, error) {
+	exponent, ok := currencyExponents[currency]
+	if !ok {
+		return 0, fmt.Errorf("%w %q", ErrUnknownCurrency, currency)
+	}
+	return exponent, nil
+}
+
+// Money is an amount in the minor unit of its currency, e.g. 2550 for 25.50 USD. Arithmetic is checked, it fails
+// on overflow and on amounts of different currencies instead of wrapping around or mixing them.
+type Money struct {
+	Amount   AccountBalance `json:"amount"`
+	Currency string         `json:"currency"`
+}
+
+// ParseMoney parses a decimal amount in major units, e.g. "25.50" USD is 2550 cents. Trailing zeros beyond the
+// exponent of the currency are accepted, any other excess decimal is an error.
+func ParseMoney(value string, currency string) (Money, error) {
+	exponent, err := CurrencyExponent(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	digits := strings.TrimSpace(value)
+	sign := ""
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		sign, digits = digits[:1], digits[1:]
+	}
+	whole, fraction, _ := strings.Cut(digits, ".")
+	fraction = strings.TrimRight(fraction, "0")
+	if whole == "" && fraction == "" || !isDigits(whole) || !isDigits(fraction) {
+		return Money{}, fmt.Errorf("%q is not a decimal amount", value)
+	}
+	if len(fraction) > exponent {
+		return Money{}, fmt.Errorf("%s has at most %d decimals", currency, exponent)
+	}
+
+	amount, err := strconv.ParseInt(sign+whole+fraction+strings.Repeat("0", exponent-len(fraction)), 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", ErrMoneyOverflow, value)
+	}
+	return Money{Amount: AccountBalance(amount), Currency: currency}, nil
+}
+
+// Format returns the amount in major units without the currency, e.g. "25.50". Amounts of an unknown currency
+// are formatted in minor units.
+func (m Money) Format() string {
+	exponent, err := CurrencyExponent(m.Currency)
+	if err != nil || exponent == 0 {
+		return strconv.FormatInt(int64(m.Amount), 10)
+	}
+
+	sign := ""
+	magnitude := uint64(m.Amount)
+	if m.Amount < 0 {
+		sign, magnitude = "-", uint64(-m.Amount)
+	}
+	digits := strconv.FormatUint(magnitude, 10)
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+// String returns the amount in major units followed by the currency, e.g. "25.50 USD"
+func (m Money) String() string {
+	return m.Format() + " " + m.Currency
+}
+
+// Add returns m + o
+func (m Money) Add(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+	sum := m.Amount + o.Amount
+	if (o.Amount > 0 && sum < m.Amount) || (o.Amount < 0 && sum > m.Amount) {
+		return Money{}, fmt.Errorf("%w: %s + %s", ErrMoneyOverflow, m, o)
+	}
+	return Money{Amount: sum, Currency: m.Currency}, nil
+}
+
+// Sub returns m - o
+func (m Money) Sub(o Money) (Money, error) {
+	if m.Currency != o.Currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+	difference := m.Amount - o.Amount
+	if (o.Amount > 0 && difference > m.Amount) || (o.Amount < 0 && difference < m.Amount) {
+		return Money{}, fmt.Errorf("%w: %s - %s", ErrMoneyOverflow, m, o)
+	}
+	return Money{Amount: difference, Currency: m.Currency}, nil
+}
+
+// Mul returns m multiplied by factor
+func (m Money) Mul(factor int64) (Money, error) {
+	amount := int64(m.Amount)
+	product := amount * factor
+	if amount != 0 && (product/amount != factor || (amount == -1 && factor == math.MinInt64) || (factor == -1 && amount == math.MinInt64)) {
+		return Money{}, fmt.Errorf("%w: %s * %d", ErrMoneyOverflow, m, factor)
+	}
+	return Money{Amount: AccountBalance(product), Currency: m.Currency}, nil
+}
+
+// Allocate splits m in parts proportional to the ratios, e.g. Allocate(1, 1, 1) of 1.00 USD is 0.34, 0.33 and 0.33.
+// The parts add up to m exactly: the minor units left over by rounding down go one each to the parts with the
+// largest remainders, the earlier part first on a tie.
+func (m Money) Allocate(ratios ...int64) ([]Money, error) {
+	var total uint64
+	for _, ratio := range ratios {
+		if ratio < 0 {
+			return nil, fmt.Errorf("allocation ratio %d is negative", ratio)
+		}
+		var carry uint64
+		if total, carry = bits.Add64(total, uint64(ratio), 0); carry != 0 || total > math.MaxInt64 {
+			return nil, fmt.Errorf("%w: sum of allocation ratios", ErrMoneyOverflow)
+		}
+	}
+	if total == 0 {
+		return nil, errors.New("allocation ratios must have a positive sum")
+	}
+
+	magnitude := uint64(m.Amount)
+	if m.Amount < 0 {
+		magnitude = uint64(-m.Amount)
+	}
+
+	shares := make([]uint64, len(ratios))
+	remainders := make([]uint64, len(ratios))
+	left := magnitude
+	for i, ratio := range ratios {
+		//magnitude*ratio/total is at most magnitude, so the quotient fits in 64 bits
+		hi, lo := bits.Mul64(magnitude, uint64(ratio))
+		shares[i], remainders[i] = bits.Div64(hi, lo, total)
+		left -= shares[i]
+	}
+
+	order := make([]int, len(ratios))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return remainders[order[a]] > remainders[order[b]] })
+	for _, i := range order[:left] {
+		shares[i]++
+	}
+
+	parts := make([]Money, len(ratios))
+	for i, share := range shares {
+		amount := AccountBalance(share)
+		if m.Amount < 0 {
+			amount = -amount
+		}
+		parts[i] = Money{Amount: amount, Currency: m.Currency}
+	}
+	return parts, nil
+}
+
+// UnmarshalJSON decodes {"amount": 2550, "currency": "USD"}, rejecting unknown currencies
+func (m *Money) UnmarshalJSON(data []byte) error {
+	type plain Money
+	var decoded plain
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if _, err := CurrencyExponent(decoded.Currency); err != nil {
+		return err
+	}
+	*m = Money(decoded)
+	return nil
+}
+
+// Value stores money as text in major units followed by the currency, e.g. "25.50 USD"
+func (m Money) Value() (driver.Value, error) {
+	if _, err := CurrencyExponent(m.Currency); err != nil {
+		return nil, err
+	}
+	return m.String(), nil
+}
+
+// Scan reads money stored by Value
+func (m *Money) Scan(src any) error {
+	var text string
+	switch value := src.(type) {
+	case []byte:
+		text = string(value)
+	case string:
+		text = value
+	default:
+		return fmt.Errorf("types.Money: cannot scan %T", src)
+	}
+
+	amount, currency, ok := strings.Cut(strings.TrimSpace(text), " ")
+	if !ok {
+		return fmt.Errorf("types.Money: cannot scan %q", text)
+	}
+	parsed, err := ParseMoney(amount, currency)
+	if err != nil {
+		return fmt.Errorf("types.Money: %w", err)
+	}
+	*m = parsed
+	return nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
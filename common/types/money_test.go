@@ -0,0 +1,170 @@
+package types
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func usd(amount AccountBalance) Money {
+	return Money{Amount: amount, Currency: "USD"}
+}
+
+func TestUnitParseMoney(t *testing.T) {
+	for value, expected := range map[string]Money{
+		"25.50":     usd(2550),
+		"+0.05":     usd(5),
+		"-1.5":      usd(-150),
+		".5":        usd(50),
+		"12.340000": usd(1234),
+		"0":         usd(0),
+		"1500 ":     usd(150000),
+	} {
+		money, err := ParseMoney(value, "USD")
+		require.NoError(t, err, value)
+		assert.Equal(t, expected, money, value)
+	}
+
+	money, err := ParseMoney("1500", "JPY")
+	require.NoError(t, err)
+	assert.Equal(t, Money{Amount: 1500, Currency: "JPY"}, money)
+
+	money, err = ParseMoney("1.234", "BHD")
+	require.NoError(t, err)
+	assert.Equal(t, Money{Amount: 1234, Currency: "BHD"}, money)
+
+	for _, value := range []string{"", ".", "-", "1.234", "1,5", "1e3", "0x10", "--1", "1.2.3"} {
+		_, err := ParseMoney(value, "USD")
+		assert.Error(t, err, value)
+	}
+
+	_, err = ParseMoney("1.5", "JPY")
+	assert.Error(t, err)
+
+	_, err = ParseMoney("92233720368547758.08", "USD")
+	assert.ErrorIs(t, err, ErrMoneyOverflow)
+
+	_, err = ParseMoney("1", "XYZ")
+	assert.ErrorIs(t, err, ErrUnknownCurrency)
+}
+
+func TestUnitMoneyFormat(t *testing.T) {
+	for money, expected := range map[Money]string{
+		usd(0):                            "0.00",
+		usd(5):                            "0.05",
+		usd(-5):                           "-0.05",
+		usd(123456):                       "1234.56",
+		usd(math.MinInt64):                "-92233720368547758.08",
+		{Amount: 1500, Currency: "JPY"}:   "1500",
+		{Amount: 1234, Currency: "BHD"}:   "1.234",
+		{Amount: 1234, Currency: "XYZ"}:   "1234",
+		{Amount: -1, Currency: "KWD"}:     "-0.001",
+		{Amount: 100000, Currency: "EUR"}: "1000.00",
+	} {
+		assert.Equal(t, expected, money.Format())
+
+		if _, err := CurrencyExponent(money.Currency); err == nil {
+			parsed, err := ParseMoney(money.Format(), money.Currency)
+			require.NoError(t, err)
+			assert.Equal(t, money, parsed)
+		}
+	}
+	assert.Equal(t, "25.50 USD", usd(2550).String())
+}
+
+func TestUnitMoneyArithmetic(t *testing.T) {
+	sum, err := usd(2550).Add(usd(50))
+	require.NoError(t, err)
+	assert.Equal(t, usd(2600), sum)
+
+	difference, err := usd(50).Sub(usd(2550))
+	require.NoError(t, err)
+	assert.Equal(t, usd(-2500), difference)
+
+	product, err := usd(-25).Mul(3)
+	require.NoError(t, err)
+	assert.Equal(t, usd(-75), product)
+
+	_, err = usd(1).Add(Money{Amount: 1, Currency: "EUR"})
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+	_, err = usd(1).Sub(Money{Amount: 1, Currency: "EUR"})
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	for name, operation := range map[string]func() (Money, error){
+		"Add":          func() (Money, error) { return usd(math.MaxInt64).Add(usd(1)) },
+		"Add negative": func() (Money, error) { return usd(math.MinInt64).Add(usd(-1)) },
+		"Sub":          func() (Money, error) { return usd(math.MinInt64).Sub(usd(1)) },
+		"Sub negative": func() (Money, error) { return usd(math.MaxInt64).Sub(usd(-1)) },
+		"Mul":          func() (Money, error) { return usd(math.MaxInt64/2 + 1).Mul(2) },
+		"Mul -1":       func() (Money, error) { return usd(math.MinInt64).Mul(-1) },
+		"Mul min":      func() (Money, error) { return usd(-1).Mul(math.MinInt64) },
+	} {
+		_, err := operation()
+		assert.ErrorIs(t, err, ErrMoneyOverflow, name)
+	}
+}
+
+func TestUnitMoneyAllocate(t *testing.T) {
+	for _, test := range []struct {
+		money    Money
+		ratios   []int64
+		expected []AccountBalance
+	}{
+		{usd(100), []int64{1, 1, 1}, []AccountBalance{34, 33, 33}},
+		{usd(-100), []int64{1, 1, 1}, []AccountBalance{-34, -33, -33}},
+		{usd(5), []int64{3, 7}, []AccountBalance{2, 3}},
+		{usd(5), []int64{2, 8}, []AccountBalance{1, 4}},
+		{usd(100), []int64{1, 0, 1}, []AccountBalance{50, 0, 50}},
+		{usd(2), []int64{1, 1, 1}, []AccountBalance{1, 1, 0}},
+		{usd(0), []int64{1, 2}, []AccountBalance{0, 0}},
+		{usd(10), []int64{70, 20, 10}, []AccountBalance{7, 2, 1}},
+		{usd(math.MaxInt64), []int64{math.MaxInt64 - 1, 1}, []AccountBalance{math.MaxInt64 - 1, 1}},
+		{usd(math.MinInt64), []int64{1}, []AccountBalance{math.MinInt64}},
+	} {
+		parts, err := test.money.Allocate(test.ratios...)
+		require.NoError(t, err)
+
+		var amounts []AccountBalance
+		total := Money{Currency: "USD"}
+		for _, part := range parts {
+			assert.Equal(t, "USD", part.Currency)
+			amounts = append(amounts, part.Amount)
+			total, err = total.Add(part)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, test.expected, amounts, test.money.String())
+		assert.Equal(t, test.money, total)
+	}
+
+	for _, ratios := range [][]int64{nil, {0, 0}, {1, -1}, {math.MaxInt64, 1, 1}} {
+		_, err := usd(100).Allocate(ratios...)
+		assert.Error(t, err, ratios)
+	}
+}
+
+func TestUnitMoneyMarshaling(t *testing.T) {
+	data, err := json.Marshal(usd(2550))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"amount":2550,"currency":"USD"}`, string(data))
+
+	var money Money
+	require.NoError(t, json.Unmarshal(data, &money))
+	assert.Equal(t, usd(2550), money)
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"amount":1,"currency":"usd"}`), &money), ErrUnknownCurrency)
+
+	value, err := usd(-2550).Value()
+	require.NoError(t, err)
+	assert.Equal(t, "-25.50 USD", value)
+
+	var scanned Money
+	require.NoError(t, scanned.Scan([]byte("-25.50 USD")))
+	assert.Equal(t, usd(-2550), scanned)
+	assert.Error(t, scanned.Scan("25.50"))
+	assert.Error(t, scanned.Scan(int64(1)))
+
+	_, err = Money{Amount: 1}.Value()
+	assert.ErrorIs(t, err, ErrUnknownCurrency)
+}
@@ -8,3 +8,14 @@ const (
 	TransactionStatusCompleted TransactionStatus = "completed"
 	TransactionStatusFailed    TransactionStatus = "failed"
 )
+
+// PaymentFileID identifies an uploaded pain.001 payment initiation
+type PaymentFileID uint64
+
+// PaymentFileStatus is the progress of an uploaded pain.001 payment initiation
+type PaymentFileStatus string
+
+const (
+	PaymentFileStatusProcessing PaymentFileStatus = "processing"
+	PaymentFileStatusProcessed  PaymentFileStatus = "processed"
+)
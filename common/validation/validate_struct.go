@@ -1,15 +1,49 @@
 package validation
 
 import (
+	"errors"
+	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 
 	"github.com/danielkhtse/supreme-adventure/common/types"
 )
 
-func ValidateStruct(reqPayload interface{}) error {
-	v := validator.New()
+// FieldError is a validation rule a field of a struct violates
+type FieldError struct {
+	// JSON name of the field
+	Field string `json:"field"`
+
+	Message string `json:"message"`
+}
+
+// FieldErrors lists every violated rule of a struct
+type FieldErrors []FieldError
+
+func (e FieldErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, fieldError := range e {
+		messages[i] = fieldError.Field + ": " + fieldError.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+var structValidator = newStructValidator()
+
+func newStructValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
 
 	v.RegisterValidation("sqlnullint64", func(fl validator.FieldLevel) bool {
 		field := fl.Field()
@@ -45,7 +79,52 @@ func ValidateStruct(reqPayload interface{}) error {
 
 	v.RegisterValidation("transaction_status", validateTransactionStatusEnum)
 
-	return nil
+	return v
+}
+
+// ValidateStruct enforces the validate tags of a struct, reporting every violated rule by its JSON field name. It
+// returns nil or FieldErrors.
+func ValidateStruct(reqPayload interface{}) error {
+	err := structValidator.Struct(reqPayload)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return err
+	}
+
+	fieldErrors := make(FieldErrors, len(validationErrors))
+	for i, fieldError := range validationErrors {
+		fieldErrors[i] = FieldError{Field: fieldError.Field(), Message: ruleMessage(fieldError)}
+	}
+	return fieldErrors
+}
+
+func ruleMessage(fieldError validator.FieldError) string {
+	switch fieldError.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		if fieldError.Kind() == reflect.String {
+			return fmt.Sprintf("must be at least %s characters", fieldError.Param())
+		}
+		return fmt.Sprintf("must be at least %s", fieldError.Param())
+	case "max":
+		if fieldError.Kind() == reflect.String {
+			return fmt.Sprintf("must be at most %s characters", fieldError.Param())
+		}
+		return fmt.Sprintf("must be at most %s", fieldError.Param())
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(fieldError.Param(), " ", ", ")
+	case "url":
+		return "must be an absolute URL"
+	case "transaction_status":
+		return "must be one of pending, completed, failed"
+	default:
+		return fmt.Sprintf("failed the %s rule", fieldError.Tag())
+	}
 }
 
 func validateTransactionStatusEnum(fl validator.FieldLevel) bool {
@@ -0,0 +1,42 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/danielkhtse/supreme-adventure/common/types"
+)
+
+type testAccount struct {
+	ID       uint64 `json:"id" validate:"required"`
+	Balance  int64  `json:"balance" validate:"min=0"`
+	Currency string `json:"currency" validate:"required,oneof=USD EUR"`
+	Owner    string `json:"owner,omitempty" validate:"max=4"`
+	Internal string `json:"-" validate:"max=1"`
+}
+
+func TestUnitValidateStruct(t *testing.T) {
+	assert.NoError(t, ValidateStruct(&testAccount{ID: 1, Currency: "USD"}))
+
+	err := ValidateStruct(&testAccount{Balance: -1, Currency: "GBP", Owner: "owner"})
+	var fieldErrors FieldErrors
+	require.ErrorAs(t, err, &fieldErrors)
+	assert.Equal(t, FieldErrors{
+		{Field: "id", Message: "is required"},
+		{Field: "balance", Message: "must be at least 0"},
+		{Field: "currency", Message: "must be one of USD, EUR"},
+		{Field: "owner", Message: "must be at most 4 characters"},
+	}, fieldErrors)
+	assert.EqualError(t, err, "id: is required; balance: must be at least 0; currency: must be one of USD, EUR; owner: must be at most 4 characters")
+}
+
+func TestUnitValidateStructTransactionStatus(t *testing.T) {
+	type testTransaction struct {
+		Status types.TransactionStatus `json:"status" validate:"required,transaction_status"`
+	}
+
+	assert.NoError(t, ValidateStruct(&testTransaction{Status: types.TransactionStatusCompleted}))
+	assert.EqualError(t, ValidateStruct(&testTransaction{Status: "settled"}), "status: must be one of pending, completed, failed")
+}
@@ -3,6 +3,10 @@
 # Exit on any error
 set -e
 
+# API versions to document, as version:directory of the handlers serving it.
+# The unversioned routes are deprecated aliases of v1 and share its documentation.
+API_VERSIONS=("v1:./internal/api")
+
 # Function to generate swagger docs for a service
 generate_swagger_docs() {
     local service=$1
@@ -29,16 +33,21 @@ generate_swagger_docs() {
         echo "Go files in ./internal/api directory:"
         find ./internal/api -name "*.go" | sort
         
-        # Run swag init with the correct parameters
-        echo "Running swag init..."
-        swag init \
-            --dir ./internal/api \
-            --output ./docs \
-            --generalInfo server.go \
-            --parseDependency \
-            --parseInternal
-            
-        echo "Swagger documentation generated in ./${service}/docs"
+        # Generate one swagger spec per API version
+        for entry in "${API_VERSIONS[@]}"; do
+            local version=${entry%%:*}
+            local dir=${entry#*:}
+
+            echo "Running swag init for ${version}..."
+            swag init \
+                --dir ${dir} \
+                --output ./docs/${version} \
+                --generalInfo server.go \
+                --parseDependency \
+                --parseInternal
+
+            echo "Swagger documentation generated in ./${service}/docs/${version}"
+        done
     fi
     
     # Return to the root directory
@@ -64,12 +73,12 @@ if [ ! -f "swagger-config.json" ]; then
 {
   "urls": [
     {
-      "name": "Account Service API",
-      "url": "/account-docs/swagger.json"
+      "name": "Account Service API v1",
+      "url": "/account-docs/v1/swagger.json"
     },
     {
-      "name": "Transaction Service API",
-      "url": "/transaction-docs/swagger.json"
+      "name": "Transaction Service API v1",
+      "url": "/transaction-docs/v1/swagger.json"
     }
   ],
   "deepLinking": true,
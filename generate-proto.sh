@@ -0,0 +1,29 @@
+#!/bin/bash
+
+# Exit on any error
+set -e
+
+# Install the code generators if not already installed
+if ! command -v buf &> /dev/null; then
+    echo "Installing buf CLI..."
+    go install github.com/bufbuild/buf/cmd/buf@latest
+fi
+if ! command -v protoc-gen-go &> /dev/null; then
+    echo "Installing protoc-gen-go..."
+    go install google.golang.org/protobuf/cmd/protoc-gen-go@latest
+fi
+if ! command -v protoc-gen-go-grpc &> /dev/null; then
+    echo "Installing protoc-gen-go-grpc..."
+    go install google.golang.org/grpc/cmd/protoc-gen-go-grpc@latest
+fi
+export PATH="$PATH:$(go env GOPATH)/bin"
+
+cd ./common/proto
+
+echo "Linting protobuf definitions..."
+buf lint
+
+echo "Generating Go code..."
+buf generate
+
+echo "Protobuf code generation complete!"
@@ -42,13 +42,13 @@ function setupTestAccounts() {
 
     // Create source account with unique integer ID
     const sourceAccount = {
-        account_id: sourceId,
+        account_id: String(sourceId),
         initial_balance: 10000, // $100.00 initial balance
     };
 
     // Create destination account with unique integer ID
     const destAccount = {
-        account_id: destId,
+        account_id: String(destId),
         initial_balance: 10000, // $100.00 initial balance
     };
 
@@ -59,7 +59,7 @@ function setupTestAccounts() {
     // Create source account first
     while (!sourceAccountCreated) {
         const sourceResponse = http.post(
-            `${accountService}/accounts`, 
+            `${accountService}/v1/accounts`, 
             JSON.stringify(sourceAccount),
             { headers: { 'Content-Type': 'application/json' } }
         );
@@ -81,7 +81,7 @@ function setupTestAccounts() {
     // Create destination account after source is created
     while (!destAccountCreated) {
         const destResponse = http.post(
-            `${accountService}/accounts`,
+            `${accountService}/v1/accounts`,
             JSON.stringify(destAccount),
             { headers: { 'Content-Type': 'application/json' } }
         );
@@ -119,7 +119,7 @@ export default function () {
     let createResponse;
     try {
         createResponse = http.post(
-            `${BASE_URL}/transactions`,
+            `${BASE_URL}/v1/transactions`,
             JSON.stringify(transactionData),
             { headers: { 'Content-Type': 'application/json' } }
         );
@@ -0,0 +1,252 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AccountClient calls the account-service API
+type AccountClient struct {
+	client
+}
+
+// NewAccountClient returns a client of the account-service at baseURL (e.g. http://localhost:8080) with DefaultConfig
+func NewAccountClient(baseURL string) *AccountClient {
+	return NewAccountClientWithConfig(baseURL, DefaultConfig())
+}
+
+// NewAccountClientWithConfig returns a client of the account-service at baseURL
+func NewAccountClientWithConfig(baseURL string, config Config) *AccountClient {
+	return &AccountClient{client: newClient(baseURL, config)}
+}
+
+// CreateAccount creates an account. It is not retried, a retry after a lost response could fail with
+// ErrAccountAlreadyExists or create a second account.
+func (c *AccountClient) CreateAccount(ctx context.Context, req CreateAccountRequest) (*Account, error) {
+	var resp accountResponse
+	if err := c.do(ctx, request{method: "POST", path: "/accounts", body: req}, &resp); err != nil {
+		return nil, err
+	}
+	return resp.account(), nil
+}
+
+// GetAccount returns the ID, balance and version of an account
+func (c *AccountClient) GetAccount(ctx context.Context, accountID uint64) (*Account, error) {
+	var resp accountResponse
+	err := c.do(ctx, request{method: "GET", path: fmt.Sprintf("/accounts/%d", accountID), retryable: true}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.account(), nil
+}
+
+// UpdateAccountStatus freezes (AccountStatusInactive) or unfreezes (AccountStatusActive) an account. When ifMatch
+// is given the account must still have one of the versions, the call fails with ErrVersionMismatch otherwise.
+func (c *AccountClient) UpdateAccountStatus(ctx context.Context, accountID uint64, status AccountStatus, ifMatch ...uint64) (*Account, error) {
+	var resp accountResponse
+	err := c.do(ctx, request{
+		method:    "PUT",
+		path:      fmt.Sprintf("/accounts/%d/status", accountID),
+		header:    ifMatchHeader(ifMatch),
+		body:      map[string]AccountStatus{"status": status},
+		retryable: true,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp.account(), nil
+}
+
+// ListAccountGrants returns the principals holding a role on an account
+func (c *AccountClient) ListAccountGrants(ctx context.Context, accountID uint64) ([]AccountGrant, error) {
+	var grants []AccountGrant
+	err := c.do(ctx, request{method: "GET", path: fmt.Sprintf("/accounts/%d/grants", accountID), retryable: true}, &grants)
+	if err != nil {
+		return nil, err
+	}
+	return grants, nil
+}
+
+// GrantAccountRole gives principal a role on an account, replacing the role it held. Only owners and admins of
+// the account can grant roles.
+func (c *AccountClient) GrantAccountRole(ctx context.Context, accountID uint64, principal string, role AccountRole) (*AccountGrant, error) {
+	var grant AccountGrant
+	err := c.do(ctx, request{
+		method:    "PUT",
+		path:      fmt.Sprintf("/accounts/%d/grants", accountID),
+		body:      map[string]any{"principal": principal, "role": role},
+		retryable: true,
+	}, &grant)
+	if err != nil {
+		return nil, err
+	}
+	return &grant, nil
+}
+
+// RevokeAccountRole removes the role of principal on an account
+func (c *AccountClient) RevokeAccountRole(ctx context.Context, accountID uint64, principal string) error {
+	return c.do(ctx, request{
+		method:    "DELETE",
+		path:      fmt.Sprintf("/accounts/%d/grants", accountID),
+		query:     url.Values{"principal": {principal}},
+		retryable: true,
+	}, nil)
+}
+
+// ifMatchHeader sends versions as the quoted entity tags of the If-Match header
+func ifMatchHeader(versions []uint64) http.Header {
+	header := http.Header{}
+	if len(versions) > 0 {
+		etags := make([]string, len(versions))
+		for i, version := range versions {
+			etags[i] = strconv.Quote(strconv.FormatUint(version, 10))
+		}
+		header.Set("If-Match", strings.Join(etags, ", "))
+	}
+	return header
+}
+
+// AccountSort orders listed accounts
+type AccountSort string
+
+const (
+	AccountSortCreatedAtAsc  AccountSort = "created_at"
+	AccountSortCreatedAtDesc AccountSort = "-created_at"
+	AccountSortBalanceAsc    AccountSort = "balance"
+	AccountSortBalanceDesc   AccountSort = "-balance"
+)
+
+// ListAccountsParams filters and sorts listed accounts, zero values do not filter
+type ListAccountsParams struct {
+	Status   AccountStatus
+	Currency string
+	Owner    string
+
+	// Inclusive balance range, nil does not filter
+	MinBalance *int64
+	MaxBalance *int64
+
+	// CreatedFrom is inclusive, CreatedTo is exclusive
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+
+	// Newest first when empty
+	Sort AccountSort
+
+	// Cursor returned as NextCursor by the previous page
+	Cursor string
+
+	// Maximum number of accounts per page, the service default when zero
+	Limit int
+}
+
+func (p ListAccountsParams) query() url.Values {
+	query := url.Values{}
+	setString(query, "status", string(p.Status))
+	setString(query, "currency", p.Currency)
+	setString(query, "owner", p.Owner)
+	if p.MinBalance != nil {
+		query.Set("min_balance", strconv.FormatInt(*p.MinBalance, 10))
+	}
+	if p.MaxBalance != nil {
+		query.Set("max_balance", strconv.FormatInt(*p.MaxBalance, 10))
+	}
+	setTime(query, "created_from", p.CreatedFrom)
+	setTime(query, "created_to", p.CreatedTo)
+	setString(query, "sort", string(p.Sort))
+	setString(query, "cursor", p.Cursor)
+	setInt(query, "limit", int64(p.Limit))
+	return query
+}
+
+// AccountPage is a page of listed accounts
+type AccountPage struct {
+	Accounts []Account `json:"accounts"`
+
+	// Cursor of the next page, empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+
+	HasMore bool `json:"has_more"`
+}
+
+// ListAccounts returns one page of accounts
+func (c *AccountClient) ListAccounts(ctx context.Context, params ListAccountsParams) (*AccountPage, error) {
+	var page AccountPage
+	if err := c.do(ctx, request{method: "GET", path: "/accounts", query: params.query(), retryable: true}, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// Accounts yields every account matching params, starting at params.Cursor
+func (c *AccountClient) Accounts(ctx context.Context, params ListAccountsParams) iter.Seq2[Account, error] {
+	return paginate(ctx, params.Cursor, func(ctx context.Context, cursor string) ([]Account, string, error) {
+		params.Cursor = cursor
+		page, err := c.ListAccounts(ctx, params)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Accounts, page.NextCursor, nil
+	})
+}
+
+// ListActivityParams pages through the activity of an account
+type ListActivityParams struct {
+	// Cursor returned as NextCursor by the previous page
+	Cursor string
+
+	// Maximum number of entries per page, the service default when zero
+	Limit int
+}
+
+// ActivityPage is a page of account activity, newest first
+type ActivityPage struct {
+	Activities []Activity `json:"activities"`
+
+	// Cursor of the next (older) page, empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+
+	HasMore bool `json:"has_more"`
+}
+
+// ListActivity returns one page of the balance movements of an account, newest first
+func (c *AccountClient) ListActivity(ctx context.Context, accountID uint64, params ListActivityParams) (*ActivityPage, error) {
+	query := url.Values{}
+	setString(query, "cursor", params.Cursor)
+	setInt(query, "limit", int64(params.Limit))
+
+	var page ActivityPage
+	err := c.do(ctx, request{method: "GET", path: fmt.Sprintf("/accounts/%d/activity", accountID), query: query, retryable: true}, &page)
+	if err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// Activity yields every balance movement of an account, newest first
+func (c *AccountClient) Activity(ctx context.Context, accountID uint64, params ListActivityParams) iter.Seq2[Activity, error] {
+	return paginate(ctx, params.Cursor, func(ctx context.Context, cursor string) ([]Activity, string, error) {
+		params.Cursor = cursor
+		page, err := c.ListActivity(ctx, accountID, params)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Activities, page.NextCursor, nil
+	})
+}
+
+// ListEvents returns one page of the account-service change feed
+func (c *AccountClient) ListEvents(ctx context.Context, params ListEventsParams) (*EventPage, error) {
+	return c.listEvents(ctx, params)
+}
+
+// Events yields the account-service events after params.After until the feed is caught up
+func (c *AccountClient) Events(ctx context.Context, params ListEventsParams) iter.Seq2[Event, error] {
+	return c.events(ctx, params)
+}
@@ -0,0 +1,164 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// client sends the requests of both service clients
+type client struct {
+	baseURL    string
+	config     Config
+	httpClient *http.Client
+}
+
+func newClient(baseURL string, config Config) client {
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return client{
+		baseURL:    strings.TrimSuffix(baseURL, "/") + "/v1",
+		config:     config,
+		httpClient: httpClient,
+	}
+}
+
+// request describes one API call
+type request struct {
+	method string
+	path   string
+	query  url.Values
+	header http.Header
+	body   any
+
+	// whether the call may be sent again after a failed attempt
+	retryable bool
+
+	// how long the server may hold the response before answering, added to the attempt timeout
+	wait time.Duration
+}
+
+// do sends the request, retrying when allowed, and decodes a successful response body into out when not nil
+func (c *client) do(ctx context.Context, req request, out any) error {
+	var body []byte
+	if req.body != nil {
+		var err error
+		if body, err = json.Marshal(req.body); err != nil {
+			return fmt.Errorf("failed to marshal request body: %w", err)
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		status, respBody, retryAfter, err := c.attempt(ctx, req, body)
+		retry := req.retryable && attempt < c.config.MaxRetries && ctx.Err() == nil
+		if err != nil {
+			if !retry {
+				return err
+			}
+		} else if status >= 200 && status < 300 {
+			if out == nil || len(respBody) == 0 {
+				return nil
+			}
+			if err := json.Unmarshal(respBody, out); err != nil {
+				return fmt.Errorf("failed to decode response: %w", err)
+			}
+			return nil
+		} else if !retry || !retryableStatus(status) {
+			return decodeError(status, respBody)
+		}
+
+		if err := sleep(ctx, c.backoff(attempt, retryAfter)); err != nil {
+			return err
+		}
+	}
+}
+
+// attempt sends the request once, err is set for failures without a response
+func (c *client) attempt(ctx context.Context, req request, body []byte) (status int, respBody []byte, retryAfter time.Duration, err error) {
+	if c.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.config.Timeout+req.wait)
+		defer cancel()
+	}
+
+	target := c.baseURL + req.path
+	if len(req.query) > 0 {
+		target += "?" + req.query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.method, target, reader)
+	if err != nil {
+		return 0, nil, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	for name, values := range c.config.Headers {
+		httpReq.Header[name] = values
+	}
+	for name, values := range req.header {
+		httpReq.Header[name] = values
+	}
+	httpReq.Header.Set("Accept", "application/json")
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
+	if c.config.UserAgent != "" {
+		httpReq.Header.Set("User-Agent", c.config.UserAgent)
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return 0, nil, 0, fmt.Errorf("failed to send %s %s: %w", req.method, req.path, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, 0, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		retryAfter = time.Duration(seconds) * time.Second
+	}
+	return resp.StatusCode, respBody, retryAfter, nil
+}
+
+func retryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || (status >= 500 && status != http.StatusNotImplemented)
+}
+
+// backoff returns the jittered exponential delay before retry attempt+1, at least retryAfter
+func (c *client) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	delay := c.config.RetryBaseDelay << attempt
+	if delay <= 0 || delay > c.config.RetryMaxDelay {
+		delay = c.config.RetryMaxDelay
+	}
+	if delay > 0 {
+		delay = delay/2 + rand.N(delay/2+1)
+	}
+	return max(delay, retryAfter)
+}
+
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
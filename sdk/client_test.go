@@ -0,0 +1,125 @@
+package sdk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testConfig() Config {
+	config := DefaultConfig()
+	config.Timeout = time.Second
+	config.MaxRetries = 2
+	config.RetryBaseDelay = time.Millisecond
+	config.RetryMaxDelay = 5 * time.Millisecond
+	return config
+}
+
+func TestUnitRetries(t *testing.T) {
+	t.Run("Retries reads", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/v1/accounts/7", r.URL.Path)
+			if calls.Add(1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(`{"account_id":"7","balance":100,"version":2}`))
+		}))
+		defer server.Close()
+
+		account, err := NewAccountClientWithConfig(server.URL, testConfig()).GetAccount(context.Background(), 7)
+		require.NoError(t, err)
+		assert.Equal(t, int32(3), calls.Load())
+		assert.Equal(t, &Account{ID: 7, Balance: 100, Version: 2}, account)
+	})
+
+	t.Run("Does not retry creation", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		_, err := NewTransactionClientWithConfig(server.URL, testConfig()).CreateTransaction(context.Background(), CreateTransactionRequest{
+			SourceAccountID: 1,
+			DestAccountID:   2,
+			Amount:          50,
+		})
+		assert.ErrorIs(t, err, &Error{Code: CodeUnavailable})
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("Does not retry client errors", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.Header().Set("Content-Type", "application/problem+json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"type":"urn:supreme-adventure:problem:transaction_not_found","title":"Not Found","status":404,"detail":"transaction not found","code":"transaction_not_found","message":"transaction not found"}`))
+		}))
+		defer server.Close()
+
+		_, err := NewTransactionClientWithConfig(server.URL, testConfig()).GetTransaction(context.Background(), 9)
+		assert.ErrorIs(t, err, ErrTransactionNotFound)
+		assert.EqualError(t, err, "transaction not found")
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("Stops when the context is done", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := NewAccountClientWithConfig(server.URL, testConfig()).GetAccount(ctx, 1)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestUnitLongPollTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.Write([]byte(`{"events":[],"next_cursor":"12","has_more":false}`))
+	}))
+	defer server.Close()
+
+	config := testConfig()
+	config.Timeout = 50 * time.Millisecond
+	config.MaxRetries = 0
+	client := NewTransactionClientWithConfig(server.URL, config)
+
+	page, err := client.ListEvents(context.Background(), ListEventsParams{After: LatestCursor, Wait: time.Second})
+	require.NoError(t, err)
+	assert.Equal(t, "12", page.NextCursor)
+
+	_, err = client.ListEvents(context.Background(), ListEventsParams{After: LatestCursor})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestUnitDecodeError(t *testing.T) {
+	e := decodeError(http.StatusConflict, []byte(`{"code":"account_frozen","status":409,"detail":"account 2 is inactive"}`))
+	assert.ErrorIs(t, e, ErrAccountFrozen)
+	assert.Equal(t, "account 2 is inactive", e.Error())
+
+	// plain error responses and bodies which are not JSON fall back to the status
+	e = decodeError(http.StatusBadRequest, []byte(`{"message":"invalid request body"}`))
+	assert.ErrorIs(t, e, ErrInvalidArgument)
+	assert.Equal(t, "invalid request body", e.Error())
+
+	e = decodeError(http.StatusBadGateway, []byte("<html>bad gateway</html>"))
+	assert.ErrorIs(t, e, ErrInternal)
+	assert.Equal(t, http.StatusBadGateway, e.Status)
+	assert.Equal(t, "Bad Gateway", e.Error())
+}
@@ -0,0 +1,39 @@
+package sdk
+
+import (
+	"net/http"
+	"time"
+)
+
+// Config tunes the clients, start from DefaultConfig
+type Config struct {
+	// HTTP client sending the requests, http.DefaultClient when nil
+	HTTPClient *http.Client
+
+	// Timeout of each attempt, zero leaves it to the context
+	Timeout time.Duration
+
+	// Retries after the first attempt of a retryable call
+	MaxRetries int
+
+	// Backoff before the first retry, doubled for every further retry up to RetryMaxDelay
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+
+	// Headers sent with every request, e.g. Authorization
+	Headers http.Header
+
+	// User-Agent of the requests
+	UserAgent string
+}
+
+// DefaultConfig returns the configuration used by NewAccountClient and NewTransactionClient
+func DefaultConfig() Config {
+	return Config{
+		Timeout:        10 * time.Second,
+		MaxRetries:     3,
+		RetryBaseDelay: 100 * time.Millisecond,
+		RetryMaxDelay:  2 * time.Second,
+		UserAgent:      "supreme-adventure-go-sdk",
+	}
+}
@@ -0,0 +1,27 @@
+// Package sdk provides typed Go clients for the v1 REST APIs of account-service and transaction-service.
+//
+// The clients share one behaviour:
+//
+//   - Every call takes a context, its deadline and cancellation bound the call including retries
+//   - Reads, deletes and transfers are retried on connection errors, 429 and 5xx responses with jittered
+//     exponential backoff. Transfers always carry a transfer ID, generated when the caller does not set one,
+//     so account-service applies a retried transfer at most once. Creating accounts and transactions is
+//     not retried as the services cannot deduplicate them.
+//   - Error responses are returned as *Error, match them with errors.Is against the Err* values
+//   - List methods return one page, the iterator methods walk every page
+//
+// Example:
+//
+//	accounts := sdk.NewAccountClient("http://localhost:8080")
+//	account, err := accounts.GetAccount(ctx, 12345)
+//	if errors.Is(err, sdk.ErrAccountNotFound) {
+//		...
+//	}
+//
+//	for account, err := range accounts.Accounts(ctx, sdk.ListAccountsParams{Status: sdk.AccountStatusActive}) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+package sdk
@@ -0,0 +1,157 @@
+package sdk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Code is the stable machine-readable code of an API error
+type Code string
+
+// Codes returned by the services
+const (
+	CodeAccountNotFound      Code = "account_not_found"
+	CodeAccountAlreadyExists Code = "account_already_exists"
+	CodeAccountFrozen        Code = "account_frozen"
+	CodeInsufficientFunds    Code = "insufficient_funds"
+	CodeSameAccount          Code = "same_account"
+	CodeTransferIDConflict   Code = "transfer_id_conflict"
+	CodeVersionMismatch      Code = "version_mismatch"
+	CodeLockTimeout          Code = "lock_timeout"
+	CodeTransactionNotFound  Code = "transaction_not_found"
+	CodeWebhookNotFound      Code = "webhook_not_found"
+	CodeDeliveryNotFound     Code = "webhook_delivery_not_found"
+	CodeInvalidCursor        Code = "invalid_cursor"
+	CodeCursorExpired        Code = "cursor_expired"
+	CodeImportNotFound       Code = "account_import_not_found"
+	CodeImportNotResumable   Code = "account_import_not_resumable"
+	CodePaymentFileNotFound  Code = "payment_file_not_found"
+	CodePaymentFileRunning   Code = "payment_file_running"
+	CodeCurrencyMismatch     Code = "currency_mismatch"
+	CodeInsufficientScope    Code = "insufficient_scope"
+	CodeAddressNotAllowed    Code = "address_not_allowed"
+	CodeAPIKeyNotFound       Code = "api_key_not_found"
+	CodeAPIKeyRevoked        Code = "api_key_revoked"
+	CodeAccountAccessDenied  Code = "account_access_denied"
+
+	CodeInvalidArgument    Code = "invalid_argument"
+	CodeUnauthenticated    Code = "unauthenticated"
+	CodeForbidden          Code = "forbidden"
+	CodeNotFound           Code = "not_found"
+	CodeConflict           Code = "conflict"
+	CodePreconditionFailed Code = "precondition_failed"
+	CodeRateLimited        Code = "rate_limited"
+	CodeUnavailable        Code = "unavailable"
+	CodeInternal           Code = "internal"
+)
+
+// Error is an error response of a service, decoded from its RFC 7807 problem details
+type Error struct {
+	// HTTP status of the response
+	Status int `json:"status"`
+
+	// Stable machine-readable code, the codes above or a newer one
+	Code Code `json:"code"`
+
+	// Human-readable explanation, may change between releases
+	Detail string `json:"detail"`
+
+	// URI reference identifying the problem type
+	Type string `json:"type,omitempty"`
+
+	// Short summary of the problem type
+	Title string `json:"title,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if e.Detail == "" {
+		return fmt.Sprintf("%s (status %d)", e.Code, e.Status)
+	}
+	return e.Detail
+}
+
+// Is reports whether target is an *Error with the same code
+func (e *Error) Is(target error) bool {
+	var t *Error
+	return errors.As(target, &t) && t.Code == e.Code
+}
+
+// Errors to match with errors.Is
+var (
+	ErrAccountNotFound      = &Error{Code: CodeAccountNotFound}
+	ErrAccountAlreadyExists = &Error{Code: CodeAccountAlreadyExists}
+	ErrAccountFrozen        = &Error{Code: CodeAccountFrozen}
+	ErrInsufficientFunds    = &Error{Code: CodeInsufficientFunds}
+	ErrSameAccount          = &Error{Code: CodeSameAccount}
+	ErrTransferIDConflict   = &Error{Code: CodeTransferIDConflict}
+	ErrVersionMismatch      = &Error{Code: CodeVersionMismatch}
+	ErrLockTimeout          = &Error{Code: CodeLockTimeout}
+	ErrTransactionNotFound  = &Error{Code: CodeTransactionNotFound}
+	ErrWebhookNotFound      = &Error{Code: CodeWebhookNotFound}
+	ErrDeliveryNotFound     = &Error{Code: CodeDeliveryNotFound}
+	ErrInvalidCursor        = &Error{Code: CodeInvalidCursor}
+	ErrCursorExpired        = &Error{Code: CodeCursorExpired}
+	ErrImportNotFound       = &Error{Code: CodeImportNotFound}
+	ErrImportNotResumable   = &Error{Code: CodeImportNotResumable}
+	ErrPaymentFileNotFound  = &Error{Code: CodePaymentFileNotFound}
+	ErrPaymentFileRunning   = &Error{Code: CodePaymentFileRunning}
+	ErrCurrencyMismatch     = &Error{Code: CodeCurrencyMismatch}
+	ErrInsufficientScope    = &Error{Code: CodeInsufficientScope}
+	ErrAddressNotAllowed    = &Error{Code: CodeAddressNotAllowed}
+	ErrAPIKeyNotFound       = &Error{Code: CodeAPIKeyNotFound}
+	ErrAPIKeyRevoked        = &Error{Code: CodeAPIKeyRevoked}
+	ErrAccountAccessDenied  = &Error{Code: CodeAccountAccessDenied}
+	ErrInvalidArgument      = &Error{Code: CodeInvalidArgument}
+	ErrUnauthenticated      = &Error{Code: CodeUnauthenticated}
+	ErrInternal             = &Error{Code: CodeInternal}
+)
+
+// decodeError builds the *Error of an error response, falling back to a code derived from the status
+// for responses which are not problem details (e.g. from a proxy)
+func decodeError(status int, body []byte) *Error {
+	problem := struct {
+		Error
+		Message string `json:"message"`
+	}{}
+	if err := json.Unmarshal(body, &problem); err != nil {
+		problem.Error = Error{}
+	}
+
+	e := problem.Error
+	e.Status = status
+	if e.Detail == "" {
+		e.Detail = problem.Message
+	}
+	if e.Detail == "" {
+		e.Detail = http.StatusText(status)
+	}
+	if e.Code == "" {
+		e.Code = codeForStatus(status)
+	}
+	return &e
+}
+
+func codeForStatus(status int) Code {
+	switch status {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return CodeInvalidArgument
+	case http.StatusUnauthorized:
+		return CodeUnauthenticated
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusPreconditionFailed:
+		return CodePreconditionFailed
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	default:
+		return CodeInternal
+	}
+}
@@ -0,0 +1,121 @@
+package sdk
+
+import (
+	"context"
+	"iter"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// pageFunc fetches the page after cursor, returning its items and the cursor of the next page, empty on the last page
+type pageFunc[T any] func(ctx context.Context, cursor string) (items []T, next string, err error)
+
+// paginate yields every item of every page starting at cursor, stopping after the first error
+func paginate[T any](ctx context.Context, cursor string, fetch pageFunc[T]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for {
+			items, next, err := fetch(ctx, cursor)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if next == "" {
+				return
+			}
+			cursor = next
+		}
+	}
+}
+
+// EventPage is a page of the change feed
+type EventPage struct {
+	Events []Event `json:"events"`
+
+	// Cursor to pass as After to continue reading, equal to the request cursor when no events were returned
+	NextCursor string `json:"next_cursor"`
+
+	// Whether more events were available than the limit allowed
+	HasMore bool `json:"has_more"`
+}
+
+// LatestCursor passed as After starts reading after the newest event, the page returns its cursor
+const LatestCursor = "latest"
+
+// ListEventsParams reads the change feed
+type ListEventsParams struct {
+	// Cursor returned as NextCursor by the previous page or LatestCursor, empty reads from the oldest retained event
+	After string
+
+	// Only events affecting this account, zero reads the events of every account, which requires a role on every
+	// account
+	AccountID uint64
+
+	// Maximum number of events, the service default when zero
+	Limit int
+
+	// How long to wait for new events when none are available, at most 30 seconds. Each attempt may take Wait
+	// longer than Config.Timeout.
+	Wait time.Duration
+}
+
+func (p ListEventsParams) query() url.Values {
+	query := url.Values{}
+	setString(query, "after", p.After)
+	if p.AccountID != 0 {
+		query.Set("account_id", strconv.FormatUint(p.AccountID, 10))
+	}
+	setInt(query, "limit", int64(p.Limit))
+	setInt(query, "wait", int64(p.Wait/time.Second))
+	return query
+}
+
+// listEvents reads one page of the change feed, served by both services
+func (c *client) listEvents(ctx context.Context, params ListEventsParams) (*EventPage, error) {
+	var page EventPage
+	err := c.do(ctx, request{method: "GET", path: "/events", query: params.query(), retryable: true, wait: params.Wait}, &page)
+	if err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// events yields the events after params.After until the feed is caught up, Wait is ignored
+func (c *client) events(ctx context.Context, params ListEventsParams) iter.Seq2[Event, error] {
+	params.Wait = 0
+	return paginate(ctx, params.After, func(ctx context.Context, cursor string) ([]Event, string, error) {
+		params.After = cursor
+		page, err := c.listEvents(ctx, params)
+		if err != nil {
+			return nil, "", err
+		}
+		if !page.HasMore {
+			return page.Events, "", nil
+		}
+		return page.Events, page.NextCursor, nil
+	})
+}
+
+func setString(query url.Values, name string, value string) {
+	if value != "" {
+		query.Set(name, value)
+	}
+}
+
+func setInt(query url.Values, name string, value int64) {
+	if value != 0 {
+		query.Set(name, strconv.FormatInt(value, 10))
+	}
+}
+
+func setTime(query url.Values, name string, value time.Time) {
+	if !value.IsZero() {
+		query.Set(name, value.Format(time.RFC3339))
+	}
+}
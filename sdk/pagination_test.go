@@ -0,0 +1,87 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitIterators(t *testing.T) {
+	t.Run("Walks every page", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "active", r.URL.Query().Get("status"))
+			assert.Equal(t, "0", r.URL.Query().Get("min_balance"))
+			switch r.URL.Query().Get("cursor") {
+			case "":
+				w.Write([]byte(`{"accounts":[{"id":"1"},{"id":"2"}],"next_cursor":"c2","has_more":true}`))
+			case "c2":
+				w.Write([]byte(`{"accounts":[{"id":"3"}],"has_more":false}`))
+			default:
+				t.Errorf("unexpected cursor %q", r.URL.Query().Get("cursor"))
+			}
+		}))
+		defer server.Close()
+
+		minBalance := int64(0)
+		var ids []uint64
+		for account, err := range NewAccountClientWithConfig(server.URL, testConfig()).Accounts(context.Background(), ListAccountsParams{
+			Status:     AccountStatusActive,
+			MinBalance: &minBalance,
+		}) {
+			require.NoError(t, err)
+			ids = append(ids, account.ID)
+		}
+		assert.Equal(t, []uint64{1, 2, 3}, ids)
+	})
+
+	t.Run("Stops on error", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("cursor") == "" {
+				w.Write([]byte(`{"transactions":[{"id":"1"}],"next_cursor":"c2","has_more":true}`))
+				return
+			}
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":"invalid_cursor","status":400,"detail":"invalid cursor"}`))
+		}))
+		defer server.Close()
+
+		var results []string
+		for transaction, err := range NewTransactionClientWithConfig(server.URL, testConfig()).Transactions(context.Background(), ListTransactionsParams{}) {
+			if err != nil {
+				assert.ErrorIs(t, err, ErrInvalidCursor)
+				results = append(results, "error")
+				continue
+			}
+			results = append(results, fmt.Sprint(transaction.ID))
+		}
+		assert.Equal(t, []string{"1", "error"}, results)
+	})
+
+	t.Run("Reads the feed until caught up", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Empty(t, r.URL.Query().Get("wait"))
+			assert.Equal(t, "7", r.URL.Query().Get("account_id"))
+			switch r.URL.Query().Get("after") {
+			case "a1":
+				w.Write([]byte(`{"events":[{"id":"2","type":"account.created"}],"next_cursor":"a2","has_more":true}`))
+			case "a2":
+				w.Write([]byte(`{"events":[{"id":"3","type":"account.balance_changed"}],"next_cursor":"a3","has_more":false}`))
+			default:
+				t.Errorf("unexpected cursor %q", r.URL.Query().Get("after"))
+			}
+		}))
+		defer server.Close()
+
+		var ids []uint64
+		for event, err := range NewAccountClientWithConfig(server.URL, testConfig()).Events(context.Background(), ListEventsParams{After: "a1", AccountID: 7, Wait: 5}) {
+			require.NoError(t, err)
+			ids = append(ids, event.ID)
+		}
+		assert.Equal(t, []uint64{2, 3}, ids)
+	})
+}
@@ -0,0 +1,229 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// TransactionClient calls the transaction-service API
+type TransactionClient struct {
+	client
+}
+
+// NewTransactionClient returns a client of the transaction-service at baseURL (e.g. http://localhost:8081) with DefaultConfig
+func NewTransactionClient(baseURL string) *TransactionClient {
+	return NewTransactionClientWithConfig(baseURL, DefaultConfig())
+}
+
+// NewTransactionClientWithConfig returns a client of the transaction-service at baseURL
+func NewTransactionClientWithConfig(baseURL string, config Config) *TransactionClient {
+	return &TransactionClient{client: newClient(baseURL, config)}
+}
+
+// CreateTransaction transfers funds between two accounts and returns the recorded transaction.
+// It is not retried, a retry after a lost response would create a second transaction.
+func (c *TransactionClient) CreateTransaction(ctx context.Context, req CreateTransactionRequest) (*Transaction, error) {
+	var transaction Transaction
+	if err := c.do(ctx, request{method: "POST", path: "/transactions", body: req}, &transaction); err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// GetTransaction returns a transaction
+func (c *TransactionClient) GetTransaction(ctx context.Context, transactionID uint64) (*Transaction, error) {
+	var transaction Transaction
+	err := c.do(ctx, request{method: "GET", path: fmt.Sprintf("/transactions/%d", transactionID), retryable: true}, &transaction)
+	if err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// ListTransactionsParams filters listed transactions, zero values do not filter
+type ListTransactionsParams struct {
+	// Account on either side of the transaction
+	AccountID uint64
+
+	Status TransactionStatus
+
+	// Inclusive amount range
+	MinAmount int64
+	MaxAmount int64
+
+	// CreatedFrom is inclusive, CreatedTo is exclusive
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+
+	// Oldest first instead of newest first
+	Ascending bool
+
+	// Cursor returned as NextCursor by the previous page
+	Cursor string
+
+	// Maximum number of transactions per page, the service default when zero
+	Limit int
+}
+
+func (p ListTransactionsParams) query() url.Values {
+	query := url.Values{}
+	if p.AccountID != 0 {
+		query.Set("account_id", strconv.FormatUint(p.AccountID, 10))
+	}
+	setString(query, "status", string(p.Status))
+	setInt(query, "min_amount", p.MinAmount)
+	setInt(query, "max_amount", p.MaxAmount)
+	setTime(query, "created_from", p.CreatedFrom)
+	setTime(query, "created_to", p.CreatedTo)
+	if p.Ascending {
+		query.Set("order", "asc")
+	}
+	setString(query, "cursor", p.Cursor)
+	setInt(query, "limit", int64(p.Limit))
+	return query
+}
+
+// TransactionPage is a page of listed transactions
+type TransactionPage struct {
+	Transactions []Transaction `json:"transactions"`
+
+	// Cursor of the next page, empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+
+	HasMore bool `json:"has_more"`
+}
+
+// ListTransactions returns one page of transactions
+func (c *TransactionClient) ListTransactions(ctx context.Context, params ListTransactionsParams) (*TransactionPage, error) {
+	var page TransactionPage
+	err := c.do(ctx, request{method: "GET", path: "/transactions", query: params.query(), retryable: true}, &page)
+	if err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// Transactions yields every transaction matching params, starting at params.Cursor
+func (c *TransactionClient) Transactions(ctx context.Context, params ListTransactionsParams) iter.Seq2[Transaction, error] {
+	return paginate(ctx, params.Cursor, func(ctx context.Context, cursor string) ([]Transaction, string, error) {
+		params.Cursor = cursor
+		page, err := c.ListTransactions(ctx, params)
+		if err != nil {
+			return nil, "", err
+		}
+		return page.Transactions, page.NextCursor, nil
+	})
+}
+
+// ListEvents returns one page of the transaction-service change feed
+func (c *TransactionClient) ListEvents(ctx context.Context, params ListEventsParams) (*EventPage, error) {
+	return c.listEvents(ctx, params)
+}
+
+// Events yields the transaction-service events after params.After until the feed is caught up
+func (c *TransactionClient) Events(ctx context.Context, params ListEventsParams) iter.Seq2[Event, error] {
+	return c.events(ctx, params)
+}
+
+// CreateWebhookEndpoint registers a webhook endpoint, the returned endpoint is the only one carrying the secret
+func (c *TransactionClient) CreateWebhookEndpoint(ctx context.Context, req CreateWebhookEndpointRequest) (*WebhookEndpoint, error) {
+	var endpoint WebhookEndpoint
+	if err := c.do(ctx, request{method: "POST", path: "/webhooks", body: req}, &endpoint); err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+// ListWebhookEndpoints returns every webhook endpoint
+func (c *TransactionClient) ListWebhookEndpoints(ctx context.Context) ([]WebhookEndpoint, error) {
+	var endpoints []WebhookEndpoint
+	if err := c.do(ctx, request{method: "GET", path: "/webhooks", retryable: true}, &endpoints); err != nil {
+		return nil, err
+	}
+	return endpoints, nil
+}
+
+// GetWebhookEndpoint returns a webhook endpoint
+func (c *TransactionClient) GetWebhookEndpoint(ctx context.Context, endpointID uint64) (*WebhookEndpoint, error) {
+	var endpoint WebhookEndpoint
+	err := c.do(ctx, request{method: "GET", path: fmt.Sprintf("/webhooks/%d", endpointID), retryable: true}, &endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+// DeleteWebhookEndpoint deletes a webhook endpoint
+func (c *TransactionClient) DeleteWebhookEndpoint(ctx context.Context, endpointID uint64) error {
+	return c.do(ctx, request{method: "DELETE", path: fmt.Sprintf("/webhooks/%d", endpointID), retryable: true}, nil)
+}
+
+// ListWebhookDeliveries returns the most recent deliveries of an endpoint, at most limit or the service default when zero
+func (c *TransactionClient) ListWebhookDeliveries(ctx context.Context, endpointID uint64, limit int) ([]WebhookDelivery, error) {
+	query := url.Values{}
+	setInt(query, "limit", int64(limit))
+
+	var deliveries []WebhookDelivery
+	err := c.do(ctx, request{method: "GET", path: fmt.Sprintf("/webhooks/%d/deliveries", endpointID), query: query, retryable: true}, &deliveries)
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// RedeliverWebhook schedules a delivery to be sent again
+func (c *TransactionClient) RedeliverWebhook(ctx context.Context, endpointID uint64, deliveryID uint64) (*WebhookDelivery, error) {
+	var delivery WebhookDelivery
+	path := fmt.Sprintf("/webhooks/%d/deliveries/%d/redeliver", endpointID, deliveryID)
+	if err := c.do(ctx, request{method: "POST", path: path, retryable: true}, &delivery); err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// CreateAPIKey creates an API key, the returned key is the only one carrying the key itself
+func (c *TransactionClient) CreateAPIKey(ctx context.Context, req CreateAPIKeyRequest) (*APIKey, error) {
+	var apiKey APIKey
+	if err := c.do(ctx, request{method: "POST", path: "/api-keys", body: req}, &apiKey); err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+// ListAPIKeys returns every API key, revoked keys included
+func (c *TransactionClient) ListAPIKeys(ctx context.Context) ([]APIKey, error) {
+	var apiKeys []APIKey
+	if err := c.do(ctx, request{method: "GET", path: "/api-keys", retryable: true}, &apiKeys); err != nil {
+		return nil, err
+	}
+	return apiKeys, nil
+}
+
+// GetAPIKey returns an API key
+func (c *TransactionClient) GetAPIKey(ctx context.Context, apiKeyID uint64) (*APIKey, error) {
+	var apiKey APIKey
+	err := c.do(ctx, request{method: "GET", path: fmt.Sprintf("/api-keys/%d", apiKeyID), retryable: true}, &apiKey)
+	if err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+// RotateAPIKey replaces the key of an API key, the previous key stops working at once. It is not retried, a retry
+// would replace the key returned by the first attempt.
+func (c *TransactionClient) RotateAPIKey(ctx context.Context, apiKeyID uint64) (*APIKey, error) {
+	var apiKey APIKey
+	if err := c.do(ctx, request{method: "POST", path: fmt.Sprintf("/api-keys/%d/rotate", apiKeyID)}, &apiKey); err != nil {
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+// RevokeAPIKey stops an API key from authenticating
+func (c *TransactionClient) RevokeAPIKey(ctx context.Context, apiKeyID uint64) error {
+	return c.do(ctx, request{method: "DELETE", path: fmt.Sprintf("/api-keys/%d", apiKeyID), retryable: true}, nil)
+}
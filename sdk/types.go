@@ -0,0 +1,272 @@
+package sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// AccountStatus is the status of an account, transfers from and to inactive accounts are rejected
+type AccountStatus string
+
+const (
+	AccountStatusActive   AccountStatus = "active"
+	AccountStatusInactive AccountStatus = "inactive"
+)
+
+// Account is an account of account-service. Amounts are in the smallest units of the currency (e.g. cents for USD).
+type Account struct {
+	ID             uint64        `json:"id,string"`
+	Balance        int64         `json:"balance"`
+	InitialBalance int64         `json:"initial_balance"`
+	Currency       string        `json:"currency"`
+	Status         AccountStatus `json:"status"`
+	Owner          string        `json:"owner,omitempty"`
+	Version        uint64        `json:"version"`
+	CreatedAt      time.Time     `json:"createdAt"`
+	UpdatedAt      time.Time     `json:"updatedAt"`
+}
+
+// accountResponse is the account returned by GET and POST /accounts, a subset of the listed account
+type accountResponse struct {
+	ID       uint64 `json:"account_id,string"`
+	Balance  int64  `json:"balance"`
+	Currency string `json:"currency"`
+	Version  uint64 `json:"version"`
+}
+
+func (r accountResponse) account() *Account {
+	return &Account{ID: r.ID, Balance: r.Balance, Currency: r.Currency, Version: r.Version}
+}
+
+// CreateAccountRequest creates an account
+type CreateAccountRequest struct {
+	// ID of the new account, generated by account-service when zero
+	AccountID uint64 `json:"account_id,string,omitempty"`
+
+	InitialBalance int64 `json:"initial_balance"`
+
+	// Optional reference to the customer owning the account, at most 64 characters
+	Owner string `json:"owner,omitempty"`
+}
+
+// ActivityType is the kind of an account activity entry
+type ActivityType string
+
+const (
+	ActivityTypeOpening  ActivityType = "opening"
+	ActivityTypeTransfer ActivityType = "transfer"
+)
+
+// ActivityDirection tells whether an activity entry credits or debits the account
+type ActivityDirection string
+
+const (
+	ActivityDirectionCredit ActivityDirection = "credit"
+	ActivityDirectionDebit  ActivityDirection = "debit"
+)
+
+// Activity is one balance movement of an account with the resulting balance
+type Activity struct {
+	ID                    uint64            `json:"id,string"`
+	AccountID             uint64            `json:"account_id,string"`
+	Type                  ActivityType      `json:"type"`
+	Direction             ActivityDirection `json:"direction"`
+	CounterpartyAccountID uint64            `json:"counterparty_account_id,string,omitempty"`
+	Amount                int64             `json:"amount"`
+	BalanceAfter          int64             `json:"balance_after"`
+	TransactionID         uint64            `json:"transaction_id,string,omitempty"`
+	TransferID            string            `json:"transfer_id,omitempty"`
+	CreatedAt             time.Time         `json:"created_at"`
+}
+
+// TransactionStatus is the status of a transaction
+type TransactionStatus string
+
+const (
+	TransactionStatusPending   TransactionStatus = "pending"
+	TransactionStatusCompleted TransactionStatus = "completed"
+	TransactionStatusFailed    TransactionStatus = "failed"
+)
+
+// Transaction is a transfer between two accounts recorded by transaction-service
+type Transaction struct {
+	ID              uint64            `json:"id,string"`
+	SourceAccountID uint64            `json:"source_account_id,string"`
+	DestAccountID   uint64            `json:"destination_account_id,string"`
+	Amount          int64             `json:"amount"`
+	Currency        string            `json:"currency"`
+	Status          TransactionStatus `json:"status"`
+	Description     string            `json:"description"`
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+}
+
+// CreateTransactionRequest creates a transaction between two accounts
+type CreateTransactionRequest struct {
+	SourceAccountID uint64 `json:"source_account_id,string"`
+	DestAccountID   uint64 `json:"destination_account_id,string"`
+	Amount          int64  `json:"amount"`
+}
+
+// EventType is the kind of a change feed event
+type EventType string
+
+const (
+	EventTypeAccountCreated           EventType = "account.created"
+	EventTypeAccountBalanceChanged    EventType = "account.balance_changed"
+	EventTypeTransferApplied          EventType = "transfer.applied"
+	EventTypeTransactionStatusChanged EventType = "transaction.status_changed"
+)
+
+// Event is a change feed event, Data holds the type specific payload
+type Event struct {
+	ID         uint64          `json:"id,string"`
+	Type       EventType       `json:"type"`
+	AccountIDs AccountIDs      `json:"account_ids"`
+	Data       json.RawMessage `json:"data"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+// AccountIDs is a list of account IDs, encoded as JSON strings like every ID of the API
+type AccountIDs []uint64
+
+func (ids AccountIDs) MarshalJSON() ([]byte, error) {
+	if ids == nil {
+		return []byte("null"), nil
+	}
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = strconv.FormatUint(id, 10)
+	}
+	return json.Marshal(values)
+}
+
+func (ids *AccountIDs) UnmarshalJSON(data []byte) error {
+	var values []string
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	if values == nil {
+		*ids = nil
+		return nil
+	}
+	*ids = make(AccountIDs, len(values))
+	for i, value := range values {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid account ID %q", value)
+		}
+		(*ids)[i] = id
+	}
+	return nil
+}
+
+// WebhookEndpoint receives signed deliveries of the events it subscribes to
+type WebhookEndpoint struct {
+	ID         uint64      `json:"id,string"`
+	URL        string      `json:"url"`
+	EventTypes []EventType `json:"event_types"`
+	AccountID  uint64      `json:"account_id,string,omitempty"`
+	Active     bool        `json:"active"`
+	CreatedAt  time.Time   `json:"created_at"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+
+	// HMAC-SHA256 key signing the deliveries, only returned when the endpoint is created
+	Secret string `json:"secret,omitempty"`
+}
+
+// CreateWebhookEndpointRequest registers a webhook endpoint
+type CreateWebhookEndpointRequest struct {
+	URL string `json:"url"`
+
+	// Event types to deliver, empty subscribes to every type
+	EventTypes []EventType `json:"event_types,omitempty"`
+
+	// Only deliver events affecting this account, zero delivers the events of every account
+	AccountID uint64 `json:"account_id,string,omitempty"`
+}
+
+// WebhookDeliveryStatus is the status of a webhook delivery
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryStatusSucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryStatusFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery is one event sent, or to be sent, to a webhook endpoint
+type WebhookDelivery struct {
+	ID                 uint64                `json:"id,string"`
+	EndpointID         uint64                `json:"endpoint_id,string"`
+	EventID            uint64                `json:"event_id,string"`
+	EventType          EventType             `json:"event_type"`
+	Payload            json.RawMessage       `json:"payload"`
+	Status             WebhookDeliveryStatus `json:"status"`
+	Attempts           int                   `json:"attempts"`
+	NextAttemptAt      time.Time             `json:"next_attempt_at"`
+	ResponseStatusCode int                   `json:"response_status_code,omitempty"`
+	LastError          string                `json:"last_error,omitempty"`
+	DeliveredAt        *time.Time            `json:"delivered_at,omitempty"`
+	CreatedAt          time.Time             `json:"created_at"`
+	UpdatedAt          time.Time             `json:"updated_at"`
+}
+
+// APIKey is a long-lived credential of a server-to-server integrator, sent as "Authorization: Bearer <key>"
+type APIKey struct {
+	ID         uint64     `json:"id,string"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	AllowedIPs []string   `json:"allowed_ips"`
+	CreatedBy  string     `json:"created_by"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RotatedAt  *time.Time `json:"rotated_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	// The key, only returned when the API key is created or rotated
+	Key string `json:"key,omitempty"`
+}
+
+// CreateAPIKeyRequest creates an API key
+type CreateAPIKeyRequest struct {
+	Name string `json:"name"`
+
+	// Scopes granted to the key, the caller must hold each of them
+	Scopes []string `json:"scopes"`
+
+	// IP addresses and CIDR ranges the key is accepted from, empty accepts any address
+	AllowedIPs []string `json:"allowed_ips,omitempty"`
+
+	// When the key stops working, nil for a key which does not expire
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// AccountRole is the role of a principal on an account
+type AccountRole string
+
+const (
+	// AccountRoleOwner views and debits the account and grants roles on it
+	AccountRoleOwner AccountRole = "owner"
+
+	// AccountRoleOperator views and debits the account
+	AccountRoleOperator AccountRole = "operator"
+
+	// AccountRoleAuditor views the account
+	AccountRoleAuditor AccountRole = "auditor"
+)
+
+// AccountGrant gives a principal a role on an account
+type AccountGrant struct {
+	AccountID uint64      `json:"account_id,string"`
+	Principal string      `json:"principal"`
+	Role      AccountRole `json:"role"`
+	GrantedBy string      `json:"granted_by"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
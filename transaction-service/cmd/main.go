@@ -1,8 +1,13 @@
 package main
 
 import (
+	"context"
+
+	"github.com/danielkhtse/supreme-adventure/common/auth"
 	"github.com/danielkhtse/supreme-adventure/transaction-service/internal/api"
+	"github.com/danielkhtse/supreme-adventure/transaction-service/internal/grpcapi"
 	"github.com/danielkhtse/supreme-adventure/transaction-service/internal/service"
+	log "github.com/sirupsen/logrus"
 )
 
 func main() {
@@ -10,12 +15,31 @@ func main() {
 	// Initial Transaction Service, handle migrations
 	transactionService := service.NewTransactionService()
 
+	// Deliver transaction and balance events to registered webhooks
+	transactionService.StartWebhookDispatcher(context.Background())
+
+	// Prune change feed events past their retention
+	transactionService.StartEventRetention(context.Background())
+
+	// Complete payment files abandoned by a stopped replica
+	transactionService.StartPaymentFileRecovery(context.Background())
+
+	// Verify bearer tokens against the configured JWKS, and API keys
+	authenticator, err := auth.FromEnv(context.Background(), transactionService.APIKeys())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if authenticator == nil {
+		log.Warn("Authentication disabled, every request is served without credentials")
+	}
+
+	// Initialize Transactions gRPC server
+	var grpcServer grpcapi.Server
+	grpcServer.Initialize(transactionService, authenticator)
+	go grpcServer.Run()
+
 	// Initialize Transactions API server
 	var server api.Server
-	server.Initialize(transactionService)
+	server.Initialize(transactionService, authenticator)
 	server.Run()
-
-	// TODO: Transaction Service GRPC Server
-	// grpcServer := grpc.NewServer()
-	// grpcServer.Serve(lis)
 }
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/danielkhtse/supreme-adventure/common/models"
+	"github.com/danielkhtse/supreme-adventure/common/response"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"github.com/danielkhtse/supreme-adventure/common/validation"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// CreateAPIKeyRequest represents the request body for creating an API key
+type CreateAPIKeyRequest struct {
+	// Name identifying the integrator or integration using the key
+	Name string `json:"name" validate:"required"` // @example Acme payouts
+
+	// Scopes granted to the key, the caller must hold each of them
+	Scopes []string `json:"scopes" validate:"required"` // @example transactions:write
+
+	// IP addresses and CIDR ranges the key is accepted from, omit to accept any address
+	AllowedIPs []string `json:"allowed_ips"` // @example 203.0.113.0/24
+
+	// When the key stops working, omit for a key which does not expire
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// APIKeySecretResponse is returned on creation and rotation and is the only response containing the key
+type APIKeySecretResponse struct {
+	models.APIKey
+
+	// The key to send as "Authorization: Bearer <key>", it cannot be retrieved again
+	Key string `json:"key"`
+}
+
+// @Summary Create an API key
+// @Description Create a long-lived API key for a server-to-server integrator, sent as a bearer token.
+// @Description The key is only returned by this call, the service stores its hash.
+// @Tags API Key
+// @Accept json
+// @Produce json
+// @Param request body CreateAPIKeyRequest true "API key creation request"
+// @Success 201 {object} APIKeySecretResponse
+// @Failure 400 {object} response.ProblemResponse "Invalid request body, name, scope, IP allowlist or expiry"
+// @Failure 403 {object} response.ProblemResponse "The caller does not hold a requested scope"
+// @Failure 500 {object} response.ProblemResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api-keys [post]
+func (s *Server) CreateAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	var request CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		response.SendError(w, response.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := validation.ValidateStruct(request); err != nil {
+		response.SendError(w, response.StatusBadRequest, err.Error())
+		return
+	}
+
+	apiKey := &models.APIKey{
+		Name:       request.Name,
+		Scopes:     request.Scopes,
+		AllowedIPs: request.AllowedIPs,
+		ExpiresAt:  request.ExpiresAt,
+	}
+	key, err := s.TransactionService.CreateAPIKey(r.Context(), apiKey)
+	if err != nil {
+		logrus.WithError(err).Error("failed to create API key")
+		response.SendProblem(w, err)
+		return
+	}
+
+	response.SendSuccess(w, response.StatusCreated, &APIKeySecretResponse{APIKey: *apiKey, Key: key})
+}
+
+// @Summary List API keys
+// @Description List all API keys, revoked keys included. Keys are identified by their prefix, the keys themselves are never returned.
+// @Tags API Key
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.APIKey
+// @Failure 500 {object} response.ProblemResponse "Internal server error"
+// @Security BearerAuth
+// @Router /api-keys [get]
+func (s *Server) ListAPIKeysHandler(w http.ResponseWriter, r *http.Request) {
+	apiKeys, err := s.TransactionService.ListAPIKeys()
+	if err != nil {
+		logrus.WithError(err).Error("failed to list API keys")
+		response.SendError(w, response.StatusInternalServerError, "failed to list API keys")
+		return
+	}
+
+	response.SendSuccess(w, response.StatusOK, &apiKeys)
+}
+
+// @Summary Get an API key
+// @Description Get an API key by ID, with the time it was last used
+// @Tags API Key
+// @Accept json
+// @Produce json
+// @Param api_key_id path string true "API key ID"
+// @Success 200 {object} models.APIKey
+// @Failure 400 {object} response.ProblemResponse "Invalid API key ID format"
+// @Failure 404 {object} response.ProblemResponse "API key not found"
+// @Security BearerAuth
+// @Router /api-keys/{api_key_id} [get]
+func (s *Server) GetAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	apiKeyID, ok := parseAPIKeyID(w, r)
+	if !ok {
+		return
+	}
+
+	apiKey, err := s.TransactionService.GetAPIKey(apiKeyID)
+	if err != nil {
+		response.SendProblem(w, err)
+		return
+	}
+
+	response.SendSuccess(w, response.StatusOK, apiKey)
+}
+
+// @Summary Rotate an API key
+// @Description Replace the key of an API key, the previous key stops working immediately. Scopes, IP allowlist and expiry are kept.
+// @Tags API Key
+// @Accept json
+// @Produce json
+// @Param api_key_id path string true "API key ID"
+// @Success 200 {object} APIKeySecretResponse
+// @Failure 400 {object} response.ProblemResponse "Invalid API key ID format"
+// @Failure 403 {object} response.ProblemResponse "The caller does not hold a scope of the key"
+// @Failure 404 {object} response.ProblemResponse "API key not found"
+// @Failure 409 {object} response.ProblemResponse "API key has been revoked"
+// @Security BearerAuth
+// @Router /api-keys/{api_key_id}/rotate [post]
+func (s *Server) RotateAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	apiKeyID, ok := parseAPIKeyID(w, r)
+	if !ok {
+		return
+	}
+
+	apiKey, key, err := s.TransactionService.RotateAPIKey(r.Context(), apiKeyID)
+	if err != nil {
+		response.SendProblem(w, err)
+		return
+	}
+
+	response.SendSuccess(w, response.StatusOK, &APIKeySecretResponse{APIKey: *apiKey, Key: key})
+}
+
+// @Summary Revoke an API key
+// @Description Stop an API key from authenticating. The key stays listed with its revocation time.
+// @Tags API Key
+// @Accept json
+// @Produce json
+// @Param api_key_id path string true "API key ID"
+// @Success 204
+// @Failure 400 {object} response.ProblemResponse "Invalid API key ID format"
+// @Failure 404 {object} response.ProblemResponse "API key not found"
+// @Security BearerAuth
+// @Router /api-keys/{api_key_id} [delete]
+func (s *Server) RevokeAPIKeyHandler(w http.ResponseWriter, r *http.Request) {
+	apiKeyID, ok := parseAPIKeyID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := s.TransactionService.RevokeAPIKey(r.Context(), apiKeyID); err != nil {
+		response.SendProblem(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func parseAPIKeyID(w http.ResponseWriter, r *http.Request) (types.APIKeyID, bool) {
+	apiKeyID, err := strconv.ParseUint(mux.Vars(r)["api_key_id"], 10, 64)
+	if err != nil {
+		response.SendError(w, response.StatusBadRequest, "invalid API key ID format")
+		return 0, false
+	}
+	return types.APIKeyID(apiKeyID), true
+}
@@ -12,7 +12,7 @@ import (
 // @Accept json
 // @Produce json
 // @Success 200
-// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Failure 500 {object} response.ProblemResponse "Internal server error"
 // @Router /health-check [get]
 func (s *Server) HealthCheckHandler(w http.ResponseWriter, r *http.Request) {
 	response.SendSuccess[string](w, response.StatusOK, nil)
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/danielkhtse/supreme-adventure/common/iso20022"
+	"github.com/danielkhtse/supreme-adventure/common/response"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+// maxPaymentFileSize caps the uploaded pain.001 file
+const maxPaymentFileSize = 10 << 20
+
+// @Summary Upload a pain.001 payment initiation
+// @Description Execute the credit transfers of an ISO 20022 pain.001.001.10 customer credit transfer initiation and return a pain.002.001.11 payment status report.
+// @Description Debtor and creditor accounts are ledger account IDs given as Othr/Id, amounts are InstdAmt in USD. Each valid transfer becomes a transaction, reported as ACSC with the transaction ID as AcctSvcrRef; rejected transfers are reported as RJCT with an ISO 20022 reason code.
+// @Description The message ID must be unique among the files of the caller: a file uploaded again is rejected as a whole (DU01) without executing any transfer. A file with wrong NbOfTxs or CtrlSum totals is rejected as a whole as well. At most 1000 transfers per file.
+// @Tags Payment Initiation
+// @Accept application/xml
+// @Produce application/xml
+// @Param file body string true "pain.001.001.10 document"
+// @Success 200 {string} string "pain.002.001.11 document"
+// @Failure 400 {object} response.ProblemResponse "Not a readable pain.001.001.10 document, or too many transfers"
+// @Failure 413 {object} response.ProblemResponse "File larger than 10 MiB"
+// @Failure 500 {object} response.ProblemResponse "Internal server error"
+// @Security BearerAuth
+// @Router /transactions/pain001 [post]
+func (s *Server) ImportPaymentFileHandler(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPaymentFileSize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			response.SendError(w, response.StatusRequestEntityTooLarge, "payment file must not exceed 10 MiB")
+			return
+		}
+		response.SendError(w, response.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	report, err := s.TransactionService.ImportPaymentFile(r.Context(), data)
+	if err != nil {
+		logrus.WithError(err).Error("failed to import payment file")
+		response.SendProblem(w, err)
+		return
+	}
+
+	sendStatusReport(w, report)
+}
+
+// @Summary Get the status report of a pain.001 payment initiation
+// @Description The pain.002.001.11 payment status report returned when the caller uploaded the pain.001 file with the message ID, e.g. when the upload response was lost. Files uploaded by other principals are not found. Each transaction the report refers to requires viewing either of its accounts.
+// @Tags Payment Initiation
+// @Produce application/xml
+// @Param message_id path string true "MsgId of the pain.001 group header"
+// @Success 200 {string} string "pain.002.001.11 document"
+// @Failure 403 {object} response.ProblemResponse "The caller may view neither account of a transaction in the report"
+// @Failure 404 {object} response.ProblemResponse "No file uploaded by the caller with the message ID"
+// @Failure 409 {object} response.ProblemResponse "File still being processed"
+// @Failure 500 {object} response.ProblemResponse "Internal server error"
+// @Security BearerAuth
+// @Router /transactions/pain001/{message_id}/report [get]
+func (s *Server) GetPaymentFileReportHandler(w http.ResponseWriter, r *http.Request) {
+	report, err := s.TransactionService.GetPaymentFileReport(r.Context(), mux.Vars(r)["message_id"])
+	if err != nil {
+		response.SendProblem(w, err)
+		return
+	}
+
+	sendStatusReport(w, report)
+}
+
+func sendStatusReport(w http.ResponseWriter, report []byte) {
+	w.Header().Set("Content-Type", iso20022.ContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(report)
+}
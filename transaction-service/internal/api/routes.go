@@ -3,26 +3,87 @@ package api
 import (
 	"net/http"
 
+	"github.com/danielkhtse/supreme-adventure/common/apiversion"
+	"github.com/danielkhtse/supreme-adventure/common/auth"
+	"github.com/danielkhtse/supreme-adventure/common/requestmeta"
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 const (
 	transactionsRoute = "/transactions"
+	accountsRoute     = "/accounts"
+	webhooksRoute     = "/webhooks"
+	eventsRoute       = "/events"
+	apiKeysRoute      = "/api-keys"
 )
 
 // NewRouter creates and configures a new router
 func (s *Server) InitializeRoutes() *mux.Router {
 	r := mux.NewRouter()
+	r.Use(requestmeta.Middleware)
 
 	r.HandleFunc("/health-check", s.HealthCheckHandler).Methods("GET")
+	r.Handle("/metrics", promhttp.Handler()).Methods("GET")
 
+	fs := http.FileServer(http.Dir("transaction-service/docs"))
+	r.PathPrefix("/docs/").Handler(http.StripPrefix("/docs/", fs))
+
+	//the unversioned routes are kept as deprecated aliases of v1 for clients predating it
+	apiversion.Mount(r,
+		apiversion.Version{Prefix: "/v1", Register: s.registerV1Routes},
+		apiversion.Version{
+			Register:    s.registerV1Routes,
+			Deprecation: apiversion.LegacyDeprecation,
+			Sunset:      apiversion.LegacySunset,
+			Successor:   "/v1",
+		},
+	)
+
+	return r
+}
+
+// registerV1Routes adds the routes of API v1, whose request and response types are defined in this package
+func (s *Server) registerV1Routes(r *mux.Router) {
 	transactions := r.PathPrefix(transactionsRoute).Subrouter()
 
 	//single account handlers
-	transactions.HandleFunc("", s.CreateTransactionHandler).Methods("POST")
+	transactions.Handle("", s.Auth.Require(auth.ScopeTransactionsWrite, s.CreateTransactionHandler)).Methods("POST")
+	transactions.Handle("", s.Auth.Require(auth.ScopeTransactionsRead, s.ListTransactionsHandler)).Methods("GET")
 
-	fs := http.FileServer(http.Dir("transaction-service/docs"))
-	r.PathPrefix("/docs/").Handler(http.StripPrefix("/docs/", fs))
+	//bulk export, registered before the single transaction route so export is not taken for a transaction ID
+	transactions.Handle("/export", s.Auth.Require(auth.ScopeTransactionsRead, s.ExportTransactionsHandler)).Methods("GET")
 
-	return r
+	//ISO 20022 payment initiation files
+	transactions.Handle("/pain001", s.Auth.Require(auth.ScopeTransactionsWrite, s.ImportPaymentFileHandler)).Methods("POST")
+	transactions.Handle("/pain001/{message_id}/report", s.Auth.Require(auth.ScopeTransactionsRead, s.GetPaymentFileReportHandler)).Methods("GET")
+
+	transactions.Handle("/{transaction_id}", s.Auth.Require(auth.ScopeTransactionsRead, s.GetTransactionHandler)).Methods("GET")
+
+	accounts := r.PathPrefix(accountsRoute).Subrouter()
+
+	//transaction status transitions of an account
+	accounts.Handle("/{account_id}/stream", s.Auth.Require(auth.ScopeTransactionsRead, s.StreamAccountTransactionsHandler)).Methods("GET")
+
+	webhooks := r.PathPrefix(webhooksRoute).Subrouter()
+
+	//webhook endpoint management and delivery log
+	webhooks.Handle("", s.Auth.Require(auth.ScopeWebhooksWrite, s.CreateWebhookEndpointHandler)).Methods("POST")
+	webhooks.Handle("", s.Auth.Require(auth.ScopeWebhooksRead, s.ListWebhookEndpointsHandler)).Methods("GET")
+	webhooks.Handle("/{webhook_id}", s.Auth.Require(auth.ScopeWebhooksRead, s.GetWebhookEndpointHandler)).Methods("GET")
+	webhooks.Handle("/{webhook_id}", s.Auth.Require(auth.ScopeWebhooksWrite, s.DeleteWebhookEndpointHandler)).Methods("DELETE")
+	webhooks.Handle("/{webhook_id}/deliveries", s.Auth.Require(auth.ScopeWebhooksRead, s.ListWebhookDeliveriesHandler)).Methods("GET")
+	webhooks.Handle("/{webhook_id}/deliveries/{delivery_id}/redeliver", s.Auth.Require(auth.ScopeWebhooksWrite, s.RedeliverWebhookHandler)).Methods("POST")
+
+	apiKeys := r.PathPrefix(apiKeysRoute).Subrouter()
+
+	//API keys of server-to-server integrators
+	apiKeys.Handle("", s.Auth.Require(auth.ScopeAPIKeysWrite, s.CreateAPIKeyHandler)).Methods("POST")
+	apiKeys.Handle("", s.Auth.Require(auth.ScopeAPIKeysRead, s.ListAPIKeysHandler)).Methods("GET")
+	apiKeys.Handle("/{api_key_id}", s.Auth.Require(auth.ScopeAPIKeysRead, s.GetAPIKeyHandler)).Methods("GET")
+	apiKeys.Handle("/{api_key_id}", s.Auth.Require(auth.ScopeAPIKeysWrite, s.RevokeAPIKeyHandler)).Methods("DELETE")
+	apiKeys.Handle("/{api_key_id}/rotate", s.Auth.Require(auth.ScopeAPIKeysWrite, s.RotateAPIKeyHandler)).Methods("POST")
+
+	//change feed
+	r.Handle(eventsRoute, s.Auth.Require(auth.ScopeEventsRead, s.ListEventsHandler)).Methods("GET")
 }
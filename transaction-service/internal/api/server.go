@@ -4,28 +4,56 @@ import (
 	"net/http"
 	"os"
 
+	"github.com/danielkhtse/supreme-adventure/common/apiversion"
+	"github.com/danielkhtse/supreme-adventure/common/auth"
+	"github.com/danielkhtse/supreme-adventure/common/authz"
+	"github.com/danielkhtse/supreme-adventure/common/compress"
+	"github.com/danielkhtse/supreme-adventure/common/events"
+	"github.com/danielkhtse/supreme-adventure/common/requestmeta"
+	"github.com/danielkhtse/supreme-adventure/common/stream"
 	"github.com/danielkhtse/supreme-adventure/transaction-service/internal/service"
-	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
 	"github.com/rs/cors"
 	log "github.com/sirupsen/logrus"
 )
 
+// @title Transaction Service API
+// @version 1.0
+// @description Transactions between accounts, their status streams, change feed and webhooks
+// @BasePath /v1
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description JWT bearer token, "Bearer <token>"
+
 // Transactions API server
 type Server struct {
 	TransactionService *service.TransactionService
+	Streamer           *stream.Handler
+	Auth               *auth.Authenticator
+	Policy             authz.Policy
 	Router             *mux.Router
 	Port               string
 }
 
-func (server *Server) Initialize(transactionService *service.TransactionService) {
+func (server *Server) Initialize(transactionService *service.TransactionService, authenticator *auth.Authenticator) {
 	server.TransactionService = transactionService
+	server.Auth = authenticator
+	server.Policy = transactionService.Policy()
 	server.Port = os.Getenv("TRANSACTION_API_SERVER_PORT")
 
 	if server.Port == "" {
 		log.Fatal("TRANSACTION_API_SERVER_PORT environment variable not set")
 	}
 
+	server.Streamer = &stream.Handler{
+		Feed:          transactionService,
+		Broker:        transactionService.Events(),
+		Authorizer:    authz.StreamAuthorizer{Policy: server.Policy},
+		Types:         []events.Type{events.TypeTransactionStatusChanged},
+		AllowedOrigin: os.Getenv("ENV_CORS_ALLOWED_ORIGIN"),
+	}
+
 	server.Router = server.InitializeRoutes()
 }
 
@@ -35,11 +63,14 @@ func (server *Server) Run() {
 	allow := os.Getenv("ENV_CORS_ALLOWED_ORIGIN")
 	log.Info("Allow origin: " + allow)
 	c := cors.New(cors.Options{
-		AllowedOrigins:   []string{allow},
-		AllowCredentials: true,
-		AllowedHeaders:   []string{"*"},
+		AllowedOrigins: []string{allow},
+		//bearer tokens are sent explicitly, cross-origin requests never need cookies
+		AllowCredentials: false,
+		AllowedHeaders:   []string{requestmeta.AuthorizationHeader, "Content-Type", requestmeta.RequestIDHeader, stream.LastEventIDHeader},
+		ExposedHeaders:   []string{"WWW-Authenticate", requestmeta.RequestIDHeader, apiversion.DeprecationHeader, apiversion.SunsetHeader, apiversion.LinkHeader},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH", "HEAD"},
 	})
 	handler := c.Handler(server.Router)
-	log.Fatal(http.ListenAndServe(":"+server.Port, handlers.CompressHandler(handler)))
+	//the export negotiates its own gzip encoding, Parquet pages are compressed already
+	log.Fatal(http.ListenAndServe(":"+server.Port, compress.Handler(handler, isTransactionExport)))
 }
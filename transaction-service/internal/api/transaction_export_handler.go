@@ -0,0 +1,307 @@
+package api
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/danielkhtse/supreme-adventure/common/authz"
+	"github.com/danielkhtse/supreme-adventure/common/models"
+	"github.com/danielkhtse/supreme-adventure/common/response"
+	"github.com/parquet-go/parquet-go"
+	"github.com/sirupsen/logrus"
+)
+
+// Export formats, csv is the default
+const (
+	exportFormatCSV     = "csv"
+	exportFormatJSONL   = "jsonl"
+	exportFormatParquet = "parquet"
+)
+
+// exportFlushInterval is the number of rows after which the export is flushed to the client
+const exportFlushInterval = 1000
+
+// parquetRowGroupSize is the number of transactions per row group of Parquet exports
+const parquetRowGroupSize = 50_000
+
+// transactionExportColumns are the columns of the CSV and Parquet exports, named like the JSON fields
+var transactionExportColumns = []string{
+	"id",
+	"source_account_id",
+	"destination_account_id",
+	"amount",
+	"currency",
+	"status",
+	"description",
+	"created_at",
+	"updated_at",
+}
+
+// transactionParquetRow is a row of Parquet exports, with the columns of transactionExportColumns
+type transactionParquetRow struct {
+	ID                   int64     `parquet:"id"`
+	SourceAccountID      int64     `parquet:"source_account_id"`
+	DestinationAccountID int64     `parquet:"destination_account_id"`
+	Amount               int64     `parquet:"amount"`
+	Currency             string    `parquet:"currency"`
+	Status               string    `parquet:"status"`
+	Description          string    `parquet:"description"`
+	CreatedAt            time.Time `parquet:"created_at,timestamp(millisecond)"`
+	UpdatedAt            time.Time `parquet:"updated_at,timestamp(millisecond)"`
+}
+
+// transactionExporter encodes exported transactions in one of the export formats
+type transactionExporter interface {
+	Write(transaction *models.Transaction) error
+
+	// Flush hands the rows written so far to the underlying writer, where the format allows it
+	Flush() error
+
+	// Close completes the file, it does not close the underlying writer
+	Close() error
+}
+
+// @Summary Export transactions
+// @Description Stream every transaction matching the filters as CSV, JSON Lines or Parquet, for ranges too large to page through.
+// @Description CSV and JSON Lines are gzip encoded when the request accepts it (Accept-Encoding: gzip), Parquet pages are always gzip compressed.
+// @Description CSV and Parquet columns: id, source_account_id, destination_account_id, amount, currency, status, description, created_at, updated_at. Parquet timestamps are milliseconds since the Unix epoch (UTC).
+// @Tags Transaction
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Produce application/vnd.apache.parquet
+// @Param format query string false "Export format (default csv)" Enums(csv, jsonl, parquet)
+// @Param account_id query string false "Account on either side of the transaction"
+// @Param status query string false "Transaction status" Enums(pending, completed, failed)
+// @Param min_amount query int false "Minimum amount (inclusive)"
+// @Param max_amount query int false "Maximum amount (inclusive)"
+// @Param created_from query string false "Created at or after (RFC 3339)"
+// @Param created_to query string false "Created before (RFC 3339)"
+// @Param order query string false "Sort order by creation time" Enums(asc, desc)
+// @Success 200 {string} string "Matching transactions in the requested format"
+// @Failure 400 {object} response.ProblemResponse "Invalid format or filter"
+// @Failure 403 {object} response.ProblemResponse "The caller may not view the account, or every account without account_id"
+// @Failure 500 {object} response.ProblemResponse "Internal server error"
+// @Security BearerAuth
+// @Router /transactions/export [get]
+func (s *Server) ExportTransactionsHandler(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseTransactionFilter(r)
+	if err != nil {
+		response.SendError(w, response.StatusBadRequest, err.Error())
+		return
+	}
+
+	//without an account filter the export spans every account, the unset account ID is authz.AllAccounts
+	if err := authz.Check(r.Context(), s.Policy, authz.ActionView, filter.AccountID); err != nil {
+		response.SendProblem(w, err)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = exportFormatCSV
+	}
+	contentType, ok := map[string]string{
+		exportFormatCSV:     "text/csv",
+		exportFormatJSONL:   "application/x-ndjson",
+		exportFormatParquet: "application/vnd.apache.parquet",
+	}[format]
+	if !ok {
+		response.SendError(w, response.StatusBadRequest, "format must be one of csv, jsonl, parquet")
+		return
+	}
+
+	// The response starts with the first row, so an invalid filter rejected by the first read is still a problem response
+	var exporter transactionExporter
+	var gzipWriter *gzip.Writer
+	start := func() error {
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", `attachment; filename="transactions.`+format+`"`)
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		var body io.Writer = w
+		if format != exportFormatParquet && acceptsGzip(r) {
+			w.Header().Set("Content-Encoding", "gzip")
+			gzipWriter = gzip.NewWriter(w)
+			body = gzipWriter
+		}
+
+		var err error
+		exporter, err = newTransactionExporter(format, body)
+		return err
+	}
+
+	rows := 0
+	err = s.TransactionService.ExportTransactions(r.Context(), filter, func(transaction *models.Transaction) error {
+		if exporter == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if err := exporter.Write(transaction); err != nil {
+			return err
+		}
+		rows++
+		if rows%exportFlushInterval != 0 {
+			return nil
+		}
+		if err := exporter.Flush(); err != nil {
+			return err
+		}
+		if gzipWriter != nil {
+			if err := gzipWriter.Flush(); err != nil {
+				return err
+			}
+		}
+		http.NewResponseController(w).Flush()
+		return nil
+	})
+	if err != nil && exporter == nil {
+		logrus.WithError(err).Error("failed to export transactions")
+		response.SendProblem(w, err)
+		return
+	}
+	if exporter == nil {
+		// no matching transactions, the export holds the header only
+		if err := start(); err != nil {
+			logrus.WithError(err).Error("failed to export transactions")
+			return
+		}
+	}
+
+	// An aborted export is not completed, so the Parquet footer is missing and the file is unreadable
+	if err == nil {
+		err = exporter.Close()
+	}
+	if gzipWriter != nil {
+		gzipWriter.Close()
+	}
+
+	// The status line has been sent already, a truncated export is only visible in the logs
+	if err != nil {
+		logrus.WithError(err).WithField("rows", rows).Error("transaction export aborted")
+	}
+}
+
+func newTransactionExporter(format string, w io.Writer) (transactionExporter, error) {
+	switch format {
+	case exportFormatJSONL:
+		buffered := bufio.NewWriter(w)
+		return &jsonlTransactionExporter{buffered: buffered, encoder: json.NewEncoder(buffered)}, nil
+	case exportFormatParquet:
+		writer := parquet.NewGenericWriter[transactionParquetRow](w,
+			parquet.Compression(&parquet.Gzip),
+			parquet.MaxRowsPerRowGroup(parquetRowGroupSize),
+		)
+		return &parquetTransactionExporter{writer: writer}, nil
+	default:
+		writer := csv.NewWriter(w)
+		return &csvTransactionExporter{writer: writer}, writer.Write(transactionExportColumns)
+	}
+}
+
+type csvTransactionExporter struct {
+	writer *csv.Writer
+}
+
+func (e *csvTransactionExporter) Write(transaction *models.Transaction) error {
+	return e.writer.Write([]string{
+		strconv.FormatUint(uint64(transaction.ID), 10),
+		strconv.FormatUint(uint64(transaction.SourceAccountID), 10),
+		strconv.FormatUint(uint64(transaction.DestAccountID), 10),
+		strconv.FormatInt(int64(transaction.Amount), 10),
+		transaction.Currency,
+		string(transaction.Status),
+		transaction.Description,
+		transaction.CreatedAt.UTC().Format(time.RFC3339),
+		transaction.UpdatedAt.UTC().Format(time.RFC3339),
+	})
+}
+
+func (e *csvTransactionExporter) Flush() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+func (e *csvTransactionExporter) Close() error {
+	return e.Flush()
+}
+
+// jsonlTransactionExporter writes one transaction per line, encoded like the API responses
+type jsonlTransactionExporter struct {
+	buffered *bufio.Writer
+	encoder  *json.Encoder
+}
+
+func (e *jsonlTransactionExporter) Write(transaction *models.Transaction) error {
+	return e.encoder.Encode(transaction)
+}
+
+func (e *jsonlTransactionExporter) Flush() error {
+	return e.buffered.Flush()
+}
+
+func (e *jsonlTransactionExporter) Close() error {
+	return e.Flush()
+}
+
+// parquetTransactionExporter writes a row group every parquetRowGroupSize transactions
+type parquetTransactionExporter struct {
+	writer *parquet.GenericWriter[transactionParquetRow]
+}
+
+func (e *parquetTransactionExporter) Write(transaction *models.Transaction) error {
+	_, err := e.writer.Write([]transactionParquetRow{{
+		ID:                   int64(transaction.ID),
+		SourceAccountID:      int64(transaction.SourceAccountID),
+		DestinationAccountID: int64(transaction.DestAccountID),
+		Amount:               int64(transaction.Amount),
+		Currency:             transaction.Currency,
+		Status:               string(transaction.Status),
+		Description:          transaction.Description,
+		CreatedAt:            transaction.CreatedAt.UTC(),
+		UpdatedAt:            transaction.UpdatedAt.UTC(),
+	}})
+	return err
+}
+
+// Flush is a no-op, row groups are written once full rather than on every flush of the response
+func (e *parquetTransactionExporter) Flush() error {
+	return nil
+}
+
+func (e *parquetTransactionExporter) Close() error {
+	return e.writer.Close()
+}
+
+// isTransactionExport reports whether the request is for the transaction export, which is left out of response
+// compression so the handler sees Accept-Encoding and Parquet files are not compressed twice
+func isTransactionExport(r *http.Request) bool {
+	return strings.HasSuffix(r.URL.Path, transactionsRoute+"/export")
+}
+
+// acceptsGzip reports whether the Accept-Encoding header of the request allows gzip
+func acceptsGzip(r *http.Request) bool {
+	for _, value := range r.Header.Values("Accept-Encoding") {
+		for _, coding := range strings.Split(value, ",") {
+			name, params, _ := strings.Cut(coding, ";")
+			if strings.TrimSpace(name) != "gzip" {
+				continue
+			}
+			// q=0 explicitly refuses the coding
+			if _, quality, ok := strings.Cut(strings.ReplaceAll(params, " ", ""), "q="); ok {
+				if q, err := strconv.ParseFloat(quality, 64); err == nil && q == 0 {
+					continue
+				}
+			}
+			return true
+		}
+	}
+	return false
+}
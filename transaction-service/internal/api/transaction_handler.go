@@ -2,38 +2,46 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
-	"strings"
+	"strconv"
+	"time"
 
+	"github.com/danielkhtse/supreme-adventure/common/authz"
 	"github.com/danielkhtse/supreme-adventure/common/models"
+	"github.com/danielkhtse/supreme-adventure/common/pagination"
 	"github.com/danielkhtse/supreme-adventure/common/response"
 	"github.com/danielkhtse/supreme-adventure/common/types"
 	"github.com/danielkhtse/supreme-adventure/common/validation"
+	"github.com/danielkhtse/supreme-adventure/transaction-service/internal/service"
+	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 )
 
 // CreateTransactionRequest represents the request body for creating a transaction
 type CreateTransactionRequest struct {
 	// The source account ID to transfer funds from
-	SourceAccountID types.AccountID `json:"source_account_id" validate:"required"` // @example 12345
+	SourceAccountID types.AccountID `json:"source_account_id,string" validate:"required" example:"12345"` // @example 12345
 
 	// The destination account ID to transfer funds to
-	DestAccountID types.AccountID `json:"destination_account_id" validate:"required"` // @example 67890
+	DestAccountID types.AccountID `json:"destination_account_id,string" validate:"required" example:"67890"` // @example 67890
 
 	// The amount to transfer in smallest currency units (e.g. cents for USD)
 	Amount types.AccountBalance `json:"amount" validate:"required,min=1"` // @example 1000
 }
 
 // @Summary Create a new transaction between accounts
-// @Description Create a new transaction between accounts
+// @Description Create a new transaction between accounts, the caller must hold the owner, operator or admin role on the source account
 // @Tags Transaction
 // @Accept json
 // @Produce json
 // @Param request body CreateTransactionRequest true "Transaction creation request"
 // @Success 201 {object} models.Transaction
-// @Failure 400 {object} response.ErrorResponse "Invalid request body, validation error, same source/dest accounts, insufficient balance, or negative amount"
-// @Failure 404 {object} response.ErrorResponse "Source or destination account not found"
-// @Failure 500 {object} response.ErrorResponse "Internal server error"
+// @Failure 400 {object} response.ProblemResponse "Invalid request body, validation error, same source/dest accounts, insufficient balance, or negative amount"
+// @Failure 403 {object} response.ProblemResponse "The caller may not debit the source account"
+// @Failure 404 {object} response.ProblemResponse "Source or destination account not found"
+// @Failure 500 {object} response.ProblemResponse "Internal server error"
+// @Security BearerAuth
 // @Router /transactions [post]
 func (s *Server) CreateTransactionHandler(w http.ResponseWriter, r *http.Request) {
 	logrus.Debug("handling create transaction request")
@@ -57,35 +65,168 @@ func (s *Server) CreateTransactionHandler(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	if err := authz.Check(r.Context(), s.Policy, authz.ActionDebit, request.SourceAccountID); err != nil {
+		response.SendProblem(w, err)
+		return
+	}
+
 	transaction := &models.Transaction{
 		SourceAccountID: request.SourceAccountID,
 		DestAccountID:   request.DestAccountID,
 		Amount:          request.Amount,
 	}
 
-	if err := s.TransactionService.CreateTransaction(transaction); err != nil {
-		errMsg := err.Error()
+	if err := s.TransactionService.CreateTransaction(r.Context(), transaction); err != nil {
 		logrus.WithError(err).WithFields(logrus.Fields{
 			"source_account_id": transaction.SourceAccountID,
 			"dest_account_id":   transaction.DestAccountID,
 			"amount":            transaction.Amount,
 		}).Error("failed to create transaction")
+		response.SendProblem(w, err)
+		return
+	}
+
+	response.SendSuccess(w, response.StatusCreated, transaction)
+}
+
+// @Summary Get a transaction
+// @Description Get a transaction by ID
+// @Tags Transaction
+// @Accept json
+// @Produce json
+// @Param transaction_id path string true "Transaction ID"
+// @Success 200 {object} models.Transaction
+// @Failure 400 {object} response.ProblemResponse "Invalid transaction ID format"
+// @Failure 403 {object} response.ProblemResponse "The caller may view neither account of the transaction"
+// @Failure 404 {object} response.ProblemResponse "Transaction not found"
+// @Failure 500 {object} response.ProblemResponse "Internal server error"
+// @Security BearerAuth
+// @Router /transactions/{transaction_id} [get]
+func (s *Server) GetTransactionHandler(w http.ResponseWriter, r *http.Request) {
+	transactionID, err := strconv.ParseUint(mux.Vars(r)["transaction_id"], 10, 64)
+	if err != nil {
+		response.SendError(w, response.StatusBadRequest, "invalid transaction ID format")
+		return
+	}
+
+	transaction, err := s.TransactionService.GetTransaction(r.Context(), types.TransactionID(transactionID))
+	if err != nil {
+		response.SendProblem(w, err)
+		return
+	}
+
+	if err := authz.CheckAny(r.Context(), s.Policy, authz.ActionView, transaction.SourceAccountID, transaction.DestAccountID); err != nil {
+		response.SendProblem(w, err)
+		return
+	}
+
+	response.SendSuccess(w, response.StatusOK, transaction)
+}
+
+// @Summary List transactions
+// @Description List transactions newest first (or oldest first with order=asc), filtered by account, status, amount and creation time.
+// @Description Pass next_cursor back as cursor, with the same filters and order, to fetch the next page.
+// @Description Without account_id the transactions of every account are listed, which requires a role on every account.
+// @Tags Transaction
+// @Accept json
+// @Produce json
+// @Param account_id query string false "Account on either side of the transaction"
+// @Param status query string false "Transaction status" Enums(pending, completed, failed)
+// @Param min_amount query int false "Minimum amount (inclusive)"
+// @Param max_amount query int false "Maximum amount (inclusive)"
+// @Param created_from query string false "Created at or after (RFC 3339)"
+// @Param created_to query string false "Created before (RFC 3339)"
+// @Param order query string false "Sort order by creation time" Enums(asc, desc)
+// @Param cursor query string false "Cursor returned as next_cursor by the previous page"
+// @Param limit query int false "Maximum number of transactions (default 50, max 200)"
+// @Success 200 {object} service.TransactionPage
+// @Failure 400 {object} response.ProblemResponse "Invalid filter, cursor or limit"
+// @Failure 403 {object} response.ProblemResponse "The caller may not view the account, or every account without account_id"
+// @Failure 500 {object} response.ProblemResponse "Internal server error"
+// @Security BearerAuth
+// @Router /transactions [get]
+func (s *Server) ListTransactionsHandler(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseTransactionFilter(r)
+	if err != nil {
+		response.SendError(w, response.StatusBadRequest, err.Error())
+		return
+	}
 
-		if strings.Contains(errMsg, "source and destination accounts cannot be the same") {
-			response.SendError(w, response.StatusBadRequest, errMsg)
-		} else if strings.Contains(errMsg, "source account not found") {
-			response.SendError(w, response.StatusNotFound, errMsg)
-		} else if strings.Contains(errMsg, "destination account not found") {
-			response.SendError(w, response.StatusNotFound, errMsg)
-		} else if strings.Contains(errMsg, "insufficient balance") {
-			response.SendError(w, response.StatusBadRequest, errMsg)
-		} else if strings.Contains(errMsg, "amount must be positive") {
-			response.SendError(w, response.StatusBadRequest, errMsg)
-		} else {
-			response.SendError(w, response.StatusInternalServerError, "failed to create transaction")
+	//without an account filter the listing spans every account, the unset account ID is authz.AllAccounts
+	if err := authz.Check(r.Context(), s.Policy, authz.ActionView, filter.AccountID); err != nil {
+		response.SendProblem(w, err)
+		return
+	}
+
+	query := r.URL.Query()
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > pagination.MaxLimit {
+			response.SendError(w, response.StatusBadRequest, "limit must be between 1 and 200")
+			return
 		}
+		filter.Limit = limit
+	}
+	filter.Cursor = query.Get("cursor")
+
+	page, err := s.TransactionService.ListTransactions(r.Context(), filter)
+	if err != nil {
+		logrus.WithError(err).Error("failed to list transactions")
+		response.SendProblem(w, err)
 		return
 	}
 
-	response.SendSuccess(w, response.StatusCreated, transaction)
+	response.SendSuccess(w, response.StatusOK, page)
+}
+
+// parseTransactionFilter reads the filter and order query parameters shared by the listing and the export
+func parseTransactionFilter(r *http.Request) (service.TransactionFilter, error) {
+	query := r.URL.Query()
+	var filter service.TransactionFilter
+
+	if value := query.Get("account_id"); value != "" {
+		accountID, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return filter, errors.New("invalid account_id")
+		}
+		filter.AccountID = types.AccountID(accountID)
+	}
+
+	if value := query.Get("status"); value != "" {
+		status := types.TransactionStatus(value)
+		if status != types.TransactionStatusPending && status != types.TransactionStatusCompleted && status != types.TransactionStatusFailed {
+			return filter, errors.New("status must be one of pending, completed, failed")
+		}
+		filter.Status = status
+	}
+
+	for name, target := range map[string]*types.AccountBalance{"min_amount": &filter.MinAmount, "max_amount": &filter.MaxAmount} {
+		if value := query.Get(name); value != "" {
+			amount, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || amount < 1 {
+				return filter, errors.New(name + " must be a positive integer")
+			}
+			*target = types.AccountBalance(amount)
+		}
+	}
+
+	for name, target := range map[string]*time.Time{"created_from": &filter.CreatedFrom, "created_to": &filter.CreatedTo} {
+		if value := query.Get(name); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return filter, errors.New(name + " must be an RFC 3339 timestamp")
+			}
+			*target = parsed
+		}
+	}
+
+	switch query.Get("order") {
+	case "", "desc":
+	case "asc":
+		filter.Ascending = true
+	default:
+		return filter, errors.New("order must be asc or desc")
+	}
+
+	return filter, nil
 }
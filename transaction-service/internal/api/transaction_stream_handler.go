@@ -0,0 +1,33 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/danielkhtse/supreme-adventure/common/response"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"github.com/gorilla/mux"
+)
+
+// @Summary Stream transaction status transitions of an account
+// @Description Stream status transitions of transactions where the account is the source or destination, as server-sent events or as WebSocket JSON messages when the request is a WebSocket upgrade.
+// @Description Each event carries its ID, reconnect with the Last-Event-ID header (or last_event_id query parameter) to resume.
+// @Tags Transaction
+// @Produce text/event-stream
+// @Param account_id path string true "Account ID"
+// @Param Last-Event-ID header string false "Resume after this event ID"
+// @Param last_event_id query string false "Resume after this event ID, for clients unable to set headers"
+// @Success 200 {object} events.Event "Stream of transaction.status_changed events"
+// @Failure 400 {object} response.ProblemResponse "Invalid account ID or last event ID format"
+// @Failure 403 {object} response.ProblemResponse "Not allowed to access this account"
+// @Security BearerAuth
+// @Router /accounts/{account_id}/stream [get]
+func (s *Server) StreamAccountTransactionsHandler(w http.ResponseWriter, r *http.Request) {
+	accountID, err := strconv.ParseUint(mux.Vars(r)["account_id"], 10, 64)
+	if err != nil {
+		response.SendError(w, response.StatusBadRequest, "invalid account ID format")
+		return
+	}
+
+	s.Streamer.Serve(w, r, types.AccountID(accountID))
+}
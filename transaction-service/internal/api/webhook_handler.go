@@ -0,0 +1,264 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/danielkhtse/supreme-adventure/common/authz"
+	"github.com/danielkhtse/supreme-adventure/common/events"
+	"github.com/danielkhtse/supreme-adventure/common/models"
+	"github.com/danielkhtse/supreme-adventure/common/response"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"github.com/danielkhtse/supreme-adventure/common/validation"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultWebhookDeliveriesLimit = 50
+	maxWebhookDeliveriesLimit     = 200
+)
+
+// CreateWebhookEndpointRequest represents the request body for registering a webhook endpoint
+type CreateWebhookEndpointRequest struct {
+	// The URL receiving POST deliveries
+	URL string `json:"url" validate:"required,url"` // @example https://example.com/webhooks
+
+	// Event types to deliver, empty subscribes to all (transaction.status_changed, account.balance_changed)
+	EventTypes []events.Type `json:"event_types"`
+
+	// Only deliver events affecting this account, omit to receive events of every account
+	AccountID types.AccountID `json:"account_id,string" example:"12345"` // @example 12345
+}
+
+// CreateWebhookEndpointResponse is returned once on registration and is the only response containing the secret
+type CreateWebhookEndpointResponse struct {
+	models.WebhookEndpoint
+
+	// The HMAC-SHA256 key used to sign deliveries in the Webhook-Signature header
+	Secret string `json:"secret"`
+}
+
+// @Summary Register a webhook endpoint
+// @Description Register an endpoint receiving signed event deliveries, filtered by event type and account.
+// @Description Endpoints receiving the events of every account require a role managing every account.
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param request body CreateWebhookEndpointRequest true "Webhook endpoint registration request"
+// @Success 201 {object} CreateWebhookEndpointResponse
+// @Failure 400 {object} response.ProblemResponse "Invalid request body, url, non-public url host or event type"
+// @Failure 403 {object} response.ProblemResponse "The caller may not manage the account, or every account without account_id"
+// @Failure 500 {object} response.ProblemResponse "Internal server error"
+// @Security BearerAuth
+// @Router /webhooks [post]
+func (s *Server) CreateWebhookEndpointHandler(w http.ResponseWriter, r *http.Request) {
+	var request CreateWebhookEndpointRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		response.SendError(w, response.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := validation.ValidateStruct(request); err != nil {
+		response.SendError(w, response.StatusBadRequest, err.Error())
+		return
+	}
+
+	//an endpoint without an account receives the events of every account, its account ID is authz.AllAccounts
+	if err := authz.Check(r.Context(), s.Policy, authz.ActionManage, request.AccountID); err != nil {
+		response.SendProblem(w, err)
+		return
+	}
+
+	endpoint := &models.WebhookEndpoint{
+		URL:        request.URL,
+		EventTypes: request.EventTypes,
+		AccountID:  request.AccountID,
+	}
+	if err := s.TransactionService.CreateWebhookEndpoint(r.Context(), endpoint); err != nil {
+		logrus.WithError(err).Error("failed to create webhook endpoint")
+		response.SendProblem(w, err)
+		return
+	}
+
+	response.SendSuccess(w, response.StatusCreated, &CreateWebhookEndpointResponse{
+		WebhookEndpoint: *endpoint,
+		Secret:          endpoint.Secret,
+	})
+}
+
+// @Summary List webhook endpoints
+// @Description List all registered webhook endpoints, which requires a role on every account
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Success 200 {array} models.WebhookEndpoint
+// @Failure 403 {object} response.ProblemResponse "The caller may not view every account"
+// @Failure 500 {object} response.ProblemResponse "Internal server error"
+// @Security BearerAuth
+// @Router /webhooks [get]
+func (s *Server) ListWebhookEndpointsHandler(w http.ResponseWriter, r *http.Request) {
+	if err := authz.CheckAll(r.Context(), s.Policy, authz.ActionView); err != nil {
+		response.SendProblem(w, err)
+		return
+	}
+
+	endpoints, err := s.TransactionService.ListWebhookEndpoints()
+	if err != nil {
+		logrus.WithError(err).Error("failed to list webhook endpoints")
+		response.SendError(w, response.StatusInternalServerError, "failed to list webhook endpoints")
+		return
+	}
+
+	response.SendSuccess(w, response.StatusOK, &endpoints)
+}
+
+// @Summary Get a webhook endpoint
+// @Description Get a webhook endpoint by ID
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param webhook_id path string true "Webhook endpoint ID"
+// @Success 200 {object} models.WebhookEndpoint
+// @Failure 400 {object} response.ProblemResponse "Invalid webhook endpoint ID format"
+// @Failure 403 {object} response.ProblemResponse "The caller may not view the account of the endpoint"
+// @Failure 404 {object} response.ProblemResponse "Webhook endpoint not found"
+// @Security BearerAuth
+// @Router /webhooks/{webhook_id} [get]
+func (s *Server) GetWebhookEndpointHandler(w http.ResponseWriter, r *http.Request) {
+	endpoint, ok := s.authorizeWebhookEndpoint(w, r, authz.ActionView)
+	if !ok {
+		return
+	}
+
+	response.SendSuccess(w, response.StatusOK, endpoint)
+}
+
+// @Summary Delete a webhook endpoint
+// @Description Stop deliveries to a webhook endpoint and remove it
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param webhook_id path string true "Webhook endpoint ID"
+// @Success 204
+// @Failure 400 {object} response.ProblemResponse "Invalid webhook endpoint ID format"
+// @Failure 403 {object} response.ProblemResponse "The caller may not manage the account of the endpoint"
+// @Failure 404 {object} response.ProblemResponse "Webhook endpoint not found"
+// @Security BearerAuth
+// @Router /webhooks/{webhook_id} [delete]
+func (s *Server) DeleteWebhookEndpointHandler(w http.ResponseWriter, r *http.Request) {
+	endpoint, ok := s.authorizeWebhookEndpoint(w, r, authz.ActionManage)
+	if !ok {
+		return
+	}
+
+	if err := s.TransactionService.DeleteWebhookEndpoint(r.Context(), endpoint.ID); err != nil {
+		response.SendProblem(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary List webhook deliveries
+// @Description List the most recent deliveries of a webhook endpoint, newest first
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param webhook_id path string true "Webhook endpoint ID"
+// @Param limit query int false "Maximum number of deliveries (default 50, max 200)"
+// @Success 200 {array} models.WebhookDelivery
+// @Failure 400 {object} response.ProblemResponse "Invalid webhook endpoint ID format or limit"
+// @Failure 403 {object} response.ProblemResponse "The caller may not view the account of the endpoint"
+// @Failure 404 {object} response.ProblemResponse "Webhook endpoint not found"
+// @Security BearerAuth
+// @Router /webhooks/{webhook_id}/deliveries [get]
+func (s *Server) ListWebhookDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	endpoint, ok := s.authorizeWebhookEndpoint(w, r, authz.ActionView)
+	if !ok {
+		return
+	}
+
+	limit := defaultWebhookDeliveriesLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxWebhookDeliveriesLimit {
+			response.SendError(w, response.StatusBadRequest, "limit must be between 1 and 200")
+			return
+		}
+		limit = parsed
+	}
+
+	deliveries, err := s.TransactionService.ListWebhookDeliveries(endpoint.ID, limit)
+	if err != nil {
+		response.SendProblem(w, err)
+		return
+	}
+
+	response.SendSuccess(w, response.StatusOK, &deliveries)
+}
+
+// @Summary Redeliver a webhook
+// @Description Schedule a past delivery to be sent again immediately with a fresh retry budget
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param webhook_id path string true "Webhook endpoint ID"
+// @Param delivery_id path string true "Webhook delivery ID"
+// @Success 202 {object} models.WebhookDelivery
+// @Failure 400 {object} response.ProblemResponse "Invalid ID format"
+// @Failure 403 {object} response.ProblemResponse "The caller may not manage the account of the endpoint"
+// @Failure 404 {object} response.ProblemResponse "Webhook endpoint or delivery not found"
+// @Security BearerAuth
+// @Router /webhooks/{webhook_id}/deliveries/{delivery_id}/redeliver [post]
+func (s *Server) RedeliverWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	endpoint, ok := s.authorizeWebhookEndpoint(w, r, authz.ActionManage)
+	if !ok {
+		return
+	}
+
+	deliveryID, err := strconv.ParseUint(mux.Vars(r)["delivery_id"], 10, 64)
+	if err != nil {
+		response.SendError(w, response.StatusBadRequest, "invalid webhook delivery ID format")
+		return
+	}
+
+	delivery, err := s.TransactionService.RedeliverWebhook(r.Context(), endpoint.ID, types.WebhookDeliveryID(deliveryID))
+	if err != nil {
+		response.SendProblem(w, err)
+		return
+	}
+
+	response.SendSuccess(w, response.StatusAccepted, delivery)
+}
+
+// authorizeWebhookEndpoint finds the webhook endpoint of the route and checks the caller may perform action on its
+// account, or on every account when the endpoint has none, writing the error response when it may not
+func (s *Server) authorizeWebhookEndpoint(w http.ResponseWriter, r *http.Request, action authz.Action) (*models.WebhookEndpoint, bool) {
+	endpointID, ok := parseWebhookEndpointID(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	endpoint, err := s.TransactionService.GetWebhookEndpoint(endpointID)
+	if err != nil {
+		response.SendProblem(w, err)
+		return nil, false
+	}
+
+	if err := authz.Check(r.Context(), s.Policy, action, endpoint.AccountID); err != nil {
+		response.SendProblem(w, err)
+		return nil, false
+	}
+	return endpoint, true
+}
+
+func parseWebhookEndpointID(w http.ResponseWriter, r *http.Request) (types.WebhookEndpointID, bool) {
+	endpointID, err := strconv.ParseUint(mux.Vars(r)["webhook_id"], 10, 64)
+	if err != nil {
+		response.SendError(w, response.StatusBadRequest, "invalid webhook endpoint ID format")
+		return 0, false
+	}
+	return types.WebhookEndpointID(endpointID), true
+}
@@ -2,60 +2,117 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"strconv"
 
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
 	"github.com/danielkhtse/supreme-adventure/common/models"
+	"github.com/danielkhtse/supreme-adventure/common/requestmeta"
 	"github.com/danielkhtse/supreme-adventure/common/response"
 	"github.com/danielkhtse/supreme-adventure/common/types"
 	"github.com/sirupsen/logrus"
 )
 
-type AccountClient struct {
+// AccountClient calls account-service on behalf of transaction-service
+type AccountClient interface {
+	// GetAccount returns the account, or an error wrapping ErrAccountNotFound when it does not exist
+	GetAccount(ctx context.Context, accountID types.AccountID) (*models.Account, error)
+
+	// TransferFunds moves funds on account-service on behalf of a transaction. A non-zero transactionID is also
+	// sent as transfer ID, account-service then applies the transfer at most once so the call is retried.
+	TransferFunds(ctx context.Context, transactionID types.TransactionID, sourceAccountID types.AccountID, destAccountID types.AccountID, amount types.AccountBalance) error
+}
+
+// ErrAccountNotFound is returned by GetAccount when account-service has no such account
+var ErrAccountNotFound = apperr.ErrAccountNotFound
+
+// HTTPAccountClient calls the account-service REST API
+type HTTPAccountClient struct {
+	*caller
 	baseURL    string
 	httpClient *http.Client
 }
 
-func NewAccountClient(baseURL string) *AccountClient {
-	return &AccountClient{
+// NewHTTPAccountClient creates a client with DefaultConfig
+func NewHTTPAccountClient(baseURL string) *HTTPAccountClient {
+	return NewHTTPAccountClientWithConfig(baseURL, DefaultConfig())
+}
+
+// NewHTTPAccountClientWithConfig creates a client with its own connection pool and circuit breaker
+func NewHTTPAccountClientWithConfig(baseURL string, config Config) *HTTPAccountClient {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = config.MaxIdleConns
+	transport.MaxIdleConnsPerHost = config.MaxIdleConnsPerHost
+	transport.MaxConnsPerHost = config.MaxConnsPerHost
+	transport.IdleConnTimeout = config.IdleConnTimeout
+
+	return &HTTPAccountClient{
+		caller:     newCaller(config),
 		baseURL:    baseURL,
-		httpClient: &http.Client{},
+		httpClient: &http.Client{Transport: transport},
 	}
 }
 
-func (c *AccountClient) GetAccount(accountID types.AccountID) (*models.Account, error) {
-	url := fmt.Sprintf("%s/accounts/%d", c.baseURL, accountID)
+// BreakerState returns the current state of the circuit breaker
+func (c *caller) BreakerState() BreakerState {
+	return c.breaker.currentState()
+}
+
+func (c *HTTPAccountClient) GetAccount(ctx context.Context, accountID types.AccountID) (*models.Account, error) {
+	url := fmt.Sprintf("%s/v1/accounts/%d", c.baseURL, accountID)
 
 	logrus.WithFields(logrus.Fields{
 		"url":    url,
 		"method": "GET",
 	}).Debug("sending request to account service")
 
-	resp, err := c.httpClient.Get(url)
+	resp, err := c.do(ctx, "get_account", http.MethodGet, url, nil, true)
 	if err != nil {
 		logrus.WithError(err).Error("failed to fetch account")
 		return nil, fmt.Errorf("failed to fetch account: %w", err)
 	}
-	defer resp.Body.Close()
 
 	logrus.WithFields(logrus.Fields{
-		"status_code": resp.StatusCode,
+		"status_code": resp.statusCode,
 	}).Debug("received response from account service")
 
-	if resp.StatusCode != http.StatusOK {
+	if resp.statusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w, status code: %d", ErrAccountNotFound, resp.statusCode)
+	}
+
+	// Denials are decoded back into the typed error account-service reported, a payer may not view the payee
+	if resp.statusCode == http.StatusForbidden {
+		var problem response.ProblemResponse
+		if err := json.Unmarshal(resp.body, &problem); err == nil && problem.Code != "" {
+			if problem.Status == 0 {
+				problem.Status = resp.statusCode
+			}
+			return nil, problem.Err()
+		}
+	}
+
+	if resp.statusCode != http.StatusOK {
 		logrus.WithFields(logrus.Fields{
-			"status_code": resp.StatusCode,
+			"status_code": resp.statusCode,
 		}).Error("failed to fetch account")
-		return nil, fmt.Errorf("failed to fetch account, status code: %d", resp.StatusCode)
+		return nil, fmt.Errorf("failed to fetch account, status code: %d", resp.statusCode)
 	}
 
-	var response models.Account
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+	var response getAccountResponse
+	if err := json.Unmarshal(resp.body, &response); err != nil {
 		logrus.WithError(err).Error("failed to decode account response")
 		return nil, fmt.Errorf("failed to decode account response: %w", err)
 	}
-	account := response
+	account := models.Account{
+		ID:       response.ID,
+		Balance:  response.Balance,
+		Currency: response.Currency,
+		Version:  response.Version,
+	}
 
 	logrus.WithFields(logrus.Fields{
 		"account_id": account.ID,
@@ -64,15 +121,25 @@ func (c *AccountClient) GetAccount(accountID types.AccountID) (*models.Account,
 	return &account, nil
 }
 
-func (c *AccountClient) TransferFunds(sourceAccountID types.AccountID, destAccountID types.AccountID, amount types.AccountBalance) (err error) {
-	url := fmt.Sprintf("%s/accounts/%d/balance/transfer", c.baseURL, sourceAccountID)
+// TransferFunds moves funds through the internal PUT /internal/accounts/{account_id}/balance/transfer
+func (c *HTTPAccountClient) TransferFunds(ctx context.Context, transactionID types.TransactionID, sourceAccountID types.AccountID, destAccountID types.AccountID, amount types.AccountBalance) (err error) {
+	url := fmt.Sprintf("%s/internal/accounts/%d/balance/transfer", c.baseURL, sourceAccountID)
+
+	transferID := ""
+	if transactionID != 0 {
+		transferID = strconv.FormatUint(uint64(transactionID), 10)
+	}
 
 	requestBody := struct {
-		DestAccountID types.AccountID      `json:"dest_account_id"`
+		DestAccountID types.AccountID      `json:"dest_account_id,string"`
 		Amount        types.AccountBalance `json:"amount"`
+		TransferID    string               `json:"transfer_id,omitempty"`
+		TransactionID types.TransactionID  `json:"transaction_id,string,omitempty"`
 	}{
 		DestAccountID: destAccountID,
 		Amount:        amount,
+		TransferID:    transferID,
+		TransactionID: transactionID,
 	}
 
 	jsonBody, err := json.Marshal(requestBody)
@@ -84,45 +151,43 @@ func (c *AccountClient) TransferFunds(sourceAccountID types.AccountID, destAccou
 	logrus.WithFields(logrus.Fields{
 		"url":             url,
 		"method":          "PUT",
+		"transfer_id":     transferID,
 		"source_account":  sourceAccountID,
 		"dest_account":    destAccountID,
 		"transfer_amount": amount,
 	}).Debug("sending transfer request to account service")
 
-	req, err := http.NewRequest(http.MethodPut, url, bytes.NewBuffer(jsonBody))
-	if err != nil {
-		logrus.WithError(err).Error("failed to create request")
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.do(ctx, "transfer_funds", http.MethodPut, url, jsonBody, transferID != "")
 	if err != nil {
 		logrus.WithError(err).Error("failed to send transfer request")
 		return fmt.Errorf("failed to send transfer request: %w", err)
 	}
-	defer resp.Body.Close()
 
 	logrus.WithFields(logrus.Fields{
-		"status_code": resp.StatusCode,
+		"status_code": resp.statusCode,
 	}).Debug("received response from account service")
 
-	if resp.StatusCode != http.StatusOK {
-		var response response.ErrorResponse
-		if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+	if resp.statusCode != http.StatusOK {
+		// Rejections are decoded back into the typed error account-service reported
+		var problem response.ProblemResponse
+		if err := json.Unmarshal(resp.body, &problem); err != nil {
 			logrus.WithError(err).Error("failed to decode error response")
 			return fmt.Errorf("failed to decode error response: %w", err)
 		}
-		if response.Message != "" {
+		if problem.Status == 0 {
+			problem.Status = resp.statusCode
+		}
+		if problem.Code != "" || problem.Message != "" {
 			logrus.WithFields(logrus.Fields{
-				"error_message": response.Message,
+				"error_code":    problem.Code,
+				"error_message": problem.Message,
 			}).Error("transfer failed with error message")
-			return fmt.Errorf("%s", response.Message)
+			return problem.Err()
 		}
 		logrus.WithFields(logrus.Fields{
-			"status_code": resp.StatusCode,
+			"status_code": resp.statusCode,
 		}).Error("transfer failed")
-		return fmt.Errorf("transfer failed with status code: %d", resp.StatusCode)
+		return fmt.Errorf("transfer failed with status code: %d", resp.statusCode)
 	}
 
 	logrus.WithFields(logrus.Fields{
@@ -133,3 +198,66 @@ func (c *AccountClient) TransferFunds(sourceAccountID types.AccountID, destAccou
 
 	return nil
 }
+
+// getAccountResponse is the body of GET /v1/accounts/{account_id}, which names the ID account_id unlike models.Account
+type getAccountResponse struct {
+	ID       types.AccountID      `json:"account_id,string"`
+	Balance  types.AccountBalance `json:"balance"`
+	Currency string               `json:"currency"`
+	Version  types.AccountVersion `json:"version"`
+}
+
+// accountResponse is a fully read response, so the body outlives the attempt context
+type accountResponse struct {
+	statusCode int
+	body       []byte
+}
+
+// do sends the request with the retries and circuit breaker of the caller. 5xx and 429 responses are retried
+// like transport errors, the last response is returned once retries are exhausted.
+func (c *HTTPAccountClient) do(ctx context.Context, operation string, method string, url string, body []byte, retryable bool) (*accountResponse, error) {
+	var resp *accountResponse
+	err := c.call(ctx, operation, retryable, func(ctx context.Context) (bool, bool, error) {
+		var err error
+		resp, err = c.attempt(ctx, method, url, body)
+		if err != nil {
+			return true, true, err
+		}
+		failed := resp.statusCode >= http.StatusInternalServerError
+		return failed, failed || resp.statusCode == http.StatusTooManyRequests, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *HTTPAccountClient) attempt(ctx context.Context, method string, url string, body []byte) (*accountResponse, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	requestmeta.SetHeaders(ctx, req.Header)
+	if err := c.config.Service.SetHeader(req.Header); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return &accountResponse{statusCode: resp.StatusCode, body: respBody}, nil
+}
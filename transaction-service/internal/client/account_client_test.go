@@ -0,0 +1,214 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/auth"
+	"github.com/danielkhtse/supreme-adventure/common/response"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testServiceAuth signs and verifies the service tokens of the tests
+func testServiceAuth(t *testing.T) *auth.ServiceAuth {
+	serviceAuth, err := auth.NewServiceAuth("transaction-service", []string{strings.Repeat("s", 32)})
+	require.NoError(t, err)
+	return serviceAuth
+}
+
+func testConfig() Config {
+	config := DefaultConfig()
+	config.Timeout = time.Second
+	config.MaxRetries = 2
+	config.RetryBaseDelay = time.Millisecond
+	config.RetryMaxDelay = 5 * time.Millisecond
+	config.BreakerThreshold = 3
+	config.BreakerCooldown = time.Minute
+	return config
+}
+
+func TestUnitAccountClientRetries(t *testing.T) {
+	t.Run("Retries idempotent GET", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(`{"account_id":"1","balance":100,"version":4}`))
+		}))
+		defer server.Close()
+
+		account, err := NewHTTPAccountClientWithConfig(server.URL, testConfig()).GetAccount(context.Background(), 1)
+		require.NoError(t, err)
+		assert.Equal(t, int32(3), calls.Load())
+		assert.EqualValues(t, 1, account.ID)
+		assert.EqualValues(t, 100, account.Balance)
+		assert.EqualValues(t, 4, account.Version)
+	})
+
+	t.Run("Does not retry transfer without transaction ID", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"message":"unavailable"}`))
+		}))
+		defer server.Close()
+
+		err := NewHTTPAccountClientWithConfig(server.URL, testConfig()).TransferFunds(context.Background(), 0, 1, 2, 50)
+		assert.Error(t, err)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("Retries transfer with transaction ID", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) == 1 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			w.Write([]byte(`{}`))
+		}))
+		defer server.Close()
+
+		err := NewHTTPAccountClientWithConfig(server.URL, testConfig()).TransferFunds(context.Background(), 42, 1, 2, 50)
+		assert.NoError(t, err)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("Does not retry client errors", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		_, err := NewHTTPAccountClientWithConfig(server.URL, testConfig()).GetAccount(context.Background(), 1)
+		assert.ErrorContains(t, err, "404")
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("Per attempt timeout", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		}))
+		defer server.Close()
+
+		config := testConfig()
+		config.Timeout = 20 * time.Millisecond
+		config.MaxRetries = 0
+
+		_, err := NewHTTPAccountClientWithConfig(server.URL, config).GetAccount(context.Background(), 1)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestUnitAccountClientServiceToken(t *testing.T) {
+	serviceAuth := testServiceAuth(t)
+	var path string
+	server := httptest.NewServer(serviceAuth.Require(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Write([]byte(`{}`))
+	})))
+	defer server.Close()
+
+	config := testConfig()
+	config.Service = serviceAuth
+	err := NewHTTPAccountClientWithConfig(server.URL, config).TransferFunds(context.Background(), 42, 1, 2, 50)
+	require.NoError(t, err)
+	assert.Equal(t, "/internal/accounts/1/balance/transfer", path)
+
+	err = NewHTTPAccountClientWithConfig(server.URL, testConfig()).TransferFunds(context.Background(), 42, 1, 2, 50)
+	assert.ErrorIs(t, err, apperr.ErrUnauthenticated)
+}
+
+func TestUnitAccountClientErrors(t *testing.T) {
+	t.Run("Decodes problem responses", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			response.SendProblem(w, apperr.ErrVersionMismatch)
+		}))
+		defer server.Close()
+
+		err := NewHTTPAccountClientWithConfig(server.URL, testConfig()).TransferFunds(context.Background(), 42, 1, 2, 50)
+		assert.ErrorIs(t, err, apperr.ErrVersionMismatch)
+		assert.EqualError(t, err, "account version mismatch")
+	})
+
+	t.Run("Falls back to the status for plain error responses", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"source account not found"}`))
+		}))
+		defer server.Close()
+
+		err := NewHTTPAccountClientWithConfig(server.URL, testConfig()).TransferFunds(context.Background(), 42, 1, 2, 50)
+		e, ok := apperr.As(err)
+		require.True(t, ok)
+		assert.Equal(t, apperr.CodeNotFound, e.Code)
+		assert.EqualError(t, err, "source account not found")
+	})
+
+	t.Run("Decodes account lookup denials", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			response.SendProblem(w, apperr.ErrAccountAccessDenied.WithMessage("the caller may not view account 2"))
+		}))
+		defer server.Close()
+
+		_, err := NewHTTPAccountClientWithConfig(server.URL, testConfig()).GetAccount(context.Background(), 2)
+		assert.ErrorIs(t, err, apperr.ErrAccountAccessDenied)
+		assert.EqualError(t, err, "the caller may not view account 2")
+	})
+}
+
+func TestUnitCircuitBreaker(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var states []BreakerState
+	breaker := newCircuitBreaker(2, time.Minute, func(state BreakerState) { states = append(states, state) })
+	breaker.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, breaker.allow())
+		breaker.record(false)
+	}
+	assert.Equal(t, BreakerOpen, breaker.currentState())
+	assert.ErrorIs(t, breaker.allow(), ErrCircuitOpen)
+
+	now = now.Add(time.Minute)
+	require.NoError(t, breaker.allow())
+	assert.Equal(t, BreakerHalfOpen, breaker.currentState())
+	assert.ErrorIs(t, breaker.allow(), ErrCircuitOpen, "only one trial call while half-open")
+
+	breaker.record(true)
+	assert.Equal(t, BreakerClosed, breaker.currentState())
+	assert.Equal(t, []BreakerState{BreakerClosed, BreakerOpen, BreakerHalfOpen, BreakerClosed}, states)
+}
+
+func TestUnitAccountClientFailsFastWhenOpen(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := NewHTTPAccountClientWithConfig(server.URL, testConfig())
+	_, err := client.GetAccount(context.Background(), 1)
+	assert.Error(t, err)
+	assert.Equal(t, BreakerOpen, client.BreakerState())
+
+	_, err = client.GetAccount(context.Background(), 1)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, int32(3), calls.Load())
+}
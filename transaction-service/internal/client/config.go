@@ -0,0 +1,155 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/danielkhtse/supreme-adventure/common/auth"
+)
+
+// Environment variables overriding the defaults of Config
+const (
+	TimeoutEnv             = "ACCOUNT_CLIENT_TIMEOUT"
+	MaxRetriesEnv          = "ACCOUNT_CLIENT_MAX_RETRIES"
+	RetryBaseDelayEnv      = "ACCOUNT_CLIENT_RETRY_BASE_DELAY"
+	RetryMaxDelayEnv       = "ACCOUNT_CLIENT_RETRY_MAX_DELAY"
+	BreakerThresholdEnv    = "ACCOUNT_CLIENT_BREAKER_THRESHOLD"
+	BreakerCooldownEnv     = "ACCOUNT_CLIENT_BREAKER_COOLDOWN"
+	MaxIdleConnsEnv        = "ACCOUNT_CLIENT_MAX_IDLE_CONNS"
+	MaxIdleConnsPerHostEnv = "ACCOUNT_CLIENT_MAX_IDLE_CONNS_PER_HOST"
+	MaxConnsPerHostEnv     = "ACCOUNT_CLIENT_MAX_CONNS_PER_HOST"
+	IdleConnTimeoutEnv     = "ACCOUNT_CLIENT_IDLE_CONN_TIMEOUT"
+
+	// TransportEnv selects the transport of the client, "http" (default) or "grpc"
+	TransportEnv = "ACCOUNT_CLIENT_TRANSPORT"
+
+	// Addresses of account-service for each transport
+	ServiceURLEnv        = "ACCOUNT_SERVICE_URL"
+	ServiceGRPCTargetEnv = "ACCOUNT_SERVICE_GRPC_TARGET"
+)
+
+// Transports of AccountClient
+const (
+	TransportHTTP = "http"
+	TransportGRPC = "grpc"
+)
+
+// Config tunes timeouts, retries, the circuit breaker and the connection pool of AccountClient
+type Config struct {
+	// Timeout bounds every single attempt, the caller context bounds the call including retries
+	Timeout time.Duration
+
+	// MaxRetries is the number of retries after the first attempt of a retryable call
+	MaxRetries int
+
+	// Retries wait a random delay up to RetryBaseDelay * 2^retry, capped at RetryMaxDelay
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+
+	// BreakerThreshold consecutive failures open the circuit for BreakerCooldown, after which one trial call is let through
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+
+	// Connection pool of the underlying http.Transport, the gRPC transport multiplexes a single
+	// connection and only closes it after IdleConnTimeout
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	MaxConnsPerHost     int
+	IdleConnTimeout     time.Duration
+
+	// Service signs the service token presented with every call, which the internal transfer endpoints of
+	// account-service require. No token is presented when nil.
+	Service *auth.ServiceAuth
+}
+
+// DefaultConfig returns the configuration used when no environment overrides are set
+func DefaultConfig() Config {
+	return Config{
+		Timeout:             5 * time.Second,
+		MaxRetries:          3,
+		RetryBaseDelay:      100 * time.Millisecond,
+		RetryMaxDelay:       2 * time.Second,
+		BreakerThreshold:    5,
+		BreakerCooldown:     30 * time.Second,
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 20,
+		MaxConnsPerHost:     0,
+		IdleConnTimeout:     90 * time.Second,
+	}
+}
+
+// ConfigFromEnv returns DefaultConfig with the ACCOUNT_CLIENT_* environment variables applied, signing service
+// tokens with the secrets of AUTH_SERVICE_SECRETS
+func ConfigFromEnv() (Config, error) {
+	config := DefaultConfig()
+
+	serviceAuth, err := auth.ServiceAuthFromEnv("transaction-service")
+	if err != nil {
+		return Config{}, err
+	}
+	config.Service = serviceAuth
+
+	durations := map[string]*time.Duration{
+		TimeoutEnv:         &config.Timeout,
+		RetryBaseDelayEnv:  &config.RetryBaseDelay,
+		RetryMaxDelayEnv:   &config.RetryMaxDelay,
+		BreakerCooldownEnv: &config.BreakerCooldown,
+		IdleConnTimeoutEnv: &config.IdleConnTimeout,
+	}
+	for name, target := range durations {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(value)
+		if err != nil || duration < 0 {
+			return Config{}, fmt.Errorf("invalid %s: %q", name, value)
+		}
+		*target = duration
+	}
+
+	ints := map[string]*int{
+		MaxRetriesEnv:          &config.MaxRetries,
+		BreakerThresholdEnv:    &config.BreakerThreshold,
+		MaxIdleConnsEnv:        &config.MaxIdleConns,
+		MaxIdleConnsPerHostEnv: &config.MaxIdleConnsPerHost,
+		MaxConnsPerHostEnv:     &config.MaxConnsPerHost,
+	}
+	for name, target := range ints {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		number, err := strconv.Atoi(value)
+		if err != nil || number < 0 {
+			return Config{}, fmt.Errorf("invalid %s: %q", name, value)
+		}
+		*target = number
+	}
+
+	return config, nil
+}
+
+// NewAccountClientFromEnv creates the AccountClient of the transport selected by ACCOUNT_CLIENT_TRANSPORT,
+// configured by ConfigFromEnv
+func NewAccountClientFromEnv() (AccountClient, error) {
+	config, err := ConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	switch transport := os.Getenv(TransportEnv); transport {
+	case "", TransportHTTP:
+		return NewHTTPAccountClientWithConfig(os.Getenv(ServiceURLEnv), config), nil
+	case TransportGRPC:
+		target := os.Getenv(ServiceGRPCTargetEnv)
+		if target == "" {
+			return nil, fmt.Errorf("%s environment variable not set", ServiceGRPCTargetEnv)
+		}
+		return NewGRPCAccountClient(target, config)
+	default:
+		return nil, fmt.Errorf("invalid %s: %q", TransportEnv, transport)
+	}
+}
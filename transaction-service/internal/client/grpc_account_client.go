@@ -0,0 +1,186 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/models"
+	ledgerv1 "github.com/danielkhtse/supreme-adventure/common/proto/ledger/v1"
+	"github.com/danielkhtse/supreme-adventure/common/requestmeta"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCAccountClient calls the account-service gRPC API
+type GRPCAccountClient struct {
+	*caller
+	conn   *grpc.ClientConn
+	client ledgerv1.AccountServiceClient
+}
+
+// NewGRPCAccountClient creates a client of the account-service gRPC server at target, e.g. account-service:9090
+func NewGRPCAccountClient(target string, config Config) (*GRPCAccountClient, error) {
+	conn, err := grpc.NewClient(target,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithIdleTimeout(config.IdleConnTimeout),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create account service connection: %w", err)
+	}
+
+	return &GRPCAccountClient{
+		caller: newCaller(config),
+		conn:   conn,
+		client: ledgerv1.NewAccountServiceClient(conn),
+	}, nil
+}
+
+// Close closes the connection to account-service
+func (c *GRPCAccountClient) Close() error {
+	return c.conn.Close()
+}
+
+func (c *GRPCAccountClient) GetAccount(ctx context.Context, accountID types.AccountID) (*models.Account, error) {
+	logrus.WithFields(logrus.Fields{
+		"account_id": accountID,
+		"request_id": requestmeta.FromContext(ctx).RequestID,
+	}).Debug("sending gRPC request to account service")
+
+	var resp *ledgerv1.GetAccountResponse
+	err := c.call(ctx, "get_account", true, func(ctx context.Context) (bool, bool, error) {
+		ctx, err := c.outgoingContext(ctx)
+		if err != nil {
+			return false, false, err
+		}
+		resp, err = c.client.GetAccount(ctx, &ledgerv1.GetAccountRequest{
+			AccountId: uint64(accountID),
+		})
+		unhealthy, retry := grpcOutcome(err)
+		return unhealthy, retry, err
+	})
+	if status.Code(err) == codes.NotFound {
+		return nil, fmt.Errorf("%w, status code: %s", ErrAccountNotFound, codes.NotFound)
+	}
+	// Denials carry the account-service error code, like the HTTP problem response
+	if status.Code(err) == codes.PermissionDenied {
+		if e, ok := apperr.FromGRPC(err); ok {
+			return nil, e
+		}
+	}
+	if err != nil {
+		logrus.WithError(err).Error("failed to fetch account")
+		return nil, fmt.Errorf("failed to fetch account: %w", err)
+	}
+
+	return fromAccountMessage(resp.GetAccount()), nil
+}
+
+// TransferFunds moves funds through the TransferFunds RPC
+func (c *GRPCAccountClient) TransferFunds(ctx context.Context, transactionID types.TransactionID, sourceAccountID types.AccountID, destAccountID types.AccountID, amount types.AccountBalance) error {
+	transferID := ""
+	if transactionID != 0 {
+		transferID = strconv.FormatUint(uint64(transactionID), 10)
+	}
+
+	request := &ledgerv1.TransferFundsRequest{
+		SourceAccountId: uint64(sourceAccountID),
+		DestAccountId:   uint64(destAccountID),
+		Amount:          int64(amount),
+		TransferId:      transferID,
+		TransactionId:   uint64(transactionID),
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"transfer_id":     transferID,
+		"request_id":      requestmeta.FromContext(ctx).RequestID,
+		"source_account":  sourceAccountID,
+		"dest_account":    destAccountID,
+		"transfer_amount": amount,
+	}).Debug("sending gRPC transfer request to account service")
+
+	err := c.call(ctx, "transfer_funds", transferID != "", func(ctx context.Context) (bool, bool, error) {
+		ctx, err := c.outgoingContext(ctx)
+		if err != nil {
+			return false, false, err
+		}
+		_, err = c.client.TransferFunds(ctx, request)
+		unhealthy, retry := grpcOutcome(err)
+		return unhealthy, retry, err
+	})
+	if err != nil {
+		// Rejections carry the account-service error code, like the HTTP problem response
+		if e, ok := apperr.FromGRPC(err); ok {
+			logrus.WithFields(logrus.Fields{
+				"error_code":    e.Code,
+				"error_message": e.Message,
+			}).Error("transfer failed with error message")
+			return e
+		}
+		if st, ok := status.FromError(err); ok && !isTransient(st.Code()) {
+			logrus.WithFields(logrus.Fields{
+				"error_message": st.Message(),
+			}).Error("transfer failed with error message")
+			return errors.New(st.Message())
+		}
+		logrus.WithError(err).Error("failed to send transfer request")
+		return fmt.Errorf("failed to send transfer request: %w", err)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"source_account":  sourceAccountID,
+		"dest_account":    destAccountID,
+		"transfer_amount": amount,
+	}).Info("successfully completed transfer")
+
+	return nil
+}
+
+// outgoingContext returns a copy of ctx sending the request metadata of the caller and the service token with
+// outgoing calls
+func (c *GRPCAccountClient) outgoingContext(ctx context.Context) (context.Context, error) {
+	return c.config.Service.OutgoingContext(requestmeta.OutgoingContext(ctx))
+}
+
+// grpcOutcome classifies the result of an attempt like HTTPAccountClient classifies status codes:
+// unavailability and server errors count against the breaker and are retried, exhaustion is only retried
+func grpcOutcome(err error) (unhealthy bool, retry bool) {
+	code := status.Code(err)
+	switch {
+	case code == codes.ResourceExhausted:
+		return false, true
+	case isTransient(code):
+		return true, true
+	default:
+		return false, false
+	}
+}
+
+func isTransient(code codes.Code) bool {
+	switch code {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Internal, codes.Unknown:
+		return true
+	default:
+		return false
+	}
+}
+
+func fromAccountMessage(message *ledgerv1.Account) *models.Account {
+	return &models.Account{
+		ID:             types.AccountID(message.GetId()),
+		Balance:        types.AccountBalance(message.GetBalance()),
+		InitialBalance: types.AccountBalance(message.GetInitialBalance()),
+		Currency:       message.GetCurrency(),
+		Status:         types.AccountStatus(message.GetStatus()),
+		Owner:          message.GetOwner(),
+		Version:        types.AccountVersion(message.GetVersion()),
+		CreatedAt:      message.GetCreatedAt().AsTime(),
+		UpdatedAt:      message.GetUpdatedAt().AsTime(),
+	}
+}
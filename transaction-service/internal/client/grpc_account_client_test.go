@@ -0,0 +1,144 @@
+package client
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/auth"
+	ledgerv1 "github.com/danielkhtse/supreme-adventure/common/proto/ledger/v1"
+	"github.com/danielkhtse/supreme-adventure/common/requestmeta"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type fakeAccountServer struct {
+	ledgerv1.UnimplementedAccountServiceServer
+
+	calls     atomic.Int32
+	failFirst int32
+	err       error
+	requestID atomic.Value
+	service   atomic.Value
+}
+
+func (s *fakeAccountServer) GetAccount(ctx context.Context, req *ledgerv1.GetAccountRequest) (*ledgerv1.GetAccountResponse, error) {
+	s.requestID.Store(requestmeta.FromContext(ctx).RequestID)
+	if s.calls.Add(1) <= s.failFirst {
+		return nil, status.Error(codes.Unavailable, "unavailable")
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &ledgerv1.GetAccountResponse{Account: &ledgerv1.Account{Id: req.GetAccountId(), Balance: 100}}, nil
+}
+
+func (s *fakeAccountServer) TransferFunds(ctx context.Context, req *ledgerv1.TransferFundsRequest) (*ledgerv1.TransferFundsResponse, error) {
+	service, _ := auth.ServiceFromContext(ctx)
+	s.service.Store(service)
+	if s.calls.Add(1) <= s.failFirst {
+		return nil, status.Error(codes.Unavailable, "unavailable")
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &ledgerv1.TransferFundsResponse{}, nil
+}
+
+func newTestGRPCClient(t *testing.T, server *fakeAccountServer) *GRPCAccountClient {
+	serviceAuth := testServiceAuth(t)
+	lis := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		requestmeta.UnaryServerInterceptor(),
+		serviceAuth.UnaryServerInterceptor(map[string]bool{ledgerv1.AccountService_TransferFunds_FullMethodName: true}),
+	))
+	ledgerv1.RegisterAccountServiceServer(grpcServer, server)
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	config := testConfig()
+	config.Service = serviceAuth
+	return &GRPCAccountClient{
+		caller: newCaller(config),
+		conn:   conn,
+		client: ledgerv1.NewAccountServiceClient(conn),
+	}
+}
+
+func TestUnitGRPCAccountClient(t *testing.T) {
+	t.Run("Retries unavailable and propagates the request ID", func(t *testing.T) {
+		server := &fakeAccountServer{failFirst: 2}
+		client := newTestGRPCClient(t, server)
+
+		ctx := requestmeta.NewContext(context.Background(), requestmeta.Metadata{RequestID: "req-1"})
+		account, err := client.GetAccount(ctx, 7)
+		require.NoError(t, err)
+		assert.Equal(t, int32(3), server.calls.Load())
+		assert.EqualValues(t, 7, account.ID)
+		assert.EqualValues(t, 100, account.Balance)
+		assert.Equal(t, "req-1", server.requestID.Load())
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		server := &fakeAccountServer{err: status.Error(codes.NotFound, "account not found")}
+		_, err := newTestGRPCClient(t, server).GetAccount(context.Background(), 7)
+		assert.ErrorIs(t, err, ErrAccountNotFound)
+		assert.Equal(t, int32(1), server.calls.Load())
+	})
+
+	t.Run("Decodes account lookup denials", func(t *testing.T) {
+		st, _ := apperr.GRPCStatus(apperr.ErrAccountAccessDenied.WithMessage("the caller may not view account 7"))
+		server := &fakeAccountServer{err: st.Err()}
+		_, err := newTestGRPCClient(t, server).GetAccount(context.Background(), 7)
+		assert.ErrorIs(t, err, apperr.ErrAccountAccessDenied)
+		assert.EqualError(t, err, "the caller may not view account 7")
+		assert.Equal(t, int32(1), server.calls.Load())
+	})
+
+	t.Run("Does not retry transfers without ID", func(t *testing.T) {
+		server := &fakeAccountServer{failFirst: 1}
+		err := newTestGRPCClient(t, server).TransferFunds(context.Background(), 0, 1, 2, 50)
+		assert.Error(t, err)
+		assert.Equal(t, int32(1), server.calls.Load())
+	})
+
+	t.Run("Retries transfers with ID", func(t *testing.T) {
+		server := &fakeAccountServer{failFirst: 1}
+		err := newTestGRPCClient(t, server).TransferFunds(context.Background(), 42, 1, 2, 50)
+		assert.NoError(t, err)
+		assert.Equal(t, int32(2), server.calls.Load())
+		assert.Equal(t, "transaction-service", server.service.Load())
+	})
+
+	t.Run("Returns the rejection message", func(t *testing.T) {
+		server := &fakeAccountServer{err: status.Error(codes.FailedPrecondition, "insufficient balance")}
+		err := newTestGRPCClient(t, server).TransferFunds(context.Background(), 42, 1, 2, 50)
+		assert.EqualError(t, err, "insufficient balance")
+		assert.Equal(t, int32(1), server.calls.Load())
+	})
+
+	t.Run("Decodes typed rejections", func(t *testing.T) {
+		st, _ := apperr.GRPCStatus(apperr.ErrAccountFrozen.WithMessage("account 2 is inactive"))
+		server := &fakeAccountServer{err: st.Err()}
+		err := newTestGRPCClient(t, server).TransferFunds(context.Background(), 42, 1, 2, 50)
+		assert.ErrorIs(t, err, apperr.ErrAccountFrozen)
+		assert.EqualError(t, err, "account 2 is inactive")
+		assert.Equal(t, int32(1), server.calls.Load())
+	})
+}
@@ -0,0 +1,33 @@
+package client
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	breakerStateGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "account_client_circuit_breaker_state",
+		Help: "State of the account-service circuit breaker: 0 closed, 1 half-open, 2 open.",
+	})
+
+	requestsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "account_client_requests_total",
+		Help: "Account-service calls by operation and outcome.",
+	}, []string{"operation", "outcome"})
+
+	retriesCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "account_client_retries_total",
+		Help: "Retried account-service attempts by operation.",
+	}, []string{"operation"})
+
+	requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "account_client_request_duration_seconds",
+		Help:    "Duration of account-service calls including retries.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"operation"})
+)
+
+func setBreakerStateMetric(state BreakerState) {
+	breakerStateGauge.Set(float64(state))
+}
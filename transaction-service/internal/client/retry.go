@@ -0,0 +1,110 @@
+package client
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// caller applies the per-attempt timeout, retries and circuit breaker shared by both transports
+type caller struct {
+	config  Config
+	breaker *circuitBreaker
+}
+
+func newCaller(config Config) *caller {
+	return &caller{
+		config:  config,
+		breaker: newCircuitBreaker(config.BreakerThreshold, config.BreakerCooldown, setBreakerStateMetric),
+	}
+}
+
+// attemptFunc performs a single attempt within ctx. unhealthy reports a failure of account-service counted
+// by the circuit breaker, retry that the attempt did not succeed and may be repeated.
+type attemptFunc func(ctx context.Context) (unhealthy bool, retry bool, err error)
+
+// call runs attempt through the circuit breaker. Retryable calls are retried with jittered backoff
+// while attempts ask for it. The error of the last attempt is returned once retries are exhausted.
+func (c *caller) call(ctx context.Context, operation string, retryable bool, attempt attemptFunc) error {
+	start := time.Now()
+	defer func() {
+		requestDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	}()
+
+	attempts := 1
+	if retryable {
+		attempts += c.config.MaxRetries
+	}
+
+	var err error
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			retriesCounter.WithLabelValues(operation).Inc()
+			delay := c.backoff(i)
+			logrus.WithFields(logrus.Fields{
+				"operation": operation,
+				"attempt":   i + 1,
+				"delay":     delay,
+			}).Warn("retrying account service call")
+			if waitErr := sleep(ctx, delay); waitErr != nil {
+				break
+			}
+		}
+
+		if breakerErr := c.breaker.allow(); breakerErr != nil {
+			requestsCounter.WithLabelValues(operation, "circuit_open").Inc()
+			return breakerErr
+		}
+
+		var unhealthy, retry bool
+		unhealthy, retry, err = c.attempt(ctx, attempt)
+		if err != nil && ctx.Err() != nil {
+			// Cancelled by the caller, says nothing about the health of account-service
+			c.breaker.release()
+			break
+		}
+
+		c.breaker.record(!unhealthy)
+		if !retry {
+			requestsCounter.WithLabelValues(operation, "success").Inc()
+			return err
+		}
+	}
+
+	requestsCounter.WithLabelValues(operation, "error").Inc()
+	return err
+}
+
+func (c *caller) attempt(ctx context.Context, attempt attemptFunc) (bool, bool, error) {
+	if c.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.config.Timeout)
+		defer cancel()
+	}
+	return attempt(ctx)
+}
+
+// backoff returns a random delay up to RetryBaseDelay * 2^(retry-1), capped at RetryMaxDelay
+func (c *caller) backoff(retry int) time.Duration {
+	ceiling := c.config.RetryBaseDelay << (retry - 1)
+	if ceiling <= 0 || ceiling > c.config.RetryMaxDelay {
+		ceiling = c.config.RetryMaxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling + 1)
+}
+
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
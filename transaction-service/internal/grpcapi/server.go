@@ -0,0 +1,89 @@
+package grpcapi
+
+import (
+	"net"
+	"os"
+
+	"github.com/danielkhtse/supreme-adventure/common/auth"
+	"github.com/danielkhtse/supreme-adventure/common/authz"
+	"github.com/danielkhtse/supreme-adventure/common/events"
+	ledgerv1 "github.com/danielkhtse/supreme-adventure/common/proto/ledger/v1"
+	"github.com/danielkhtse/supreme-adventure/common/requestmeta"
+	"github.com/danielkhtse/supreme-adventure/common/stream"
+	"github.com/danielkhtse/supreme-adventure/transaction-service/internal/service"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// Transactions gRPC server
+type Server struct {
+	ledgerv1.UnimplementedTransactionServiceServer
+
+	TransactionService *service.TransactionService
+	Streamer           *stream.Handler
+	Auth               *auth.Authenticator
+	Policy             authz.Policy
+	GRPCServer         *grpc.Server
+	Health             *health.Server
+	Port               string
+}
+
+func (server *Server) Initialize(transactionService *service.TransactionService, authenticator *auth.Authenticator) {
+	server.TransactionService = transactionService
+	server.Auth = authenticator
+	server.Policy = transactionService.Policy()
+	server.Port = os.Getenv("TRANSACTION_GRPC_SERVER_PORT")
+
+	if server.Port == "" {
+		log.Fatal("TRANSACTION_GRPC_SERVER_PORT environment variable not set")
+	}
+
+	server.Streamer = &stream.Handler{
+		Feed:       transactionService,
+		Broker:     transactionService.Events(),
+		Authorizer: authz.StreamAuthorizer{Policy: server.Policy},
+		Types:      []events.Type{events.TypeTransactionStatusChanged, events.TypeAccountBalanceChanged},
+	}
+
+	server.GRPCServer = NewGRPCServer(server)
+}
+
+// methodScopes are the scopes required by the methods of the transaction service, health checks and reflection are
+// served without authentication
+var methodScopes = map[string]string{
+	ledgerv1.TransactionService_CreateTransaction_FullMethodName: auth.ScopeTransactionsWrite,
+	ledgerv1.TransactionService_GetTransaction_FullMethodName:    auth.ScopeTransactionsRead,
+	ledgerv1.TransactionService_ListTransactions_FullMethodName:  auth.ScopeTransactionsRead,
+	ledgerv1.TransactionService_WatchTransactions_FullMethodName: auth.ScopeTransactionsRead,
+}
+
+// NewGRPCServer creates a gRPC server serving the transaction service, the health service and reflection
+func NewGRPCServer(server *Server) *grpc.Server {
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(requestmeta.UnaryServerInterceptor(), server.Auth.UnaryServerInterceptor(methodScopes)),
+		grpc.ChainStreamInterceptor(requestmeta.StreamServerInterceptor(), server.Auth.StreamServerInterceptor(methodScopes)),
+	)
+	ledgerv1.RegisterTransactionServiceServer(grpcServer, server)
+
+	server.Health = health.NewServer()
+	server.Health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	server.Health.SetServingStatus(ledgerv1.TransactionService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(grpcServer, server.Health)
+
+	reflection.Register(grpcServer)
+	return grpcServer
+}
+
+func (server *Server) Run() {
+	lis, err := net.Listen("tcp", ":"+server.Port)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Info("Listening to port " + server.Port)
+	log.Info("Transaction gRPC Server Started")
+	log.Fatal(server.GRPCServer.Serve(lis))
+}
@@ -0,0 +1,187 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/auth"
+	"github.com/danielkhtse/supreme-adventure/common/auth/authtest"
+	"github.com/danielkhtse/supreme-adventure/common/authz"
+	"github.com/danielkhtse/supreme-adventure/common/events"
+	"github.com/danielkhtse/supreme-adventure/common/models"
+	"github.com/danielkhtse/supreme-adventure/common/pagination"
+	ledgerv1 "github.com/danielkhtse/supreme-adventure/common/proto/ledger/v1"
+	"github.com/danielkhtse/supreme-adventure/common/stream"
+	"github.com/danielkhtse/supreme-adventure/common/stream/streamtest"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestUnitToStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		code codes.Code
+	}{
+		{apperr.ErrTransactionNotFound, codes.NotFound},
+		{apperr.ErrAccountNotFound.WithMessage("source account not found"), codes.NotFound},
+		{apperr.ErrInsufficientFunds.WithMessage("insufficient balance in source account 1"), codes.FailedPrecondition},
+		{apperr.ErrSameAccount, codes.InvalidArgument},
+		{apperr.Invalid("min_amount must not exceed max_amount"), codes.InvalidArgument},
+		{fmt.Errorf("bad cursor: %w", pagination.ErrInvalidCursor), codes.InvalidArgument},
+		{fmt.Errorf("failed to transfer funds: %w", apperr.ErrVersionMismatch), codes.Aborted},
+		{errors.New("failed to create transaction: connection refused"), codes.Internal},
+	}
+
+	for _, test := range tests {
+		t.Run(test.err.Error(), func(t *testing.T) {
+			assert.Equal(t, test.code, status.Code(toStatus(test.err)))
+		})
+	}
+}
+
+func newTestConn(t *testing.T, server *Server) *grpc.ClientConn {
+	lis := bufconn.Listen(1024 * 1024)
+	grpcServer := NewGRPCServer(server)
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestUnitMethodScopes(t *testing.T) {
+	//a method missing from methodScopes would be served without authentication
+	desc := ledgerv1.TransactionService_ServiceDesc
+	for _, method := range desc.Methods {
+		assert.Contains(t, methodScopes, "/"+desc.ServiceName+"/"+method.MethodName)
+	}
+	for _, stream := range desc.Streams {
+		assert.Contains(t, methodScopes, "/"+desc.ServiceName+"/"+stream.StreamName)
+	}
+}
+
+func TestUnitGRPCServer(t *testing.T) {
+	conn := newTestConn(t, &Server{})
+
+	t.Run("Health", func(t *testing.T) {
+		resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{
+			Service: ledgerv1.TransactionService_ServiceDesc.ServiceName,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+	})
+
+	t.Run("Invalid requests", func(t *testing.T) {
+		client := ledgerv1.NewTransactionServiceClient(conn)
+
+		_, err := client.CreateTransaction(context.Background(), &ledgerv1.CreateTransactionRequest{SourceAccountId: 1, DestAccountId: 2})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = client.ListTransactions(context.Background(), &ledgerv1.ListTransactionsRequest{Status: "unknown"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestUnitWatchTransactions(t *testing.T) {
+	feed := streamtest.NewFeed()
+	publish := func(id uint64, eventType events.Type, data any) {
+		event, err := events.New(id, eventType, data, 1, 2)
+		require.NoError(t, err)
+		feed.Append(event)
+	}
+
+	transaction := models.Transaction{ID: 10, SourceAccountID: 1, DestAccountID: 2, Amount: 100, Status: types.TransactionStatusPending}
+	publish(1, events.TypeTransactionStatusChanged, transaction)
+	publish(2, events.TypeAccountBalanceChanged, events.BalanceChange{AccountID: 1, Amount: -100, TransactionID: 10})
+
+	conn := newTestConn(t, &Server{Streamer: &stream.Handler{
+		Feed:   feed,
+		Broker: feed.Broker,
+		Types:  []events.Type{events.TypeTransactionStatusChanged, events.TypeAccountBalanceChanged},
+	}})
+	client := ledgerv1.NewTransactionServiceClient(conn)
+
+	transaction.Status = types.TransactionStatusCompleted
+	publish(3, events.TypeAccountBalanceChanged, events.BalanceChange{AccountID: 1, Amount: -100, TransactionID: 10})
+	publish(4, events.TypeTransactionStatusChanged, transaction)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// balance changes are skipped unless requested
+	watch, err := client.WatchTransactions(ctx, &ledgerv1.WatchTransactionsRequest{AccountId: 1, LastEventId: 1})
+	require.NoError(t, err)
+
+	resp, err := watch.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), resp.GetStatusChange().GetEventId())
+	assert.Equal(t, "completed", resp.GetStatusChange().GetTransaction().GetStatus())
+
+	resumed, err := client.WatchTransactions(ctx, &ledgerv1.WatchTransactionsRequest{AccountId: 1, LastEventId: 1, IncludeBalanceChanges: true})
+	require.NoError(t, err)
+
+	resp, err = resumed.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), resp.GetBalanceChange().GetEventId())
+	assert.Equal(t, int64(-100), resp.GetBalanceChange().GetAmount())
+
+	resp, err = resumed.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), resp.GetBalanceChange().GetEventId())
+}
+
+// denyAll is a policy denying every action
+type denyAll struct{}
+
+func (denyAll) Authorize(_ context.Context, _ string, action authz.Action, accountID types.AccountID) error {
+	return apperr.ErrAccountAccessDenied.WithMessage("the caller may not %s account %d", action, accountID)
+}
+
+func TestUnitTransactionAccessDenied(t *testing.T) {
+	issuer := authtest.NewIssuer(t)
+	server := &Server{Auth: issuer.Authenticator, Policy: denyAll{}}
+	server.Streamer = &stream.Handler{Feed: streamtest.NewFeed(), Authorizer: authz.StreamAuthorizer{Policy: server.Policy}}
+	client := ledgerv1.NewTransactionServiceClient(newTestConn(t, server))
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization",
+		issuer.Token(t, "user-1", auth.ScopeTransactionsRead))
+
+	t.Run("Listing an account", func(t *testing.T) {
+		_, err := client.ListTransactions(ctx, &ledgerv1.ListTransactionsRequest{AccountId: 1})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+
+	t.Run("Listing every account", func(t *testing.T) {
+		_, err := client.ListTransactions(ctx, &ledgerv1.ListTransactionsRequest{})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		e, ok := apperr.FromGRPC(err)
+		require.True(t, ok)
+		assert.ErrorIs(t, e, apperr.ErrAccountAccessDenied)
+	})
+
+	t.Run("Watching an account", func(t *testing.T) {
+		watch, err := client.WatchTransactions(ctx, &ledgerv1.WatchTransactionsRequest{AccountId: 1})
+		require.NoError(t, err)
+		_, err = watch.Recv()
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+}
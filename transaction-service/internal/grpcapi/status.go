@@ -0,0 +1,17 @@
+package grpcapi
+
+import (
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// toStatus maps a transaction service error to a gRPC status carrying its error code
+func toStatus(err error) error {
+	if st, ok := apperr.GRPCStatus(err); ok {
+		return st.Err()
+	}
+	log.WithError(err).Error("transaction service request failed")
+	return status.Error(codes.Internal, "internal error")
+}
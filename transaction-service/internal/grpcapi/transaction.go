@@ -0,0 +1,191 @@
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/authz"
+	"github.com/danielkhtse/supreme-adventure/common/events"
+	"github.com/danielkhtse/supreme-adventure/common/models"
+	"github.com/danielkhtse/supreme-adventure/common/pagination"
+	ledgerv1 "github.com/danielkhtse/supreme-adventure/common/proto/ledger/v1"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"github.com/danielkhtse/supreme-adventure/transaction-service/internal/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// CreateTransaction validates both accounts, records the transaction and performs the transfer
+func (s *Server) CreateTransaction(ctx context.Context, req *ledgerv1.CreateTransactionRequest) (*ledgerv1.CreateTransactionResponse, error) {
+	if req.GetSourceAccountId() == 0 || req.GetDestAccountId() == 0 {
+		return nil, toStatus(apperr.Invalid("source and destination account IDs are required"))
+	}
+	if req.GetAmount() < 1 {
+		return nil, toStatus(apperr.Invalid("amount must be positive"))
+	}
+
+	if err := authz.Check(ctx, s.Policy, authz.ActionDebit, types.AccountID(req.GetSourceAccountId())); err != nil {
+		return nil, toStatus(err)
+	}
+
+	transaction := &models.Transaction{
+		SourceAccountID: types.AccountID(req.GetSourceAccountId()),
+		DestAccountID:   types.AccountID(req.GetDestAccountId()),
+		Amount:          types.AccountBalance(req.GetAmount()),
+	}
+	if err := s.TransactionService.CreateTransaction(ctx, transaction); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &ledgerv1.CreateTransactionResponse{Transaction: toTransaction(transaction)}, nil
+}
+
+// GetTransaction returns a transaction by ID
+func (s *Server) GetTransaction(ctx context.Context, req *ledgerv1.GetTransactionRequest) (*ledgerv1.GetTransactionResponse, error) {
+	transaction, err := s.TransactionService.GetTransaction(ctx, types.TransactionID(req.GetTransactionId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	if err := authz.CheckAny(ctx, s.Policy, authz.ActionView, transaction.SourceAccountID, transaction.DestAccountID); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &ledgerv1.GetTransactionResponse{Transaction: toTransaction(transaction)}, nil
+}
+
+// ListTransactions returns a page of transactions sorted by creation time
+func (s *Server) ListTransactions(ctx context.Context, req *ledgerv1.ListTransactionsRequest) (*ledgerv1.ListTransactionsResponse, error) {
+	filter := service.TransactionFilter{
+		AccountID: types.AccountID(req.GetAccountId()),
+		Status:    types.TransactionStatus(req.GetStatus()),
+		MinAmount: types.AccountBalance(req.GetMinAmount()),
+		MaxAmount: types.AccountBalance(req.GetMaxAmount()),
+		Ascending: req.GetAscending(),
+		Cursor:    req.GetCursor(),
+		Limit:     int(req.GetLimit()),
+	}
+
+	switch filter.Status {
+	case "", types.TransactionStatusPending, types.TransactionStatusCompleted, types.TransactionStatusFailed:
+	default:
+		return nil, toStatus(apperr.Invalid("status must be one of pending, completed, failed"))
+	}
+	if filter.MinAmount < 0 || filter.MaxAmount < 0 {
+		return nil, toStatus(apperr.Invalid("min_amount and max_amount must not be negative"))
+	}
+	if filter.Limit < 0 || filter.Limit > pagination.MaxLimit {
+		return nil, toStatus(apperr.Invalid("limit must be between 1 and 200"))
+	}
+	if req.GetCreatedFrom() != nil {
+		filter.CreatedFrom = req.GetCreatedFrom().AsTime()
+	}
+	if req.GetCreatedTo() != nil {
+		filter.CreatedTo = req.GetCreatedTo().AsTime()
+	}
+
+	//without an account filter the listing spans every account, the unset account ID is authz.AllAccounts
+	if err := authz.Check(ctx, s.Policy, authz.ActionView, filter.AccountID); err != nil {
+		return nil, toStatus(err)
+	}
+
+	page, err := s.TransactionService.ListTransactions(ctx, filter)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &ledgerv1.ListTransactionsResponse{
+		Transactions: make([]*ledgerv1.Transaction, 0, len(page.Transactions)),
+		NextCursor:   page.NextCursor,
+		HasMore:      page.HasMore,
+	}
+	for i := range page.Transactions {
+		resp.Transactions = append(resp.Transactions, toTransaction(&page.Transactions[i]))
+	}
+	return resp, nil
+}
+
+// WatchTransactions streams the status changes of the transactions of an account, replaying retained
+// changes after last_event_id first
+func (s *Server) WatchTransactions(req *ledgerv1.WatchTransactionsRequest, stream ledgerv1.TransactionService_WatchTransactionsServer) error {
+	if req.GetAccountId() == 0 {
+		return toStatus(apperr.Invalid("account ID is required"))
+	}
+	if err := s.Streamer.Authorize(stream.Context(), types.AccountID(req.GetAccountId())); err != nil {
+		return toStatus(err)
+	}
+
+	err := s.Streamer.Watch(stream.Context(), types.AccountID(req.GetAccountId()), req.GetLastEventId(), func(event events.Event) error {
+		resp, err := toWatchResponse(event, req.GetIncludeBalanceChanges())
+		if err != nil || resp == nil {
+			return err
+		}
+		return stream.Send(resp)
+	})
+	if err != nil && stream.Context().Err() != nil {
+		return status.FromContextError(stream.Context().Err()).Err()
+	}
+	return err
+}
+
+// toWatchResponse converts a status or balance change event, balance changes are skipped unless requested
+func toWatchResponse(event events.Event, includeBalanceChanges bool) (*ledgerv1.WatchTransactionsResponse, error) {
+	switch event.Type {
+	case events.TypeTransactionStatusChanged:
+		var transaction models.Transaction
+		if err := json.Unmarshal(event.Data, &transaction); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to decode event %d", event.ID)
+		}
+		return &ledgerv1.WatchTransactionsResponse{
+			Event: &ledgerv1.WatchTransactionsResponse_StatusChange{StatusChange: &ledgerv1.TransactionStatusChange{
+				EventId:     event.ID,
+				Transaction: toTransaction(&transaction),
+				OccurredAt:  timestamppb.New(event.OccurredAt),
+			}},
+		}, nil
+	case events.TypeAccountBalanceChanged:
+		if !includeBalanceChanges {
+			return nil, nil
+		}
+		var change events.BalanceChange
+		if err := json.Unmarshal(event.Data, &change); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to decode event %d", event.ID)
+		}
+		return &ledgerv1.WatchTransactionsResponse{
+			Event: &ledgerv1.WatchTransactionsResponse_BalanceChange{BalanceChange: toBalanceChange(event, change)},
+		}, nil
+	default:
+		return nil, nil
+	}
+}
+
+func toTransaction(transaction *models.Transaction) *ledgerv1.Transaction {
+	return &ledgerv1.Transaction{
+		Id:              uint64(transaction.ID),
+		SourceAccountId: uint64(transaction.SourceAccountID),
+		DestAccountId:   uint64(transaction.DestAccountID),
+		Amount:          int64(transaction.Amount),
+		Currency:        transaction.Currency,
+		Status:          string(transaction.Status),
+		Description:     transaction.Description,
+		CreatedAt:       timestamppb.New(transaction.CreatedAt),
+		UpdatedAt:       timestamppb.New(transaction.UpdatedAt),
+	}
+}
+
+func toBalanceChange(event events.Event, change events.BalanceChange) *ledgerv1.BalanceChange {
+	balanceChange := &ledgerv1.BalanceChange{
+		EventId:       event.ID,
+		AccountId:     uint64(change.AccountID),
+		Amount:        int64(change.Amount),
+		Currency:      change.Currency,
+		TransactionId: uint64(change.TransactionID),
+		OccurredAt:    timestamppb.New(event.OccurredAt),
+	}
+	if change.Balance != nil {
+		balance := int64(*change.Balance)
+		balanceChange.Balance = &balance
+	}
+	return balanceChange
+}
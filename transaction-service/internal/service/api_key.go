@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/auth"
+	"github.com/danielkhtse/supreme-adventure/common/models"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"gorm.io/gorm"
+)
+
+const maxAPIKeyNameLength = 100
+
+// APIKeys returns the store verifying the API keys managed by the service
+func (s *TransactionService) APIKeys() *auth.APIKeyStore {
+	return s.apiKeys
+}
+
+// CreateAPIKey stores a new API key and returns the key, which is only known until this call returns
+func (s *TransactionService) CreateAPIKey(ctx context.Context, apiKey *models.APIKey) (string, error) {
+	if apiKey == nil {
+		return "", apperr.Invalid("API key cannot be nil")
+	}
+
+	apiKey.Name = strings.TrimSpace(apiKey.Name)
+	if apiKey.Name == "" || len(apiKey.Name) > maxAPIKeyNameLength {
+		return "", apperr.Invalid("name is required and must be at most %d characters", maxAPIKeyNameLength)
+	}
+
+	if len(apiKey.Scopes) == 0 {
+		return "", apperr.Invalid("at least one scope is required")
+	}
+	for _, scope := range apiKey.Scopes {
+		if !slices.Contains(auth.Scopes, scope) {
+			return "", apperr.Invalid("unsupported scope %s", scope)
+		}
+	}
+	apiKey.Scopes = slices.Compact(slices.Sorted(slices.Values(apiKey.Scopes)))
+	if err := checkGrantedScopes(ctx, apiKey.Scopes); err != nil {
+		return "", err
+	}
+
+	allowedIPs, err := auth.ParseAllowedIPs(apiKey.AllowedIPs)
+	if err != nil {
+		return "", apperr.Invalid("%s", err.Error())
+	}
+	apiKey.AllowedIPs = allowedIPs
+
+	if apiKey.ExpiresAt != nil && !apiKey.ExpiresAt.After(time.Now()) {
+		return "", apperr.Invalid("expires_at must be in the future")
+	}
+
+	id, err := s.idGenerator.NextID()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate API key ID: %w", err)
+	}
+	apiKey.ID = types.APIKeyID(id)
+
+	key, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	apiKey.Prefix = prefix
+	apiKey.Hash = hash
+	if identity, ok := auth.FromContext(ctx); ok {
+		apiKey.CreatedBy = identity.Subject
+	}
+
+	if err := s.db.Create(apiKey).Error; err != nil {
+		return "", fmt.Errorf("failed to create API key: %w", err)
+	}
+
+	auth.Audit(ctx).WithField("api_key_id", apiKey.ID).WithField("prefix", apiKey.Prefix).
+		WithField("scopes", apiKey.Scopes).Info("API key created")
+	return key, nil
+}
+
+// ListAPIKeys returns all API keys, revoked keys included
+func (s *TransactionService) ListAPIKeys() ([]models.APIKey, error) {
+	var apiKeys []models.APIKey
+	if err := s.db.Order("id").Find(&apiKeys).Error; err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	return apiKeys, nil
+}
+
+// GetAPIKey retrieves an API key by ID
+func (s *TransactionService) GetAPIKey(id types.APIKeyID) (*models.APIKey, error) {
+	var apiKey models.APIKey
+	if err := s.db.First(&apiKey, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperr.ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+	return &apiKey, nil
+}
+
+// RotateAPIKey replaces the key of an API key, the previous key stops working at once. The scopes, allowlist and
+// expiry are kept, so is the subject callers act as.
+func (s *TransactionService) RotateAPIKey(ctx context.Context, id types.APIKeyID) (*models.APIKey, string, error) {
+	apiKey, err := s.GetAPIKey(id)
+	if err != nil {
+		return nil, "", err
+	}
+	if apiKey.RevokedAt != nil {
+		return nil, "", apperr.ErrAPIKeyRevoked
+	}
+	//a caller could otherwise obtain scopes it was not granted by rotating the key of another integrator
+	if err := checkGrantedScopes(ctx, apiKey.Scopes); err != nil {
+		return nil, "", err
+	}
+
+	key, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	previousPrefix := apiKey.Prefix
+	now := time.Now().UTC()
+	apiKey.Prefix = prefix
+	apiKey.Hash = hash
+	apiKey.RotatedAt = &now
+
+	result := s.db.Model(apiKey).Where("revoked_at IS NULL").
+		Updates(map[string]any{"prefix": prefix, "hash": hash, "rotated_at": now})
+	if result.Error != nil {
+		return nil, "", fmt.Errorf("failed to rotate API key: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, "", apperr.ErrAPIKeyRevoked
+	}
+
+	auth.Audit(ctx).WithField("api_key_id", id).WithField("prefix", prefix).
+		WithField("previous_prefix", previousPrefix).Info("API key rotated")
+	return apiKey, key, nil
+}
+
+// RevokeAPIKey stops an API key from authenticating, the key is kept for auditing. Revoking a revoked key
+// succeeds without changing it.
+func (s *TransactionService) RevokeAPIKey(ctx context.Context, id types.APIKeyID) error {
+	if _, err := s.GetAPIKey(id); err != nil {
+		return err
+	}
+
+	result := s.db.Model(&models.APIKey{}).Where("id = ? AND revoked_at IS NULL", id).
+		UpdateColumn("revoked_at", time.Now().UTC())
+	if result.Error != nil {
+		return fmt.Errorf("failed to revoke API key: %w", result.Error)
+	}
+
+	if result.RowsAffected > 0 {
+		auth.Audit(ctx).WithField("api_key_id", id).Info("API key revoked")
+	}
+	return nil
+}
+
+// checkGrantedScopes rejects handing out scopes the caller was not granted itself, callers served without
+// authentication may hand out any scope
+func checkGrantedScopes(ctx context.Context, scopes []string) error {
+	identity, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	for _, scope := range scopes {
+		if !identity.HasScope(scope) {
+			return apperr.ErrInsufficientScope.WithMessage("the %s scope cannot be granted without holding it", scope)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/auth"
+	"github.com/danielkhtse/supreme-adventure/common/idgen"
+	"github.com/danielkhtse/supreme-adventure/common/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var apiKeyColumns = []string{"id", "name", "prefix", "hash", "scopes", "allowed_ips", "revoked_at"}
+
+func TestUnitCreateAPIKey(t *testing.T) {
+	mockDB, mock, db := setupMockDB(t)
+	defer mockDB.Close()
+
+	idGenerator, err := idgen.NewSnowflake(1)
+	require.NoError(t, err)
+	service := &TransactionService{db: db, idGenerator: idGenerator}
+
+	admin := auth.NewContext(context.Background(), &auth.Identity{
+		Subject: "admin",
+		Scheme:  auth.SchemeJWT,
+		Scopes:  []string{auth.ScopeAPIKeysWrite, auth.ScopeTransactionsWrite, auth.ScopeTransactionsRead},
+	})
+
+	t.Run("Created", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO "api_keys"`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectCommit()
+
+		expiresAt := time.Now().Add(24 * time.Hour)
+		apiKey := &models.APIKey{
+			Name:       " Acme payouts ",
+			Scopes:     []string{auth.ScopeTransactionsWrite, auth.ScopeTransactionsRead, auth.ScopeTransactionsWrite},
+			AllowedIPs: []string{"203.0.113.7", "198.51.100.9/24"},
+			ExpiresAt:  &expiresAt,
+		}
+		key, err := service.CreateAPIKey(admin, apiKey)
+		require.NoError(t, err)
+
+		assert.NotZero(t, apiKey.ID)
+		assert.Equal(t, "Acme payouts", apiKey.Name)
+		assert.Equal(t, []string{auth.ScopeTransactionsRead, auth.ScopeTransactionsWrite}, apiKey.Scopes)
+		assert.Equal(t, []string{"203.0.113.7", "198.51.100.0/24"}, apiKey.AllowedIPs)
+		assert.Equal(t, "admin", apiKey.CreatedBy)
+		assert.Equal(t, apiKey.Prefix, key[:len(apiKey.Prefix)])
+		assert.Equal(t, auth.HashAPIKey(key), apiKey.Hash)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		past := time.Now().Add(-time.Minute)
+		tests := []struct {
+			name   string
+			apiKey *models.APIKey
+		}{
+			{"Nil", nil},
+			{"Missing name", &models.APIKey{Scopes: []string{auth.ScopeTransactionsWrite}}},
+			{"Missing scopes", &models.APIKey{Name: "Acme"}},
+			{"Unknown scope", &models.APIKey{Name: "Acme", Scopes: []string{"admin"}}},
+			{"Invalid allowlist", &models.APIKey{Name: "Acme", Scopes: []string{auth.ScopeTransactionsWrite}, AllowedIPs: []string{"acme.example.com"}}},
+			{"Expired", &models.APIKey{Name: "Acme", Scopes: []string{auth.ScopeTransactionsWrite}, ExpiresAt: &past}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := service.CreateAPIKey(admin, tt.apiKey)
+				assert.ErrorIs(t, err, apperr.ErrInvalidArgument)
+			})
+		}
+	})
+
+	t.Run("Scope not held by caller", func(t *testing.T) {
+		_, err := service.CreateAPIKey(admin, &models.APIKey{Name: "Acme", Scopes: []string{auth.ScopeAccountsWrite}})
+		assert.ErrorIs(t, err, apperr.ErrInsufficientScope)
+		assert.EqualError(t, err, "the accounts:write scope cannot be granted without holding it")
+	})
+}
+
+func TestUnitRotateAPIKey(t *testing.T) {
+	mockDB, mock, db := setupMockDB(t)
+	defer mockDB.Close()
+
+	service := &TransactionService{db: db}
+	ctx := auth.NewContext(context.Background(), &auth.Identity{
+		Subject: "admin",
+		Scopes:  []string{auth.ScopeAPIKeysWrite, auth.ScopeTransactionsWrite},
+	})
+
+	t.Run("Rotated", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT \* FROM "api_keys" WHERE id = \$1`).
+			WithArgs(7, 1).
+			WillReturnRows(sqlmock.NewRows(apiKeyColumns).AddRow(7, "Acme", "ldg_000000000000", "old", `["transactions:write"]`, "[]", nil))
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "api_keys" SET "hash"=\$1,"prefix"=\$2,"rotated_at"=\$3,"updated_at"=\$4 WHERE revoked_at IS NULL AND "id" = \$5`).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		apiKey, key, err := service.RotateAPIKey(ctx, 7)
+		require.NoError(t, err)
+		assert.NotEqual(t, "ldg_000000000000", apiKey.Prefix)
+		assert.Equal(t, apiKey.Prefix, key[:len(apiKey.Prefix)])
+		assert.Equal(t, auth.HashAPIKey(key), apiKey.Hash)
+		assert.NotNil(t, apiKey.RotatedAt)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Revoked", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT \* FROM "api_keys" WHERE id = \$1`).
+			WillReturnRows(sqlmock.NewRows(apiKeyColumns).AddRow(7, "Acme", "ldg_000000000000", "old", `["transactions:write"]`, "[]", time.Now()))
+
+		_, _, err := service.RotateAPIKey(ctx, 7)
+		assert.ErrorIs(t, err, apperr.ErrAPIKeyRevoked)
+	})
+
+	t.Run("Scope not held by caller", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT \* FROM "api_keys" WHERE id = \$1`).
+			WillReturnRows(sqlmock.NewRows(apiKeyColumns).AddRow(7, "Acme", "ldg_000000000000", "old", `["accounts:write"]`, "[]", nil))
+
+		_, _, err := service.RotateAPIKey(ctx, 7)
+		assert.ErrorIs(t, err, apperr.ErrInsufficientScope)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT \* FROM "api_keys" WHERE id = \$1`).WillReturnRows(sqlmock.NewRows(apiKeyColumns))
+
+		_, _, err := service.RotateAPIKey(ctx, 7)
+		assert.ErrorIs(t, err, apperr.ErrAPIKeyNotFound)
+	})
+}
+
+func TestUnitRevokeAPIKey(t *testing.T) {
+	mockDB, mock, db := setupMockDB(t)
+	defer mockDB.Close()
+
+	service := &TransactionService{db: db}
+
+	t.Run("Revoked", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT \* FROM "api_keys" WHERE id = \$1`).
+			WillReturnRows(sqlmock.NewRows(apiKeyColumns).AddRow(7, "Acme", "ldg_000000000000", "hash", `["transactions:write"]`, "[]", nil))
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "api_keys" SET "revoked_at"=\$1 WHERE id = \$2 AND revoked_at IS NULL`).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, service.RevokeAPIKey(context.Background(), 7))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Already revoked", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT \* FROM "api_keys" WHERE id = \$1`).
+			WillReturnRows(sqlmock.NewRows(apiKeyColumns).AddRow(7, "Acme", "ldg_000000000000", "hash", `["transactions:write"]`, "[]", time.Now()))
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "api_keys" SET "revoked_at"=\$1`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		assert.NoError(t, service.RevokeAPIKey(context.Background(), 7))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT \* FROM "api_keys" WHERE id = \$1`).WillReturnRows(sqlmock.NewRows(apiKeyColumns))
+
+		assert.ErrorIs(t, service.RevokeAPIKey(context.Background(), 7), apperr.ErrAPIKeyNotFound)
+	})
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/danielkhtse/supreme-adventure/common/events"
+	"github.com/danielkhtse/supreme-adventure/common/feed"
+	"github.com/danielkhtse/supreme-adventure/common/models"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"gorm.io/gorm"
+)
+
+// Events returns the broker receiving the transaction events published by this service
+func (s *TransactionService) Events() *events.Broker {
+	return s.broker
+}
+
+// ListEvents returns the change feed after the cursor, only the events affecting the account unless accountID is
+// zero, waiting up to wait for new events when none are available
+func (s *TransactionService) ListEvents(ctx context.Context, after string, accountID types.AccountID, limit int, wait time.Duration) (*feed.Page, error) {
+	return s.feed.List(ctx, after, accountID, limit, wait)
+}
+
+// EventCursor returns the change feed cursor after the event with the given ID, retained is false when the event
+// is no longer in the feed
+func (s *TransactionService) EventCursor(ctx context.Context, eventID uint64) (cursor string, retained bool, err error) {
+	return s.feed.EventCursor(ctx, eventID)
+}
+
+// StartEventRetention prunes events older than the configured retention until ctx is done
+func (s *TransactionService) StartEventRetention(ctx context.Context) {
+	s.feed.StartRetention(ctx)
+}
+
+// saveTransaction saves the transaction and records its status change atomically, then publishes the events
+func (s *TransactionService) saveTransaction(transaction *models.Transaction, create bool) error {
+	var recorded []events.Event
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if create {
+			if err := tx.Create(transaction).Error; err != nil {
+				return err
+			}
+		} else if err := tx.Save(transaction).Error; err != nil {
+			return err
+		}
+
+		var err error
+		recorded, err = s.recordTransactionEvents(tx, transaction)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.publishEvents(recorded)
+	return nil
+}
+
+// recordTransactionEvents stores the status change of a transaction and, once completed, the resulting balance changes within tx
+func (s *TransactionService) recordTransactionEvents(tx *gorm.DB, transaction *models.Transaction) ([]events.Event, error) {
+	if s.feed == nil {
+		return nil, nil
+	}
+
+	statusChanged, err := s.newEvent(events.TypeTransactionStatusChanged, transaction, transaction.SourceAccountID, transaction.DestAccountID)
+	if err != nil {
+		return nil, err
+	}
+	recorded := []events.Event{statusChanged}
+
+	if transaction.Status == types.TransactionStatusCompleted {
+		sourceChanged, err := s.newEvent(events.TypeAccountBalanceChanged, events.BalanceChange{
+			AccountID:     transaction.SourceAccountID,
+			Amount:        -transaction.Amount,
+			Currency:      transaction.Currency,
+			TransactionID: transaction.ID,
+		}, transaction.SourceAccountID)
+		if err != nil {
+			return nil, err
+		}
+
+		destChanged, err := s.newEvent(events.TypeAccountBalanceChanged, events.BalanceChange{
+			AccountID:     transaction.DestAccountID,
+			Amount:        transaction.Amount,
+			Currency:      transaction.Currency,
+			TransactionID: transaction.ID,
+		}, transaction.DestAccountID)
+		if err != nil {
+			return nil, err
+		}
+
+		recorded = append(recorded, sourceChanged, destChanged)
+	}
+
+	return recorded, s.feed.Append(tx, recorded...)
+}
+
+// publishEvents notifies stream subscribers of events whose transaction has committed
+func (s *TransactionService) publishEvents(recorded []events.Event) {
+	if s.feed == nil {
+		return
+	}
+	s.feed.Publish(recorded...)
+}
+
+func (s *TransactionService) newEvent(eventType events.Type, data any, accountIDs ...types.AccountID) (events.Event, error) {
+	id, err := s.idGenerator.NextID()
+	if err != nil {
+		return events.Event{}, err
+	}
+	return events.New(id, eventType, data, accountIDs...)
+}
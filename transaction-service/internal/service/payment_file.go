@@ -0,0 +1,397 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/auth"
+	"github.com/danielkhtse/supreme-adventure/common/authz"
+	"github.com/danielkhtse/supreme-adventure/common/iso20022"
+	"github.com/danielkhtse/supreme-adventure/common/models"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MaxPaymentFileTransactions is the number of credit transfers a pain.001 file may hold, transfers are executed
+// while the upload request waits for the report
+const MaxPaymentFileTransactions = 1000
+
+const (
+	// a processing file without progress for this long was abandoned by a stopped replica, every transfer
+	// records its outcome well within it
+	paymentFileStaleAfter = 5 * time.Minute
+
+	paymentFileRecoveryInterval = time.Minute
+)
+
+// ImportPaymentFile executes the credit transfers of a pain.001 payment initiation and returns its encoded pain.002
+// status report. Each valid transfer becomes a transaction, the others are rejected with an ISO 20022 reason code.
+// A file whose message ID was already uploaded is rejected as a whole without executing any transfer. The outcome
+// of each transfer is recorded as it executes, a file abandoned half way is completed by StartPaymentFileRecovery.
+func (s *TransactionService) ImportPaymentFile(ctx context.Context, data []byte) ([]byte, error) {
+	initiation, err := iso20022.ParsePaymentInitiation(data)
+	if err != nil {
+		return nil, apperr.Invalid("invalid pain.001 file: %v", err)
+	}
+
+	transfers := initiation.Transfers()
+	if len(transfers) > MaxPaymentFileTransactions {
+		return nil, apperr.Invalid("pain.001 file holds %d credit transfers, at most %d are allowed", len(transfers), MaxPaymentFileTransactions)
+	}
+
+	id, err := s.idGenerator.NextID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate payment file ID: %w", err)
+	}
+	file := &models.PaymentFile{
+		ID:        types.PaymentFileID(id),
+		Principal: paymentFilePrincipal(ctx),
+		MessageID: initiation.MessageID,
+		Status:    types.PaymentFileStatusProcessing,
+		Document:  data,
+	}
+
+	//the message ID unique to the principal claims the file, a second upload must not execute the transfers again
+	result := s.db.WithContext(ctx).Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "principal"}, {Name: "message_id"}}, DoNothing: true}).Create(file)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to create payment file: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		initiation.Rejection = &iso20022.StatusReason{
+			Code:                  iso20022.ReasonDuplicateMessageID,
+			AdditionalInformation: fmt.Sprintf("message %s was already uploaded", initiation.MessageID),
+		}
+		return encodeStatusReport(file, initiation)
+	}
+
+	auth.Audit(ctx).WithField("payment_file_id", file.ID).WithField("message_id", file.MessageID).Info("payment file accepted")
+
+	//a client going away must not abandon the file half way
+	ctx = context.WithoutCancel(ctx)
+
+	if initiation.Rejection == nil {
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		for _, transfer := range transfers {
+			if transfer.Status != "" {
+				continue
+			}
+			if err := s.settleCreditTransfer(ctx, file, transfer, today); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return s.completePaymentFile(ctx, file, initiation)
+}
+
+// completePaymentFile stores the status report of a file whose transfers are settled and marks it processed
+func (s *TransactionService) completePaymentFile(ctx context.Context, file *models.PaymentFile, initiation *iso20022.PaymentInitiation) ([]byte, error) {
+	report, err := encodeStatusReport(file, initiation)
+	if err != nil {
+		return nil, err
+	}
+
+	//the transfers are executed, the report is returned even when it cannot be kept
+	if err := s.db.WithContext(ctx).Model(file).Updates(map[string]any{
+		"status": types.PaymentFileStatusProcessed,
+		"report": report,
+	}).Error; err != nil {
+		log.Printf("Failed to store the status report of payment file %d: %v", file.ID, err)
+	}
+
+	return report, nil
+}
+
+// GetPaymentFileReport returns the encoded pain.002 status report of the pain.001 file the caller uploaded with the
+// message ID. Files uploaded by other principals are not found, their message IDs may collide with the caller's.
+func (s *TransactionService) GetPaymentFileReport(ctx context.Context, messageID string) ([]byte, error) {
+	var file models.PaymentFile
+	if err := s.db.WithContext(ctx).Where("principal = ? AND message_id = ?", paymentFilePrincipal(ctx), messageID).First(&file).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperr.ErrPaymentFileNotFound
+		}
+		return nil, fmt.Errorf("failed to get payment file: %w", err)
+	}
+
+	if file.Status != types.PaymentFileStatusProcessed {
+		return nil, apperr.ErrPaymentFileRunning.WithMessage("payment file %s is still being processed", messageID)
+	}
+	if err := s.authorizePaymentFileReport(ctx, &file); err != nil {
+		return nil, err
+	}
+	return file.Report, nil
+}
+
+// authorizePaymentFileReport requires the caller to be permitted to read each transaction the report refers to,
+// by viewing either of its accounts like GetTransaction. Roles revoked since the upload apply to the report too.
+func (s *TransactionService) authorizePaymentFileReport(ctx context.Context, file *models.PaymentFile) error {
+	if _, ok := auth.FromContext(ctx); s.policy == nil || !ok {
+		return nil
+	}
+
+	initiation, err := iso20022.ParsePaymentInitiation(file.Document)
+	if err != nil {
+		return fmt.Errorf("failed to parse the stored pain.001 file: %w", err)
+	}
+
+	//transfers rejected before a transaction was created only echo the uploaded file
+	var records []models.PaymentFileTransfer
+	if err := s.db.WithContext(ctx).Where("payment_file_id = ? AND transaction_id <> 0", file.ID).Find(&records).Error; err != nil {
+		return fmt.Errorf("failed to load payment file transfers: %w", err)
+	}
+	executed := make(map[string]bool, len(records))
+	for _, record := range records {
+		executed[record.EndToEndID] = true
+	}
+
+	for _, transfer := range initiation.Transfers() {
+		if !executed[transfer.EndToEndID] {
+			continue
+		}
+		if err := authz.CheckAny(ctx, s.policy, authz.ActionView, transfer.DebtorAccountID, transfer.CreditorAccountID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// settleCreditTransfer records the transfer with a reserved transaction ID, executes it and records its outcome.
+// Recording the outcome also marks the progress of the file, keeping it from being taken for abandoned.
+func (s *TransactionService) settleCreditTransfer(ctx context.Context, file *models.PaymentFile, transfer *iso20022.CreditTransfer, today time.Time) error {
+	id, err := s.idGenerator.NextID()
+	if err != nil {
+		return fmt.Errorf("failed to generate transaction ID: %w", err)
+	}
+	record := &models.PaymentFileTransfer{
+		PaymentFileID: file.ID,
+		EndToEndID:    transfer.EndToEndID,
+		TransactionID: types.TransactionID(id),
+	}
+	if err := s.db.WithContext(ctx).Create(record).Error; err != nil {
+		return fmt.Errorf("failed to record credit transfer %s: %w", transfer.EndToEndID, err)
+	}
+
+	s.executeCreditTransfer(ctx, transfer, record.TransactionID, today)
+
+	record.Status = transfer.Status
+	record.TransactionID = transfer.TransactionID
+	if transfer.Reason != nil {
+		record.ReasonCode = transfer.Reason.Code
+		record.ReasonInformation = transfer.Reason.AdditionalInformation
+	}
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(record).Error; err != nil {
+			return fmt.Errorf("failed to record the outcome of credit transfer %s: %w", transfer.EndToEndID, err)
+		}
+		return tx.Model(file).Update("updated_at", time.Now().UTC()).Error
+	})
+}
+
+// executeCreditTransfer creates the transaction of a credit transfer under the reserved ID and sets the transfer
+// status from its outcome
+func (s *TransactionService) executeCreditTransfer(ctx context.Context, transfer *iso20022.CreditTransfer, transactionID types.TransactionID, today time.Time) {
+	//transfers are executed immediately, scheduling is left to the client
+	if transfer.RequestedExecutionDate.After(today) {
+		transfer.Reject(iso20022.ReasonInvalidDate, "requested execution date %s is in the future", transfer.RequestedExecutionDate.Format(time.DateOnly))
+		return
+	}
+
+	if err := authz.Check(ctx, s.policy, authz.ActionDebit, transfer.DebtorAccountID); err != nil {
+		if errors.Is(err, apperr.ErrAccountAccessDenied) {
+			transfer.Reject(iso20022.ReasonTransactionForbidden, "%v", err)
+			return
+		}
+		log.Printf("Failed to authorize credit transfer %s: %v", transfer.EndToEndID, err)
+		transfer.Reject(iso20022.ReasonNarrative, "transfer could not be executed, it can be submitted again in a new file")
+		return
+	}
+
+	description := transfer.RemittanceInformation
+	if description == "" {
+		description = transfer.EndToEndID
+	}
+	transaction := &models.Transaction{
+		ID:              transactionID,
+		SourceAccountID: transfer.DebtorAccountID,
+		DestAccountID:   transfer.CreditorAccountID,
+		Amount:          types.AccountBalance(transfer.Amount),
+		Currency:        transfer.Currency,
+		Description:     description,
+	}
+
+	err := s.CreateTransaction(ctx, transaction)
+	switch {
+	case transaction.Status == types.TransactionStatusCompleted:
+		//the funds moved, a failure to record the completion does not undo the transfer
+		if err != nil {
+			log.Printf("Credit transfer %s completed as transaction %d: %v", transfer.EndToEndID, transaction.ID, err)
+		}
+		transfer.Status = iso20022.StatusAcceptedSettlementCompleted
+		transfer.TransactionID = transaction.ID
+		return
+	case errors.Is(err, apperr.ErrAccountNotFound):
+		transfer.Reject(iso20022.ReasonIncorrectAccountNumber, "%v", err)
+	case errors.Is(err, apperr.ErrAccountFrozen):
+		transfer.Reject(iso20022.ReasonBlockedAccount, "%v", err)
+	case errors.Is(err, apperr.ErrInsufficientFunds):
+		transfer.Reject(iso20022.ReasonInsufficientFunds, "%v", err)
+	case errors.Is(err, apperr.ErrCurrencyMismatch):
+		transfer.Reject(iso20022.ReasonNotAllowedCurrency, "%v", err)
+	case errors.Is(err, apperr.ErrSameAccount):
+		transfer.Reject(iso20022.ReasonTransactionForbidden, "%v", err)
+	default:
+		log.Printf("Failed to execute credit transfer %s: %v", transfer.EndToEndID, err)
+		transfer.Reject(iso20022.ReasonNarrative, "transfer could not be executed, it can be submitted again in a new file")
+	}
+
+	//a failed transaction is kept, the report refers to it
+	if transaction.Status == types.TransactionStatusFailed {
+		transfer.TransactionID = transaction.ID
+	}
+}
+
+// StartPaymentFileRecovery completes the payment files abandoned while processing every minute until ctx is done
+func (s *TransactionService) StartPaymentFileRecovery(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(paymentFileRecoveryInterval)
+		defer ticker.Stop()
+		for {
+			if err := s.recoverPaymentFiles(ctx); err != nil {
+				log.Printf("Failed to recover payment files: %v", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// recoverPaymentFiles completes the processing files without progress for paymentFileStaleAfter
+func (s *TransactionService) recoverPaymentFiles(ctx context.Context) error {
+	var files []models.PaymentFile
+	if err := s.db.WithContext(ctx).
+		Where("status = ? AND updated_at < ?", types.PaymentFileStatusProcessing, time.Now().UTC().Add(-paymentFileStaleAfter)).
+		Order("id").
+		Find(&files).Error; err != nil {
+		return fmt.Errorf("failed to list abandoned payment files: %w", err)
+	}
+
+	for i := range files {
+		if err := s.recoverPaymentFile(ctx, &files[i]); err != nil {
+			log.Printf("Failed to recover payment file %d: %v", files[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// recoverPaymentFile reports an abandoned file from the recorded transfers. Transfers are not executed again,
+// the caller's credentials are gone: a transfer whose transaction completed is accepted, one whose transaction is
+// still pending is reported pending, and the others are rejected so they can be submitted again in a new file.
+func (s *TransactionService) recoverPaymentFile(ctx context.Context, file *models.PaymentFile) error {
+	//claiming the file by its last progress lets a single replica recover it
+	claim := s.db.WithContext(ctx).Model(&models.PaymentFile{}).
+		Where("id = ? AND status = ? AND updated_at = ?", file.ID, types.PaymentFileStatusProcessing, file.UpdatedAt).
+		Update("updated_at", time.Now().UTC())
+	if claim.Error != nil {
+		return fmt.Errorf("failed to claim payment file: %w", claim.Error)
+	}
+	if claim.RowsAffected == 0 {
+		return nil
+	}
+
+	initiation, err := iso20022.ParsePaymentInitiation(file.Document)
+	if err != nil {
+		return fmt.Errorf("failed to parse the stored pain.001 file: %w", err)
+	}
+
+	var records []models.PaymentFileTransfer
+	if err := s.db.WithContext(ctx).Where("payment_file_id = ?", file.ID).Find(&records).Error; err != nil {
+		return fmt.Errorf("failed to load payment file transfers: %w", err)
+	}
+	recorded := make(map[string]*models.PaymentFileTransfer, len(records))
+	for i := range records {
+		recorded[records[i].EndToEndID] = &records[i]
+	}
+
+	if initiation.Rejection == nil {
+		for _, transfer := range initiation.Transfers() {
+			if transfer.Status != "" {
+				continue
+			}
+			if err := s.recoverCreditTransfer(ctx, transfer, recorded[transfer.EndToEndID]); err != nil {
+				return err
+			}
+		}
+	}
+
+	if _, err := s.completePaymentFile(ctx, file, initiation); err != nil {
+		return err
+	}
+	log.Printf("Completed abandoned payment file %d from its recorded transfers", file.ID)
+	return nil
+}
+
+// recoverCreditTransfer sets the status of a transfer of an abandoned file from its record and its transaction
+func (s *TransactionService) recoverCreditTransfer(ctx context.Context, transfer *iso20022.CreditTransfer, record *models.PaymentFileTransfer) error {
+	switch {
+	case record == nil:
+		transfer.Reject(iso20022.ReasonNarrative, "transfer was not executed before processing stopped, it can be submitted again in a new file")
+		return nil
+	case record.Status != "":
+		transfer.Status = record.Status
+		transfer.TransactionID = record.TransactionID
+		if record.ReasonCode != "" {
+			transfer.Reason = &iso20022.StatusReason{Code: record.ReasonCode, AdditionalInformation: record.ReasonInformation}
+		}
+		return nil
+	}
+
+	//processing stopped while the transfer executed, its transaction tells how far it got
+	var transaction models.Transaction
+	err := s.db.WithContext(ctx).Where("id = ?", record.TransactionID).Limit(1).Find(&transaction).Error
+	if err != nil {
+		return fmt.Errorf("failed to get the transaction of credit transfer %s: %w", transfer.EndToEndID, err)
+	}
+	switch transaction.Status {
+	case types.TransactionStatusCompleted:
+		transfer.Status = iso20022.StatusAcceptedSettlementCompleted
+		transfer.TransactionID = transaction.ID
+	case types.TransactionStatusPending:
+		//the funds may or may not have moved, the transaction is reconciled with account-service separately
+		transfer.Status = iso20022.StatusPending
+		transfer.TransactionID = transaction.ID
+	case types.TransactionStatusFailed:
+		transfer.Reject(iso20022.ReasonNarrative, "transfer could not be executed, it can be submitted again in a new file")
+		transfer.TransactionID = transaction.ID
+	default:
+		transfer.Reject(iso20022.ReasonNarrative, "transfer was not executed before processing stopped, it can be submitted again in a new file")
+	}
+	return nil
+}
+
+// paymentFilePrincipal is the principal uploading or reading payment files, empty for requests served without
+// authentication
+func paymentFilePrincipal(ctx context.Context) string {
+	if identity, ok := auth.FromContext(ctx); ok {
+		return identity.Subject
+	}
+	return ""
+}
+
+func encodeStatusReport(file *models.PaymentFile, initiation *iso20022.PaymentInitiation) ([]byte, error) {
+	document := iso20022.NewStatusReport(fmt.Sprintf("PAIN002-%d", file.ID), time.Now(), initiation)
+
+	var buf bytes.Buffer
+	if err := iso20022.Encode(&buf, document); err != nil {
+		return nil, fmt.Errorf("failed to encode status report: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,432 @@
+package service
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/xml"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/auth"
+	"github.com/danielkhtse/supreme-adventure/common/idgen"
+	"github.com/danielkhtse/supreme-adventure/common/iso20022"
+	"github.com/danielkhtse/supreme-adventure/common/models"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPaymentFile pays 1.00 to account 2, 5.00 more than the balance of account 1, 1.00 to the unknown account 3
+// and 1.00 to account 2 on a future date
+const testPaymentFile = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.10">
+  <CstmrCdtTrfInitn>
+    <GrpHdr>
+      <MsgId>ERP-1</MsgId>
+      <CreDtTm>2026-03-01T08:00:00</CreDtTm>
+      <NbOfTxs>4</NbOfTxs>
+      <CtrlSum>8.00</CtrlSum>
+    </GrpHdr>
+    <PmtInf>
+      <PmtInfId>BATCH-1</PmtInfId>
+      <PmtMtd>TRF</PmtMtd>
+      <ReqdExctnDt><Dt>2026-03-01</Dt></ReqdExctnDt>
+      <DbtrAcct><Id><Othr><Id>1</Id></Othr></Id></DbtrAcct>
+      <CdtTrfTxInf>
+        <PmtId><EndToEndId>INV-1</EndToEndId></PmtId>
+        <Amt><InstdAmt Ccy="USD">1.00</InstdAmt></Amt>
+        <CdtrAcct><Id><Othr><Id>2</Id></Othr></Id></CdtrAcct>
+        <RmtInf><Ustrd>Invoice 1</Ustrd></RmtInf>
+      </CdtTrfTxInf>
+      <CdtTrfTxInf>
+        <PmtId><EndToEndId>INV-2</EndToEndId></PmtId>
+        <Amt><InstdAmt Ccy="USD">5.00</InstdAmt></Amt>
+        <CdtrAcct><Id><Othr><Id>2</Id></Othr></Id></CdtrAcct>
+      </CdtTrfTxInf>
+      <CdtTrfTxInf>
+        <PmtId><EndToEndId>INV-3</EndToEndId></PmtId>
+        <Amt><InstdAmt Ccy="USD">1.00</InstdAmt></Amt>
+        <CdtrAcct><Id><Othr><Id>3</Id></Othr></Id></CdtrAcct>
+      </CdtTrfTxInf>
+    </PmtInf>
+    <PmtInf>
+      <PmtInfId>BATCH-2</PmtInfId>
+      <PmtMtd>TRF</PmtMtd>
+      <ReqdExctnDt><Dt>2999-01-01</Dt></ReqdExctnDt>
+      <DbtrAcct><Id><Othr><Id>1</Id></Othr></Id></DbtrAcct>
+      <CdtTrfTxInf>
+        <PmtId><EndToEndId>INV-4</EndToEndId></PmtId>
+        <Amt><InstdAmt Ccy="USD">1.00</InstdAmt></Amt>
+        <CdtrAcct><Id><Othr><Id>2</Id></Othr></Id></CdtrAcct>
+      </CdtTrfTxInf>
+    </PmtInf>
+  </CstmrCdtTrfInitn>
+</Document>`
+
+func decodeStatusReport(t *testing.T, data []byte) iso20022.CustomerPaymentStatusReportV11 {
+	var document iso20022.StatusReportDocument
+	require.NoError(t, xml.Unmarshal(data, &document))
+	return document.CstmrPmtStsRpt
+}
+
+// expectCreditTransferRecorded expects the record of a credit transfer, written before it executes
+func expectCreditTransferRecorded(mock sqlmock.Sqlmock, endToEndID string) {
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO "payment_file_transfers"`).
+		WithArgs(sqlmock.AnyArg(), endToEndID, sqlmock.AnyArg(), "", "", "", sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+}
+
+// expectCreditTransferOutcome expects the outcome of a credit transfer and the progress of its file
+func expectCreditTransferOutcome(mock sqlmock.Sqlmock, endToEndID string, status string, reasonCode string) {
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "payment_file_transfers" SET "transaction_id"=\$1,"status"=\$2,"reason_code"=\$3,"reason_information"=\$4,"created_at"=\$5,"updated_at"=\$6 WHERE "payment_file_id" = \$7 AND "end_to_end_id" = \$8`).
+		WithArgs(sqlmock.AnyArg(), status, reasonCode, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), endToEndID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "payment_files" SET "updated_at"=\$1 WHERE "id" = \$2`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+}
+
+func TestUnitImportPaymentFile(t *testing.T) {
+	mockDB, mock, db := setupMockDB(t)
+	defer mockDB.Close()
+
+	idGenerator, err := idgen.NewSnowflake(1)
+	require.NoError(t, err)
+
+	accountClient := &fakeAccountClient{
+		accounts: map[types.AccountID]*models.Account{
+			1: {ID: 1, Balance: 200, Currency: "USD"},
+			2: {ID: 2, Balance: 50, Currency: "USD"},
+		},
+	}
+	mockService := &TransactionService{
+		db:            db,
+		accountClient: accountClient,
+		idGenerator:   idGenerator,
+	}
+
+	t.Run("Partially accepted", func(t *testing.T) {
+		ctx := auth.NewContext(context.Background(), &auth.Identity{Subject: "alice"})
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO "payment_files" .* ON CONFLICT \("principal","message_id"\) DO NOTHING`).
+			WithArgs("alice", "ERP-1", types.PaymentFileStatusProcessing, []byte(testPaymentFile), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectCommit()
+		expectCreditTransferRecorded(mock, "INV-1")
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO "transactions"`).
+			WithArgs(1, 2, 100, "USD", types.TransactionStatusPending, "Invoice 1", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectCommit()
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "transactions" SET`).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		expectCreditTransferOutcome(mock, "INV-1", iso20022.StatusAcceptedSettlementCompleted, "")
+		expectCreditTransferRecorded(mock, "INV-2")
+		expectCreditTransferOutcome(mock, "INV-2", iso20022.StatusRejected, iso20022.ReasonInsufficientFunds)
+		expectCreditTransferRecorded(mock, "INV-3")
+		expectCreditTransferOutcome(mock, "INV-3", iso20022.StatusRejected, iso20022.ReasonIncorrectAccountNumber)
+		expectCreditTransferRecorded(mock, "INV-4")
+		expectCreditTransferOutcome(mock, "INV-4", iso20022.StatusRejected, iso20022.ReasonInvalidDate)
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "payment_files" SET "report"=\$1,"status"=\$2,"updated_at"=\$3 WHERE "id" = \$4`).
+			WithArgs(sqlmock.AnyArg(), types.PaymentFileStatusProcessed, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		data, err := mockService.ImportPaymentFile(ctx, []byte(testPaymentFile))
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		require.Len(t, accountClient.transfers, 1)
+
+		report := decodeStatusReport(t, data)
+		assert.Equal(t, "ERP-1", report.OrgnlGrpInfAndSts.OrgnlMsgId)
+		assert.Equal(t, iso20022.StatusPartiallyAccepted, report.OrgnlGrpInfAndSts.GrpSts)
+
+		var statuses []string
+		for _, payment := range report.OrgnlPmtInfAndSts {
+			for _, transaction := range payment.TxInfAndSts {
+				status := transaction.TxSts
+				if len(transaction.StsRsnInf) > 0 {
+					status += " " + transaction.StsRsnInf[0].Rsn.Cd
+				}
+				statuses = append(statuses, status)
+			}
+		}
+		assert.Equal(t, []string{"ACSC", "RJCT AM04", "RJCT AC01", "RJCT DT01"}, statuses)
+		assert.Equal(t, strconv.FormatUint(uint64(accountClient.transfers[0]), 10), report.OrgnlPmtInfAndSts[0].TxInfAndSts[0].AcctSvcrRef)
+	})
+
+	t.Run("Debtor account not controlled by the caller", func(t *testing.T) {
+		accountClient.transfers = nil
+		mockService.policy = fakePolicy{2: true}
+		defer func() { mockService.policy = nil }()
+		ctx := auth.NewContext(context.Background(), &auth.Identity{Subject: "bob"})
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO "payment_files"`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+		mock.ExpectCommit()
+		for _, endToEndID := range []string{"INV-1", "INV-2", "INV-3"} {
+			expectCreditTransferRecorded(mock, endToEndID)
+			expectCreditTransferOutcome(mock, endToEndID, iso20022.StatusRejected, iso20022.ReasonTransactionForbidden)
+		}
+		expectCreditTransferRecorded(mock, "INV-4")
+		expectCreditTransferOutcome(mock, "INV-4", iso20022.StatusRejected, iso20022.ReasonInvalidDate)
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "payment_files" SET`).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		data, err := mockService.ImportPaymentFile(ctx, []byte(testPaymentFile))
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		assert.Empty(t, accountClient.transfers)
+
+		report := decodeStatusReport(t, data)
+		assert.Equal(t, iso20022.StatusRejected, report.OrgnlGrpInfAndSts.GrpSts)
+		reason := report.OrgnlPmtInfAndSts[0].TxInfAndSts[0].StsRsnInf[0]
+		assert.Equal(t, iso20022.ReasonTransactionForbidden, reason.Rsn.Cd)
+	})
+
+	t.Run("Duplicate message ID", func(t *testing.T) {
+		accountClient.transfers = nil
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO "payment_files"`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectCommit()
+
+		data, err := mockService.ImportPaymentFile(context.Background(), []byte(testPaymentFile))
+		require.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		assert.Empty(t, accountClient.transfers)
+
+		report := decodeStatusReport(t, data)
+		assert.Equal(t, iso20022.StatusRejected, report.OrgnlGrpInfAndSts.GrpSts)
+		assert.Equal(t, iso20022.ReasonDuplicateMessageID, report.OrgnlGrpInfAndSts.StsRsnInf[0].Rsn.Cd)
+		assert.Empty(t, report.OrgnlPmtInfAndSts)
+	})
+
+	t.Run("Unreadable file", func(t *testing.T) {
+		_, err := mockService.ImportPaymentFile(context.Background(), []byte("<Document/>"))
+		assert.ErrorIs(t, err, apperr.ErrInvalidArgument)
+	})
+}
+
+// capturedBytes is a query argument matching any []byte and keeping it
+type capturedBytes struct {
+	value *[]byte
+}
+
+func (c capturedBytes) Match(v driver.Value) bool {
+	b, ok := v.([]byte)
+	*c.value = b
+	return ok
+}
+
+func TestUnitRecoverPaymentFiles(t *testing.T) {
+	fileColumns := []string{"id", "message_id", "status", "document", "updated_at"}
+	transferColumns := []string{"payment_file_id", "end_to_end_id", "transaction_id", "status", "reason_code", "reason_information"}
+	transactionColumns := []string{"id", "source_account_id", "dest_account_id", "amount", "currency", "status"}
+	abandonedAt := time.Now().UTC().Add(-time.Hour)
+
+	t.Run("Reports the abandoned file from its records without executing transfers", func(t *testing.T) {
+		mockDB, mock, db := setupMockDB(t)
+		defer mockDB.Close()
+
+		accountClient := &fakeAccountClient{}
+		mockService := &TransactionService{db: db, accountClient: accountClient}
+
+		mock.ExpectQuery(`SELECT \* FROM "payment_files" WHERE status = \$1 AND updated_at < \$2 ORDER BY id`).
+			WithArgs(types.PaymentFileStatusProcessing, sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows(fileColumns).AddRow(3, "ERP-1", "processing", []byte(testPaymentFile), abandonedAt))
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "payment_files" SET "updated_at"=\$1 WHERE id = \$2 AND status = \$3 AND updated_at = \$4`).
+			WithArgs(sqlmock.AnyArg(), 3, types.PaymentFileStatusProcessing, abandonedAt).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		// the service stopped after INV-1 settled and while INV-3 executed, INV-4 was never reached
+		mock.ExpectQuery(`SELECT \* FROM "payment_file_transfers" WHERE payment_file_id = \$1`).
+			WithArgs(3).
+			WillReturnRows(sqlmock.NewRows(transferColumns).
+				AddRow(3, "INV-1", 501, "", "", "").
+				AddRow(3, "INV-2", 0, "RJCT", "AM04", "insufficient balance in source account 1").
+				AddRow(3, "INV-3", 503, "", "", ""))
+		mock.ExpectQuery(`SELECT \* FROM "transactions" WHERE id = \$1`).
+			WithArgs(501, 1).
+			WillReturnRows(sqlmock.NewRows(transactionColumns).AddRow(501, 1, 2, 100, "USD", "completed"))
+		mock.ExpectQuery(`SELECT \* FROM "transactions" WHERE id = \$1`).
+			WithArgs(503, 1).
+			WillReturnRows(sqlmock.NewRows(transactionColumns).AddRow(503, 1, 3, 100, "USD", "pending"))
+		mock.ExpectBegin()
+		var data []byte
+		mock.ExpectExec(`UPDATE "payment_files" SET "report"=\$1,"status"=\$2,"updated_at"=\$3 WHERE "id" = \$4`).
+			WithArgs(capturedBytes{&data}, types.PaymentFileStatusProcessed, sqlmock.AnyArg(), 3).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		require.NoError(t, mockService.recoverPaymentFiles(context.Background()))
+		assert.NoError(t, mock.ExpectationsWereMet())
+		assert.Empty(t, accountClient.transfers)
+
+		report := decodeStatusReport(t, data)
+		var statuses []string
+		for _, payment := range report.OrgnlPmtInfAndSts {
+			for _, transaction := range payment.TxInfAndSts {
+				status := transaction.TxSts
+				if len(transaction.StsRsnInf) > 0 {
+					status += " " + transaction.StsRsnInf[0].Rsn.Cd
+				}
+				statuses = append(statuses, status)
+			}
+		}
+		assert.Equal(t, []string{"ACSC", "RJCT AM04", "PDNG", "RJCT NARR"}, statuses)
+		assert.Equal(t, "501", report.OrgnlPmtInfAndSts[0].TxInfAndSts[0].AcctSvcrRef)
+	})
+
+	t.Run("File claimed by another replica", func(t *testing.T) {
+		mockDB, mock, db := setupMockDB(t)
+		defer mockDB.Close()
+
+		mockService := &TransactionService{db: db}
+
+		mock.ExpectQuery(`SELECT \* FROM "payment_files"`).
+			WillReturnRows(sqlmock.NewRows(fileColumns).AddRow(3, "ERP-1", "processing", []byte(testPaymentFile), abandonedAt))
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "payment_files" SET "updated_at"`).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		require.NoError(t, mockService.recoverPaymentFiles(context.Background()))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUnitRecoverCreditTransfer(t *testing.T) {
+	mockDB, mock, db := setupMockDB(t)
+	defer mockDB.Close()
+	mockService := &TransactionService{db: db}
+	transactionColumns := []string{"id", "status"}
+
+	tests := []struct {
+		name        string
+		record      *models.PaymentFileTransfer
+		transaction *sqlmock.Rows
+		status      string
+		reason      string
+	}{
+		{"Never executed", nil, nil, iso20022.StatusRejected, iso20022.ReasonNarrative},
+		{"Recorded outcome", &models.PaymentFileTransfer{TransactionID: 5, Status: "RJCT", ReasonCode: "AM04"}, nil, iso20022.StatusRejected, iso20022.ReasonInsufficientFunds},
+		{"Transaction completed", &models.PaymentFileTransfer{TransactionID: 5}, sqlmock.NewRows(transactionColumns).AddRow(5, "completed"), iso20022.StatusAcceptedSettlementCompleted, ""},
+		{"Transaction pending", &models.PaymentFileTransfer{TransactionID: 5}, sqlmock.NewRows(transactionColumns).AddRow(5, "pending"), iso20022.StatusPending, ""},
+		{"Transaction failed", &models.PaymentFileTransfer{TransactionID: 5}, sqlmock.NewRows(transactionColumns).AddRow(5, "failed"), iso20022.StatusRejected, iso20022.ReasonNarrative},
+		{"Transaction never stored", &models.PaymentFileTransfer{TransactionID: 5}, sqlmock.NewRows(transactionColumns), iso20022.StatusRejected, iso20022.ReasonNarrative},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.transaction != nil {
+				mock.ExpectQuery(`SELECT \* FROM "transactions" WHERE id = \$1`).WillReturnRows(tt.transaction)
+			}
+
+			transfer := &iso20022.CreditTransfer{EndToEndID: "INV-1"}
+			require.NoError(t, mockService.recoverCreditTransfer(context.Background(), transfer, tt.record))
+			assert.Equal(t, tt.status, transfer.Status)
+			if tt.reason != "" {
+				require.NotNil(t, transfer.Reason)
+				assert.Equal(t, tt.reason, transfer.Reason.Code)
+			} else {
+				assert.Nil(t, transfer.Reason)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUnitGetPaymentFileReport(t *testing.T) {
+	mockDB, mock, db := setupMockDB(t)
+	defer mockDB.Close()
+
+	mockService := &TransactionService{db: db}
+	columns := []string{"id", "message_id", "status", "report"}
+
+	t.Run("Processed", func(t *testing.T) {
+		ctx := auth.NewContext(context.Background(), &auth.Identity{Subject: "alice"})
+		mock.ExpectQuery(`SELECT \* FROM "payment_files" WHERE principal = \$1 AND message_id = \$2`).
+			WithArgs("alice", "ERP-1", 1).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "ERP-1", "processed", []byte("<Document/>")))
+
+		report, err := mockService.GetPaymentFileReport(ctx, "ERP-1")
+		require.NoError(t, err)
+		assert.Equal(t, "<Document/>", string(report))
+	})
+
+	t.Run("Transactions the caller may not view", func(t *testing.T) {
+		mockService.policy = fakePolicy{3: true}
+		defer func() { mockService.policy = nil }()
+		ctx := auth.NewContext(context.Background(), &auth.Identity{Subject: "alice"})
+
+		// INV-1 from account 1 to 2 became transaction 501, alice has since lost her role on both accounts
+		mock.ExpectQuery(`SELECT \* FROM "payment_files" WHERE principal = \$1 AND message_id = \$2`).
+			WithArgs("alice", "ERP-1", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "message_id", "status", "document", "report"}).
+				AddRow(1, "ERP-1", "processed", []byte(testPaymentFile), []byte("<Document/>")))
+		mock.ExpectQuery(`SELECT \* FROM "payment_file_transfers" WHERE payment_file_id = \$1 AND transaction_id <> 0`).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"payment_file_id", "end_to_end_id", "transaction_id", "status"}).
+				AddRow(1, "INV-1", 501, "ACSC"))
+
+		_, err := mockService.GetPaymentFileReport(ctx, "ERP-1")
+		assert.ErrorIs(t, err, apperr.ErrAccountAccessDenied)
+
+		// INV-3 to account 3 was rejected without a transaction, viewing account 2 is enough
+		mockService.policy = fakePolicy{2: true}
+		mock.ExpectQuery(`SELECT \* FROM "payment_files"`).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "message_id", "status", "document", "report"}).
+				AddRow(1, "ERP-1", "processed", []byte(testPaymentFile), []byte("<Document/>")))
+		mock.ExpectQuery(`SELECT \* FROM "payment_file_transfers"`).
+			WillReturnRows(sqlmock.NewRows([]string{"payment_file_id", "end_to_end_id", "transaction_id", "status"}).
+				AddRow(1, "INV-1", 501, "ACSC"))
+
+		report, err := mockService.GetPaymentFileReport(ctx, "ERP-1")
+		require.NoError(t, err)
+		assert.Equal(t, "<Document/>", string(report))
+	})
+
+	t.Run("Uploaded by another principal", func(t *testing.T) {
+		// alice uploaded ERP-1, the lookup of mallory only finds her own files
+		ctx := auth.NewContext(context.Background(), &auth.Identity{Subject: "mallory"})
+		mock.ExpectQuery(`SELECT \* FROM "payment_files" WHERE principal = \$1 AND message_id = \$2`).
+			WithArgs("mallory", "ERP-1", 1).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		_, err := mockService.GetPaymentFileReport(ctx, "ERP-1")
+		assert.ErrorIs(t, err, apperr.ErrPaymentFileNotFound)
+	})
+
+	t.Run("Processing", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT \* FROM "payment_files"`).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "ERP-1", "processing", nil))
+
+		_, err := mockService.GetPaymentFileReport(context.Background(), "ERP-1")
+		assert.ErrorIs(t, err, apperr.ErrPaymentFileRunning)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT \* FROM "payment_files"`).
+			WillReturnRows(sqlmock.NewRows(columns))
+
+		_, err := mockService.GetPaymentFileReport(context.Background(), "ERP-2")
+		assert.ErrorIs(t, err, apperr.ErrPaymentFileNotFound)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,23 +1,44 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
-	"strings"
+	"strconv"
 
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/auth"
+	"github.com/danielkhtse/supreme-adventure/common/authz"
 	"github.com/danielkhtse/supreme-adventure/common/db"
+	"github.com/danielkhtse/supreme-adventure/common/events"
+	"github.com/danielkhtse/supreme-adventure/common/feed"
+	"github.com/danielkhtse/supreme-adventure/common/idgen"
 	"github.com/danielkhtse/supreme-adventure/common/models"
 	"github.com/danielkhtse/supreme-adventure/common/types"
 	"github.com/danielkhtse/supreme-adventure/transaction-service/internal/client"
 	"github.com/joho/godotenv"
+	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
 // TransactionService handles business logic for transaction operations
 type TransactionService struct {
 	db            *gorm.DB
-	accountClient *client.AccountClient
+	accountClient client.AccountClient
+	idGenerator   idgen.Generator
+	broker        *events.Broker
+	feed          *feed.Store
+	apiKeys       *auth.APIKeyStore
+
+	// policy authorizes callers to act on accounts, with the roles granted on account-service in the shared
+	// account_grants table
+	policy authz.Policy
+
+	webhookHTTPClient  *http.Client
+	webhookMaxAttempts int
 }
 
 // NewTransactionService creates a new TransactionService instance
@@ -40,60 +61,155 @@ func NewTransactionService() *TransactionService {
 	}
 
 	//TODO: use migration script to replace AutoMigrate
-	if err := db.GetDB().AutoMigrate(&models.Transaction{}); err != nil {
+	if err := db.GetDB().AutoMigrate(&models.Transaction{}, &models.WebhookEndpoint{}, &models.WebhookDelivery{}, &models.PaymentFile{}, &models.PaymentFileTransfer{}); err != nil {
+		log.Fatal(err)
+	}
+	//message IDs used to be unique across every principal, the index now includes the principal
+	if migrator := db.GetDB().Migrator(); migrator.HasIndex(&models.PaymentFile{}, "idx_payment_files_message_id") {
+		if err := migrator.DropIndex(&models.PaymentFile{}, "idx_payment_files_message_id"); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	accountClient, err := client.NewAccountClientFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	idGenerator, err := idgen.NewGeneratorFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	webhookMaxAttempts := 0
+	if value := os.Getenv("WEBHOOK_MAX_ATTEMPTS"); value != "" {
+		if webhookMaxAttempts, err = strconv.Atoi(value); err != nil {
+			log.Fatal("invalid WEBHOOK_MAX_ATTEMPTS: " + err.Error())
+		}
+	}
+
+	retention, err := feed.RetentionFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	broker := events.NewBroker()
+	eventStore := feed.NewStore(db.GetDB(), models.TransactionEventTableName, broker, retention)
+	if err := eventStore.Migrate(); err != nil {
+		log.Fatal(err)
+	}
+	if err := migrateWebhookDispatcher(db.GetDB(), eventStore); err != nil {
 		log.Fatal(err)
 	}
 
-	accountClient := client.NewAccountClient(os.Getenv("ACCOUNT_SERVICE_URL"))
+	apiKeys := auth.NewAPIKeyStore(db.GetDB())
+	if err := apiKeys.Migrate(); err != nil {
+		log.Fatal(err)
+	}
+
+	roles, err := authz.RolesFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	policy := authz.NewGrantPolicy(db.GetDB(), roles)
+	if err := policy.Migrate(); err != nil {
+		log.Fatal(err)
+	}
 
 	return &TransactionService{
-		db:            db.GetDB(),
-		accountClient: accountClient,
+		db:                 db.GetDB(),
+		accountClient:      accountClient,
+		idGenerator:        idGenerator,
+		broker:             broker,
+		feed:               eventStore,
+		apiKeys:            apiKeys,
+		policy:             policy,
+		webhookHTTPClient:  newWebhookHTTPClient(),
+		webhookMaxAttempts: webhookMaxAttempts,
 	}
 }
 
-func (s *TransactionService) CreateTransaction(transaction *models.Transaction) error {
+// Policy returns the policy authorizing callers to act on accounts
+func (s *TransactionService) Policy() authz.Policy {
+	return s.policy
+}
+
+// CreateTransaction validates both accounts, stores the transaction as pending and performs the transfer
+func (s *TransactionService) CreateTransaction(ctx context.Context, transaction *models.Transaction) error {
 
 	if transaction == nil {
 		return fmt.Errorf("transaction cannot be nil")
 	}
 
 	if transaction.SourceAccountID == transaction.DestAccountID {
-		return fmt.Errorf("source and destination accounts cannot be the same")
+		return apperr.ErrSameAccount.WithMessage("source and destination accounts cannot be the same")
+	}
+
+	if transaction.Currency == "" {
+		transaction.Currency = types.DefaultCurrency
 	}
 
-	sourceAccount, err := s.accountClient.GetAccount(transaction.SourceAccountID)
+	sourceAccount, err := s.accountClient.GetAccount(ctx, transaction.SourceAccountID)
 	if err != nil {
-		if strings.Contains(err.Error(), "404") {
-			return fmt.Errorf("source account not found")
+		if errors.Is(err, apperr.ErrAccountNotFound) {
+			return apperr.ErrAccountNotFound.WithMessage("source account not found")
 		}
 		return fmt.Errorf("failed to fetch source account: %w", err)
 	}
 
-	if sourceAccount.Balance < types.AccountBalance(transaction.Amount) {
-		return fmt.Errorf("insufficient balance in source account %d", transaction.SourceAccountID)
+	//both accounts must hold the currency of the transaction
+	if sourceAccount.Currency != transaction.Currency {
+		return apperr.ErrCurrencyMismatch.WithMessage("source account %d holds %s, the transaction is in %s", sourceAccount.ID, sourceAccount.Currency, transaction.Currency)
 	}
 
-	_, err = s.accountClient.GetAccount(transaction.DestAccountID)
+	remaining, err := sourceAccount.BalanceMoney().Sub(transaction.Money())
 	if err != nil {
-		if strings.Contains(err.Error(), "404") {
-			return fmt.Errorf("destination account not found")
+		return apperr.Money(err)
+	}
+	if remaining.Amount < 0 {
+		return apperr.ErrInsufficientFunds.WithMessage("insufficient balance in source account %d", transaction.SourceAccountID)
+	}
+
+	//payers need not be able to view the accounts they pay into, the transfer then validates the destination
+	destAccount, err := s.accountClient.GetAccount(ctx, transaction.DestAccountID)
+	if err != nil && !errors.Is(err, apperr.ErrAccountAccessDenied) {
+		if errors.Is(err, apperr.ErrAccountNotFound) {
+			return apperr.ErrAccountNotFound.WithMessage("destination account not found")
 		}
 		return fmt.Errorf("failed to fetch destination account: %w", err)
 	}
 
+	if destAccount != nil && destAccount.Currency != transaction.Currency {
+		return apperr.ErrCurrencyMismatch.WithMessage("destination account %d holds %s, the transaction is in %s", destAccount.ID, destAccount.Currency, transaction.Currency)
+	}
+
+	//assign a time-ordered ID, unique across replicas, unless the caller reserved one
+	if transaction.ID == 0 {
+		id, err := s.idGenerator.NextID()
+		if err != nil {
+			return fmt.Errorf("failed to generate transaction ID: %w", err)
+		}
+		transaction.ID = types.TransactionID(id)
+	}
+
 	//create trasnaction as pending
 	transaction.Status = types.TransactionStatusPending
 
 	//save transaction to db
-	if err := s.db.Create(transaction).Error; err != nil {
+	if err := s.saveTransaction(transaction, true); err != nil {
 		return fmt.Errorf("failed to create transaction: %w", err)
 	}
 
-	//perform transfer
-	if err := s.TransferFunds(transaction); err != nil {
-		return err
-	}
-
-	return nil
+	//perform transfer, a caller going away must not abandon it half way
+	err = s.TransferFunds(context.WithoutCancel(ctx), transaction)
+
+	auth.Audit(ctx).WithFields(logrus.Fields{
+		"transaction_id":    transaction.ID,
+		"source_account_id": transaction.SourceAccountID,
+		"dest_account_id":   transaction.DestAccountID,
+		"amount":            transaction.Amount,
+		"currency":          transaction.Currency,
+		"status":            transaction.Status,
+	}).Info("transaction created")
+	return err
 }
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/models"
+	"github.com/danielkhtse/supreme-adventure/common/pagination"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"gorm.io/gorm"
+)
+
+const (
+	sortCreatedAtAsc  = "created_at"
+	sortCreatedAtDesc = "-created_at"
+
+	// exportBatchSize is the page size ExportTransactions reads with
+	exportBatchSize = 1000
+)
+
+// TransactionFilter selects the transactions returned by ListTransactions, zero values do not filter
+type TransactionFilter struct {
+	// AccountID matches transactions where the account is either the source or the destination
+	AccountID types.AccountID
+	Status    types.TransactionStatus
+
+	// Inclusive amount range
+	MinAmount types.AccountBalance
+	MaxAmount types.AccountBalance
+
+	// CreatedFrom is inclusive, CreatedTo is exclusive
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+
+	// Ascending sorts oldest first, the default is newest first
+	Ascending bool
+
+	// Cursor returned as next_cursor by the previous page
+	Cursor string
+	Limit  int
+}
+
+// TransactionPage is one page of a transaction listing
+type TransactionPage struct {
+	Transactions []models.Transaction `json:"transactions"`
+
+	// Opaque cursor of the next page, empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+
+	// Whether more transactions match the filter
+	HasMore bool `json:"has_more"`
+}
+
+// GetTransaction returns a single transaction by ID
+func (s *TransactionService) GetTransaction(ctx context.Context, transactionID types.TransactionID) (*models.Transaction, error) {
+	var transaction models.Transaction
+	if err := s.db.WithContext(ctx).First(&transaction, transactionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperr.ErrTransactionNotFound
+		}
+		return nil, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	return &transaction, nil
+}
+
+// ListTransactions returns a page of transactions matching the filter, sorted by creation time
+func (s *TransactionService) ListTransactions(ctx context.Context, filter TransactionFilter) (*TransactionPage, error) {
+	return s.listTransactions(ctx, filter, pagination.ClampLimit(filter.Limit))
+}
+
+// ExportTransactions calls fn for every transaction matching the filter in sort order, reading in batches so
+// large ranges are streamed rather than loaded at once. Cursor and Limit of the filter are ignored.
+func (s *TransactionService) ExportTransactions(ctx context.Context, filter TransactionFilter, fn func(transaction *models.Transaction) error) error {
+	filter.Cursor = ""
+	for {
+		page, err := s.listTransactions(ctx, filter, exportBatchSize)
+		if err != nil {
+			return err
+		}
+		for i := range page.Transactions {
+			if err := fn(&page.Transactions[i]); err != nil {
+				return err
+			}
+		}
+		if !page.HasMore {
+			return nil
+		}
+		filter.Cursor = page.NextCursor
+	}
+}
+
+func (s *TransactionService) listTransactions(ctx context.Context, filter TransactionFilter, limit int) (*TransactionPage, error) {
+	sort, direction, comparison := sortCreatedAtDesc, "DESC", "<"
+	if filter.Ascending {
+		sort, direction, comparison = sortCreatedAtAsc, "ASC", ">"
+	}
+
+	cursor, err := pagination.Decode(filter.Cursor, sort)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter.MinAmount > 0 && filter.MaxAmount > 0 && filter.MinAmount > filter.MaxAmount {
+		return nil, apperr.Invalid("min_amount must not exceed max_amount")
+	}
+	if !filter.CreatedFrom.IsZero() && !filter.CreatedTo.IsZero() && !filter.CreatedFrom.Before(filter.CreatedTo) {
+		return nil, apperr.Invalid("created_from must be before created_to")
+	}
+
+	query := s.db.WithContext(ctx).Model(&models.Transaction{})
+	if filter.AccountID != 0 {
+		query = query.Where("source_account_id = ? OR dest_account_id = ?", filter.AccountID, filter.AccountID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.MinAmount > 0 {
+		query = query.Where("amount >= ?", filter.MinAmount)
+	}
+	if filter.MaxAmount > 0 {
+		query = query.Where("amount <= ?", filter.MaxAmount)
+	}
+	if !filter.CreatedFrom.IsZero() {
+		query = query.Where("created_at >= ?", filter.CreatedFrom)
+	}
+	if !filter.CreatedTo.IsZero() {
+		query = query.Where("created_at < ?", filter.CreatedTo)
+	}
+	if cursor != nil {
+		createdAt, err := time.Parse(time.RFC3339Nano, cursor.Value)
+		if err != nil {
+			return nil, pagination.ErrInvalidCursor
+		}
+		query = query.Where(fmt.Sprintf("(created_at, id) %s (?, ?)", comparison), createdAt, cursor.ID)
+	}
+
+	var transactions []models.Transaction
+	if err := query.
+		Order("created_at " + direction).
+		Order("id " + direction).
+		Limit(limit + 1).
+		Find(&transactions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list transactions: %w", err)
+	}
+
+	page := &TransactionPage{Transactions: transactions}
+	if len(transactions) > limit {
+		page.HasMore = true
+		page.Transactions = transactions[:limit]
+		last := page.Transactions[limit-1]
+		page.NextCursor = pagination.Cursor{
+			Sort:  sort,
+			Value: last.CreatedAt.UTC().Format(time.RFC3339Nano),
+			ID:    uint64(last.ID),
+		}.Encode()
+	}
+	if page.Transactions == nil {
+		page.Transactions = []models.Transaction{}
+	}
+	return page, nil
+}
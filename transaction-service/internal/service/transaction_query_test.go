@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/danielkhtse/supreme-adventure/common/models"
+	"github.com/danielkhtse/supreme-adventure/common/pagination"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestUnitGetTransaction(t *testing.T) {
+	mockDB, mock, db := setupMockDB(t)
+	defer mockDB.Close()
+
+	service := &TransactionService{db: db}
+
+	t.Run("Found", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT \* FROM "transactions" WHERE "transactions"."id" = \$1 ORDER BY "transactions"."id" LIMIT \$2`).
+			WithArgs(7, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "source_account_id", "dest_account_id", "amount", "status"}).
+				AddRow(7, 1, 2, 100, "completed"))
+
+		transaction, err := service.GetTransaction(context.Background(), 7)
+		require.NoError(t, err)
+		assert.Equal(t, types.TransactionID(7), transaction.ID)
+		assert.Equal(t, types.TransactionStatusCompleted, transaction.Status)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT \* FROM "transactions"`).
+			WithArgs(8, 1).
+			WillReturnError(gorm.ErrRecordNotFound)
+
+		_, err := service.GetTransaction(context.Background(), 8)
+		assert.ErrorContains(t, err, "transaction not found")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUnitListTransactions(t *testing.T) {
+	mockDB, mock, db := setupMockDB(t)
+	defer mockDB.Close()
+
+	service := &TransactionService{db: db}
+	createdAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	columns := []string{"id", "source_account_id", "dest_account_id", "amount", "status", "created_at"}
+
+	t.Run("Filters and returns next cursor", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT \* FROM "transactions" WHERE \(source_account_id = \$1 OR dest_account_id = \$2\) AND status = \$3 AND amount >= \$4 AND amount <= \$5 ORDER BY created_at DESC,id DESC LIMIT \$6`).
+			WithArgs(1, 1, "completed", 10, 500, 3).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(30, 1, 2, 100, "completed", createdAt.Add(2*time.Minute)).
+				AddRow(20, 2, 1, 50, "completed", createdAt.Add(time.Minute)).
+				AddRow(10, 1, 3, 20, "completed", createdAt))
+
+		page, err := service.ListTransactions(context.Background(), TransactionFilter{
+			AccountID: 1,
+			Status:    types.TransactionStatusCompleted,
+			MinAmount: 10,
+			MaxAmount: 500,
+			Limit:     2,
+		})
+		require.NoError(t, err)
+		assert.Len(t, page.Transactions, 2)
+		assert.True(t, page.HasMore)
+
+		cursor, err := pagination.Decode(page.NextCursor, sortCreatedAtDesc)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(20), cursor.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Continues after cursor", func(t *testing.T) {
+		cursor := pagination.Cursor{Sort: sortCreatedAtAsc, Value: createdAt.Format(time.RFC3339Nano), ID: 10}.Encode()
+
+		mock.ExpectQuery(`SELECT \* FROM "transactions" WHERE \(created_at, id\) > \(\$1, \$2\) ORDER BY created_at ASC,id ASC LIMIT \$3`).
+			WithArgs(createdAt, 10, 51).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(20, 2, 1, 50, "completed", createdAt.Add(time.Minute)))
+
+		page, err := service.ListTransactions(context.Background(), TransactionFilter{Ascending: true, Cursor: cursor})
+		require.NoError(t, err)
+		assert.Len(t, page.Transactions, 1)
+		assert.False(t, page.HasMore)
+		assert.Empty(t, page.NextCursor)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Cursor from another sort order", func(t *testing.T) {
+		cursor := pagination.Cursor{Sort: sortCreatedAtAsc, Value: createdAt.Format(time.RFC3339Nano), ID: 10}.Encode()
+
+		_, err := service.ListTransactions(context.Background(), TransactionFilter{Cursor: cursor})
+		assert.ErrorIs(t, err, pagination.ErrInvalidCursor)
+	})
+
+	t.Run("Invalid amount range", func(t *testing.T) {
+		_, err := service.ListTransactions(context.Background(), TransactionFilter{MinAmount: 10, MaxAmount: 5})
+		assert.ErrorContains(t, err, "min_amount")
+	})
+}
+
+func TestUnitExportTransactions(t *testing.T) {
+	mockDB, mock, db := setupMockDB(t)
+	defer mockDB.Close()
+
+	service := &TransactionService{db: db}
+	createdAt := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`SELECT \* FROM "transactions" WHERE status = \$1 AND created_at >= \$2 ORDER BY created_at ASC,id ASC LIMIT \$3`).
+		WithArgs("completed", createdAt, exportBatchSize+1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "source_account_id", "dest_account_id", "amount", "status", "created_at"}).
+			AddRow(10, 1, 2, 100, "completed", createdAt).
+			AddRow(20, 2, 1, 50, "completed", createdAt.Add(time.Minute)))
+
+	var exported []types.TransactionID
+	err := service.ExportTransactions(context.Background(), TransactionFilter{
+		Status:      types.TransactionStatusCompleted,
+		CreatedFrom: createdAt,
+		Ascending:   true,
+		Limit:       1,
+	}, func(transaction *models.Transaction) error {
+		exported = append(exported, transaction.ID)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []types.TransactionID{10, 20}, exported)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,14 +1,20 @@
 package service
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/authz"
+	"github.com/danielkhtse/supreme-adventure/common/idgen"
 	"github.com/danielkhtse/supreme-adventure/common/models"
+	"github.com/danielkhtse/supreme-adventure/common/types"
 	"github.com/danielkhtse/supreme-adventure/transaction-service/internal/client"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -41,19 +47,21 @@ func TestUnitCreateTransaction(t *testing.T) {
 		var exists bool
 
 		switch r.URL.Path {
-		case "/accounts/1":
+		case "/v1/accounts/1":
 			response = &models.Account{
-				ID:      1,
-				Balance: 200,
+				ID:       1,
+				Balance:  200,
+				Currency: "USD",
 			}
 			exists = true
-		case "/accounts/2":
+		case "/v1/accounts/2":
 			response = &models.Account{
-				ID:      2,
-				Balance: 50,
+				ID:       2,
+				Balance:  50,
+				Currency: "USD",
 			}
 			exists = true
-		case "/accounts/1/transfer":
+		case "/internal/accounts/1/balance/transfer":
 			w.WriteHeader(http.StatusOK)
 			return
 		default:
@@ -73,13 +81,17 @@ func TestUnitCreateTransaction(t *testing.T) {
 	mockDB, mock, db := setupMockDB(t)
 	defer mockDB.Close()
 
+	idGenerator, err := idgen.NewSnowflake(1)
+	require.NoError(t, err)
+
 	mockService := &TransactionService{
 		db:            db,
-		accountClient: client.NewAccountClient(mockServer.URL),
+		accountClient: client.NewHTTPAccountClient(mockServer.URL),
+		idGenerator:   idGenerator,
 	}
 
 	t.Run("Nil transaction", func(t *testing.T) {
-		err := mockService.CreateTransaction(nil)
+		err := mockService.CreateTransaction(context.Background(), nil)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "transaction cannot be nil")
 		assert.NoError(t, mock.ExpectationsWereMet())
@@ -92,7 +104,7 @@ func TestUnitCreateTransaction(t *testing.T) {
 			Amount:          100,
 		}
 
-		err := mockService.CreateTransaction(transaction)
+		err := mockService.CreateTransaction(context.Background(), transaction)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "source and destination accounts cannot be the same")
 		assert.NoError(t, mock.ExpectationsWereMet())
@@ -105,7 +117,7 @@ func TestUnitCreateTransaction(t *testing.T) {
 			Amount:          300,
 		}
 
-		err := mockService.CreateTransaction(transaction)
+		err := mockService.CreateTransaction(context.Background(), transaction)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "insufficient balance in source account 1")
 		assert.NoError(t, mock.ExpectationsWereMet())
@@ -118,7 +130,7 @@ func TestUnitCreateTransaction(t *testing.T) {
 			Amount:          100,
 		}
 
-		err := mockService.CreateTransaction(transaction)
+		err := mockService.CreateTransaction(context.Background(), transaction)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "source account not found")
 		assert.NoError(t, mock.ExpectationsWereMet())
@@ -131,10 +143,168 @@ func TestUnitCreateTransaction(t *testing.T) {
 			Amount:          100,
 		}
 
-		err := mockService.CreateTransaction(transaction)
+		err := mockService.CreateTransaction(context.Background(), transaction)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "destination account not found")
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
+	t.Run("Assigns generated transaction ID", func(t *testing.T) {
+		transaction := &models.Transaction{
+			SourceAccountID: 1,
+			DestAccountID:   2,
+			Amount:          100,
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO "transactions" \("source_account_id","dest_account_id","amount","currency","status","description","created_at","updated_at","id"\) VALUES \(\$1,\$2,\$3,\$4,\$5,\$6,\$7,\$8,\$9\) RETURNING "id"`).
+			WithArgs(transaction.SourceAccountID, transaction.DestAccountID, transaction.Amount, sqlmock.AnyArg(), types.TransactionStatusPending, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectCommit()
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "transactions" SET`).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := mockService.CreateTransaction(context.Background(), transaction)
+		assert.NoError(t, err)
+		assert.NotZero(t, transaction.ID)
+		assert.Equal(t, types.TransactionStatusCompleted, transaction.Status)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+// fakeAccountClient serves accounts from memory, denies looking up the hidden ones and fails transfers with
+// transferErr
+type fakeAccountClient struct {
+	accounts    map[types.AccountID]*models.Account
+	hidden      map[types.AccountID]bool
+	transferErr error
+	transfers   []types.TransactionID
+}
+
+func (c *fakeAccountClient) GetAccount(ctx context.Context, accountID types.AccountID) (*models.Account, error) {
+	if c.hidden[accountID] {
+		return nil, apperr.ErrAccountAccessDenied.WithMessage("the caller may not view account %d", accountID)
+	}
+	account, ok := c.accounts[accountID]
+	if !ok {
+		return nil, client.ErrAccountNotFound
+	}
+	return account, nil
+}
+
+func (c *fakeAccountClient) TransferFunds(ctx context.Context, transactionID types.TransactionID, sourceAccountID types.AccountID, destAccountID types.AccountID, amount types.AccountBalance) error {
+	c.transfers = append(c.transfers, transactionID)
+	return c.transferErr
+}
+
+// fakePolicy allows every action on the accounts it holds and denies the others
+type fakePolicy map[types.AccountID]bool
+
+func (p fakePolicy) Authorize(_ context.Context, _ string, action authz.Action, accountID types.AccountID) error {
+	if p[accountID] {
+		return nil
+	}
+	return apperr.ErrAccountAccessDenied.WithMessage("the caller may not %s account %d", action, accountID)
+}
+
+func TestUnitCreateTransactionWithFakeAccountClient(t *testing.T) {
+	mockDB, mock, db := setupMockDB(t)
+	defer mockDB.Close()
+
+	idGenerator, err := idgen.NewSnowflake(1)
+	require.NoError(t, err)
+
+	accountClient := &fakeAccountClient{
+		accounts: map[types.AccountID]*models.Account{
+			1: {ID: 1, Balance: 200, Currency: "USD"},
+			2: {ID: 2, Balance: 50, Currency: "USD"},
+			4: {ID: 4, Balance: 50, Currency: "EUR"},
+		},
+	}
+	mockService := &TransactionService{
+		db:            db,
+		accountClient: accountClient,
+		idGenerator:   idGenerator,
+	}
+
+	t.Run("Currency mismatch", func(t *testing.T) {
+		err := mockService.CreateTransaction(context.Background(), &models.Transaction{
+			SourceAccountID: 1,
+			DestAccountID:   4,
+			Amount:          100,
+		})
+		assert.ErrorIs(t, err, apperr.ErrCurrencyMismatch)
+		assert.EqualError(t, err, "destination account 4 holds EUR, the transaction is in USD")
+
+		err = mockService.CreateTransaction(context.Background(), &models.Transaction{
+			SourceAccountID: 1,
+			DestAccountID:   2,
+			Amount:          100,
+			Currency:        "EUR",
+		})
+		assert.ErrorIs(t, err, apperr.ErrCurrencyMismatch)
+		assert.Empty(t, accountClient.transfers)
+	})
+
+	t.Run("Destination account not found", func(t *testing.T) {
+		err := mockService.CreateTransaction(context.Background(), &models.Transaction{
+			SourceAccountID: 1,
+			DestAccountID:   3,
+			Amount:          100,
+		})
+		assert.EqualError(t, err, "destination account not found")
+		assert.Empty(t, accountClient.transfers)
+	})
+
+	t.Run("Destination account hidden from the caller", func(t *testing.T) {
+		accountClient.hidden = map[types.AccountID]bool{2: true}
+		defer func() { accountClient.hidden = nil }()
+		transaction := &models.Transaction{
+			SourceAccountID: 1,
+			DestAccountID:   2,
+			Amount:          100,
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO "transactions"`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectCommit()
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "transactions" SET`).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		require.NoError(t, mockService.CreateTransaction(context.Background(), transaction))
+		assert.Equal(t, types.TransactionStatusCompleted, transaction.Status)
+		assert.Equal(t, []types.TransactionID{transaction.ID}, accountClient.transfers)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		accountClient.transfers = nil
+	})
+
+	t.Run("Rejected transfer marks the transaction failed", func(t *testing.T) {
+		accountClient.transferErr = errors.New("account version mismatch")
+		transaction := &models.Transaction{
+			SourceAccountID: 1,
+			DestAccountID:   2,
+			Amount:          100,
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO "transactions"`).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectCommit()
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "transactions" SET`).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := mockService.CreateTransaction(context.Background(), transaction)
+		assert.Error(t, err)
+		assert.Equal(t, types.TransactionStatusFailed, transaction.Status)
+		assert.Equal(t, "account version mismatch", transaction.Description)
+		assert.Equal(t, []types.TransactionID{transaction.ID}, accountClient.transfers)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
 }
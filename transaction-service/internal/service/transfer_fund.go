@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"log"
 
@@ -9,19 +10,19 @@ import (
 )
 
 // TransferFunds transfers funds between two accounts and creates a transaction record
-func (s *TransactionService) TransferFunds(transaction *models.Transaction) error {
+func (s *TransactionService) TransferFunds(ctx context.Context, transaction *models.Transaction) error {
 
 	if transaction == nil {
 		return fmt.Errorf("transaction cannot be nil")
 	}
 
-	// Call account service to transfer funds
-	err := s.accountClient.TransferFunds(transaction.SourceAccountID, transaction.DestAccountID, transaction.Amount)
+	// Call account service to transfer funds, the transaction ID makes retries safe
+	err := s.accountClient.TransferFunds(ctx, transaction.ID, transaction.SourceAccountID, transaction.DestAccountID, transaction.Amount)
 	if err != nil {
 		log.Printf("Failed to transfer funds: %v", err)
 		transaction.Status = types.TransactionStatusFailed
 		transaction.Description = err.Error()
-		if dbErr := s.db.Save(transaction).Error; dbErr != nil {
+		if dbErr := s.saveTransaction(transaction, false); dbErr != nil {
 			return fmt.Errorf("failed to update transaction status after transfer failure: %w", dbErr)
 		}
 		return fmt.Errorf("failed to transfer funds: %w", err)
@@ -29,7 +30,7 @@ func (s *TransactionService) TransferFunds(transaction *models.Transaction) erro
 
 	// Update transaction status to completed
 	transaction.Status = types.TransactionStatusCompleted
-	if err := s.db.Save(transaction).Error; err != nil {
+	if err := s.saveTransaction(transaction, false); err != nil {
 		return fmt.Errorf("failed to update transaction status: %w", err)
 	}
 
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -19,22 +20,24 @@ func TestUnitTransferFunds(t *testing.T) {
 		var exists bool
 
 		switch r.URL.Path {
-		case "/accounts/1":
+		case "/v1/accounts/1":
 			response = &models.Account{
-				ID:      1,
-				Balance: 200,
+				ID:       1,
+				Balance:  200,
+				Currency: "USD",
 			}
 			exists = true
-		case "/accounts/2":
+		case "/v1/accounts/2":
 			response = &models.Account{
-				ID:      2,
-				Balance: 50,
+				ID:       2,
+				Balance:  50,
+				Currency: "USD",
 			}
 			exists = true
-		case "/accounts/1/balance/transfer":
+		case "/internal/accounts/1/balance/transfer":
 			w.WriteHeader(http.StatusOK)
 			return
-		case "/accounts/999/balance/transfer":
+		case "/internal/accounts/999/balance/transfer":
 			w.WriteHeader(http.StatusNotFound)
 
 			errorResp := &struct {
@@ -63,11 +66,11 @@ func TestUnitTransferFunds(t *testing.T) {
 
 	mockService := &TransactionService{
 		db:            db,
-		accountClient: client.NewAccountClient(mockServer.URL),
+		accountClient: client.NewHTTPAccountClient(mockServer.URL),
 	}
 
 	t.Run("Nil transaction", func(t *testing.T) {
-		err := mockService.TransferFunds(nil)
+		err := mockService.TransferFunds(context.Background(), nil)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "transaction cannot be nil")
 		assert.NoError(t, mock.ExpectationsWereMet())
@@ -75,6 +78,7 @@ func TestUnitTransferFunds(t *testing.T) {
 
 	t.Run("Successful transfer", func(t *testing.T) {
 		transaction := &models.Transaction{
+			ID:              1,
 			SourceAccountID: 1,
 			DestAccountID:   2,
 			Amount:          100,
@@ -83,12 +87,12 @@ func TestUnitTransferFunds(t *testing.T) {
 			Description:     "",
 		}
 		mock.ExpectBegin()
-		mock.ExpectQuery("INSERT INTO \"transactions\" \\(\"source_account_id\",\"dest_account_id\",\"amount\",\"currency\",\"status\",\"description\",\"created_at\",\"updated_at\"\\) VALUES \\(\\$1,\\$2,\\$3,\\$4,\\$5,\\$6,\\$7,\\$8\\) RETURNING \"id\"").
-			WithArgs(transaction.SourceAccountID, transaction.DestAccountID, transaction.Amount, transaction.Currency, types.TransactionStatusCompleted, transaction.Description, sqlmock.AnyArg(), sqlmock.AnyArg()).
-			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectExec("UPDATE \"transactions\" SET \"source_account_id\"=\\$1,\"dest_account_id\"=\\$2,\"amount\"=\\$3,\"currency\"=\\$4,\"status\"=\\$5,\"description\"=\\$6,\"created_at\"=\\$7,\"updated_at\"=\\$8 WHERE \"id\" = \\$9").
+			WithArgs(transaction.SourceAccountID, transaction.DestAccountID, transaction.Amount, transaction.Currency, types.TransactionStatusCompleted, transaction.Description, sqlmock.AnyArg(), sqlmock.AnyArg(), transaction.ID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
 		mock.ExpectCommit()
 
-		err := mockService.TransferFunds(transaction)
+		err := mockService.TransferFunds(context.Background(), transaction)
 		assert.NoError(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/auth"
+	"github.com/danielkhtse/supreme-adventure/common/events"
+	"github.com/danielkhtse/supreme-adventure/common/models"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"gorm.io/gorm"
+)
+
+const (
+	webhookSecretPrefix = "whsec_"
+)
+
+var errWebhookAddress = errors.New("webhook address is not public")
+
+// CreateWebhookEndpoint registers a new webhook endpoint and generates its signing secret
+func (s *TransactionService) CreateWebhookEndpoint(ctx context.Context, endpoint *models.WebhookEndpoint) error {
+	if endpoint == nil {
+		return apperr.Invalid("webhook endpoint cannot be nil")
+	}
+
+	parsed, err := url.Parse(endpoint.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return apperr.Invalid("webhook url must be an absolute http(s) url")
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, parsed.Hostname())
+	if err != nil || len(addrs) == 0 {
+		return apperr.Invalid("webhook url host %s could not be resolved", parsed.Hostname())
+	}
+	for _, addr := range addrs {
+		if !publicWebhookAddress(addr.IP) {
+			return apperr.Invalid("webhook url must not resolve to a private, loopback or link-local address")
+		}
+	}
+
+	for _, eventType := range endpoint.EventTypes {
+		if !isWebhookEventType(eventType) {
+			return apperr.Invalid("unsupported event type %s", eventType)
+		}
+	}
+
+	id, err := s.idGenerator.NextID()
+	if err != nil {
+		return fmt.Errorf("failed to generate webhook endpoint ID: %w", err)
+	}
+	endpoint.ID = types.WebhookEndpointID(id)
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	endpoint.Secret = secret
+	endpoint.Active = true
+
+	if err := s.db.Create(endpoint).Error; err != nil {
+		return fmt.Errorf("failed to create webhook endpoint: %w", err)
+	}
+
+	auth.Audit(ctx).WithField("webhook_id", endpoint.ID).WithField("url", endpoint.URL).Info("webhook endpoint created")
+	return nil
+}
+
+// ListWebhookEndpoints returns all registered webhook endpoints
+func (s *TransactionService) ListWebhookEndpoints() ([]models.WebhookEndpoint, error) {
+	var endpoints []models.WebhookEndpoint
+	if err := s.db.Order("id").Find(&endpoints).Error; err != nil {
+		return nil, fmt.Errorf("failed to list webhook endpoints: %w", err)
+	}
+	return endpoints, nil
+}
+
+// GetWebhookEndpoint retrieves a webhook endpoint by ID
+func (s *TransactionService) GetWebhookEndpoint(id types.WebhookEndpointID) (*models.WebhookEndpoint, error) {
+	var endpoint models.WebhookEndpoint
+	if err := s.db.First(&endpoint, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperr.ErrWebhookNotFound
+		}
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+// DeleteWebhookEndpoint removes a webhook endpoint, its delivery log is kept for auditing
+func (s *TransactionService) DeleteWebhookEndpoint(ctx context.Context, id types.WebhookEndpointID) error {
+	result := s.db.Delete(&models.WebhookEndpoint{}, "id = ?", id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete webhook endpoint: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return apperr.ErrWebhookNotFound
+	}
+
+	auth.Audit(ctx).WithField("webhook_id", id).Info("webhook endpoint deleted")
+	return nil
+}
+
+// ListWebhookDeliveries returns the most recent deliveries of an endpoint
+func (s *TransactionService) ListWebhookDeliveries(endpointID types.WebhookEndpointID, limit int) ([]models.WebhookDelivery, error) {
+	if _, err := s.GetWebhookEndpoint(endpointID); err != nil {
+		return nil, err
+	}
+
+	var deliveries []models.WebhookDelivery
+	if err := s.db.Where("endpoint_id = ?", endpointID).Order("id DESC").Limit(limit).Find(&deliveries).Error; err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// RedeliverWebhook schedules an existing delivery to be sent again immediately
+func (s *TransactionService) RedeliverWebhook(ctx context.Context, endpointID types.WebhookEndpointID, deliveryID types.WebhookDeliveryID) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	if err := s.db.First(&delivery, "id = ? AND endpoint_id = ?", deliveryID, endpointID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, apperr.ErrDeliveryNotFound
+		}
+		return nil, err
+	}
+
+	delivery.Status = types.WebhookDeliveryStatusPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now().UTC()
+	delivery.LastError = ""
+	if err := s.db.Save(&delivery).Error; err != nil {
+		return nil, fmt.Errorf("failed to schedule redelivery: %w", err)
+	}
+
+	auth.Audit(ctx).WithField("webhook_id", endpointID).WithField("delivery_id", deliveryID).Info("webhook redelivery scheduled")
+	return &delivery, nil
+}
+
+// cgnatRange is the shared address space of carrier-grade NAT, not routable on the internet either
+var cgnatRange = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// publicWebhookAddress reports whether webhooks may be sent to ip, addresses of the service's own network and host
+// (private, loopback, link-local including cloud metadata, unspecified and multicast) are refused
+func publicWebhookAddress(ip net.IP) bool {
+	return !ip.IsPrivate() && !ip.IsLoopback() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast() && !ip.IsUnspecified() && !cgnatRange.Contains(ip)
+}
+
+// newWebhookHTTPClient returns the client sending webhooks. It refuses to connect to addresses which are not
+// public, so a host which resolved to a public address at registration and a redirect are checked again at
+// every delivery.
+func newWebhookHTTPClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 5 * time.Second,
+		Control: func(network, address string, c syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !publicWebhookAddress(ip) {
+				return fmt.Errorf("%w: %s", errWebhookAddress, host)
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	// a proxy would be dialed instead of the endpoint and bypass the address check
+	transport.Proxy = nil
+
+	return &http.Client{Timeout: 10 * time.Second, Transport: transport}
+}
+
+func isWebhookEventType(eventType events.Type) bool {
+	switch eventType {
+	case events.TypeTransactionStatusChanged, events.TypeAccountBalanceChanged:
+		return true
+	default:
+		return false
+	}
+}
+
+func generateWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return webhookSecretPrefix + hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,319 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/danielkhtse/supreme-adventure/common/feed"
+	"github.com/danielkhtse/supreme-adventure/common/models"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	log "github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	WebhookSignatureHeader = "Webhook-Signature"
+	WebhookIDHeader        = "Webhook-Id"
+	WebhookEventHeader     = "Webhook-Event"
+
+	defaultWebhookMaxAttempts = 8
+	webhookBaseBackoff        = 10 * time.Second
+	webhookMaxBackoff         = time.Hour
+	webhookPollInterval       = time.Second
+	webhookBatchSize          = 20
+	webhookFeedBatchSize      = 100
+
+	// name of the change feed cursor of the dispatcher
+	webhookDispatchCursorName = "webhooks"
+
+	// while a replica sends a claimed delivery, other replicas skip it until the lease expires
+	webhookClaimLease = time.Minute
+)
+
+// StartWebhookDispatcher turns change feed events into webhook deliveries and sends due deliveries until ctx is done
+func (s *TransactionService) StartWebhookDispatcher(ctx context.Context) {
+	if s.feed == nil {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(webhookPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				for {
+					more, err := s.enqueueWebhookDeliveries(ctx)
+					if err != nil {
+						log.WithError(err).Error("failed to enqueue webhook deliveries")
+					}
+					if err != nil || !more {
+						break
+					}
+				}
+			}
+		}
+	}()
+
+	go func() {
+		ticker := time.NewTicker(webhookPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.sendDueWebhookDeliveries(ctx); err != nil {
+					log.WithError(err).Error("failed to send webhook deliveries")
+				}
+			}
+		}
+	}()
+}
+
+// migrateWebhookDispatcher creates the dispatch cursor at the head of the change feed on first start, so endpoints
+// receive the events committed from then on
+func migrateWebhookDispatcher(db *gorm.DB, eventStore *feed.Store) error {
+	if err := db.AutoMigrate(&models.WebhookDispatchCursor{}); err != nil {
+		return err
+	}
+	head, err := eventStore.Head(context.Background())
+	if err != nil {
+		return err
+	}
+	return db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.WebhookDispatchCursor{Name: webhookDispatchCursorName, Cursor: head}).Error
+}
+
+// enqueueWebhookDeliveries reads the next page of the change feed after the dispatch cursor and records a pending
+// delivery for every endpoint subscribed to each event. The deliveries and the advanced cursor commit together, so
+// an event is enqueued exactly once even when the service stops in between. It reports whether more events are
+// waiting.
+func (s *TransactionService) enqueueWebhookDeliveries(ctx context.Context) (bool, error) {
+	tx := s.db.WithContext(ctx).Begin()
+	if err := tx.Error; err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	// the row lock lets one replica at a time enqueue, the others skip the round
+	var cursor models.WebhookDispatchCursor
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("name = ?", webhookDispatchCursorName).
+		Limit(1).
+		Find(&cursor)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to lock webhook dispatch cursor: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	page, err := s.feed.List(ctx, cursor.Cursor, 0, webhookFeedBatchSize, 0)
+	if errors.Is(err, feed.ErrCursorExpired) {
+		log.WithField("cursor", cursor.Cursor).Error("webhook dispatcher fell behind the event retention, continuing from the oldest retained event")
+		page, err = s.feed.List(ctx, "", 0, webhookFeedBatchSize, 0)
+	}
+	if err != nil {
+		return false, err
+	}
+	if len(page.Events) == 0 {
+		return false, nil
+	}
+
+	var endpoints []models.WebhookEndpoint
+	if err := tx.Where("active = ?", true).Find(&endpoints).Error; err != nil {
+		return false, fmt.Errorf("failed to load webhook endpoints: %w", err)
+	}
+
+	for _, event := range page.Events {
+		for _, endpoint := range endpoints {
+			if !endpoint.Matches(event) {
+				continue
+			}
+
+			id, err := s.idGenerator.NextID()
+			if err != nil {
+				return false, fmt.Errorf("failed to generate webhook delivery ID: %w", err)
+			}
+
+			delivery := &models.WebhookDelivery{
+				ID:            types.WebhookDeliveryID(id),
+				EndpointID:    endpoint.ID,
+				EventID:       event.ID,
+				EventType:     event.Type,
+				Payload:       event.Data,
+				Status:        types.WebhookDeliveryStatusPending,
+				NextAttemptAt: time.Now().UTC(),
+			}
+			if err := tx.Create(delivery).Error; err != nil {
+				return false, fmt.Errorf("failed to create webhook delivery: %w", err)
+			}
+		}
+	}
+
+	cursor.Cursor = page.NextCursor
+	if err := tx.Save(&cursor).Error; err != nil {
+		return false, fmt.Errorf("failed to advance webhook dispatch cursor: %w", err)
+	}
+	if err := tx.Commit().Error; err != nil {
+		return false, err
+	}
+	return page.HasMore, nil
+}
+
+// sendDueWebhookDeliveries claims pending deliveries whose next attempt is due and sends them
+func (s *TransactionService) sendDueWebhookDeliveries(ctx context.Context) error {
+	var deliveries []models.WebhookDelivery
+
+	now := time.Now().UTC()
+	tx := s.db.Begin()
+	if err := tx.Error; err != nil {
+		return err
+	}
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("status = ? AND next_attempt_at <= ?", types.WebhookDeliveryStatusPending, now).
+		Order("next_attempt_at").
+		Limit(webhookBatchSize).
+		Find(&deliveries).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	for i := range deliveries {
+		deliveries[i].NextAttemptAt = now.Add(webhookClaimLease)
+		if err := tx.Model(&deliveries[i]).Update("next_attempt_at", deliveries[i].NextAttemptAt).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
+
+	for i := range deliveries {
+		if err := s.attemptWebhookDelivery(ctx, &deliveries[i]); err != nil {
+			log.WithError(err).WithField("delivery_id", deliveries[i].ID).Error("failed to record webhook delivery attempt")
+		}
+	}
+
+	return nil
+}
+
+// attemptWebhookDelivery sends one delivery and records the outcome, scheduling a retry on failure
+func (s *TransactionService) attemptWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	endpoint, err := s.GetWebhookEndpoint(delivery.EndpointID)
+	if err != nil {
+		delivery.Status = types.WebhookDeliveryStatusFailed
+		delivery.LastError = err.Error()
+		return s.db.Save(delivery).Error
+	}
+
+	delivery.Attempts++
+	statusCode, sendErr := s.sendWebhook(ctx, endpoint, delivery)
+	delivery.ResponseStatusCode = statusCode
+
+	logger := log.WithFields(log.Fields{
+		"delivery_id": delivery.ID,
+		"endpoint_id": endpoint.ID,
+		"attempt":     delivery.Attempts,
+		"status_code": statusCode,
+	})
+
+	switch {
+	case sendErr == nil:
+		deliveredAt := time.Now().UTC()
+		delivery.Status = types.WebhookDeliveryStatusSucceeded
+		delivery.DeliveredAt = &deliveredAt
+		delivery.LastError = ""
+		logger.Info("webhook delivered")
+	case delivery.Attempts >= s.maxWebhookAttempts():
+		delivery.Status = types.WebhookDeliveryStatusFailed
+		delivery.LastError = sendErr.Error()
+		logger.WithError(sendErr).Error("webhook delivery failed, giving up")
+	default:
+		delivery.LastError = sendErr.Error()
+		delivery.NextAttemptAt = time.Now().UTC().Add(webhookBackoff(delivery.Attempts))
+		logger.WithError(sendErr).Warn("webhook delivery failed, scheduling retry")
+	}
+
+	return s.db.Save(delivery).Error
+}
+
+// sendWebhook posts the signed payload, any non-2xx response counts as a failure
+func (s *TransactionService) sendWebhook(ctx context.Context, endpoint *models.WebhookEndpoint, delivery *models.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookIDHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(WebhookEventHeader, string(delivery.EventType))
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(endpoint.Secret, timestamp, body))
+
+	client := s.webhookHTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook endpoint responded with status code %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func (s *TransactionService) maxWebhookAttempts() int {
+	if s.webhookMaxAttempts > 0 {
+		return s.webhookMaxAttempts
+	}
+	return defaultWebhookMaxAttempts
+}
+
+// SignWebhookPayload returns the Webhook-Signature header value "t=<unix>,v1=<hex hmac-sha256>".
+// The MAC covers "<unix>.<body>" so receivers can reject replayed deliveries by their timestamp.
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	ts := strconv.FormatInt(timestamp, 10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookBackoff returns the delay before the next attempt, doubling from webhookBaseBackoff up to webhookMaxBackoff
+func webhookBackoff(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	delay := webhookBaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= webhookMaxBackoff {
+			return webhookMaxBackoff
+		}
+	}
+	return delay
+}
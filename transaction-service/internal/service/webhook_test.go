@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/danielkhtse/supreme-adventure/common/apperr"
+	"github.com/danielkhtse/supreme-adventure/common/events"
+	"github.com/danielkhtse/supreme-adventure/common/feed"
+	"github.com/danielkhtse/supreme-adventure/common/idgen"
+	"github.com/danielkhtse/supreme-adventure/common/models"
+	"github.com/danielkhtse/supreme-adventure/common/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitSignWebhookPayload(t *testing.T) {
+	body := []byte(`{"id":1}`)
+	signature := SignWebhookPayload("secret", 1700000000, body)
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("1700000000." + string(body)))
+	assert.Equal(t, "t=1700000000,v1="+hex.EncodeToString(mac.Sum(nil)), signature)
+}
+
+func TestUnitWebhookBackoff(t *testing.T) {
+	assert.Equal(t, 10*time.Second, webhookBackoff(1))
+	assert.Equal(t, 20*time.Second, webhookBackoff(2))
+	assert.Equal(t, 80*time.Second, webhookBackoff(4))
+	assert.Equal(t, time.Hour, webhookBackoff(20))
+}
+
+func TestUnitWebhookEndpointMatches(t *testing.T) {
+	event := events.Event{
+		Type:       events.TypeAccountBalanceChanged,
+		AccountIDs: []types.AccountID{1},
+	}
+
+	t.Run("All events", func(t *testing.T) {
+		endpoint := &models.WebhookEndpoint{Active: true}
+		assert.True(t, endpoint.Matches(event))
+	})
+
+	t.Run("Filtered by event type", func(t *testing.T) {
+		endpoint := &models.WebhookEndpoint{Active: true, EventTypes: []events.Type{events.TypeTransactionStatusChanged}}
+		assert.False(t, endpoint.Matches(event))
+	})
+
+	t.Run("Filtered by account", func(t *testing.T) {
+		endpoint := &models.WebhookEndpoint{Active: true, AccountID: 2}
+		assert.False(t, endpoint.Matches(event))
+
+		endpoint.AccountID = 1
+		assert.True(t, endpoint.Matches(event))
+	})
+
+	t.Run("Inactive endpoint", func(t *testing.T) {
+		endpoint := &models.WebhookEndpoint{Active: false}
+		assert.False(t, endpoint.Matches(event))
+	})
+}
+
+func TestUnitAttemptWebhookDelivery(t *testing.T) {
+	idGenerator, err := idgen.NewSnowflake(1)
+	require.NoError(t, err)
+
+	endpointColumns := []string{"id", "url", "secret", "event_types", "account_id", "active"}
+
+	t.Run("Successful delivery is signed", func(t *testing.T) {
+		var received *http.Request
+		var receivedBody []byte
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r
+			receivedBody, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer receiver.Close()
+
+		mockDB, mock, db := setupMockDB(t)
+		defer mockDB.Close()
+
+		service := &TransactionService{db: db, idGenerator: idGenerator, webhookHTTPClient: receiver.Client()}
+
+		mock.ExpectQuery(`SELECT \* FROM "webhook_endpoints" WHERE id = \$1`).
+			WillReturnRows(sqlmock.NewRows(endpointColumns).AddRow(7, receiver.URL, "secret", "[]", 0, true))
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "webhook_deliveries" SET`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		delivery := &models.WebhookDelivery{
+			ID:         9,
+			EndpointID: 7,
+			EventID:    1,
+			EventType:  events.TypeTransactionStatusChanged,
+			Payload:    []byte(`{"id":1}`),
+			Status:     types.WebhookDeliveryStatusPending,
+		}
+		err := service.attemptWebhookDelivery(context.Background(), delivery)
+		require.NoError(t, err)
+
+		assert.Equal(t, types.WebhookDeliveryStatusSucceeded, delivery.Status)
+		assert.Equal(t, 1, delivery.Attempts)
+		assert.NotNil(t, delivery.DeliveredAt)
+		require.NotNil(t, received)
+		assert.Equal(t, "9", received.Header.Get(WebhookIDHeader))
+		assert.Equal(t, string(events.TypeTransactionStatusChanged), received.Header.Get(WebhookEventHeader))
+		assert.Contains(t, received.Header.Get(WebhookSignatureHeader), ",v1=")
+		assert.JSONEq(t, `{"id":1}`, string(receivedBody))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Failed delivery is retried later", func(t *testing.T) {
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer receiver.Close()
+
+		mockDB, mock, db := setupMockDB(t)
+		defer mockDB.Close()
+
+		service := &TransactionService{db: db, idGenerator: idGenerator, webhookHTTPClient: receiver.Client()}
+
+		mock.ExpectQuery(`SELECT \* FROM "webhook_endpoints" WHERE id = \$1`).
+			WillReturnRows(sqlmock.NewRows(endpointColumns).AddRow(7, receiver.URL, "secret", "[]", 0, true))
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "webhook_deliveries" SET`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		delivery := &models.WebhookDelivery{ID: 9, EndpointID: 7, EventID: 1, EventType: events.TypeTransactionStatusChanged, Payload: []byte(`{}`), Status: types.WebhookDeliveryStatusPending}
+		before := time.Now().UTC()
+		err := service.attemptWebhookDelivery(context.Background(), delivery)
+		require.NoError(t, err)
+
+		assert.Equal(t, types.WebhookDeliveryStatusPending, delivery.Status)
+		assert.Equal(t, http.StatusServiceUnavailable, delivery.ResponseStatusCode)
+		assert.True(t, delivery.NextAttemptAt.After(before.Add(webhookBaseBackoff-time.Second)))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Gives up after max attempts", func(t *testing.T) {
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer receiver.Close()
+
+		mockDB, mock, db := setupMockDB(t)
+		defer mockDB.Close()
+
+		service := &TransactionService{db: db, idGenerator: idGenerator, webhookHTTPClient: receiver.Client(), webhookMaxAttempts: 2}
+
+		mock.ExpectQuery(`SELECT \* FROM "webhook_endpoints" WHERE id = \$1`).
+			WillReturnRows(sqlmock.NewRows(endpointColumns).AddRow(7, receiver.URL, "secret", "[]", 0, true))
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "webhook_deliveries" SET`).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		delivery := &models.WebhookDelivery{ID: 9, EndpointID: 7, EventID: 1, EventType: events.TypeTransactionStatusChanged, Payload: []byte(`{}`), Status: types.WebhookDeliveryStatusPending, Attempts: 1}
+		err := service.attemptWebhookDelivery(context.Background(), delivery)
+		require.NoError(t, err)
+
+		assert.Equal(t, types.WebhookDeliveryStatusFailed, delivery.Status)
+		assert.Equal(t, 2, delivery.Attempts)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUnitPublicWebhookAddress(t *testing.T) {
+	tests := []struct {
+		ip     string
+		public bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.0.0.5", false},
+		{"172.16.3.4", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"fd00::1", false},
+		{"::ffff:127.0.0.1", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.public, publicWebhookAddress(net.ParseIP(tt.ip)), tt.ip)
+	}
+}
+
+func TestUnitCreateWebhookEndpointRejectsInternalAddresses(t *testing.T) {
+	idGenerator, err := idgen.NewSnowflake(1)
+	require.NoError(t, err)
+
+	mockDB, mock, db := setupMockDB(t)
+	defer mockDB.Close()
+	service := &TransactionService{db: db, idGenerator: idGenerator}
+
+	for _, url := range []string{
+		"http://127.0.0.1:8080/hooks",
+		"http://localhost/hooks",
+		"https://10.1.2.3/hooks",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::1]/hooks",
+	} {
+		err := service.CreateWebhookEndpoint(context.Background(), &models.WebhookEndpoint{URL: url})
+		assert.ErrorIs(t, err, apperr.ErrInvalidArgument, url)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUnitWebhookHTTPClientRefusesInternalAddresses(t *testing.T) {
+	var called bool
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer receiver.Close()
+
+	_, err := newWebhookHTTPClient().Post(receiver.URL, "application/json", nil)
+	assert.ErrorIs(t, err, errWebhookAddress)
+	assert.False(t, called)
+}
+
+func TestUnitEnqueueWebhookDeliveries(t *testing.T) {
+	idGenerator, err := idgen.NewSnowflake(1)
+	require.NoError(t, err)
+
+	cursorColumns := []string{"name", "cursor", "updated_at"}
+	eventColumns := []string{"id", "position", "type", "account_ids", "data", "occurred_at"}
+	endpointColumns := []string{"id", "url", "secret", "event_types", "account_id", "active"}
+
+	t.Run("Enqueues matching endpoints and advances the cursor", func(t *testing.T) {
+		mockDB, mock, db := setupMockDB(t)
+		defer mockDB.Close()
+		service := &TransactionService{db: db, idGenerator: idGenerator, feed: feed.NewStore(db, models.TransactionEventTableName, nil, 0)}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT \* FROM "webhook_dispatch_cursors" WHERE name = \$1 LIMIT \$2 FOR UPDATE SKIP LOCKED`).
+			WithArgs(webhookDispatchCursorName, 1).
+			WillReturnRows(sqlmock.NewRows(cursorColumns).AddRow(webhookDispatchCursorName, "10", time.Now()))
+		mock.ExpectQuery(`SELECT \* FROM "event_feed_horizons"`).
+			WillReturnRows(sqlmock.NewRows([]string{"feed", "position"}))
+		mock.ExpectQuery(`SELECT \* FROM "transaction_events" WHERE position > \$1`).
+			WithArgs(10, webhookFeedBatchSize+1).
+			WillReturnRows(sqlmock.NewRows(eventColumns).
+				AddRow(101, 11, "account.balance_changed", "[1]", `{"account_id":"1"}`, time.Now()).
+				AddRow(102, 12, "account.balance_changed", "[2]", `{"account_id":"2"}`, time.Now()))
+		mock.ExpectQuery(`SELECT \* FROM "webhook_endpoints" WHERE active = \$1`).
+			WillReturnRows(sqlmock.NewRows(endpointColumns).AddRow(7, "https://example.com/hooks", "secret", "[]", 1, true))
+		mock.ExpectQuery(`INSERT INTO "webhook_deliveries"`).
+			WithArgs(7, 101, "account.balance_changed", sqlmock.AnyArg(), "pending", 0, sqlmock.AnyArg(), 0, "", nil, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectExec(`UPDATE "webhook_dispatch_cursors" SET "cursor"=\$1,"updated_at"=\$2 WHERE "name" = \$3`).
+			WithArgs("12", sqlmock.AnyArg(), webhookDispatchCursorName).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		more, err := service.enqueueWebhookDeliveries(context.Background())
+		require.NoError(t, err)
+		assert.False(t, more)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Skips the round while another replica holds the cursor", func(t *testing.T) {
+		mockDB, mock, db := setupMockDB(t)
+		defer mockDB.Close()
+		service := &TransactionService{db: db, idGenerator: idGenerator, feed: feed.NewStore(db, models.TransactionEventTableName, nil, 0)}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT \* FROM "webhook_dispatch_cursors"`).
+			WillReturnRows(sqlmock.NewRows(cursorColumns))
+		mock.ExpectRollback()
+
+		more, err := service.enqueueWebhookDeliveries(context.Background())
+		require.NoError(t, err)
+		assert.False(t, more)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}